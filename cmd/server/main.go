@@ -6,7 +6,9 @@ import (
 
 	"github.com/Maxito7/hotel_backend/internal/application"
 	"github.com/Maxito7/hotel_backend/internal/config"
+	"github.com/Maxito7/hotel_backend/internal/domain"
 	"github.com/Maxito7/hotel_backend/internal/email"
+	"github.com/Maxito7/hotel_backend/internal/infrastructure/canal"
 	"github.com/Maxito7/hotel_backend/internal/infrastructure/repository"
 	handlers "github.com/Maxito7/hotel_backend/internal/interfaces/http"
 	"github.com/Maxito7/hotel_backend/internal/openai"
@@ -45,9 +47,34 @@ func main() {
 		MaxAge:           86400,
 	}))
 
-	// Habitaciones
+	// Habitaciones y disponibilidad
 	habitacionRepo := repository.NewHabitacionRepository(db)
-	habitacionService := application.NewHabitacionService(habitacionRepo)
+	reservaHabitacionRepo := repository.NewReservaHabitacionRepository(db)
+	bloqueGrupoRepo := repository.NewBloqueGrupoRepository(db)
+	restriccionRepo := repository.NewRestriccionEstadiaRepository(db)
+	calendarioExternoRepo := repository.NewCalendarioExternoRepository(db)
+	listaEsperaRepo := repository.NewListaEsperaRepository(db)
+	availabilityService := application.NewAvailabilityService(habitacionRepo, reservaHabitacionRepo, bloqueGrupoRepo, restriccionRepo, calendarioExternoRepo, listaEsperaRepo)
+	restriccionService := application.NewRestriccionService(restriccionRepo, habitacionRepo)
+	restriccionHandler := handlers.NewRestriccionHandler(restriccionService)
+
+	// Calendario de tarifas, planes tarifarios y reglas de precio dinámico: todas las cotizaciones pasan por él
+	servicioRepo := repository.NewServicioRepository(db)
+	tarifaRepo := repository.NewTarifaRepository(db)
+	planTarifaRepo := repository.NewPlanTarifaRepository(db)
+	reglaPrecioRepo := repository.NewReglaPrecioRepository(db)
+	tarifaService := application.NewTarifaService(tarifaRepo, planTarifaRepo, habitacionRepo, servicioRepo, reglaPrecioRepo, availabilityService)
+	tarifaHandler := handlers.NewTarifaHandler(tarifaService)
+
+	// Matriz de inventario por tipo de habitación y noche
+	inventarioService := application.NewInventarioService(repository.NewInventarioRepository(db), habitacionRepo, tarifaService)
+	inventarioHandler := handlers.NewInventarioHandler(inventarioService)
+
+	// Limpieza: tablero de habitaciones, tareas y personal
+	limpiezaService := application.NewLimpiezaService(repository.NewLimpiezaRepository(db))
+	limpiezaHandler := handlers.NewLimpiezaHandler(limpiezaService)
+
+	habitacionService := application.NewHabitacionService(habitacionRepo, availabilityService, tarifaService)
 	habitacionHandler := handlers.NewHabitacionHandler(habitacionService)
 
 	// Search
@@ -56,7 +83,6 @@ func main() {
 	searchHandler := handlers.NewSearchHandler(searchService)
 
 	// Servicios
-	servicioRepo := repository.NewServicioRepository(db)
 	servicioService := application.NewServicioService(servicioRepo)
 	servicioHandler := handlers.NewServicioHandler(servicioService)
 
@@ -92,8 +118,10 @@ func main() {
 	// Reservas (repositorios)
 	paymentRepo := repository.NewPaymentRepository(db)
 	reservaRepo := repository.NewReservaRepository(db)
-	reservaHabitacionRepo := repository.NewReservaHabitacionRepository(db)
 	reservationGuestRepo := repository.NewReservationGuestRepository(db)
+	politicaCancelacionRepo := repository.NewPoliticaCancelacionRepository(db)
+	historialEstadoRepo := repository.NewHistorialEstadoRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
 
 	// Encuestas de satisfacción (crear ANTES de ReservaService)
 	surveyRepo := repository.NewSatisfactionSurveyRepository(db)
@@ -101,16 +129,52 @@ func main() {
 	surveyService := application.NewSatisfactionSurveyService(surveyRepo, reservaRepo, tokenRepo)
 	surveyHandler := handlers.NewSatisfactionSurveyHandler(surveyService)
 
+	// Lista de espera (crear ANTES de ReservaService, que la avisa al liberar inventario)
+	listaEsperaService := application.NewListaEsperaService(listaEsperaRepo, habitacionRepo, availabilityService, emailClient, cfg.FrontendURL, cfg.WaitlistLinkDuration(), tarifaService)
+	listaEsperaHandler := handlers.NewListaEsperaHandler(listaEsperaService)
+
+	// Códigos promocionales de campañas (crear ANTES de ReservaService, que los canjea)
+	codigoPromocionalRepo := repository.NewCodigoPromocionalRepository(db)
+	codigoPromocionalService := application.NewCodigoPromocionalService(codigoPromocionalRepo, habitacionRepo)
+	codigoPromocionalHandler := handlers.NewCodigoPromocionalHandler(codigoPromocionalService)
+
 	// Reservas (servicio - ahora puede usar surveyService)
-	reservaService := application.NewReservaService(reservaRepo, reservaHabitacionRepo, habitacionRepo, personRepo, clientRepo, paymentRepo, reservationGuestRepo, emailClient, surveyService)
-	reservaHandler := handlers.NewReservaHandler(reservaService)
+	reservaService := application.NewReservaService(reservaRepo, reservaHabitacionRepo, habitacionRepo, personRepo, clientRepo, paymentRepo, reservationGuestRepo, politicaCancelacionRepo, historialEstadoRepo, servicioRepo, unitOfWork, availabilityService, cfg.ReservationHoldDuration(), emailClient, surveyService, listaEsperaService, bloqueGrupoRepo, tarifaService, codigoPromocionalService)
+	reservaHandler := handlers.NewReservaHandler(reservaService, listaEsperaService)
+
+	// Calendarios iCal
+	calendarioFeedRepo := repository.NewCalendarioFeedRepository(db)
+	calendarioService := application.NewCalendarioService(calendarioFeedRepo, reservaHabitacionRepo, habitacionRepo)
+	calendarioHandler := handlers.NewCalendarioHandler(calendarioService)
+	calendarioExternoService := application.NewCalendarioExternoService(calendarioExternoRepo, habitacionRepo, reservaHabitacionRepo, repository.NewAlertaRepository(db))
+	calendarioExternoHandler := handlers.NewCalendarioExternoHandler(calendarioExternoService)
+
+	// Canales de venta: cada canal se atiende con el adaptador registrado con su código
+	var channelAdapters []domain.ChannelAdapter
+	if cfg.ChannelFileDir != "" {
+		archivoAdapter, err := canal.NewArchivoAdapter("archivo", cfg.ChannelFileDir)
+		if err != nil {
+			log.Fatalf("Error initializing file channel adapter: %v", err)
+		}
+		channelAdapters = append(channelAdapters, archivoAdapter)
+	}
+	canalService := application.NewCanalService(repository.NewCanalRepository(db), habitacionRepo, repository.NewAlertaRepository(db), reservaService, availabilityService, tarifaService, channelAdapters)
+	canalHandler := handlers.NewCanalHandler(canalService)
+
+	// Bloques de grupo
+	bloqueGrupoService := application.NewBloqueGrupoService(bloqueGrupoRepo, habitacionRepo, availabilityService, listaEsperaService)
+	bloqueGrupoHandler := handlers.NewBloqueGrupoHandler(bloqueGrupoService)
+
+	// Políticas de cancelación
+	politicaCancelacionService := application.NewPoliticaCancelacionService(politicaCancelacionRepo)
+	politicaCancelacionHandler := handlers.NewPoliticaCancelacionHandler(politicaCancelacionService)
 
 	// Chatbot Service (después de reservaService porque lo necesita)
-	chatbotService := application.NewChatbotService(chatbotRepo, openaiClient, habitacionRepo, tavilyClient, cfg.HotelLocation, searchService, reservaService, personRepo, clientRepo)
+	chatbotService := application.NewChatbotService(chatbotRepo, openaiClient, habitacionRepo, availabilityService, tavilyClient, cfg.HotelLocation, searchService, reservaService, personRepo, clientRepo, listaEsperaService, tarifaService)
 	chatbotHandler := handlers.NewChatbotHandler(chatbotService)
 
-	// Scheduler para actualizar reservas completadas automáticamente
-	reservationScheduler := scheduler.NewReservationScheduler(reservaRepo)
+	// Scheduler para actualizar reservas completadas y detectar no-shows automáticamente
+	reservationScheduler := scheduler.NewReservationScheduler(reservaRepo, reservaService, cfg.NoShowCutoff(), listaEsperaService, bloqueGrupoRepo, calendarioExternoService, canalService)
 	reservationScheduler.Start()
 
 	// S3
@@ -127,6 +191,9 @@ func main() {
 	habitaciones.Get("/disponibles", habitacionHandler.GetAvailableRooms)
 	habitaciones.Get("/fechas-bloqueadas", habitacionHandler.GetFechasBloqueadas)
 
+	// Rutas de inventario
+	api.Get("/inventario", inventarioHandler.GetInventario)
+
 	// Public amenities list
 	api.Get("/amenities", habitacionHandler.ListAmenities)
 
@@ -143,6 +210,14 @@ func main() {
 	habitaciones.Put("/:id", habitacionHandler.UpdateRoom)
 	habitaciones.Delete("/:id", habitacionHandler.DeleteRoom)
 
+	// Rutas de calendarios iCal (los feeds .ics se leen con ?token=)
+	habitaciones.Get("/:id/calendar.ics", calendarioHandler.ExportarHabitacion)
+	habitaciones.Get("/:id/calendar-feed", calendarioHandler.GetFeedHabitacion)
+	habitaciones.Post("/:id/calendar-feed/rotar", calendarioHandler.RotarTokenHabitacion)
+	habitaciones.Get("/tipos/:id/calendar.ics", calendarioHandler.ExportarTipoHabitacion)
+	habitaciones.Get("/tipos/:id/calendar-feed", calendarioHandler.GetFeedTipoHabitacion)
+	habitaciones.Post("/tipos/:id/calendar-feed/rotar", calendarioHandler.RotarTokenTipoHabitacion)
+
 	// duplicate earlier listing route (kept)
 	habitaciones.Get("/tipos", habitacionHandler.GetRoomTypes)
 
@@ -166,15 +241,119 @@ func main() {
 	// Rutas de reservas
 	reservas := api.Group("/reservas")
 	reservas.Post("/", reservaHandler.CreateReserva)
+	reservas.Get("/lookup", reservaHandler.LookupReserva) // Debe ir antes de /:id
 	reservas.Get("/:id", reservaHandler.GetReservaByID)
+	reservas.Put("/:id", reservaHandler.ModificarReserva)
 	reservas.Get("/cliente/:clienteId", reservaHandler.GetReservasCliente)
 	reservas.Patch("/:id/estado", reservaHandler.UpdateReservaEstado)
+	reservas.Get("/:id/historial", reservaHandler.GetHistorial)
+	reservas.Get("/:id/servicios", reservaHandler.GetServicios)
+	reservas.Post("/:id/servicios", reservaHandler.AgregarServicio)
+	reservas.Delete("/:id/servicios/:servicioId", reservaHandler.QuitarServicio)
 	reservas.Post("/:id/cancelar", reservaHandler.CancelarReserva)
 	reservas.Post("/:id/confirmar", reservaHandler.ConfirmarReserva)
 	reservas.Post("/:id/confirmar-pago", reservaHandler.ConfirmarPago) // NUEVO: Confirma pago y envía email
+	reservas.Post("/:id/check-in", reservaHandler.CheckIn)
+	reservas.Post("/:id/check-out", reservaHandler.CheckOut)
 	reservas.Post("/verificar-disponibilidad", reservaHandler.VerificarDisponibilidad)
 	reservas.Get("/rango", reservaHandler.GetReservasEnRango)
 
+	// Rutas de bloques de grupo
+	bloques := api.Group("/bloques-grupo")
+	bloques.Get("/", bloqueGrupoHandler.GetAll)
+	bloques.Post("/", bloqueGrupoHandler.Create)
+	bloques.Get("/:id", bloqueGrupoHandler.GetByID)
+	bloques.Put("/:id", bloqueGrupoHandler.Update)
+	bloques.Post("/:id/liberar", bloqueGrupoHandler.Liberar)
+
+	// Rutas de lista de espera
+	listaEspera := api.Group("/lista-espera")
+	listaEspera.Post("/", listaEsperaHandler.Inscribir)
+	listaEspera.Get("/enlace/:token", listaEsperaHandler.ValidarEnlace)
+	listaEspera.Get("/:id", listaEsperaHandler.GetByID)
+	listaEspera.Delete("/:id", listaEsperaHandler.Cancelar)
+
+	// Rutas de calendario de tarifas
+	tarifas := api.Group("/tarifas")
+	tarifas.Get("/cotizacion", tarifaHandler.Cotizar)
+	tarifas.Get("/temporadas", tarifaHandler.GetTemporadas)
+	tarifas.Post("/temporadas", tarifaHandler.CreateTemporada)
+	tarifas.Put("/temporadas/:id", tarifaHandler.UpdateTemporada)
+	tarifas.Delete("/temporadas/:id", tarifaHandler.DeleteTemporada)
+	tarifas.Get("/fechas", tarifaHandler.GetPreciosFecha)
+	tarifas.Put("/fechas", tarifaHandler.GuardarPrecioFecha)
+	tarifas.Delete("/fechas/:tipoHabitacionId/:fecha", tarifaHandler.DeletePrecioFecha)
+	tarifas.Get("/planes", tarifaHandler.GetPlanes)
+	tarifas.Post("/planes", tarifaHandler.CreatePlan)
+	tarifas.Get("/planes/:id", tarifaHandler.GetPlan)
+	tarifas.Put("/planes/:id", tarifaHandler.UpdatePlan)
+	tarifas.Get("/ocupacion/:tipoHabitacionId", tarifaHandler.GetTarifaOcupacion)
+	tarifas.Put("/ocupacion/:tipoHabitacionId", tarifaHandler.GuardarTarifaOcupacion)
+	tarifas.Get("/reglas", tarifaHandler.GetReglasPrecio)
+	tarifas.Post("/reglas", tarifaHandler.CreateReglaPrecio)
+	tarifas.Get("/reglas/simulacion", tarifaHandler.SimularReglasPrecio)
+	tarifas.Get("/reglas/:id", tarifaHandler.GetReglaPrecio)
+	tarifas.Put("/reglas/:id", tarifaHandler.UpdateReglaPrecio)
+	tarifas.Delete("/reglas/:id", tarifaHandler.DeleteReglaPrecio)
+
+	// Rutas de restricciones de estadía
+	restricciones := api.Group("/restricciones")
+	restricciones.Get("/", restriccionHandler.GetAll)
+	restricciones.Post("/", restriccionHandler.Create)
+	restricciones.Get("/:id", restriccionHandler.GetByID)
+	restricciones.Put("/:id", restriccionHandler.Update)
+	restricciones.Delete("/:id", restriccionHandler.Delete)
+
+	// Rutas de calendarios externos (iCal de otras plataformas que bloquean habitaciones)
+	calendariosExternos := api.Group("/calendarios-externos")
+	calendariosExternos.Get("/", calendarioExternoHandler.GetAll)
+	calendariosExternos.Post("/", calendarioExternoHandler.Create)
+	calendariosExternos.Post("/archivo", calendarioExternoHandler.CreateArchivo)
+	calendariosExternos.Get("/conflictos", calendarioExternoHandler.GetConflictos)
+	calendariosExternos.Get("/:id", calendarioExternoHandler.GetByID)
+	calendariosExternos.Put("/:id", calendarioExternoHandler.Update)
+	calendariosExternos.Delete("/:id", calendarioExternoHandler.Delete)
+	calendariosExternos.Post("/:id/archivo", calendarioExternoHandler.SubirArchivo)
+	calendariosExternos.Post("/:id/sincronizar", calendarioExternoHandler.Sincronizar)
+
+	// Rutas de canales de venta (OTAs y channel managers)
+	canales := api.Group("/canales")
+	canales.Get("/", canalHandler.GetAll)
+	canales.Post("/", canalHandler.Create)
+	canales.Get("/:id", canalHandler.GetByID)
+	canales.Put("/:id", canalHandler.Update)
+	canales.Put("/:id/mapeos", canalHandler.UpdateMapeos)
+	canales.Post("/:id/sincronizar", canalHandler.Sincronizar)
+
+	// Rutas de limpieza (housekeeping)
+	limpieza := api.Group("/limpieza")
+	limpieza.Get("/tablero", limpiezaHandler.GetTablero)
+	limpieza.Put("/habitaciones/:id/estado", limpiezaHandler.CambiarEstadoHabitacion)
+	limpieza.Get("/tareas", limpiezaHandler.GetTareas)
+	limpieza.Post("/tareas", limpiezaHandler.CreateTarea)
+	limpieza.Get("/tareas/:id", limpiezaHandler.GetTareaByID)
+	limpieza.Put("/tareas/:id/asignar", limpiezaHandler.AsignarTarea)
+	limpieza.Post("/tareas/:id/iniciar", limpiezaHandler.IniciarTarea)
+	limpieza.Post("/tareas/:id/completar", limpiezaHandler.CompletarTarea)
+	limpieza.Post("/tareas/:id/cancelar", limpiezaHandler.CancelarTarea)
+	limpieza.Get("/personal", limpiezaHandler.GetPersonal)
+	limpieza.Post("/personal", limpiezaHandler.CreatePersonal)
+	limpieza.Put("/personal/:id", limpiezaHandler.UpdatePersonal)
+
+	// Rutas de códigos promocionales
+	promociones := api.Group("/promociones")
+	promociones.Get("/codigos", codigoPromocionalHandler.GetAll)
+	promociones.Post("/codigos", codigoPromocionalHandler.Create)
+	promociones.Get("/codigos/:id", codigoPromocionalHandler.GetByID)
+	promociones.Put("/codigos/:id", codigoPromocionalHandler.Update)
+	promociones.Get("/campanas/:id/reporte", codigoPromocionalHandler.GetReporteCampana)
+
+	// Rutas de políticas de cancelación
+	politicas := api.Group("/politicas-cancelacion")
+	politicas.Get("/", politicaCancelacionHandler.GetAll)
+	politicas.Post("/", politicaCancelacionHandler.Create)
+	politicas.Put("/:id", politicaCancelacionHandler.Update)
+
 	// Rutas de personas
 	personas := api.Group("/personas")
 	personas.Get("/buscar", personHandler.GetPersonByDocumentNumber)
@@ -0,0 +1,774 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// AvailabilityService es el único punto donde se decide si hay inventario disponible.
+// Reglas:
+//   - Solo las habitaciones con room.status = 'Disponible' se pueden vender.
+//   - Una reserva ocupa sus habitaciones mientras su estado retenga inventario
+//     (ver domain.EstadosQueRetienenInventario); una pendiente solo hasta que vence su retención.
+//   - Los rangos son semiabiertos [entrada, salida): la noche del día de salida
+//     queda libre para un nuevo check-in.
+//   - Las habitaciones apartadas por bloques de grupo activos y aún no recogidas se descuentan
+//     de la venta general; solo las reservas recogidas contra el bloque pueden usarlas.
+//     VerificarDisponibilidad(ParaReserva) comprueba una habitación concreta y no las descuenta.
+//   - Un enlace vigente de la lista de espera aparta una habitación de su tipo y fechas igual que
+//     un bloque de grupo; solo la reserva hecha con ese enlace puede usarla.
+//   - Las restricciones de estadía (estadía mínima/máxima, cerrado a llegadas o salidas, bloqueos)
+//     no cambian el inventario: GetAvailableRooms excluye los tipos restringidos y
+//     VerificarRestricciones explica por qué se rechaza una estadía.
+//   - Los bloqueos importados de calendarios externos activos (habitaciones vendidas en otras
+//     plataformas) ocupan su habitación igual que una reserva.
+//   - Solo se venden las habitaciones habilitadas que no están fuera de servicio
+//     (domain.Habitacion.Vendible), también al verificar una habitación concreta.
+type AvailabilityService struct {
+	habitacionRepo        domain.HabitacionRepository
+	reservaHabitacionRepo domain.ReservaHabitacionRepository
+	bloqueRepo            domain.BloqueGrupoRepository
+	restriccionRepo       domain.RestriccionEstadiaRepository
+	calendarioExternoRepo domain.CalendarioExternoRepository
+	listaEsperaRepo       domain.ListaEsperaRepository
+}
+
+// NewAvailabilityService crea una nueva instancia del servicio de disponibilidad
+func NewAvailabilityService(
+	habitacionRepo domain.HabitacionRepository,
+	reservaHabitacionRepo domain.ReservaHabitacionRepository,
+	bloqueRepo domain.BloqueGrupoRepository,
+	restriccionRepo domain.RestriccionEstadiaRepository,
+	calendarioExternoRepo domain.CalendarioExternoRepository,
+	listaEsperaRepo domain.ListaEsperaRepository,
+) *AvailabilityService {
+	return &AvailabilityService{
+		habitacionRepo:        habitacionRepo,
+		reservaHabitacionRepo: reservaHabitacionRepo,
+		bloqueRepo:            bloqueRepo,
+		restriccionRepo:       restriccionRepo,
+		calendarioExternoRepo: calendarioExternoRepo,
+		listaEsperaRepo:       listaEsperaRepo,
+	}
+}
+
+// VerificarDisponibilidad indica si una habitación vendible está libre para todo el rango dado
+func (s *AvailabilityService) VerificarDisponibilidad(habitacionID int, fechaEntrada, fechaSalida time.Time) (bool, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return false, err
+	}
+
+	if vendible, err := s.habitacionVendible(habitacionID); err != nil || !vendible {
+		return false, err
+	}
+
+	ocupaciones, err := s.getOcupaciones(entrada, salida)
+	if err != nil {
+		return false, err
+	}
+
+	return habitacionLibre(habitacionID, ocupaciones, entrada, salida), nil
+}
+
+// VerificarDisponibilidadParaReserva indica si una habitación vendible está libre para el rango
+// sin considerar las ocupaciones de la propia reserva (usado al modificarla)
+func (s *AvailabilityService) VerificarDisponibilidadParaReserva(reservaID, habitacionID int, fechaEntrada, fechaSalida time.Time) (bool, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return false, err
+	}
+
+	if vendible, err := s.habitacionVendible(habitacionID); err != nil || !vendible {
+		return false, err
+	}
+
+	ocupaciones, err := s.getOcupaciones(entrada, salida)
+	if err != nil {
+		return false, err
+	}
+
+	return habitacionLibre(habitacionID, excluirReserva(ocupaciones, reservaID), entrada, salida), nil
+}
+
+// GetAvailableRooms retorna los tipos de habitación con al menos una habitación libre en el rango
+// cuya estadía admiten las restricciones
+func (s *AvailabilityService) GetAvailableRooms(fechaEntrada, fechaSalida time.Time) ([]domain.TipoHabitacion, error) {
+	disponibles, _, err := s.ConsultarDisponibilidad(fechaEntrada, fechaSalida)
+	return disponibles, err
+}
+
+// ConsultarDisponibilidad separa los tipos de habitación con inventario libre en el rango entre
+// los que se pueden vender y los que rechazan las restricciones de estadía, con sus motivos
+func (s *AvailabilityService) ConsultarDisponibilidad(fechaEntrada, fechaSalida time.Time) ([]domain.TipoHabitacion, []domain.RechazoEstadia, error) {
+	libres, err := s.tiposLibres(fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entrada, salida := soloFecha(fechaEntrada), soloFecha(fechaSalida)
+	restricciones, err := s.restriccionRepo.GetEnRango(entrada, salida)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	disponibles := make([]domain.TipoHabitacion, 0, len(libres))
+	rechazos := make([]domain.RechazoEstadia, 0)
+	for _, tipo := range libres {
+		if motivos := domain.MotivosRechazo(restricciones, tipo.ID, entrada, salida); len(motivos) > 0 {
+			rechazos = append(rechazos, domain.RechazoEstadia{TipoHabitacionID: tipo.ID, Titulo: tipo.Titulo, Motivos: motivos})
+			continue
+		}
+		disponibles = append(disponibles, tipo)
+	}
+
+	return disponibles, rechazos, nil
+}
+
+// VerificarRestricciones retorna domain.ErrEstadiaRestringida con los motivos si las
+// restricciones de estadía no admiten el rango para el tipo de habitación
+func (s *AvailabilityService) VerificarRestricciones(roomTypeID int, fechaEntrada, fechaSalida time.Time) error {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return err
+	}
+
+	restricciones, err := s.restriccionRepo.GetEnRango(entrada, salida)
+	if err != nil {
+		return err
+	}
+
+	return domain.VerificarRestricciones(restricciones, roomTypeID, entrada, salida)
+}
+
+// tiposLibres retorna los tipos de habitación con al menos una habitación libre en el rango
+func (s *AvailabilityService) tiposLibres(fechaEntrada, fechaSalida time.Time) ([]domain.TipoHabitacion, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, err
+	}
+
+	libres, err := s.habitacionesDisponibles(entrada, salida, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tiposLibres := make(map[int]bool)
+	for _, h := range libres {
+		tiposLibres[h.TipoHabitacion.ID] = true
+	}
+
+	tipos, err := s.habitacionRepo.GetRoomTypes()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tipos de habitación: %w", err)
+	}
+
+	disponibles := make([]domain.TipoHabitacion, 0)
+	for _, tipo := range tipos {
+		if tiposLibres[tipo.ID] {
+			disponibles = append(disponibles, tipo)
+		}
+	}
+
+	return disponibles, nil
+}
+
+// FindAvailableRoomByType retorna el ID de una habitación libre del tipo dado para el rango
+func (s *AvailabilityService) FindAvailableRoomByType(roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.findAvailableRoomByType(roomTypeID, entrada, salida, 0)
+}
+
+// FindAvailableRoomByTypeParaReserva busca una habitación libre del tipo dado sin considerar
+// las ocupaciones de la propia reserva (usado al modificarla)
+func (s *AvailabilityService) FindAvailableRoomByTypeParaReserva(reservaID, roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.findAvailableRoomByType(roomTypeID, entrada, salida, reservaID)
+}
+
+// ContarHabitacionesLibres retorna cuántas habitaciones del tipo dado están libres en todo el rango
+func (s *AvailabilityService) ContarHabitacionesLibres(roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.contarDisponibles(roomTypeID, entrada, salida, 0)
+}
+
+// ContarHabitacionesLibresParaBloque es como ContarHabitacionesLibres pero sin descontar lo que
+// aparta el propio bloque (usado al crear o ampliar un bloque de grupo)
+func (s *AvailabilityService) ContarHabitacionesLibresParaBloque(bloqueID, roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.contarDisponibles(roomTypeID, entrada, salida, bloqueID)
+}
+
+// contarDisponibles cuenta las habitaciones del tipo disponibles para la venta en el rango
+func (s *AvailabilityService) contarDisponibles(roomTypeID int, entrada, salida time.Time, bloqueExcluido int) (int, error) {
+	libres, err := s.habitacionesDisponibles(entrada, salida, 0, bloqueExcluido, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	cantidad := 0
+	for _, h := range libres {
+		if h.TipoHabitacion.ID == roomTypeID {
+			cantidad++
+		}
+	}
+
+	return cantidad, nil
+}
+
+// findAvailableRoomByType retorna la primera habitación libre del tipo; reservaExcluida = 0 no excluye ninguna
+func (s *AvailabilityService) findAvailableRoomByType(roomTypeID int, entrada, salida time.Time, reservaExcluida int) (int, error) {
+	libres, err := s.habitacionesDisponibles(entrada, salida, reservaExcluida, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, h := range libres {
+		if h.TipoHabitacion.ID == roomTypeID {
+			return h.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no se encontró habitación disponible del tipo %d", roomTypeID)
+}
+
+// AsignarHabitaciones elige habitaciones distintas y libres para cada solicitud por tipo y cantidad.
+// Una habitación asignada a una solicitud no se reutiliza en otra cuyas fechas se solapen.
+// Si algún tipo no tiene suficientes habitaciones libres no se asigna ninguna. Las solicitudes
+// recogidas contra un bloque de grupo usan las habitaciones que aparta ese bloque, y las hechas
+// con un enlace de la lista de espera, la habitación que aparta ese enlace
+func (s *AvailabilityService) AsignarHabitaciones(solicitudes []domain.SolicitudHabitaciones) ([]domain.ReservaHabitacion, error) {
+	var asignadas []domain.ReservaHabitacion
+	recogidas := make(map[int]int)
+
+	for _, solicitud := range solicitudes {
+		if solicitud.Cantidad < 1 {
+			return nil, fmt.Errorf("la cantidad de habitaciones del tipo %d debe ser mayor a 0", solicitud.TipoHabitacionID)
+		}
+
+		entrada, salida, err := normalizarRango(solicitud.FechaEntrada, solicitud.FechaSalida)
+		if err != nil {
+			return nil, err
+		}
+
+		if solicitud.BloqueGrupoID != 0 {
+			recogidas[solicitud.BloqueGrupoID] += solicitud.Cantidad
+			if err := s.verificarRecogida(solicitud, entrada, salida, recogidas[solicitud.BloqueGrupoID]); err != nil {
+				return nil, err
+			}
+		}
+
+		libres, err := s.habitacionesDisponibles(entrada, salida, 0, solicitud.BloqueGrupoID, solicitud.ListaEsperaID)
+		if err != nil {
+			return nil, err
+		}
+
+		encontradas := 0
+		for _, h := range libres {
+			if encontradas == solicitud.Cantidad {
+				break
+			}
+			if h.TipoHabitacion.ID != solicitud.TipoHabitacionID || yaAsignada(asignadas, h.ID, entrada, salida) {
+				continue
+			}
+
+			asignadas = append(asignadas, domain.ReservaHabitacion{
+				HabitacionID: h.ID,
+				Precio:       solicitud.Precio,
+				FechaEntrada: solicitud.FechaEntrada,
+				FechaSalida:  solicitud.FechaSalida,
+				Estado:       1, // Activa
+			})
+			encontradas++
+		}
+
+		if encontradas < solicitud.Cantidad {
+			return nil, fmt.Errorf("%w: solo hay %d de %d habitaciones del tipo %d libres entre %s y %s",
+				domain.ErrHabitacionNoDisponible,
+				encontradas,
+				solicitud.Cantidad,
+				solicitud.TipoHabitacionID,
+				entrada.Format("2006-01-02"),
+				salida.Format("2006-01-02"),
+			)
+		}
+	}
+
+	return asignadas, nil
+}
+
+// verificarRecogida valida que el bloque de la solicitud esté activo, sea del tipo y las fechas
+// pedidas y tenga cupo para cantidad habitaciones
+func (s *AvailabilityService) verificarRecogida(solicitud domain.SolicitudHabitaciones, entrada, salida time.Time, cantidad int) error {
+	if s.bloqueRepo == nil {
+		return domain.ErrBloqueGrupoNoEncontrado
+	}
+
+	bloque, err := s.bloqueRepo.GetByID(solicitud.BloqueGrupoID)
+	if err != nil {
+		return err
+	}
+
+	if bloque.TipoHabitacionID != solicitud.TipoHabitacionID {
+		return fmt.Errorf("%w: el bloque %s es del tipo de habitación %d", domain.ErrBloqueGrupoSinCupo, bloque.Nombre, bloque.TipoHabitacionID)
+	}
+	if entrada.Before(bloque.FechaEntrada) || salida.After(bloque.FechaSalida) {
+		return fmt.Errorf("%w: las fechas deben estar entre %s y %s", domain.ErrBloqueGrupoSinCupo,
+			bloque.FechaEntrada.Format("2006-01-02"), bloque.FechaSalida.Format("2006-01-02"))
+	}
+	if bloque.Pendientes() < cantidad {
+		return fmt.Errorf("%w: quedan %d de %d habitaciones en el bloque %s",
+			domain.ErrBloqueGrupoSinCupo, bloque.Pendientes(), bloque.Cantidad, bloque.Nombre)
+	}
+
+	return nil
+}
+
+// yaAsignada indica si la habitación ya fue asignada a otra solicitud con fechas solapadas
+func yaAsignada(asignadas []domain.ReservaHabitacion, habitacionID int, entrada, salida time.Time) bool {
+	for _, a := range asignadas {
+		if a.HabitacionID == habitacionID && rangosSeSolapan(a.FechaEntrada, a.FechaSalida, entrada, salida) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDisponibilidadFechas retorna cuántas habitaciones quedan libres cada noche entre desde y hasta (inclusive)
+func (s *AvailabilityService) GetDisponibilidadFechas(desde, hasta time.Time) ([]domain.DisponibilidadFecha, error) {
+	return s.GetDisponibilidadTipoFechas(0, desde, hasta)
+}
+
+// GetDisponibilidadTipoFechas retorna cuántas habitaciones de un tipo (0 = todos) quedan libres
+// cada noche entre desde y hasta (inclusive)
+func (s *AvailabilityService) GetDisponibilidadTipoFechas(roomTypeID int, desde, hasta time.Time) ([]domain.DisponibilidadFecha, error) {
+	desde, hasta = soloFecha(desde), soloFecha(hasta)
+	if hasta.Before(desde) {
+		return nil, fmt.Errorf("la fecha hasta debe ser igual o posterior a la fecha desde")
+	}
+
+	habitaciones, err := s.habitacionesVendibles()
+	if err != nil {
+		return nil, err
+	}
+
+	// La última noche consultada termina el día siguiente a "hasta"
+	ocupaciones, err := s.getOcupaciones(desde, hasta.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	vendibles := make(map[int]bool, len(habitaciones))
+	for _, h := range habitaciones {
+		if roomTypeID == 0 || h.TipoHabitacion.ID == roomTypeID {
+			vendibles[h.ID] = true
+		}
+	}
+
+	activos, err := s.getBloquesActivos(desde, hasta.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	bloques := make([]domain.BloqueGrupo, 0, len(activos))
+	for _, b := range activos {
+		if roomTypeID == 0 || b.TipoHabitacionID == roomTypeID {
+			bloques = append(bloques, b)
+		}
+	}
+
+	vigentes, err := s.getEnlacesVigentes(desde, hasta.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	apartadas := make([]domain.InscripcionListaEspera, 0, len(vigentes))
+	for _, a := range vigentes {
+		if roomTypeID == 0 || a.TipoHabitacionID == roomTypeID {
+			apartadas = append(apartadas, a)
+		}
+	}
+
+	disponibilidad := disponibilidadPorNoche(vendibles, ocupaciones, desde, hasta)
+	return descontarBloquesPorNoche(disponibilidad, bloques, apartadas, time.Now().UTC()), nil
+}
+
+// GetOcupacionFechas retorna la ocupación del hotel cada noche entre desde y hasta (inclusive):
+// las habitaciones vendibles ocupadas o apartadas por bloques y por la lista de espera sobre el
+// total vendible
+func (s *AvailabilityService) GetOcupacionFechas(desde, hasta time.Time) ([]domain.OcupacionFecha, error) {
+	habitaciones, err := s.habitacionesVendibles()
+	if err != nil {
+		return nil, err
+	}
+
+	disponibilidad, err := s.GetDisponibilidadFechas(desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(habitaciones)
+	ocupacion := make([]domain.OcupacionFecha, len(disponibilidad))
+	for i, d := range disponibilidad {
+		ocupacion[i] = domain.OcupacionFecha{
+			Fecha:                d.Fecha,
+			HabitacionesTotales:  total,
+			HabitacionesOcupadas: total - d.Habitaciones,
+		}
+		if total > 0 {
+			ocupacion[i].Porcentaje = math.Round(float64(total-d.Habitaciones)*10000/float64(total)) / 100
+		}
+	}
+
+	return ocupacion, nil
+}
+
+// GetFechasBloqueadas retorna las fechas en que no queda ninguna habitación libre o que un
+// bloqueo de todo el hotel no vende, las fechas cerradas a llegadas o salidas en todo el hotel
+// y las restricciones de estadía vigentes en el rango
+func (s *AvailabilityService) GetFechasBloqueadas(desde, hasta time.Time) (*domain.FechasBloqueadas, error) {
+	disponibilidad, err := s.GetDisponibilidadFechas(desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	restricciones, err := s.restriccionRepo.GetEnRango(soloFecha(desde), soloFecha(hasta))
+	if err != nil {
+		return nil, err
+	}
+
+	fechasBloqueadas := &domain.FechasBloqueadas{
+		FechasNoDisponibles: make([]time.Time, 0),
+		FechasSinLlegada:    make([]time.Time, 0),
+		FechasSinSalida:     make([]time.Time, 0),
+		Restricciones:       restricciones,
+	}
+	for _, d := range disponibilidad {
+		bloqueada, sinLlegada, sinSalida := !d.Disponible, false, false
+		for _, r := range restricciones {
+			if r.TipoHabitacionID != nil || !r.Cubre(d.Fecha) {
+				continue
+			}
+			switch r.Tipo {
+			case domain.RestriccionBloqueo:
+				bloqueada = true
+			case domain.RestriccionCerradoLlegada:
+				sinLlegada = true
+			case domain.RestriccionCerradoSalida:
+				sinSalida = true
+			}
+		}
+
+		if bloqueada {
+			fechasBloqueadas.FechasNoDisponibles = append(fechasBloqueadas.FechasNoDisponibles, d.Fecha)
+		}
+		if sinLlegada {
+			fechasBloqueadas.FechasSinLlegada = append(fechasBloqueadas.FechasSinLlegada, d.Fecha)
+		}
+		if sinSalida {
+			fechasBloqueadas.FechasSinSalida = append(fechasBloqueadas.FechasSinSalida, d.Fecha)
+		}
+	}
+
+	return fechasBloqueadas, nil
+}
+
+// habitacionesLibres retorna las habitaciones vendibles sin ocupaciones en [entrada, salida),
+// ignorando las de reservaExcluida (0 no excluye ninguna)
+func (s *AvailabilityService) habitacionesLibres(entrada, salida time.Time, reservaExcluida int) ([]domain.Habitacion, error) {
+	habitaciones, err := s.habitacionesVendibles()
+	if err != nil {
+		return nil, err
+	}
+
+	ocupaciones, err := s.getOcupaciones(entrada, salida)
+	if err != nil {
+		return nil, err
+	}
+	ocupaciones = excluirReserva(ocupaciones, reservaExcluida)
+
+	var libres []domain.Habitacion
+	for _, h := range habitaciones {
+		if habitacionLibre(h.ID, ocupaciones, entrada, salida) {
+			libres = append(libres, h)
+		}
+	}
+
+	return libres, nil
+}
+
+// habitacionesDisponibles retorna las habitaciones libres que se pueden vender en el rango:
+// descuenta, por tipo, las apartadas por bloques de grupo salvo las de bloqueExcluido y las
+// apartadas por enlaces de la lista de espera salvo la de inscripcionExcluida
+func (s *AvailabilityService) habitacionesDisponibles(entrada, salida time.Time, reservaExcluida, bloqueExcluido, inscripcionExcluida int) ([]domain.Habitacion, error) {
+	libres, err := s.habitacionesLibres(entrada, salida, reservaExcluida)
+	if err != nil {
+		return nil, err
+	}
+
+	bloques, err := s.getBloquesActivos(entrada, salida)
+	if err != nil {
+		return nil, err
+	}
+
+	apartadas, err := s.getEnlacesVigentes(entrada, salida)
+	if err != nil {
+		return nil, err
+	}
+
+	retenidas := retencionPorTipo(bloques, apartadas, entrada, salida, bloqueExcluido, inscripcionExcluida, time.Now().UTC())
+	return descontarBloques(libres, retenidas), nil
+}
+
+// getBloquesActivos obtiene los bloques de grupo activos que se solapan con [desde, hasta)
+func (s *AvailabilityService) getBloquesActivos(desde, hasta time.Time) ([]domain.BloqueGrupo, error) {
+	if s.bloqueRepo == nil {
+		return nil, nil
+	}
+
+	bloques, err := s.bloqueRepo.GetActivos(desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener bloques de grupo: %w", err)
+	}
+
+	return bloques, nil
+}
+
+// getEnlacesVigentes obtiene las inscripciones de la lista de espera con enlace vigente que se
+// solapan con [desde, hasta)
+func (s *AvailabilityService) getEnlacesVigentes(desde, hasta time.Time) ([]domain.InscripcionListaEspera, error) {
+	if s.listaEsperaRepo == nil {
+		return nil, nil
+	}
+
+	apartadas, err := s.listaEsperaRepo.GetEnlacesVigentes(desde, hasta, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener enlaces de la lista de espera: %w", err)
+	}
+
+	return apartadas, nil
+}
+
+// retencionPorTipo retorna, por tipo de habitación, cuántas habitaciones apartan los bloques y los
+// enlaces de la lista de espera en la noche de mayor retención del rango, sin contar
+// bloqueExcluido ni inscripcionExcluida
+func retencionPorTipo(bloques []domain.BloqueGrupo, apartadas []domain.InscripcionListaEspera, entrada, salida time.Time, bloqueExcluido, inscripcionExcluida int, ahora time.Time) map[int]int {
+	retenidas := make(map[int]int)
+
+	for noche := entrada; noche.Before(salida); noche = noche.AddDate(0, 0, 1) {
+		porTipo := make(map[int]int)
+		for _, b := range bloques {
+			if b.ID != bloqueExcluido && b.RetieneNoche(noche) {
+				porTipo[b.TipoHabitacionID] += b.Pendientes()
+			}
+		}
+		for _, a := range apartadas {
+			if a.ID != inscripcionExcluida && a.ApartaNoche(noche, ahora) {
+				porTipo[a.TipoHabitacionID]++
+			}
+		}
+		for tipo, cantidad := range porTipo {
+			if cantidad > retenidas[tipo] {
+				retenidas[tipo] = cantidad
+			}
+		}
+	}
+
+	return retenidas
+}
+
+// descontarBloques quita de las habitaciones libres, por tipo, las apartadas por bloques
+func descontarBloques(libres []domain.Habitacion, retenidas map[int]int) []domain.Habitacion {
+	if len(retenidas) == 0 {
+		return libres
+	}
+
+	porTipo := make(map[int]int)
+	for _, h := range libres {
+		porTipo[h.TipoHabitacion.ID]++
+	}
+
+	vendibles := make([]domain.Habitacion, 0, len(libres))
+	tomadas := make(map[int]int)
+	for _, h := range libres {
+		tipo := h.TipoHabitacion.ID
+		if tomadas[tipo] < porTipo[tipo]-retenidas[tipo] {
+			vendibles = append(vendibles, h)
+			tomadas[tipo]++
+		}
+	}
+
+	return vendibles
+}
+
+// descontarBloquesPorNoche resta de cada noche las habitaciones apartadas por bloques y por
+// enlaces de la lista de espera
+func descontarBloquesPorNoche(disponibilidad []domain.DisponibilidadFecha, bloques []domain.BloqueGrupo, apartadas []domain.InscripcionListaEspera, ahora time.Time) []domain.DisponibilidadFecha {
+	for i, d := range disponibilidad {
+		for _, b := range bloques {
+			if b.RetieneNoche(d.Fecha) {
+				disponibilidad[i].Habitaciones -= b.Pendientes()
+			}
+		}
+		for _, a := range apartadas {
+			if a.ApartaNoche(d.Fecha, ahora) {
+				disponibilidad[i].Habitaciones--
+			}
+		}
+		if disponibilidad[i].Habitaciones < 0 {
+			disponibilidad[i].Habitaciones = 0
+		}
+		disponibilidad[i].Disponible = disponibilidad[i].Habitaciones > 0
+	}
+
+	return disponibilidad
+}
+
+// habitacionVendible indica si el estado de la habitación permite venderla
+func (s *AvailabilityService) habitacionVendible(habitacionID int) (bool, error) {
+	habitacion, err := s.habitacionRepo.GetRoomByID(habitacionID)
+	if err != nil {
+		return false, fmt.Errorf("error al obtener habitación %d: %w", habitacionID, err)
+	}
+	return habitacion.Vendible(), nil
+}
+
+// habitacionesVendibles retorna las habitaciones cuyo estado permite venderlas; las que están
+// fuera de servicio por limpieza o mantenimiento no se ofrecen, las sucias sí
+func (s *AvailabilityService) habitacionesVendibles() ([]domain.Habitacion, error) {
+	habitaciones, err := s.habitacionRepo.GetAllRooms()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener habitaciones: %w", err)
+	}
+
+	var vendibles []domain.Habitacion
+	for _, h := range habitaciones {
+		if h.Vendible() {
+			vendibles = append(vendibles, h)
+		}
+	}
+
+	return vendibles, nil
+}
+
+// getOcupaciones obtiene las ocupaciones del rango, descarta las que no retienen inventario y
+// agrega los bloqueos de calendarios externos
+func (s *AvailabilityService) getOcupaciones(desde, hasta time.Time) ([]domain.Ocupacion, error) {
+	ocupaciones, err := s.reservaHabitacionRepo.GetOcupaciones(desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener ocupaciones: %w", err)
+	}
+
+	ahora := time.Now().UTC()
+	vigentes := make([]domain.Ocupacion, 0, len(ocupaciones))
+	for _, o := range ocupaciones {
+		if o.RetieneInventario(ahora) {
+			vigentes = append(vigentes, o)
+		}
+	}
+
+	bloqueos, err := s.calendarioExternoRepo.GetBloqueosEnRango(desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener bloqueos externos: %w", err)
+	}
+	for _, b := range bloqueos {
+		vigentes = append(vigentes, domain.Ocupacion{
+			HabitacionID:     b.HabitacionID,
+			FechaEntrada:     b.FechaInicio,
+			FechaSalida:      b.FechaFin,
+			BloqueoExternoID: b.ID,
+		})
+	}
+
+	return vigentes, nil
+}
+
+// excluirReserva descarta las ocupaciones de la reserva indicada; reservaID = 0 no descarta ninguna
+func excluirReserva(ocupaciones []domain.Ocupacion, reservaID int) []domain.Ocupacion {
+	if reservaID == 0 {
+		return ocupaciones
+	}
+
+	filtradas := make([]domain.Ocupacion, 0, len(ocupaciones))
+	for _, o := range ocupaciones {
+		if o.ReservaID != reservaID {
+			filtradas = append(filtradas, o)
+		}
+	}
+	return filtradas
+}
+
+// habitacionLibre indica si ninguna ocupación de la habitación se solapa con [entrada, salida)
+func habitacionLibre(habitacionID int, ocupaciones []domain.Ocupacion, entrada, salida time.Time) bool {
+	for _, o := range ocupaciones {
+		if o.HabitacionID == habitacionID && rangosSeSolapan(o.FechaEntrada, o.FechaSalida, entrada, salida) {
+			return false
+		}
+	}
+	return true
+}
+
+// disponibilidadPorNoche cuenta, para cada noche entre desde y hasta, las habitaciones vendibles sin ocupar
+func disponibilidadPorNoche(vendibles map[int]bool, ocupaciones []domain.Ocupacion, desde, hasta time.Time) []domain.DisponibilidadFecha {
+	var resultado []domain.DisponibilidadFecha
+
+	for noche := desde; !noche.After(hasta); noche = noche.AddDate(0, 0, 1) {
+		ocupadas := make(map[int]bool)
+		for _, o := range ocupaciones {
+			if vendibles[o.HabitacionID] && ocupaNoche(o, noche) {
+				ocupadas[o.HabitacionID] = true
+			}
+		}
+
+		libres := len(vendibles) - len(ocupadas)
+		resultado = append(resultado, domain.DisponibilidadFecha{
+			Fecha:        noche,
+			Disponible:   libres > 0,
+			Habitaciones: libres,
+		})
+	}
+
+	return resultado
+}
+
+// rangosSeSolapan compara dos rangos semiabiertos [entrada, salida) a nivel de fecha
+func rangosSeSolapan(entradaA, salidaA, entradaB, salidaB time.Time) bool {
+	return soloFecha(entradaA).Before(soloFecha(salidaB)) && soloFecha(entradaB).Before(soloFecha(salidaA))
+}
+
+// ocupaNoche indica si la ocupación cubre la noche que empieza en la fecha dada
+func ocupaNoche(o domain.Ocupacion, noche time.Time) bool {
+	noche = soloFecha(noche)
+	return !noche.Before(soloFecha(o.FechaEntrada)) && noche.Before(soloFecha(o.FechaSalida))
+}
+
+// normalizarRango lleva el rango a fechas puras y valida que la salida sea posterior a la entrada
+func normalizarRango(fechaEntrada, fechaSalida time.Time) (time.Time, time.Time, error) {
+	entrada, salida := soloFecha(fechaEntrada), soloFecha(fechaSalida)
+	if !salida.After(entrada) {
+		return entrada, salida, fmt.Errorf("la fecha de salida debe ser posterior a la fecha de entrada")
+	}
+	return entrada, salida, nil
+}
+
+// soloFecha descarta la hora y la zona horaria para comparar días de calendario
+func soloFecha(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
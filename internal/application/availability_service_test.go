@@ -0,0 +1,597 @@
+package application
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// Repositorios en memoria para AvailabilityService y CalendarioExternoService. Embeben la
+// interfaz para no tener que implementar los métodos que los servicios no usan
+
+type habitacionRepoFake struct {
+	domain.HabitacionRepository
+	habitaciones []domain.Habitacion
+}
+
+func (r *habitacionRepoFake) GetAllRooms() ([]domain.Habitacion, error) {
+	return r.habitaciones, nil
+}
+
+func (r *habitacionRepoFake) GetRoomByID(id int) (domain.Habitacion, error) {
+	for _, h := range r.habitaciones {
+		if h.ID == id {
+			return h, nil
+		}
+	}
+	return domain.Habitacion{}, fmt.Errorf("habitación %d no encontrada", id)
+}
+
+func (r *habitacionRepoFake) GetRoomTypes() ([]domain.TipoHabitacion, error) {
+	vistos := make(map[int]bool)
+	var tipos []domain.TipoHabitacion
+	for _, h := range r.habitaciones {
+		if !vistos[h.TipoHabitacion.ID] {
+			vistos[h.TipoHabitacion.ID] = true
+			tipos = append(tipos, h.TipoHabitacion)
+		}
+	}
+	return tipos, nil
+}
+
+type reservaHabitacionRepoFake struct {
+	domain.ReservaHabitacionRepository
+	ocupaciones []domain.Ocupacion
+}
+
+func (r *reservaHabitacionRepoFake) GetOcupaciones(desde, hasta time.Time) ([]domain.Ocupacion, error) {
+	var enRango []domain.Ocupacion
+	for _, o := range r.ocupaciones {
+		if rangosSeSolapan(o.FechaEntrada, o.FechaSalida, desde, hasta) {
+			enRango = append(enRango, o)
+		}
+	}
+	return enRango, nil
+}
+
+func (r *reservaHabitacionRepoFake) GetOcupacionesHabitaciones(habitacionIDs []int, desde time.Time) ([]domain.Ocupacion, error) {
+	var ocupaciones []domain.Ocupacion
+	for _, o := range r.ocupaciones {
+		for _, id := range habitacionIDs {
+			if o.HabitacionID == id && o.FechaSalida.After(desde) {
+				ocupaciones = append(ocupaciones, o)
+			}
+		}
+	}
+	return ocupaciones, nil
+}
+
+type bloqueGrupoRepoFake struct {
+	domain.BloqueGrupoRepository
+	bloques []domain.BloqueGrupo
+}
+
+func (r *bloqueGrupoRepoFake) GetActivos(desde, hasta time.Time) ([]domain.BloqueGrupo, error) {
+	var activos []domain.BloqueGrupo
+	for _, b := range r.bloques {
+		if b.Estado == domain.BloqueActivo && rangosSeSolapan(b.FechaEntrada, b.FechaSalida, desde, hasta) {
+			activos = append(activos, b)
+		}
+	}
+	return activos, nil
+}
+
+type restriccionRepoFake struct {
+	domain.RestriccionEstadiaRepository
+}
+
+func (r *restriccionRepoFake) GetEnRango(desde, hasta time.Time) ([]domain.RestriccionEstadia, error) {
+	return nil, nil
+}
+
+type calendarioExternoRepoFake struct {
+	domain.CalendarioExternoRepository
+	calendarios []domain.CalendarioExterno
+	bloqueos    []domain.BloqueoExterno
+}
+
+func (r *calendarioExternoRepoFake) GetAll(habitacionID int) ([]domain.CalendarioExterno, error) {
+	var calendarios []domain.CalendarioExterno
+	for _, c := range r.calendarios {
+		if habitacionID == 0 || c.HabitacionID == habitacionID {
+			calendarios = append(calendarios, c)
+		}
+	}
+	return calendarios, nil
+}
+
+func (r *calendarioExternoRepoFake) GetByID(id int) (*domain.CalendarioExterno, error) {
+	for i := range r.calendarios {
+		if r.calendarios[i].ID == id {
+			calendario := r.calendarios[i]
+			return &calendario, nil
+		}
+	}
+	return nil, domain.ErrCalendarioExternoNoEncontrado
+}
+
+func (r *calendarioExternoRepoFake) GetBloqueos(calendarioID int) ([]domain.BloqueoExterno, error) {
+	var bloqueos []domain.BloqueoExterno
+	for _, b := range r.bloqueos {
+		if b.CalendarioExternoID == calendarioID {
+			bloqueos = append(bloqueos, b)
+		}
+	}
+	return bloqueos, nil
+}
+
+func (r *calendarioExternoRepoFake) GuardarSincronizacion(calendarioID int, bloqueos []domain.BloqueoExterno, fecha time.Time) error {
+	conservados := make([]domain.BloqueoExterno, 0, len(r.bloqueos))
+	for _, b := range r.bloqueos {
+		if b.CalendarioExternoID != calendarioID {
+			conservados = append(conservados, b)
+		}
+	}
+	r.bloqueos = append(conservados, bloqueos...)
+	return nil
+}
+
+func (r *calendarioExternoRepoFake) RegistrarErrorSincronizacion(calendarioID int, fecha time.Time, mensaje string) error {
+	for i := range r.calendarios {
+		if r.calendarios[i].ID == calendarioID {
+			r.calendarios[i].UltimoError = mensaje
+		}
+	}
+	return nil
+}
+
+func (r *calendarioExternoRepoFake) GetBloqueosEnRango(desde, hasta time.Time) ([]domain.BloqueoExterno, error) {
+	var enRango []domain.BloqueoExterno
+	for _, b := range r.bloqueos {
+		if rangosSeSolapan(b.FechaInicio, b.FechaFin, desde, hasta) {
+			enRango = append(enRango, b)
+		}
+	}
+	return enRango, nil
+}
+
+type listaEsperaRepoFake struct {
+	domain.ListaEsperaRepository
+	inscripciones []domain.InscripcionListaEspera
+}
+
+func (r *listaEsperaRepoFake) GetEnlacesVigentes(desde, hasta, ahora time.Time) ([]domain.InscripcionListaEspera, error) {
+	var vigentes []domain.InscripcionListaEspera
+	for _, i := range r.inscripciones {
+		if i.Estado == domain.ListaEsperaNotificada && i.VenceEnlace != nil && ahora.Before(*i.VenceEnlace) &&
+			rangosSeSolapan(i.FechaEntrada, i.FechaSalida, desde, hasta) {
+			vigentes = append(vigentes, i)
+		}
+	}
+	return vigentes, nil
+}
+
+func dia(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func habitacionDePrueba(id, tipoID int) domain.Habitacion {
+	return domain.Habitacion{
+		ID:             id,
+		Numero:         strconv.Itoa(100 + id),
+		Estado:         domain.EstadoHabitacionDisponible,
+		TipoHabitacion: domain.TipoHabitacion{ID: tipoID, Titulo: "Tipo"},
+		EstadoLimpieza: domain.HabitacionLimpia,
+	}
+}
+
+func nuevoAvailabilityServiceDePrueba(habitaciones []domain.Habitacion, ocupaciones []domain.Ocupacion, bloques []domain.BloqueGrupo, bloqueos []domain.BloqueoExterno) *AvailabilityService {
+	return NewAvailabilityService(
+		&habitacionRepoFake{habitaciones: habitaciones},
+		&reservaHabitacionRepoFake{ocupaciones: ocupaciones},
+		&bloqueGrupoRepoFake{bloques: bloques},
+		&restriccionRepoFake{},
+		&calendarioExternoRepoFake{bloqueos: bloqueos},
+		nil,
+	)
+}
+
+func TestVerificarDisponibilidadEstados(t *testing.T) {
+	ahora := time.Now().UTC()
+	vigente := ahora.Add(time.Hour)
+	vencida := ahora.Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		estado         domain.EstadoReserva
+		venceRetencion *time.Time
+		libre          bool
+	}{
+		{"pendiente con retención vigente", domain.ReservaPendiente, &vigente, false},
+		{"pendiente sin plazo", domain.ReservaPendiente, nil, false},
+		{"pendiente con retención vencida", domain.ReservaPendiente, &vencida, true},
+		{"confirmada", domain.ReservaConfirmada, nil, false},
+		{"en curso", domain.ReservaEnCurso, nil, false},
+		{"cancelada", domain.ReservaCancelada, nil, true},
+		{"no show", domain.ReservaNoShow, nil, true},
+		{"completada", domain.ReservaCompletada, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := nuevoAvailabilityServiceDePrueba(
+				[]domain.Habitacion{habitacionDePrueba(1, 1)},
+				[]domain.Ocupacion{{
+					HabitacionID:   1,
+					ReservaID:      10,
+					EstadoReserva:  tt.estado,
+					FechaEntrada:   dia("2030-03-10"),
+					FechaSalida:    dia("2030-03-13"),
+					VenceRetencion: tt.venceRetencion,
+				}},
+				nil, nil,
+			)
+
+			libre, err := s.VerificarDisponibilidad(1, dia("2030-03-11"), dia("2030-03-12"))
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if libre != tt.libre {
+				t.Errorf("libre = %v, se esperaba %v", libre, tt.libre)
+			}
+		})
+	}
+}
+
+func TestVerificarDisponibilidadRangos(t *testing.T) {
+	// La habitación 1 está ocupada las noches del 10, 11 y 12; sale el 13
+	ocupaciones := []domain.Ocupacion{{
+		HabitacionID:  1,
+		ReservaID:     10,
+		EstadoReserva: domain.ReservaConfirmada,
+		FechaEntrada:  dia("2030-03-10"),
+		FechaSalida:   dia("2030-03-13"),
+	}}
+
+	tests := []struct {
+		name    string
+		entrada string
+		salida  string
+		libre   bool
+	}{
+		{"llega el día de salida", "2030-03-13", "2030-03-15", true},
+		{"sale el día de llegada", "2030-03-08", "2030-03-10", true},
+		{"se solapa con la última noche", "2030-03-12", "2030-03-14", false},
+		{"se solapa con la primera noche", "2030-03-09", "2030-03-11", false},
+		{"contiene la estadía", "2030-03-09", "2030-03-14", false},
+		{"contenida en la estadía", "2030-03-11", "2030-03-12", false},
+		{"la hora no cambia el día", "2030-03-13T08:00:00Z", "2030-03-14T11:00:00Z", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := nuevoAvailabilityServiceDePrueba([]domain.Habitacion{habitacionDePrueba(1, 1)}, ocupaciones, nil, nil)
+
+			entrada, salida := parseFechaPrueba(t, tt.entrada), parseFechaPrueba(t, tt.salida)
+			libre, err := s.VerificarDisponibilidad(1, entrada, salida)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if libre != tt.libre {
+				t.Errorf("libre = %v, se esperaba %v", libre, tt.libre)
+			}
+		})
+	}
+}
+
+func TestVerificarDisponibilidadHabitacionNoVendible(t *testing.T) {
+	tests := []struct {
+		name       string
+		estado     string
+		limpieza   domain.EstadoLimpieza
+		disponible bool
+	}{
+		{"habilitada y limpia", domain.EstadoHabitacionDisponible, domain.HabitacionLimpia, true},
+		{"fuera de servicio", domain.EstadoHabitacionDisponible, domain.HabitacionFueraDeServicio, false},
+		{"deshabilitada", "Mantenimiento", domain.HabitacionLimpia, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habitacion := habitacionDePrueba(1, 1)
+			habitacion.Estado = tt.estado
+			habitacion.EstadoLimpieza = tt.limpieza
+			s := nuevoAvailabilityServiceDePrueba([]domain.Habitacion{habitacion}, nil, nil, nil)
+
+			libre, err := s.VerificarDisponibilidad(1, dia("2030-03-10"), dia("2030-03-12"))
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if libre != tt.disponible {
+				t.Errorf("VerificarDisponibilidad = %v, se esperaba %v", libre, tt.disponible)
+			}
+
+			libre, err = s.VerificarDisponibilidadParaReserva(10, 1, dia("2030-03-10"), dia("2030-03-12"))
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if libre != tt.disponible {
+				t.Errorf("VerificarDisponibilidadParaReserva = %v, se esperaba %v", libre, tt.disponible)
+			}
+		})
+	}
+}
+
+func TestVerificarDisponibilidadRangoInvalido(t *testing.T) {
+	s := nuevoAvailabilityServiceDePrueba([]domain.Habitacion{habitacionDePrueba(1, 1)}, nil, nil, nil)
+
+	if _, err := s.VerificarDisponibilidad(1, dia("2030-03-10"), dia("2030-03-10")); err == nil {
+		t.Error("se esperaba error para un rango sin noches")
+	}
+}
+
+func TestGetDisponibilidadFechasDiaDeSalidaLibre(t *testing.T) {
+	s := nuevoAvailabilityServiceDePrueba(
+		[]domain.Habitacion{habitacionDePrueba(1, 1)},
+		[]domain.Ocupacion{{
+			HabitacionID:  1,
+			ReservaID:     10,
+			EstadoReserva: domain.ReservaConfirmada,
+			FechaEntrada:  dia("2030-03-10"),
+			FechaSalida:   dia("2030-03-12"),
+		}},
+		nil, nil,
+	)
+
+	disponibilidad, err := s.GetDisponibilidadFechas(dia("2030-03-09"), dia("2030-03-12"))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	esperado := map[string]bool{
+		"2030-03-09": true,
+		"2030-03-10": false,
+		"2030-03-11": false,
+		"2030-03-12": true,
+	}
+	if len(disponibilidad) != len(esperado) {
+		t.Fatalf("se obtuvieron %d noches, se esperaban %d", len(disponibilidad), len(esperado))
+	}
+	for _, d := range disponibilidad {
+		if d.Disponible != esperado[d.Fecha.Format("2006-01-02")] {
+			t.Errorf("%s: disponible = %v, se esperaba %v", d.Fecha.Format("2006-01-02"), d.Disponible, !d.Disponible)
+		}
+	}
+}
+
+func TestContarHabitacionesLibresDescuentos(t *testing.T) {
+	habitaciones := []domain.Habitacion{
+		habitacionDePrueba(1, 1),
+		habitacionDePrueba(2, 1),
+		habitacionDePrueba(3, 1),
+		habitacionDePrueba(4, 2),
+	}
+
+	bloque := func(id, tipoID, cantidad, recogidas int, estado domain.EstadoBloqueGrupo) domain.BloqueGrupo {
+		return domain.BloqueGrupo{
+			ID:               id,
+			Nombre:           "Grupo",
+			TipoHabitacionID: tipoID,
+			Cantidad:         cantidad,
+			Recogidas:        recogidas,
+			FechaEntrada:     dia("2030-03-10"),
+			FechaSalida:      dia("2030-03-13"),
+			FechaLiberacion:  dia("2030-03-01"),
+			Estado:           estado,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		bloques    []domain.BloqueGrupo
+		bloqueos   []domain.BloqueoExterno
+		bloqueID   int
+		entrada    string
+		salida     string
+		esperadas  int
+		paraBloque bool
+	}{
+		{
+			name:      "sin bloques ni bloqueos",
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 3,
+		},
+		{
+			name:      "bloque de grupo aparta habitaciones del tipo",
+			bloques:   []domain.BloqueGrupo{bloque(1, 1, 2, 0, domain.BloqueActivo)},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 1,
+		},
+		{
+			name:      "solo se descuentan las habitaciones no recogidas",
+			bloques:   []domain.BloqueGrupo{bloque(1, 1, 2, 1, domain.BloqueActivo)},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 2,
+		},
+		{
+			name:      "un bloque liberado no aparta",
+			bloques:   []domain.BloqueGrupo{bloque(1, 1, 2, 0, domain.BloqueLiberado)},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 3,
+		},
+		{
+			name:      "un bloque de otro tipo no aparta",
+			bloques:   []domain.BloqueGrupo{bloque(1, 2, 1, 0, domain.BloqueActivo)},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 3,
+		},
+		{
+			name:      "el bloque no aparta fuera de sus fechas",
+			bloques:   []domain.BloqueGrupo{bloque(1, 1, 2, 0, domain.BloqueActivo)},
+			entrada:   "2030-03-13",
+			salida:    "2030-03-15",
+			esperadas: 3,
+		},
+		{
+			name:       "el propio bloque no se descuenta",
+			bloques:    []domain.BloqueGrupo{bloque(1, 1, 2, 0, domain.BloqueActivo)},
+			bloqueID:   1,
+			entrada:    "2030-03-10",
+			salida:     "2030-03-12",
+			esperadas:  3,
+			paraBloque: true,
+		},
+		{
+			name: "bloqueo externo ocupa su habitación",
+			bloqueos: []domain.BloqueoExterno{
+				{ID: 1, HabitacionID: 2, FechaInicio: dia("2030-03-11"), FechaFin: dia("2030-03-12")},
+			},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 2,
+		},
+		{
+			name: "bloqueo externo termina el día de llegada",
+			bloqueos: []domain.BloqueoExterno{
+				{ID: 1, HabitacionID: 2, FechaInicio: dia("2030-03-08"), FechaFin: dia("2030-03-10")},
+			},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 3,
+		},
+		{
+			name:    "bloque y bloqueo externo se descuentan juntos",
+			bloques: []domain.BloqueGrupo{bloque(1, 1, 1, 0, domain.BloqueActivo)},
+			bloqueos: []domain.BloqueoExterno{
+				{ID: 1, HabitacionID: 3, FechaInicio: dia("2030-03-10"), FechaFin: dia("2030-03-11")},
+			},
+			entrada:   "2030-03-10",
+			salida:    "2030-03-12",
+			esperadas: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := nuevoAvailabilityServiceDePrueba(habitaciones, nil, tt.bloques, tt.bloqueos)
+
+			var cantidad int
+			var err error
+			if tt.paraBloque {
+				cantidad, err = s.ContarHabitacionesLibresParaBloque(tt.bloqueID, 1, dia(tt.entrada), dia(tt.salida))
+			} else {
+				cantidad, err = s.ContarHabitacionesLibres(1, dia(tt.entrada), dia(tt.salida))
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if cantidad != tt.esperadas {
+				t.Errorf("habitaciones libres = %d, se esperaban %d", cantidad, tt.esperadas)
+			}
+		})
+	}
+}
+
+func TestGetDisponibilidadTipoFechasDescuentaBloques(t *testing.T) {
+	s := nuevoAvailabilityServiceDePrueba(
+		[]domain.Habitacion{habitacionDePrueba(1, 1), habitacionDePrueba(2, 1)},
+		nil,
+		[]domain.BloqueGrupo{{
+			ID:               1,
+			Nombre:           "Grupo",
+			TipoHabitacionID: 1,
+			Cantidad:         1,
+			FechaEntrada:     dia("2030-03-10"),
+			FechaSalida:      dia("2030-03-11"),
+			FechaLiberacion:  dia("2030-03-01"),
+			Estado:           domain.BloqueActivo,
+		}},
+		[]domain.BloqueoExterno{
+			{ID: 1, HabitacionID: 2, FechaInicio: dia("2030-03-10"), FechaFin: dia("2030-03-12")},
+		},
+	)
+
+	disponibilidad, err := s.GetDisponibilidadTipoFechas(1, dia("2030-03-10"), dia("2030-03-12"))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	esperadas := []int{0, 1, 2}
+	for i, d := range disponibilidad {
+		if d.Habitaciones != esperadas[i] {
+			t.Errorf("%s: habitaciones = %d, se esperaban %d", d.Fecha.Format("2006-01-02"), d.Habitaciones, esperadas[i])
+		}
+	}
+}
+
+func parseFechaPrueba(t *testing.T, s string) time.Time {
+	t.Helper()
+	if len(s) == len("2006-01-02") {
+		return dia(s)
+	}
+	f, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("fecha inválida %q: %v", s, err)
+	}
+	return f
+}
+
+func TestEnlaceListaEsperaApartaHabitacion(t *testing.T) {
+	vence := time.Now().UTC().Add(time.Hour)
+	vencido := time.Now().UTC().Add(-time.Hour)
+	inscripcion := func(id int, vence *time.Time) domain.InscripcionListaEspera {
+		return domain.InscripcionListaEspera{
+			ID:               id,
+			TipoHabitacionID: 1,
+			FechaEntrada:     dia("2030-03-10"),
+			FechaSalida:      dia("2030-03-12"),
+			Estado:           domain.ListaEsperaNotificada,
+			VenceEnlace:      vence,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		inscripciones []domain.InscripcionListaEspera
+		listaEsperaID int
+		esperadas     int
+	}{
+		{"enlace vigente aparta una habitación", []domain.InscripcionListaEspera{inscripcion(1, &vence)}, 0, 1},
+		{"enlace vencido no aparta", []domain.InscripcionListaEspera{inscripcion(1, &vencido)}, 0, 2},
+		{"la reserva con el enlace usa la apartada", []domain.InscripcionListaEspera{inscripcion(1, &vence)}, 1, 2},
+		{"otro enlace sigue apartando", []domain.InscripcionListaEspera{inscripcion(1, &vence), inscripcion(2, &vence)}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewAvailabilityService(
+				&habitacionRepoFake{habitaciones: []domain.Habitacion{habitacionDePrueba(1, 1), habitacionDePrueba(2, 1)}},
+				&reservaHabitacionRepoFake{},
+				&bloqueGrupoRepoFake{},
+				&restriccionRepoFake{},
+				&calendarioExternoRepoFake{},
+				&listaEsperaRepoFake{inscripciones: tt.inscripciones},
+			)
+
+			libres, err := s.habitacionesDisponibles(dia("2030-03-10"), dia("2030-03-12"), 0, 0, tt.listaEsperaID)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if len(libres) != tt.esperadas {
+				t.Errorf("habitaciones disponibles = %d, se esperaban %d", len(libres), tt.esperadas)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// BloqueGrupoService gestiona los bloques de habitaciones para grupos (allotments)
+type BloqueGrupoService struct {
+	repo           domain.BloqueGrupoRepository
+	habitacionRepo domain.HabitacionRepository
+	availability   *AvailabilityService
+	listaEspera    *ListaEsperaService
+}
+
+// NewBloqueGrupoService crea una nueva instancia del servicio de bloques de grupo
+func NewBloqueGrupoService(
+	repo domain.BloqueGrupoRepository,
+	habitacionRepo domain.HabitacionRepository,
+	availability *AvailabilityService,
+	listaEspera *ListaEsperaService,
+) *BloqueGrupoService {
+	return &BloqueGrupoService{
+		repo:           repo,
+		habitacionRepo: habitacionRepo,
+		availability:   availability,
+		listaEspera:    listaEspera,
+	}
+}
+
+// GetAll obtiene todos los bloques de grupo
+func (s *BloqueGrupoService) GetAll() ([]domain.BloqueGrupo, error) {
+	return s.repo.GetAll()
+}
+
+// GetByID obtiene un bloque de grupo por su ID
+func (s *BloqueGrupoService) GetByID(id int) (*domain.BloqueGrupo, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create aparta las habitaciones del bloque si hay suficientes disponibles para la venta
+func (s *BloqueGrupoService) Create(bloque *domain.BloqueGrupo) error {
+	normalizarBloque(bloque)
+	if err := bloque.Validar(); err != nil {
+		return err
+	}
+	if bloque.FechaLiberacion.Before(soloFecha(time.Now())) {
+		return fmt.Errorf("%w: la fecha de liberación no puede ser anterior a hoy", domain.ErrBloqueGrupoInvalido)
+	}
+	if _, err := s.habitacionRepo.GetRoomTypeByID(bloque.TipoHabitacionID); err != nil {
+		return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrBloqueGrupoInvalido, bloque.TipoHabitacionID)
+	}
+
+	if err := s.verificarInventario(bloque, bloque.Cantidad); err != nil {
+		return err
+	}
+
+	bloque.Estado = domain.BloqueActivo
+	bloque.Recogidas = 0
+	bloque.FechaCreacion = time.Now().UTC()
+
+	return s.repo.Create(bloque)
+}
+
+// Update modifica un bloque activo. La cantidad no puede quedar por debajo de lo ya recogido
+// y, si se amplía el bloque o sus fechas, debe haber inventario para lo que sigue apartado
+func (s *BloqueGrupoService) Update(bloque *domain.BloqueGrupo) error {
+	actual, err := s.repo.GetByID(bloque.ID)
+	if err != nil {
+		return err
+	}
+	if actual.Estado != domain.BloqueActivo {
+		return fmt.Errorf("%w: el bloque ya fue liberado", domain.ErrBloqueGrupoInvalido)
+	}
+
+	// El tipo de habitación no se puede cambiar: las recogidas ya son de ese tipo
+	bloque.TipoHabitacionID = actual.TipoHabitacionID
+	normalizarBloque(bloque)
+	if err := bloque.Validar(); err != nil {
+		return err
+	}
+	if bloque.Cantidad < actual.Recogidas {
+		return fmt.Errorf("%w: ya se recogieron %d habitaciones del bloque", domain.ErrBloqueGrupoInvalido, actual.Recogidas)
+	}
+
+	if err := s.verificarInventario(bloque, bloque.Cantidad-actual.Recogidas); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(bloque); err != nil {
+		return err
+	}
+
+	bloque.Estado = actual.Estado
+	bloque.Recogidas = actual.Recogidas
+	bloque.FechaCreacion = actual.FechaCreacion
+
+	// Reducir el bloque devuelve habitaciones a la venta general
+	if bloque.Cantidad < actual.Cantidad {
+		s.notificarListaEspera()
+	}
+
+	return nil
+}
+
+// Liberar devuelve a la venta general las habitaciones no recogidas del bloque
+func (s *BloqueGrupoService) Liberar(id int) error {
+	if err := s.repo.Liberar(id, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	s.notificarListaEspera()
+	return nil
+}
+
+// verificarInventario comprueba que haya pendientes habitaciones del tipo del bloque disponibles
+// para la venta en sus fechas, sin contar lo que el propio bloque ya aparta
+func (s *BloqueGrupoService) verificarInventario(bloque *domain.BloqueGrupo, pendientes int) error {
+	libres, err := s.availability.ContarHabitacionesLibresParaBloque(bloque.ID, bloque.TipoHabitacionID, bloque.FechaEntrada, bloque.FechaSalida)
+	if err != nil {
+		return err
+	}
+
+	if libres < pendientes {
+		return fmt.Errorf("%w: solo hay %d habitaciones del tipo %d libres entre %s y %s",
+			domain.ErrHabitacionNoDisponible,
+			libres,
+			bloque.TipoHabitacionID,
+			bloque.FechaEntrada.Format("2006-01-02"),
+			bloque.FechaSalida.Format("2006-01-02"),
+		)
+	}
+
+	return nil
+}
+
+// notificarListaEspera avisa a la lista de espera que se liberó inventario
+func (s *BloqueGrupoService) notificarListaEspera() {
+	if s.listaEspera != nil {
+		s.listaEspera.NotificarEnSegundoPlano()
+	}
+}
+
+// normalizarBloque lleva las fechas del bloque a días de calendario y limpia los textos
+func normalizarBloque(bloque *domain.BloqueGrupo) {
+	bloque.Nombre = strings.TrimSpace(bloque.Nombre)
+	bloque.Contacto = strings.TrimSpace(bloque.Contacto)
+	bloque.FechaEntrada = soloFecha(bloque.FechaEntrada)
+	bloque.FechaSalida = soloFecha(bloque.FechaSalida)
+	bloque.FechaLiberacion = soloFecha(bloque.FechaLiberacion)
+}
@@ -0,0 +1,346 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+const (
+	// timeoutDescargaCalendario es el tiempo máximo para descargar un feed iCal externo
+	timeoutDescargaCalendario = 30 * time.Second
+	// tamanoMaximoCalendario es el tamaño máximo aceptado de un archivo o feed iCal (5 MB)
+	tamanoMaximoCalendario = 5 << 20
+)
+
+// CalendarioExternoService importa calendarios iCal de otras plataformas como bloqueos de
+// habitaciones. AvailabilityService trata esos bloqueos como ocupaciones; los que se solapan
+// con reservas del hotel se reportan como conflictos y generan una alerta para recepción
+type CalendarioExternoService struct {
+	repo                  domain.CalendarioExternoRepository
+	habitacionRepo        domain.HabitacionRepository
+	reservaHabitacionRepo domain.ReservaHabitacionRepository
+	alertaRepo            domain.AlertaRepository
+	client                *http.Client
+}
+
+// NewCalendarioExternoService crea una nueva instancia del servicio de calendarios externos
+func NewCalendarioExternoService(
+	repo domain.CalendarioExternoRepository,
+	habitacionRepo domain.HabitacionRepository,
+	reservaHabitacionRepo domain.ReservaHabitacionRepository,
+	alertaRepo domain.AlertaRepository,
+) *CalendarioExternoService {
+	return &CalendarioExternoService{
+		repo:                  repo,
+		habitacionRepo:        habitacionRepo,
+		reservaHabitacionRepo: reservaHabitacionRepo,
+		alertaRepo:            alertaRepo,
+		client:                &http.Client{Timeout: timeoutDescargaCalendario},
+	}
+}
+
+// GetAll obtiene los calendarios externos de una habitación (0 = todos)
+func (s *CalendarioExternoService) GetAll(habitacionID int) ([]domain.CalendarioExterno, error) {
+	return s.repo.GetAll(habitacionID)
+}
+
+// GetByID obtiene un calendario externo con sus bloqueos importados
+func (s *CalendarioExternoService) GetByID(id int) (*domain.CalendarioExterno, []domain.BloqueoExterno, error) {
+	calendario, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bloqueos, err := s.repo.GetBloqueos(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return calendario, bloqueos, nil
+}
+
+// Create registra un calendario externo (URL o archivo .ics) y, si está activo, lo sincroniza
+// de inmediato
+func (s *CalendarioExternoService) Create(calendario *domain.CalendarioExterno) (*domain.ResultadoSincronizacion, error) {
+	if err := s.preparar(calendario); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(calendario); err != nil {
+		return nil, err
+	}
+	if !calendario.Activo {
+		return nil, nil
+	}
+	return s.sincronizar(*calendario), nil
+}
+
+// Update actualiza un calendario externo y, si está activo, lo vuelve a sincronizar. Si no se
+// indica URL ni archivo se conserva el origen actual
+func (s *CalendarioExternoService) Update(calendario *domain.CalendarioExterno) (*domain.ResultadoSincronizacion, error) {
+	actual, err := s.repo.GetByID(calendario.ID)
+	if err != nil {
+		return nil, err
+	}
+	if calendario.URL == "" && calendario.Contenido == "" {
+		calendario.URL = actual.URL
+		calendario.Contenido = actual.Contenido
+	}
+
+	if err := s.preparar(calendario); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(calendario); err != nil {
+		return nil, err
+	}
+	if !calendario.Activo {
+		return nil, nil
+	}
+	return s.sincronizar(*calendario), nil
+}
+
+// SubirArchivo reemplaza el origen del calendario por un archivo .ics y lo sincroniza
+func (s *CalendarioExternoService) SubirArchivo(id int, contenido string) (*domain.ResultadoSincronizacion, error) {
+	calendario, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	calendario.URL = ""
+	calendario.Contenido = contenido
+	return s.Update(calendario)
+}
+
+// Delete elimina un calendario externo y libera sus bloqueos
+func (s *CalendarioExternoService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// Sincronizar importa de nuevo un calendario externo
+func (s *CalendarioExternoService) Sincronizar(id int) (*domain.ResultadoSincronizacion, error) {
+	calendario, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !calendario.Activo {
+		return nil, fmt.Errorf("%w: el calendario está inactivo", domain.ErrCalendarioExternoInvalido)
+	}
+	return s.sincronizar(*calendario), nil
+}
+
+// SincronizarTodos importa todos los calendarios externos activos (usado por el scheduler)
+func (s *CalendarioExternoService) SincronizarTodos() ([]domain.ResultadoSincronizacion, error) {
+	calendarios, err := s.repo.GetActivos()
+	if err != nil {
+		return nil, err
+	}
+
+	resultados := make([]domain.ResultadoSincronizacion, 0, len(calendarios))
+	for _, calendario := range calendarios {
+		resultados = append(resultados, *s.sincronizar(calendario))
+	}
+	return resultados, nil
+}
+
+// GetConflictos retorna los bloqueos importados que se solapan con reservas del hotel en una
+// habitación (0 = todas)
+func (s *CalendarioExternoService) GetConflictos(habitacionID int) ([]domain.ConflictoCalendario, error) {
+	calendarios, err := s.repo.GetAll(habitacionID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflictos := make([]domain.ConflictoCalendario, 0)
+	for _, calendario := range calendarios {
+		if !calendario.Activo {
+			continue
+		}
+		bloqueos, err := s.repo.GetBloqueos(calendario.ID)
+		if err != nil {
+			return nil, err
+		}
+		encontrados, err := s.conflictos(calendario.HabitacionID, bloqueos)
+		if err != nil {
+			return nil, err
+		}
+		conflictos = append(conflictos, encontrados...)
+	}
+	return conflictos, nil
+}
+
+// sincronizar lee el calendario, reemplaza sus bloqueos y avisa a recepción de los conflictos
+// nuevos. Si la lectura falla se conservan los bloqueos anteriores
+func (s *CalendarioExternoService) sincronizar(calendario domain.CalendarioExterno) *domain.ResultadoSincronizacion {
+	resultado := &domain.ResultadoSincronizacion{
+		CalendarioExternoID: calendario.ID,
+		Conflictos:          make([]domain.ConflictoCalendario, 0),
+	}
+	ahora := time.Now().UTC()
+
+	fallo := func(err error) *domain.ResultadoSincronizacion {
+		resultado.Error = err.Error()
+		if errRegistro := s.repo.RegistrarErrorSincronizacion(calendario.ID, ahora, resultado.Error); errRegistro != nil {
+			log.Printf("Error registrando la sincronización del calendario externo %d: %v", calendario.ID, errRegistro)
+		}
+		return resultado
+	}
+
+	contenido := calendario.Contenido
+	if calendario.URL != "" {
+		descargado, err := s.descargar(calendario.URL)
+		if err != nil {
+			return fallo(err)
+		}
+		contenido = descargado
+	}
+
+	eventos, err := domain.ParsearICal(contenido)
+	if err != nil {
+		return fallo(err)
+	}
+
+	anteriores, err := s.repo.GetBloqueos(calendario.ID)
+	if err != nil {
+		return fallo(err)
+	}
+	previos, err := s.conflictos(calendario.HabitacionID, anteriores)
+	if err != nil {
+		return fallo(err)
+	}
+
+	bloqueos := domain.BloqueosDesdeEventos(calendario, eventos, soloFecha(ahora))
+	if err := s.repo.GuardarSincronizacion(calendario.ID, bloqueos, ahora); err != nil {
+		return fallo(err)
+	}
+	resultado.Bloqueos = len(bloqueos)
+
+	conflictos, err := s.conflictos(calendario.HabitacionID, bloqueos)
+	if err != nil {
+		log.Printf("Error buscando conflictos del calendario externo %d: %v", calendario.ID, err)
+		return resultado
+	}
+	resultado.Conflictos = conflictos
+
+	yaAvisados := make(map[string]bool, len(previos))
+	for _, c := range previos {
+		yaAvisados[claveConflicto(c)] = true
+	}
+	for _, c := range conflictos {
+		if yaAvisados[claveConflicto(c)] {
+			continue
+		}
+		if err := s.alertarConflicto(calendario, c); err != nil {
+			log.Printf("Error registrando alerta de conflicto del calendario externo %d: %v", calendario.ID, err)
+		}
+	}
+
+	return resultado
+}
+
+// conflictos retorna los bloqueos que se solapan con reservas de la habitación que retienen
+// inventario
+func (s *CalendarioExternoService) conflictos(habitacionID int, bloqueos []domain.BloqueoExterno) ([]domain.ConflictoCalendario, error) {
+	conflictos := make([]domain.ConflictoCalendario, 0)
+	if len(bloqueos) == 0 {
+		return conflictos, nil
+	}
+
+	ahora := time.Now().UTC()
+	ocupaciones, err := s.reservaHabitacionRepo.GetOcupacionesHabitaciones([]int{habitacionID}, soloFecha(ahora))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reservas de la habitación: %w", err)
+	}
+
+	for _, b := range bloqueos {
+		for _, o := range ocupaciones {
+			if !o.RetieneInventario(ahora) || !rangosSeSolapan(b.FechaInicio, b.FechaFin, o.FechaEntrada, o.FechaSalida) {
+				continue
+			}
+			conflictos = append(conflictos, domain.ConflictoCalendario{
+				Bloqueo:      b,
+				ReservaID:    o.ReservaID,
+				FechaEntrada: o.FechaEntrada,
+				FechaSalida:  o.FechaSalida,
+			})
+		}
+	}
+	return conflictos, nil
+}
+
+// alertarConflicto deja una alerta para recepción sobre una habitación vendida en dos plataformas
+func (s *CalendarioExternoService) alertarConflicto(calendario domain.CalendarioExterno, conflicto domain.ConflictoCalendario) error {
+	habitacion := fmt.Sprintf("%d", calendario.HabitacionID)
+	if h, err := s.habitacionRepo.GetRoomByID(calendario.HabitacionID); err == nil {
+		habitacion = h.Numero
+	}
+
+	return s.alertaRepo.Create(&domain.Alerta{
+		Tipo: domain.AlertaConflictoCalendario,
+		Mensaje: fmt.Sprintf(
+			"Hab. %s ocupada en %q del %s al %s y reservada del %s al %s",
+			habitacion, calendario.Nombre,
+			conflicto.Bloqueo.FechaInicio.Format("02/01/2006"), conflicto.Bloqueo.FechaFin.Format("02/01/2006"),
+			conflicto.FechaEntrada.Format("02/01/2006"), conflicto.FechaSalida.Format("02/01/2006"),
+		),
+		ReservaID: &conflicto.ReservaID,
+		Fecha:     time.Now().UTC(),
+	})
+}
+
+// descargar obtiene el contenido de un feed iCal externo
+func (s *CalendarioExternoService) descargar(url string) (string, error) {
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("error al descargar calendario externo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error al descargar calendario externo: respuesta %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, tamanoMaximoCalendario+1))
+	if err != nil {
+		return "", fmt.Errorf("error al leer calendario externo: %w", err)
+	}
+	if len(body) > tamanoMaximoCalendario {
+		return "", errors.New("el calendario externo supera el tamaño máximo de 5 MB")
+	}
+
+	return string(body), nil
+}
+
+// preparar normaliza y valida un calendario externo antes de guardarlo. Los archivos subidos
+// se validan al guardarlos para no registrar un .ics ilegible
+func (s *CalendarioExternoService) preparar(calendario *domain.CalendarioExterno) error {
+	calendario.Nombre = strings.TrimSpace(calendario.Nombre)
+	calendario.URL = strings.TrimSpace(calendario.URL)
+	if calendario.URL != "" {
+		calendario.Contenido = ""
+	}
+	if err := calendario.Validar(); err != nil {
+		return err
+	}
+	if len(calendario.Contenido) > tamanoMaximoCalendario {
+		return fmt.Errorf("%w: el archivo supera el tamaño máximo de 5 MB", domain.ErrCalendarioExternoInvalido)
+	}
+	if calendario.Contenido != "" {
+		if _, err := domain.ParsearICal(calendario.Contenido); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrCalendarioExternoInvalido, err)
+		}
+	}
+	if _, err := s.habitacionRepo.GetRoomByID(calendario.HabitacionID); err != nil {
+		return fmt.Errorf("%w: habitación %d no encontrada", domain.ErrCalendarioExternoInvalido, calendario.HabitacionID)
+	}
+	return nil
+}
+
+// claveConflicto identifica un conflicto entre un evento externo y una reserva
+func claveConflicto(c domain.ConflictoCalendario) string {
+	return fmt.Sprintf("%s|%d", c.Bloqueo.UID, c.ReservaID)
+}
@@ -0,0 +1,162 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type alertaRepoFake struct {
+	alertas []domain.Alerta
+}
+
+func (r *alertaRepoFake) Create(alerta *domain.Alerta) error {
+	r.alertas = append(r.alertas, *alerta)
+	return nil
+}
+
+// calendarioConEventos arma un calendario externo de la habitación 1 subido como archivo .ics
+func calendarioConEventos(eventos ...domain.EventoCalendario) domain.CalendarioExterno {
+	return domain.CalendarioExterno{
+		ID:           7,
+		HabitacionID: 1,
+		Nombre:       "Airbnb",
+		Contenido:    domain.GenerarICal("Airbnb", eventos, time.Now().UTC()),
+		Activo:       true,
+	}
+}
+
+func TestSincronizarReportaConflictos(t *testing.T) {
+	evento := domain.EventoCalendario{UID: "airbnb-1", Inicio: dia("2030-03-10"), Fin: dia("2030-03-13"), Resumen: "Reserved"}
+
+	tests := []struct {
+		name       string
+		ocupacion  domain.Ocupacion
+		conflictos int
+	}{
+		{
+			name:       "reserva confirmada que se solapa",
+			ocupacion:  domain.Ocupacion{HabitacionID: 1, ReservaID: 20, EstadoReserva: domain.ReservaConfirmada, FechaEntrada: dia("2030-03-12"), FechaSalida: dia("2030-03-15")},
+			conflictos: 1,
+		},
+		{
+			name:       "reserva que sale el día que empieza el bloqueo",
+			ocupacion:  domain.Ocupacion{HabitacionID: 1, ReservaID: 20, EstadoReserva: domain.ReservaConfirmada, FechaEntrada: dia("2030-03-07"), FechaSalida: dia("2030-03-10")},
+			conflictos: 0,
+		},
+		{
+			name:       "reserva cancelada",
+			ocupacion:  domain.Ocupacion{HabitacionID: 1, ReservaID: 20, EstadoReserva: domain.ReservaCancelada, FechaEntrada: dia("2030-03-11"), FechaSalida: dia("2030-03-12")},
+			conflictos: 0,
+		},
+		{
+			name:       "reserva de otra habitación",
+			ocupacion:  domain.Ocupacion{HabitacionID: 2, ReservaID: 20, EstadoReserva: domain.ReservaConfirmada, FechaEntrada: dia("2030-03-11"), FechaSalida: dia("2030-03-12")},
+			conflictos: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &calendarioExternoRepoFake{calendarios: []domain.CalendarioExterno{calendarioConEventos(evento)}}
+			alertas := &alertaRepoFake{}
+			s := NewCalendarioExternoService(
+				repo,
+				&habitacionRepoFake{habitaciones: []domain.Habitacion{habitacionDePrueba(1, 1), habitacionDePrueba(2, 1)}},
+				&reservaHabitacionRepoFake{ocupaciones: []domain.Ocupacion{tt.ocupacion}},
+				alertas,
+			)
+
+			resultado, err := s.Sincronizar(7)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if resultado.Error != "" {
+				t.Fatalf("la sincronización falló: %s", resultado.Error)
+			}
+			if resultado.Bloqueos != 1 {
+				t.Errorf("bloqueos = %d, se esperaba 1", resultado.Bloqueos)
+			}
+			if len(resultado.Conflictos) != tt.conflictos {
+				t.Fatalf("conflictos = %d, se esperaba %d", len(resultado.Conflictos), tt.conflictos)
+			}
+			if len(alertas.alertas) != tt.conflictos {
+				t.Errorf("alertas = %d, se esperaba %d", len(alertas.alertas), tt.conflictos)
+			}
+			if tt.conflictos == 0 {
+				return
+			}
+
+			conflicto := resultado.Conflictos[0]
+			if conflicto.ReservaID != tt.ocupacion.ReservaID || conflicto.Bloqueo.UID != evento.UID {
+				t.Errorf("conflicto = %+v, se esperaba la reserva %d con el evento %s", conflicto, tt.ocupacion.ReservaID, evento.UID)
+			}
+			alerta := alertas.alertas[0]
+			if alerta.Tipo != domain.AlertaConflictoCalendario || alerta.ReservaID == nil || *alerta.ReservaID != tt.ocupacion.ReservaID {
+				t.Errorf("alerta = %+v, se esperaba un conflicto de calendario de la reserva %d", alerta, tt.ocupacion.ReservaID)
+			}
+		})
+	}
+}
+
+func TestSincronizarNoRepiteAlertas(t *testing.T) {
+	evento := domain.EventoCalendario{UID: "airbnb-1", Inicio: dia("2030-03-10"), Fin: dia("2030-03-13")}
+	repo := &calendarioExternoRepoFake{calendarios: []domain.CalendarioExterno{calendarioConEventos(evento)}}
+	alertas := &alertaRepoFake{}
+	s := NewCalendarioExternoService(
+		repo,
+		&habitacionRepoFake{habitaciones: []domain.Habitacion{habitacionDePrueba(1, 1)}},
+		&reservaHabitacionRepoFake{ocupaciones: []domain.Ocupacion{{
+			HabitacionID: 1, ReservaID: 20, EstadoReserva: domain.ReservaConfirmada,
+			FechaEntrada: dia("2030-03-12"), FechaSalida: dia("2030-03-15"),
+		}}},
+		alertas,
+	)
+
+	for i := 0; i < 2; i++ {
+		resultado, err := s.Sincronizar(7)
+		if err != nil {
+			t.Fatalf("error inesperado: %v", err)
+		}
+		if len(resultado.Conflictos) != 1 {
+			t.Fatalf("sincronización %d: conflictos = %d, se esperaba 1", i+1, len(resultado.Conflictos))
+		}
+	}
+	if len(alertas.alertas) != 1 {
+		t.Errorf("alertas = %d, se esperaba 1: el conflicto ya se había avisado", len(alertas.alertas))
+	}
+
+	conflictos, err := s.GetConflictos(1)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(conflictos) != 1 {
+		t.Errorf("GetConflictos = %d conflictos, se esperaba 1", len(conflictos))
+	}
+}
+
+func TestSincronizarConICalInvalidoConservaBloqueos(t *testing.T) {
+	anterior := domain.BloqueoExterno{ID: 1, CalendarioExternoID: 7, HabitacionID: 1, UID: "airbnb-1", FechaInicio: dia("2030-03-10"), FechaFin: dia("2030-03-13")}
+	calendario := calendarioConEventos()
+	calendario.Contenido = "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:sin-inicio\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	repo := &calendarioExternoRepoFake{
+		calendarios: []domain.CalendarioExterno{calendario},
+		bloqueos:    []domain.BloqueoExterno{anterior},
+	}
+	s := NewCalendarioExternoService(repo, &habitacionRepoFake{}, &reservaHabitacionRepoFake{}, &alertaRepoFake{})
+
+	resultado, err := s.Sincronizar(7)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if resultado.Error == "" {
+		t.Fatal("se esperaba un error de sincronización")
+	}
+	if len(repo.bloqueos) != 1 || repo.bloqueos[0] != anterior {
+		t.Errorf("bloqueos = %+v, se esperaba conservar %+v", repo.bloqueos, anterior)
+	}
+	if repo.calendarios[0].UltimoError != resultado.Error {
+		t.Errorf("UltimoError = %q, se esperaba %q", repo.calendarios[0].UltimoError, resultado.Error)
+	}
+}
@@ -0,0 +1,155 @@
+package application
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// diasHistorialCalendario son los días hacia atrás que el feed iCal sigue publicando las
+// estadías ya terminadas
+const diasHistorialCalendario = 30
+
+// CalendarioService publica las ocupaciones de habitaciones y tipos de habitación como feeds
+// iCal protegidos por un token secreto por feed
+type CalendarioService struct {
+	feedRepo              domain.CalendarioFeedRepository
+	reservaHabitacionRepo domain.ReservaHabitacionRepository
+	habitacionRepo        domain.HabitacionRepository
+}
+
+// NewCalendarioService crea una nueva instancia del servicio de calendarios iCal
+func NewCalendarioService(
+	feedRepo domain.CalendarioFeedRepository,
+	reservaHabitacionRepo domain.ReservaHabitacionRepository,
+	habitacionRepo domain.HabitacionRepository,
+) *CalendarioService {
+	return &CalendarioService{
+		feedRepo:              feedRepo,
+		reservaHabitacionRepo: reservaHabitacionRepo,
+		habitacionRepo:        habitacionRepo,
+	}
+}
+
+// GetFeed obtiene el feed de una habitación o tipo de habitación, creándolo con un token
+// nuevo la primera vez
+func (s *CalendarioService) GetFeed(alcance domain.AlcanceCalendario, recursoID int) (*domain.CalendarioFeed, error) {
+	feed, err := s.feedRepo.Get(alcance, recursoID)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, domain.ErrCalendarioNoEncontrado) {
+		return nil, err
+	}
+	return s.RotarToken(alcance, recursoID)
+}
+
+// RotarToken reemplaza el token del feed; las URLs compartidas con el token anterior dejan de
+// funcionar
+func (s *CalendarioService) RotarToken(alcance domain.AlcanceCalendario, recursoID int) (*domain.CalendarioFeed, error) {
+	if _, _, err := s.habitacionesFeed(alcance, recursoID); err != nil {
+		return nil, err
+	}
+
+	token, err := domain.GenerarTokenCalendario()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &domain.CalendarioFeed{Alcance: alcance, RecursoID: recursoID, Token: token}
+	if err := s.feedRepo.Save(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// ExportarICal genera el feed iCal de una habitación o tipo de habitación: un VEVENT por cada
+// habitación ocupada por una reserva que retiene inventario. Retorna ErrTokenCalendarioInvalido
+// si el token no corresponde al feed
+func (s *CalendarioService) ExportarICal(alcance domain.AlcanceCalendario, recursoID int, token string) (string, error) {
+	feed, err := s.feedRepo.Get(alcance, recursoID)
+	if errors.Is(err, domain.ErrCalendarioNoEncontrado) {
+		return "", domain.ErrTokenCalendarioInvalido
+	}
+	if err != nil {
+		return "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(feed.Token), []byte(token)) != 1 {
+		return "", domain.ErrTokenCalendarioInvalido
+	}
+
+	nombre, habitaciones, err := s.habitacionesFeed(alcance, recursoID)
+	if err != nil {
+		return "", err
+	}
+
+	ahora := time.Now().UTC()
+	eventos := make([]domain.EventoCalendario, 0)
+	if len(habitaciones) > 0 {
+		ids := make([]int, 0, len(habitaciones))
+		for id := range habitaciones {
+			ids = append(ids, id)
+		}
+
+		desde := soloFecha(ahora).AddDate(0, 0, -diasHistorialCalendario)
+		ocupaciones, err := s.reservaHabitacionRepo.GetOcupacionesHabitaciones(ids, desde)
+		if err != nil {
+			return "", fmt.Errorf("error al obtener ocupaciones del calendario: %w", err)
+		}
+
+		for _, o := range ocupaciones {
+			if !o.RetieneInventario(ahora) {
+				continue
+			}
+			resumen := "Ocupada"
+			if alcance == domain.CalendarioTipoHabitacion {
+				resumen = fmt.Sprintf("Ocupada - Habitación %s", habitaciones[o.HabitacionID].Numero)
+			}
+			eventos = append(eventos, domain.EventoCalendario{
+				UID:     domain.UIDEventoCalendario(o.ReservaID, o.HabitacionID),
+				Inicio:  soloFecha(o.FechaEntrada),
+				Fin:     soloFecha(o.FechaSalida),
+				Resumen: resumen,
+			})
+		}
+	}
+
+	return domain.GenerarICal(nombre, eventos, ahora), nil
+}
+
+// habitacionesFeed retorna el nombre del calendario y las habitaciones (por ID) que publica
+// el feed. Retorna ErrCalendarioNoEncontrado si la habitación o el tipo no existe
+func (s *CalendarioService) habitacionesFeed(alcance domain.AlcanceCalendario, recursoID int) (string, map[int]domain.Habitacion, error) {
+	habitaciones := make(map[int]domain.Habitacion)
+
+	switch alcance {
+	case domain.CalendarioHabitacion:
+		habitacion, err := s.habitacionRepo.GetRoomByID(recursoID)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: habitación %d no encontrada", domain.ErrCalendarioNoEncontrado, recursoID)
+		}
+		habitaciones[habitacion.ID] = habitacion
+		return fmt.Sprintf("Habitación %s", habitacion.Numero), habitaciones, nil
+
+	case domain.CalendarioTipoHabitacion:
+		tipo, err := s.habitacionRepo.GetRoomTypeByID(recursoID)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrCalendarioNoEncontrado, recursoID)
+		}
+		todas, err := s.habitacionRepo.GetAllRooms()
+		if err != nil {
+			return "", nil, fmt.Errorf("error al obtener habitaciones: %w", err)
+		}
+		for _, h := range todas {
+			if h.TipoHabitacion.ID == recursoID {
+				habitaciones[h.ID] = h
+			}
+		}
+		return tipo.Titulo, habitaciones, nil
+	}
+
+	return "", nil, fmt.Errorf("alcance de calendario desconocido: %s", alcance)
+}
@@ -0,0 +1,438 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+const (
+	// horizonteCanal es la cantidad de noches de inventario y tarifas que se publican en los canales
+	horizonteCanal = 90
+	// intentosCanal es la cantidad de intentos de cada llamada a un canal antes de darla por fallida
+	intentosCanal = 3
+	// esperaReintentoCanal es la espera antes del primer reintento; se duplica en cada intento
+	esperaReintentoCanal = 2 * time.Second
+)
+
+// CanalService orquesta la sincronización con los canales de venta. Cada canal configurado se
+// atiende con el ChannelAdapter registrado con su código: primero se importan las reservas del
+// canal (creándolas o cancelándolas con ReservaService) y se confirman, y después se publican el
+// inventario y las tarifas de los tipos mapeados. Cada llamada al canal se reintenta con espera
+// creciente
+type CanalService struct {
+	repo           domain.CanalRepository
+	habitacionRepo domain.HabitacionRepository
+	alertaRepo     domain.AlertaRepository
+	reservas       *ReservaService
+	availability   *AvailabilityService
+	tarifas        *TarifaService
+	adapters       map[string]domain.ChannelAdapter
+	// esperaReintento es la espera antes del primer reintento de una llamada al canal
+	esperaReintento time.Duration
+}
+
+// NewCanalService crea una nueva instancia del servicio de canales de venta
+func NewCanalService(
+	repo domain.CanalRepository,
+	habitacionRepo domain.HabitacionRepository,
+	alertaRepo domain.AlertaRepository,
+	reservas *ReservaService,
+	availability *AvailabilityService,
+	tarifas *TarifaService,
+	adapters []domain.ChannelAdapter,
+) *CanalService {
+	porCodigo := make(map[string]domain.ChannelAdapter, len(adapters))
+	for _, adapter := range adapters {
+		porCodigo[adapter.Codigo()] = adapter
+	}
+
+	return &CanalService{
+		repo:            repo,
+		habitacionRepo:  habitacionRepo,
+		alertaRepo:      alertaRepo,
+		reservas:        reservas,
+		availability:    availability,
+		tarifas:         tarifas,
+		adapters:        porCodigo,
+		esperaReintento: esperaReintentoCanal,
+	}
+}
+
+// GetAll obtiene todos los canales con sus mapeos
+func (s *CanalService) GetAll() ([]domain.Canal, error) {
+	return s.repo.GetAll()
+}
+
+// GetByID obtiene un canal con sus mapeos
+func (s *CanalService) GetByID(id int) (*domain.Canal, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create registra un canal de venta con sus mapeos
+func (s *CanalService) Create(canal *domain.Canal) error {
+	if err := s.preparar(canal); err != nil {
+		return err
+	}
+	return s.repo.Create(canal)
+}
+
+// Update modifica un canal de venta y reemplaza sus mapeos
+func (s *CanalService) Update(canal *domain.Canal) error {
+	if err := s.preparar(canal); err != nil {
+		return err
+	}
+	return s.repo.Update(canal)
+}
+
+// UpdateMapeos reemplaza los mapeos de tipos de habitación de un canal
+func (s *CanalService) UpdateMapeos(id int, mapeos []domain.MapeoCanal) (*domain.Canal, error) {
+	canal, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	canal.Mapeos = mapeos
+	if err := s.Update(canal); err != nil {
+		return nil, err
+	}
+	return canal, nil
+}
+
+// Sincronizar sincroniza un canal activo
+func (s *CanalService) Sincronizar(id int) (*domain.ResultadoSincronizacionCanal, error) {
+	canal, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canal.Activo {
+		return nil, fmt.Errorf("%w: el canal está inactivo", domain.ErrCanalInvalido)
+	}
+	return s.sincronizar(*canal), nil
+}
+
+// SincronizarTodos sincroniza todos los canales activos (usado por el scheduler)
+func (s *CanalService) SincronizarTodos() ([]domain.ResultadoSincronizacionCanal, error) {
+	canales, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resultados := make([]domain.ResultadoSincronizacionCanal, 0, len(canales))
+	for _, canal := range canales {
+		if canal.Activo {
+			resultados = append(resultados, *s.sincronizar(canal))
+		}
+	}
+	return resultados, nil
+}
+
+// sincronizar importa las reservas del canal y publica su inventario y tarifas. Un fallo
+// detiene la sincronización y queda registrado en el canal; las reservas ya procesadas se
+// conservan y el canal las vuelve a enviar si no recibió la confirmación
+func (s *CanalService) sincronizar(canal domain.Canal) *domain.ResultadoSincronizacionCanal {
+	resultado := &domain.ResultadoSincronizacionCanal{
+		CanalID:            canal.ID,
+		Codigo:             canal.Codigo,
+		ReservasCreadas:    make([]string, 0),
+		ReservasCanceladas: make([]string, 0),
+		Rechazadas:         make([]domain.ConfirmacionCanal, 0),
+	}
+
+	if err := s.ejecutarSincronizacion(canal, resultado); err != nil {
+		resultado.Error = err.Error()
+	}
+
+	if err := s.repo.RegistrarSincronizacion(canal.ID, time.Now().UTC(), resultado.Error); err != nil {
+		log.Printf("Error registrando la sincronización del canal %s: %v", canal.Codigo, err)
+	}
+	return resultado
+}
+
+// ejecutarSincronizacion realiza los pasos de la sincronización y completa el resultado
+func (s *CanalService) ejecutarSincronizacion(canal domain.Canal, resultado *domain.ResultadoSincronizacionCanal) error {
+	adapter, ok := s.adapters[canal.Codigo]
+	if !ok {
+		return fmt.Errorf("no hay un adaptador registrado para el canal %s", canal.Codigo)
+	}
+
+	var pendientes []domain.ReservaCanal
+	err := s.reintentar(canal.Codigo, "obtener reservas", func() error {
+		var err error
+		pendientes, err = adapter.PullReservas()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	confirmaciones := make([]domain.ConfirmacionCanal, 0, len(pendientes))
+	for _, reservaCanal := range pendientes {
+		confirmacion := s.procesarReserva(canal, reservaCanal, resultado)
+		if confirmacion.Error != "" {
+			resultado.Rechazadas = append(resultado.Rechazadas, confirmacion)
+		}
+		confirmaciones = append(confirmaciones, confirmacion)
+	}
+
+	if len(confirmaciones) > 0 {
+		err := s.reintentar(canal.Codigo, "confirmar reservas", func() error {
+			return adapter.AckReservas(confirmaciones)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(canal.Mapeos) == 0 {
+		return nil
+	}
+
+	inventario, tarifas, err := s.inventarioYTarifas(canal)
+	if err != nil {
+		return err
+	}
+
+	err = s.reintentar(canal.Codigo, "publicar inventario", func() error {
+		return adapter.PushInventario(inventario)
+	})
+	if err != nil {
+		return err
+	}
+	resultado.Inventario = len(inventario)
+
+	err = s.reintentar(canal.Codigo, "publicar tarifas", func() error {
+		return adapter.PushTarifas(tarifas)
+	})
+	if err != nil {
+		return err
+	}
+	resultado.Tarifas = len(tarifas)
+
+	return nil
+}
+
+// procesarReserva crea o cancela la reserva del hotel que corresponde a una reserva del canal.
+// Es idempotente: una reserva ya registrada (o ya cancelada) se confirma sin volver a procesarla
+func (s *CanalService) procesarReserva(canal domain.Canal, reservaCanal domain.ReservaCanal, resultado *domain.ResultadoSincronizacionCanal) domain.ConfirmacionCanal {
+	confirmacion := domain.ConfirmacionCanal{Referencia: reservaCanal.Referencia}
+	rechazar := func(err error) domain.ConfirmacionCanal {
+		confirmacion.Error = err.Error()
+		s.alertarRechazo(canal, reservaCanal, err)
+		return confirmacion
+	}
+
+	if strings.TrimSpace(reservaCanal.Referencia) == "" {
+		return rechazar(errors.New("la reserva no tiene referencia"))
+	}
+
+	existente, err := s.reservas.GetReservaCanal(canal.Codigo, reservaCanal.Referencia)
+	if err != nil && !errors.Is(err, domain.ErrReservaNoEncontrada) {
+		return rechazar(err)
+	}
+
+	if reservaCanal.Cancelada {
+		if existente == nil {
+			return rechazar(errors.New("la reserva cancelada no está registrada en el hotel"))
+		}
+		confirmacion.CodigoReserva = existente.Codigo
+		if existente.Estado == domain.ReservaCancelada {
+			return confirmacion
+		}
+		_, err := s.reservas.CancelarReserva(existente.ID, domain.CambioEstado{
+			Actor:  domain.ActorCanal,
+			Motivo: fmt.Sprintf("Cancelada en el canal %s", canal.Nombre),
+		})
+		if err != nil {
+			return rechazar(err)
+		}
+		resultado.ReservasCanceladas = append(resultado.ReservasCanceladas, existente.Codigo)
+		return confirmacion
+	}
+
+	if existente != nil {
+		confirmacion.CodigoReserva = existente.Codigo
+		return confirmacion
+	}
+
+	reserva, person, err := s.construirReserva(canal, reservaCanal)
+	if err != nil {
+		return rechazar(err)
+	}
+	if err := s.reservas.CreateReservaCanal(person, reserva); err != nil {
+		return rechazar(err)
+	}
+
+	confirmacion.CodigoReserva = reserva.Codigo
+	resultado.ReservasCreadas = append(resultado.ReservasCreadas, reserva.Codigo)
+	return confirmacion
+}
+
+// construirReserva traduce una reserva del canal a una reserva del hotel con habitaciones libres
+// del tipo mapeado. Si el canal informa el total, se cobra repartido en partes iguales por noche
+func (s *CanalService) construirReserva(canal domain.Canal, reservaCanal domain.ReservaCanal) (*domain.Reserva, *domain.Person, error) {
+	tipoID, ok := canal.TipoHabitacion(reservaCanal.CodigoHabitacion)
+	if !ok {
+		return nil, nil, fmt.Errorf("el código de habitación %q no está mapeado", reservaCanal.CodigoHabitacion)
+	}
+
+	entrada, salida, err := normalizarRango(reservaCanal.FechaEntrada, reservaCanal.FechaSalida)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cantidad := reservaCanal.Habitaciones
+	if cantidad <= 0 {
+		cantidad = 1
+	}
+
+	solicitud := domain.SolicitudHabitaciones{
+		TipoHabitacionID: tipoID,
+		Cantidad:         cantidad,
+		FechaEntrada:     entrada,
+		FechaSalida:      salida,
+	}
+	if reservaCanal.Total > 0 {
+		noches := int(salida.Sub(entrada).Hours() / 24)
+		solicitud.Precio = math.Round(reservaCanal.Total/float64(noches*cantidad)*100) / 100
+	}
+
+	habitaciones, err := s.reservas.AsignarHabitaciones([]domain.SolicitudHabitaciones{solicitud})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	adultos := reservaCanal.Adultos
+	if adultos <= 0 {
+		adultos = 1
+	}
+
+	huesped := reservaCanal.Huesped
+	documento := strings.TrimSpace(huesped.Documento)
+	if documento == "" {
+		// Los canales no siempre envían el documento; se identifica al titular por la reserva
+		documento = fmt.Sprintf("%s-%s", canal.Codigo, reservaCanal.Referencia)
+	}
+
+	person := &domain.Person{
+		Name:             huesped.Nombre,
+		FirstSurname:     huesped.Apellido,
+		DocumentNumber:   documento,
+		Email:            huesped.Email,
+		Phone1:           huesped.Telefono,
+		ReferenceCountry: huesped.Pais,
+		Active:           true,
+	}
+
+	reserva := &domain.Reserva{
+		CantidadAdultos: adultos,
+		CantidadNinhos:  reservaCanal.Ninhos,
+		Habitaciones:    habitaciones,
+		Subtotal:        reservaCanal.Total,
+		Canal:           canal.Codigo,
+		ReferenciaCanal: reservaCanal.Referencia,
+	}
+
+	return reserva, person, nil
+}
+
+// inventarioYTarifas calcula, para cada tipo mapeado, las habitaciones libres y el precio de
+// cada noche del horizonte de publicación
+func (s *CanalService) inventarioYTarifas(canal domain.Canal) ([]domain.InventarioCanal, []domain.TarifaCanal, error) {
+	desde := soloFecha(time.Now().UTC())
+	hasta := desde.AddDate(0, 0, horizonteCanal)
+
+	var inventario []domain.InventarioCanal
+	var tarifas []domain.TarifaCanal
+	for _, mapeo := range canal.Mapeos {
+		disponibilidad, err := s.availability.GetDisponibilidadTipoFechas(mapeo.TipoHabitacionID, desde, hasta.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, d := range disponibilidad {
+			inventario = append(inventario, domain.InventarioCanal{
+				CodigoHabitacion: mapeo.CodigoExterno,
+				Fecha:            d.Fecha,
+				Disponibles:      d.Habitaciones,
+			})
+		}
+
+		cotizacion, err := s.tarifas.Cotizar(mapeo.TipoHabitacionID, desde, hasta)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, noche := range cotizacion.Noches {
+			tarifas = append(tarifas, domain.TarifaCanal{
+				CodigoHabitacion: mapeo.CodigoExterno,
+				Fecha:            noche.Fecha,
+				Precio:           noche.Precio,
+			})
+		}
+	}
+
+	return inventario, tarifas, nil
+}
+
+// reintentar ejecuta una llamada al canal hasta intentosCanal veces, duplicando la espera entre
+// intentos. Retorna el último error
+func (s *CanalService) reintentar(codigo, operacion string, fn func() error) error {
+	espera := s.esperaReintento
+	var err error
+	for intento := 1; intento <= intentosCanal; intento++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if intento < intentosCanal {
+			log.Printf("Error al %s en el canal %s (intento %d de %d): %v", operacion, codigo, intento, intentosCanal, err)
+			time.Sleep(espera)
+			espera *= 2
+		}
+	}
+	return fmt.Errorf("error al %s en el canal %s: %w", operacion, codigo, err)
+}
+
+// alertarRechazo deja una alerta para recepción sobre una reserva del canal que no se registró
+func (s *CanalService) alertarRechazo(canal domain.Canal, reservaCanal domain.ReservaCanal, motivo error) {
+	err := s.alertaRepo.Create(&domain.Alerta{
+		Tipo: domain.AlertaReservaCanalRechazada,
+		Mensaje: fmt.Sprintf(
+			"Reserva %s de %s rechazada (%s, %s al %s): %v",
+			reservaCanal.Referencia, canal.Nombre, reservaCanal.CodigoHabitacion,
+			reservaCanal.FechaEntrada.Format("02/01/2006"), reservaCanal.FechaSalida.Format("02/01/2006"),
+			motivo,
+		),
+		Fecha: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("Error registrando alerta de reserva rechazada del canal %s: %v", canal.Codigo, err)
+	}
+}
+
+// preparar normaliza y valida un canal antes de guardarlo
+func (s *CanalService) preparar(canal *domain.Canal) error {
+	canal.Codigo = strings.ToLower(strings.TrimSpace(canal.Codigo))
+	canal.Nombre = strings.TrimSpace(canal.Nombre)
+	for i := range canal.Mapeos {
+		canal.Mapeos[i].CodigoExterno = strings.TrimSpace(canal.Mapeos[i].CodigoExterno)
+	}
+	if canal.Mapeos == nil {
+		canal.Mapeos = make([]domain.MapeoCanal, 0)
+	}
+
+	if err := canal.Validar(); err != nil {
+		return err
+	}
+	if _, ok := s.adapters[canal.Codigo]; !ok {
+		return fmt.Errorf("%w: no hay un adaptador registrado para el código %s", domain.ErrCanalInvalido, canal.Codigo)
+	}
+	for _, m := range canal.Mapeos {
+		if _, err := s.habitacionRepo.GetRoomTypeByID(m.TipoHabitacionID); err != nil {
+			return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrCanalInvalido, m.TipoHabitacionID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	canalarchivo "github.com/Maxito7/hotel_backend/internal/infrastructure/canal"
+)
+
+// Repositorios en memoria para sincronizar un canal simulado con archivos (ArchivoAdapter)
+
+type canalRepoFake struct {
+	domain.CanalRepository
+	canal   domain.Canal
+	errores []string
+}
+
+func (r *canalRepoFake) GetByID(id int) (*domain.Canal, error) {
+	if id != r.canal.ID {
+		return nil, domain.ErrCanalNoEncontrado
+	}
+	canal := r.canal
+	return &canal, nil
+}
+
+func (r *canalRepoFake) RegistrarSincronizacion(id int, fecha time.Time, mensaje string) error {
+	r.errores = append(r.errores, mensaje)
+	return nil
+}
+
+type reservaRepoFake struct {
+	domain.ReservaRepository
+	reservas []*domain.Reserva
+}
+
+func (r *reservaRepoFake) GetReservaByID(id int) (*domain.Reserva, error) {
+	for _, reserva := range r.reservas {
+		if reserva.ID == id {
+			copia := *reserva
+			return &copia, nil
+		}
+	}
+	return nil, domain.ErrReservaNoEncontrada
+}
+
+func (r *reservaRepoFake) GetReservaByReferenciaCanal(canal, referencia string) (*domain.Reserva, error) {
+	for _, reserva := range r.reservas {
+		if reserva.Canal == canal && reserva.ReferenciaCanal == referencia {
+			copia := *reserva
+			return &copia, nil
+		}
+	}
+	return nil, domain.ErrReservaNoEncontrada
+}
+
+func (r *reservaRepoFake) UpdateReservaEstado(id int, anterior, estado domain.EstadoReserva) error {
+	for _, reserva := range r.reservas {
+		if reserva.ID == id && reserva.Estado == anterior {
+			reserva.Estado = estado
+			return nil
+		}
+	}
+	return domain.ErrTransicionInvalida
+}
+
+type reservaHabitacionEstadoRepoFake struct {
+	domain.ReservaHabitacionRepository
+	liberadas []int
+}
+
+func (r *reservaHabitacionEstadoRepoFake) UpdateReservaHabitacionEstado(reservaID, habitacionID int, estado int) error {
+	if estado == 0 {
+		r.liberadas = append(r.liberadas, habitacionID)
+	}
+	return nil
+}
+
+type historialEstadoRepoFake struct {
+	domain.HistorialEstadoRepository
+	cambios []domain.HistorialEstadoReserva
+}
+
+func (r *historialEstadoRepoFake) Create(historial *domain.HistorialEstadoReserva) error {
+	r.cambios = append(r.cambios, *historial)
+	return nil
+}
+
+type paymentRepoFake struct {
+	domain.PaymentRepository
+	pagos []domain.Payment
+}
+
+func (r *paymentRepoFake) GetAllByReservationID(reservationID int) ([]domain.Payment, error) {
+	var pagos []domain.Payment
+	for _, p := range r.pagos {
+		if p.ReservationID == reservationID {
+			pagos = append(pagos, p)
+		}
+	}
+	return pagos, nil
+}
+
+func (r *paymentRepoFake) Create(payment *domain.Payment) error {
+	payment.PaymentID = len(r.pagos) + 1
+	r.pagos = append(r.pagos, *payment)
+	return nil
+}
+
+func (r *paymentRepoFake) UpdateStatus(paymentID int, status domain.PaymentStatus) error {
+	for i := range r.pagos {
+		if r.pagos[i].PaymentID == paymentID {
+			r.pagos[i].Status = status
+			return nil
+		}
+	}
+	return fmt.Errorf("pago con ID %d no encontrado", paymentID)
+}
+
+func (r *paymentRepoFake) UpdateAmount(paymentID int, amount float64) error {
+	for i := range r.pagos {
+		if r.pagos[i].PaymentID == paymentID {
+			r.pagos[i].Amount = amount
+			return nil
+		}
+	}
+	return fmt.Errorf("pago con ID %d no encontrado", paymentID)
+}
+
+type politicaRepoFake struct {
+	domain.PoliticaCancelacionRepository
+}
+
+func (r *politicaRepoFake) GetParaTipoHabitacion(tipoHabitacionID int) (*domain.PoliticaCancelacion, error) {
+	return nil, nil
+}
+
+type uowFake struct {
+	repos domain.Repositories
+}
+
+func (u *uowFake) Do(fn func(repos domain.Repositories) error) error {
+	return fn(u.repos)
+}
+
+// adapterContado cuenta las llamadas a PullReservas del adaptador que envuelve
+type adapterContado struct {
+	domain.ChannelAdapter
+	pulls int
+}
+
+func (a *adapterContado) PullReservas() ([]domain.ReservaCanal, error) {
+	a.pulls++
+	return a.ChannelAdapter.PullReservas()
+}
+
+type entornoCanalPrueba struct {
+	servicio     *CanalService
+	adapter      *adapterContado
+	directorio   string
+	canales      *canalRepoFake
+	reservas     *reservaRepoFake
+	habitaciones *reservaHabitacionEstadoRepoFake
+	historial    *historialEstadoRepoFake
+	alertas      *alertaRepoFake
+}
+
+func nuevoEntornoCanalPrueba(t *testing.T, reservas ...*domain.Reserva) *entornoCanalPrueba {
+	t.Helper()
+	directorio := t.TempDir()
+	archivo, err := canalarchivo.NewArchivoAdapter("archivo", directorio)
+	if err != nil {
+		t.Fatalf("error al crear el canal simulado: %v", err)
+	}
+
+	e := &entornoCanalPrueba{
+		adapter:      &adapterContado{ChannelAdapter: archivo},
+		directorio:   directorio,
+		canales:      &canalRepoFake{canal: domain.Canal{ID: 1, Codigo: "archivo", Nombre: "Canal de prueba", Activo: true}},
+		reservas:     &reservaRepoFake{reservas: reservas},
+		habitaciones: &reservaHabitacionEstadoRepoFake{},
+		historial:    &historialEstadoRepoFake{},
+		alertas:      &alertaRepoFake{},
+	}
+
+	habitacionRepo := &habitacionRepoFake{habitaciones: []domain.Habitacion{habitacionDePrueba(1, 1)}}
+	reservaService := NewReservaService(
+		e.reservas, e.habitaciones, habitacionRepo, nil, nil, &paymentRepoFake{}, nil,
+		&politicaRepoFake{}, e.historial, nil,
+		&uowFake{repos: domain.Repositories{
+			Reserva:           e.reservas,
+			ReservaHabitacion: e.habitaciones,
+			HistorialEstado:   e.historial,
+		}},
+		nuevoAvailabilityServiceDePrueba(habitacionRepo.habitaciones, nil, nil, nil),
+		0, nil, nil, nil, nil, nil, nil,
+	)
+
+	e.servicio = NewCanalService(e.canales, habitacionRepo, e.alertas, reservaService, nil, nil, []domain.ChannelAdapter{e.adapter})
+	e.servicio.esperaReintento = 0
+	return e
+}
+
+func (e *entornoCanalPrueba) encolar(t *testing.T, reservas ...domain.ReservaCanal) {
+	t.Helper()
+	contenido, err := json.Marshal(reservas)
+	if err != nil {
+		t.Fatalf("error al codificar reservas: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.directorio, "reservas.json"), contenido, 0o644); err != nil {
+		t.Fatalf("error al escribir reservas.json: %v", err)
+	}
+}
+
+func (e *entornoCanalPrueba) confirmaciones(t *testing.T) []domain.ConfirmacionCanal {
+	t.Helper()
+	contenido, err := os.ReadFile(filepath.Join(e.directorio, "confirmaciones.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("error al leer confirmaciones.json: %v", err)
+	}
+	var confirmaciones []domain.ConfirmacionCanal
+	if err := json.Unmarshal(contenido, &confirmaciones); err != nil {
+		t.Fatalf("error al decodificar confirmaciones.json: %v", err)
+	}
+	return confirmaciones
+}
+
+func (e *entornoCanalPrueba) pendientes(t *testing.T) []domain.ReservaCanal {
+	t.Helper()
+	reservas, err := e.adapter.ChannelAdapter.PullReservas()
+	if err != nil {
+		t.Fatalf("error al leer la cola del canal: %v", err)
+	}
+	return reservas
+}
+
+func reservaDelCanal(referencia string, estado domain.EstadoReserva) *domain.Reserva {
+	return &domain.Reserva{
+		ID:              20,
+		Codigo:          "RES-0020",
+		Estado:          estado,
+		CantidadAdultos: 2,
+		Subtotal:        300,
+		Canal:           "archivo",
+		ReferenciaCanal: referencia,
+		Habitaciones: []domain.ReservaHabitacion{{
+			ReservaID: 20, HabitacionID: 1, Precio: 100, Total: 300, Estado: 1,
+			FechaEntrada: dia("2030-03-10"), FechaSalida: dia("2030-03-13"),
+		}},
+	}
+}
+
+func TestSincronizarCanalAgotaReintentos(t *testing.T) {
+	e := nuevoEntornoCanalPrueba(t)
+	if err := os.WriteFile(filepath.Join(e.directorio, "reservas.json"), []byte("{no es json"), 0o644); err != nil {
+		t.Fatalf("error al escribir reservas.json: %v", err)
+	}
+
+	resultado, err := e.servicio.Sincronizar(1)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	if e.adapter.pulls != intentosCanal {
+		t.Errorf("PullReservas se llamó %d veces, se esperaban %d", e.adapter.pulls, intentosCanal)
+	}
+	if !strings.Contains(resultado.Error, "obtener reservas") {
+		t.Errorf("Error = %q, se esperaba el fallo al obtener reservas", resultado.Error)
+	}
+	if len(e.canales.errores) != 1 || e.canales.errores[0] != resultado.Error {
+		t.Errorf("errores registrados = %q, se esperaba %q", e.canales.errores, resultado.Error)
+	}
+	if len(e.confirmaciones(t)) != 0 {
+		t.Error("no se debía confirmar nada al canal")
+	}
+}
+
+func TestSincronizarCanalReservaYaRegistrada(t *testing.T) {
+	e := nuevoEntornoCanalPrueba(t, reservaDelCanal("BK-1", domain.ReservaConfirmada))
+	e.encolar(t, domain.ReservaCanal{
+		Referencia: "BK-1", CodigoHabitacion: "DBL", Adultos: 2,
+		FechaEntrada: dia("2030-03-10"), FechaSalida: dia("2030-03-13"),
+	})
+
+	// El canal reenvía la reserva cuando no recibió la confirmación: solo se vuelve a confirmar
+	resultado, err := e.servicio.Sincronizar(1)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if resultado.Error != "" {
+		t.Fatalf("la sincronización falló: %s", resultado.Error)
+	}
+	if len(resultado.ReservasCreadas) != 0 || len(resultado.ReservasCanceladas) != 0 || len(resultado.Rechazadas) != 0 {
+		t.Errorf("resultado = %+v, se esperaba solo la confirmación", resultado)
+	}
+
+	confirmaciones := e.confirmaciones(t)
+	esperada := domain.ConfirmacionCanal{Referencia: "BK-1", CodigoReserva: "RES-0020"}
+	if len(confirmaciones) != 1 || confirmaciones[0] != esperada {
+		t.Errorf("confirmaciones = %+v, se esperaba %+v", confirmaciones, esperada)
+	}
+	if len(e.pendientes(t)) != 0 {
+		t.Error("la reserva confirmada debía salir de la cola del canal")
+	}
+	if len(e.historial.cambios) != 0 || len(e.alertas.alertas) != 0 {
+		t.Error("una reserva ya registrada no debía cambiar de estado ni generar alertas")
+	}
+}
+
+func TestSincronizarCanalCancelacion(t *testing.T) {
+	tests := []struct {
+		name      string
+		estado    domain.EstadoReserva
+		cancelada bool
+	}{
+		{"reserva confirmada se cancela", domain.ReservaConfirmada, true},
+		{"reserva ya cancelada solo se confirma", domain.ReservaCancelada, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := nuevoEntornoCanalPrueba(t, reservaDelCanal("BK-1", tt.estado))
+			e.encolar(t, domain.ReservaCanal{
+				Referencia: "BK-1", CodigoHabitacion: "DBL", Cancelada: true,
+				FechaEntrada: dia("2030-03-10"), FechaSalida: dia("2030-03-13"),
+			})
+
+			resultado, err := e.servicio.Sincronizar(1)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if resultado.Error != "" {
+				t.Fatalf("la sincronización falló: %s", resultado.Error)
+			}
+
+			if got := e.reservas.reservas[0].Estado; got != domain.ReservaCancelada {
+				t.Errorf("estado = %s, se esperaba Cancelada", got)
+			}
+			if tt.cancelada {
+				if len(resultado.ReservasCanceladas) != 1 || resultado.ReservasCanceladas[0] != "RES-0020" {
+					t.Errorf("ReservasCanceladas = %v, se esperaba [RES-0020]", resultado.ReservasCanceladas)
+				}
+				if len(e.habitaciones.liberadas) != 1 {
+					t.Errorf("habitaciones liberadas = %v, se esperaba la habitación 1", e.habitaciones.liberadas)
+				}
+				if len(e.historial.cambios) != 1 || e.historial.cambios[0].Actor != domain.ActorCanal {
+					t.Errorf("historial = %+v, se esperaba un cambio registrado por el canal", e.historial.cambios)
+				}
+			} else if len(resultado.ReservasCanceladas) != 0 || len(e.historial.cambios) != 0 {
+				t.Errorf("una reserva ya cancelada no debía procesarse de nuevo")
+			}
+
+			confirmaciones := e.confirmaciones(t)
+			esperada := domain.ConfirmacionCanal{Referencia: "BK-1", CodigoReserva: "RES-0020"}
+			if len(confirmaciones) != 1 || confirmaciones[0] != esperada {
+				t.Errorf("confirmaciones = %+v, se esperaba %+v", confirmaciones, esperada)
+			}
+		})
+	}
+}
+
+func TestSincronizarCanalCodigoHabitacionNoMapeado(t *testing.T) {
+	e := nuevoEntornoCanalPrueba(t)
+	e.encolar(t, domain.ReservaCanal{
+		Referencia: "BK-2", CodigoHabitacion: "SUITE", Adultos: 2,
+		FechaEntrada: dia("2030-03-10"), FechaSalida: dia("2030-03-13"),
+	})
+
+	resultado, err := e.servicio.Sincronizar(1)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if resultado.Error != "" {
+		t.Fatalf("la sincronización falló: %s", resultado.Error)
+	}
+	if len(resultado.ReservasCreadas) != 0 {
+		t.Errorf("ReservasCreadas = %v, no se esperaba ninguna", resultado.ReservasCreadas)
+	}
+	if len(resultado.Rechazadas) != 1 || !strings.Contains(resultado.Rechazadas[0].Error, "no está mapeado") {
+		t.Fatalf("Rechazadas = %+v, se esperaba el código SUITE sin mapear", resultado.Rechazadas)
+	}
+
+	// El rechazo se confirma al canal con el motivo y queda una alerta para recepción
+	confirmaciones := e.confirmaciones(t)
+	if len(confirmaciones) != 1 || confirmaciones[0].Referencia != "BK-2" || confirmaciones[0].Error == "" {
+		t.Errorf("confirmaciones = %+v, se esperaba el rechazo de BK-2", confirmaciones)
+	}
+	if len(e.alertas.alertas) != 1 || e.alertas.alertas[0].Tipo != domain.AlertaReservaCanalRechazada {
+		t.Fatalf("alertas = %+v, se esperaba una alerta de reserva rechazada", e.alertas.alertas)
+	}
+	if !strings.Contains(e.alertas.alertas[0].Mensaje, "BK-2") {
+		t.Errorf("la alerta %q no menciona la reserva BK-2", e.alertas.alertas[0].Mensaje)
+	}
+}
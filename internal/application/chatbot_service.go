@@ -16,34 +16,41 @@ type ChatbotService struct {
 	repo             domain.ChatbotRepository
 	openaiClient     *openai.Client
 	habitacionRepo   domain.HabitacionRepository
+	availability     *AvailabilityService
 	tavilyClient     *tavily.Client
 	searchService    *SearchService
 	location         string
 	reservationTools *ReservationTools
+	tarifas          *TarifaService
 }
 
 func NewChatbotService(
 	repo domain.ChatbotRepository,
 	openaiClient *openai.Client,
 	habitacionRepo domain.HabitacionRepository,
+	availability *AvailabilityService,
 	tavilyClient *tavily.Client,
 	location string,
 	searchService *SearchService,
 	reservaService *ReservaService,
 	personRepo domain.PersonRepository,
 	clientRepo domain.ClientRepository,
+	listaEspera *ListaEsperaService,
+	tarifas *TarifaService,
 ) *ChatbotService {
 	// Crear las herramientas de reserva
-	reservationTools := NewReservationTools(habitacionRepo, reservaService, personRepo, clientRepo)
+	reservationTools := NewReservationTools(habitacionRepo, availability, reservaService, personRepo, clientRepo, listaEspera, tarifas)
 
 	return &ChatbotService{
 		repo:             repo,
 		openaiClient:     openaiClient,
 		habitacionRepo:   habitacionRepo,
+		availability:     availability,
 		tavilyClient:     tavilyClient,
 		searchService:    searchService,
 		location:         location,
 		reservationTools: reservationTools,
+		tarifas:          tarifas,
 	}
 }
 
@@ -420,7 +427,7 @@ func (s *ChatbotService) getHotelInfo(req domain.ChatRequest) (string, error) {
 
 	for titulo, tipo := range tiposMap {
 		info.WriteString(fmt.Sprintf("\n• %s:\n", titulo))
-		info.WriteString(fmt.Sprintf("  - Precio: S/%.2f por noche\n", tipo.Precio))
+		info.WriteString(fmt.Sprintf("  - Tarifa base: S/%.2f por noche (el precio real depende de las fechas)\n", tipo.Precio))
 		info.WriteString(fmt.Sprintf("  - Capacidad: %d adultos, %d niños\n",
 			tipo.CapacidadAdultos, tipo.CapacidadNinhos))
 		info.WriteString(fmt.Sprintf("  - Camas: %d\n", tipo.CantidadCamas))
@@ -430,7 +437,7 @@ func (s *ChatbotService) getHotelInfo(req domain.ChatRequest) (string, error) {
 	fechasInicio := time.Now()
 	fechasFin := fechasInicio.AddDate(0, 0, 30) // Próximos 30 días
 
-	disponibles, err := s.habitacionRepo.GetAvailableRooms(fechasInicio, fechasFin)
+	disponibles, err := s.availability.GetAvailableRooms(fechasInicio, fechasFin)
 	if err == nil {
 		info.WriteString(fmt.Sprintf("Habitaciones disponibles para el próximo mes (%s - %s): %d\n",
 			fechasInicio.Format("2006-01-02"), fechasFin.Format("2006-01-02"), len(disponibles)))
@@ -463,7 +470,7 @@ func (s *ChatbotService) getHotelInfo(req domain.ChatRequest) (string, error) {
 		if err == nil {
 			fechaSalida, err := time.Parse("2006-01-02", *req.Context.FechaSalida)
 			if err == nil {
-				tiposDisponibles, err := s.habitacionRepo.GetAvailableRooms(fechaEntrada, fechaSalida)
+				tiposDisponibles, err := s.availability.GetAvailableRooms(fechaEntrada, fechaSalida)
 				if err == nil {
 					info.WriteString(fmt.Sprintf("\n\nDISPONIBILIDAD PARA %s - %s:\n",
 						*req.Context.FechaEntrada, *req.Context.FechaSalida))
@@ -472,8 +479,14 @@ func (s *ChatbotService) getHotelInfo(req domain.ChatRequest) (string, error) {
 						info.WriteString("❌ No hay habitaciones disponibles para estas fechas.\n")
 					} else {
 						for _, tipo := range tiposDisponibles {
-							info.WriteString(fmt.Sprintf("✅ %s: Disponible (Precio: $%.2f, Capacidad: %d adultos + %d niños)\n",
-								tipo.Titulo, tipo.Precio, tipo.CapacidadAdultos, tipo.CapacidadNinhos))
+							// El precio de la estadía sale del calendario de tarifas, igual que al reservar
+							cotizacion, err := s.tarifas.Cotizar(tipo.ID, fechaEntrada, fechaSalida)
+							if err != nil {
+								fmt.Printf("Warning: no se pudo cotizar %s: %v\n", tipo.Titulo, err)
+								continue
+							}
+							info.WriteString(fmt.Sprintf("✅ %s: Disponible (Precio: S/%.2f en total, promedio S/%.2f por noche, Capacidad: %d adultos + %d niños)\n",
+								tipo.Titulo, cotizacion.Total, cotizacion.PrecioPromedio(), tipo.CapacidadAdultos, tipo.CapacidadNinhos))
 						}
 					}
 				}
@@ -22,22 +22,31 @@ type Tool struct {
 // ReservationTools contiene todas las herramientas relacionadas con reservas
 type ReservationTools struct {
 	habitacionRepo domain.HabitacionRepository
+	availability   *AvailabilityService
 	reservaService *ReservaService
 	personRepo     domain.PersonRepository
 	clientRepo     domain.ClientRepository
+	listaEspera    *ListaEsperaService
+	tarifas        *TarifaService
 }
 
 func NewReservationTools(
 	habitacionRepo domain.HabitacionRepository,
+	availability *AvailabilityService,
 	reservaService *ReservaService,
 	personRepo domain.PersonRepository,
 	clientRepo domain.ClientRepository,
+	listaEspera *ListaEsperaService,
+	tarifas *TarifaService,
 ) *ReservationTools {
 	return &ReservationTools{
 		habitacionRepo: habitacionRepo,
+		availability:   availability,
 		reservaService: reservaService,
 		personRepo:     personRepo,
 		clientRepo:     clientRepo,
+		listaEspera:    listaEspera,
+		tarifas:        tarifas,
 	}
 }
 
@@ -56,19 +65,24 @@ func (rt *ReservationTools) GetAvailableTools() []Tool {
 		},
 		{
 			Name:        "calculate_price",
-			Description: "Calcula el precio total de una reserva. Args: {\"tipoHabitacionId\": 1, \"fechaEntrada\": \"YYYY-MM-DD\", \"fechaSalida\": \"YYYY-MM-DD\"}",
+			Description: "Calcula el precio total de una reserva. Args: {\"tipoHabitacionId\": 1, \"fechaEntrada\": \"YYYY-MM-DD\", \"fechaSalida\": \"YYYY-MM-DD\", \"planTarifaId\": 2 (opcional), \"adultos\": 2, \"ninhos\": 1, \"edadesNinhos\": [5] (opcionales, para recargos por huéspedes adicionales)}",
 			Execute:     rt.CalculatePrice,
 		},
 		{
 			Name:        "create_reservation",
-			Description: "Crea una nueva reserva. Args: JSON con todos los datos de la reserva incluyendo fechas, habitación, datos personales del cliente",
+			Description: "Crea una nueva reserva. Args: JSON con todos los datos de la reserva incluyendo fechas, habitación, datos personales del cliente. Para varias habitaciones usa \"habitaciones\":[{\"tipoHabitacionId\":INT,\"cantidad\":INT}] en lugar de tipoHabitacionId. El plan tarifario elegido va en \"planTarifaId\" (opcional), el código promocional del cliente en \"codigoPromocional\" (opcional) y las edades de los niños en \"edadesNinhos\":[INT] (opcional)",
 			Execute:     rt.CreateReservation,
 		},
 		{
 			Name:        "generate_booking_link",
-			Description: "Genera un enlace de reserva pre-llenado. Args: {\"fechaEntrada\":\"YYYY-MM-DD\",\"fechaSalida\":\"YYYY-MM-DD\",\"cantidadAdultos\":INT,\"cantidadNinhos\":INT,\"tipoHabitacionId\":INT,\"email\":\"opcional@email.com\"}",
+			Description: "Genera un enlace de reserva pre-llenado. Args: {\"fechaEntrada\":\"YYYY-MM-DD\",\"fechaSalida\":\"YYYY-MM-DD\",\"cantidadAdultos\":INT,\"cantidadNinhos\":INT,\"tipoHabitacionId\":INT,\"email\":\"opcional@email.com\"}. Para varias habitaciones usa \"habitaciones\":[{\"tipoHabitacionId\":INT,\"cantidad\":INT}]",
 			Execute:     rt.GenerateBookingLink,
 		},
+		{
+			Name:        "join_waitlist",
+			Description: "Inscribe al huésped en la lista de espera cuando no hay disponibilidad. Args: {\"tipoHabitacionId\":INT,\"fechaEntrada\":\"YYYY-MM-DD\",\"fechaSalida\":\"YYYY-MM-DD\",\"cantidadAdultos\":INT,\"cantidadNinhos\":INT,\"nombre\":\"...\",\"email\":\"...\"}",
+			Execute:     rt.JoinWaitlist,
+		},
 	}
 }
 
@@ -84,7 +98,8 @@ func (rt *ReservationTools) GetRoomTypes(args string) (string, error) {
 
 	for _, tipo := range tipos {
 		result.WriteString(fmt.Sprintf("• %s (ID: %d)\n", tipo.Titulo, tipo.ID))
-		result.WriteString(fmt.Sprintf("  Precio: S/%.2f por noche\n", tipo.Precio))
+		// Sin fechas solo se conoce la tarifa base; el precio real sale del calendario de tarifas
+		result.WriteString(fmt.Sprintf("  Tarifa base: S/%.2f por noche (el precio varía según las fechas; cotiza con calculate_price)\n", tipo.Precio))
 		result.WriteString(fmt.Sprintf("  Capacidad: %d adultos, %d niños\n", tipo.CapacidadAdultos, tipo.CapacidadNinhos))
 		result.WriteString(fmt.Sprintf("  Camas: %d\n", tipo.CantidadCamas))
 		result.WriteString(fmt.Sprintf("  Descripción: %s\n\n", tipo.Descripcion))
@@ -114,22 +129,55 @@ func (rt *ReservationTools) CheckAvailability(args string) (string, error) {
 		return "", fmt.Errorf("fecha de salida inválida: %w", err)
 	}
 
-	disponibles, err := rt.habitacionRepo.GetAvailableRooms(fechaEntrada, fechaSalida)
+	disponibles, rechazos, err := rt.availability.ConsultarDisponibilidad(fechaEntrada, fechaSalida)
 	if err != nil {
 		return "", fmt.Errorf("error al verificar disponibilidad: %w", err)
 	}
 
+	// Tipos con habitaciones libres que las restricciones de estadía no permiten vender
+	var restringidos strings.Builder
+	for _, rechazo := range rechazos {
+		restringidos.WriteString(fmt.Sprintf("⛔ %s (ID: %d): %s\n", rechazo.Titulo, rechazo.TipoHabitacionID, strings.Join(rechazo.Motivos, "; ")))
+	}
+
+	if len(disponibles) == 0 && len(rechazos) > 0 {
+		return fmt.Sprintf("Hay habitaciones libres del %s al %s, pero la estadía no cumple las restricciones del hotel:\n\n%s\n"+
+			"Explica el motivo al huésped y sugiérele ajustar las fechas o la cantidad de noches.",
+			input.FechaEntrada, input.FechaSalida, restringidos.String()), nil
+	}
+
 	if len(disponibles) == 0 {
-		return fmt.Sprintf("No hay habitaciones disponibles para las fechas %s a %s", input.FechaEntrada, input.FechaSalida), nil
+		return fmt.Sprintf("No hay habitaciones disponibles para las fechas %s a %s. "+
+			"Puedes ofrecer al huésped unirse a la lista de espera (join_waitlist) para avisarle por correo si se libera una habitación.",
+			input.FechaEntrada, input.FechaSalida), nil
 	}
 
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Habitaciones disponibles para %s - %s:\n\n", input.FechaEntrada, input.FechaSalida))
 
 	for _, tipo := range disponibles {
+		cotizacion, err := rt.tarifas.Cotizar(tipo.ID, fechaEntrada, fechaSalida)
+		if err != nil {
+			return "", fmt.Errorf("error al cotizar la estadía: %w", err)
+		}
+
 		result.WriteString(fmt.Sprintf("✅ %s (ID: %d)\n", tipo.Titulo, tipo.ID))
-		result.WriteString(fmt.Sprintf("   Precio: S/%.2f por noche\n", tipo.Precio))
-		result.WriteString(fmt.Sprintf("   Capacidad: %d adultos, %d niños\n\n", tipo.CapacidadAdultos, tipo.CapacidadNinhos))
+		result.WriteString(fmt.Sprintf("   Precio: S/%.2f en total (promedio S/%.2f por noche)\n", cotizacion.Total, cotizacion.PrecioPromedio()))
+		result.WriteString(fmt.Sprintf("   Capacidad: %d adultos, %d niños\n", tipo.CapacidadAdultos, tipo.CapacidadNinhos))
+
+		planes, err := rt.tarifas.CotizarPlanes(tipo.ID, fechaEntrada, fechaSalida)
+		if err != nil {
+			return "", fmt.Errorf("error al cotizar planes tarifarios: %w", err)
+		}
+		for _, p := range planes {
+			result.WriteString(fmt.Sprintf("   Plan %s (planTarifaId: %d): S/%.2f en total\n", p.Plan.Nombre, p.Plan.ID, p.Cotizacion.Total))
+		}
+		result.WriteString("\n")
+	}
+
+	if restringidos.Len() > 0 {
+		result.WriteString("No disponibles para estas fechas por restricciones de estadía:\n")
+		result.WriteString(restringidos.String())
 	}
 
 	return result.String(), nil
@@ -139,8 +187,12 @@ func (rt *ReservationTools) CheckAvailability(args string) (string, error) {
 func (rt *ReservationTools) CalculatePrice(args string) (string, error) {
 	var input struct {
 		TipoHabitacionID int    `json:"tipoHabitacionId"`
+		PlanTarifaID     int    `json:"planTarifaId,omitempty"`
 		FechaEntrada     string `json:"fechaEntrada"`
 		FechaSalida      string `json:"fechaSalida"`
+		Adultos          int    `json:"adultos,omitempty"`
+		Ninhos           int    `json:"ninhos,omitempty"`
+		EdadesNinhos     []int  `json:"edadesNinhos,omitempty"`
 	}
 
 	if err := json.Unmarshal([]byte(args), &input); err != nil {
@@ -164,128 +216,72 @@ func (rt *ReservationTools) CalculatePrice(args string) (string, error) {
 		return "", fmt.Errorf("fecha de salida inválida: %w", err)
 	}
 
-	// Calcular noches
-	noches := int(fechaSalida.Sub(fechaEntrada).Hours() / 24)
-	if noches < 1 {
-		noches = 1
+	// Cotizar noche a noche con el calendario de tarifas y el plan elegido
+	var cotizacion *domain.CotizacionEstadia
+	if input.PlanTarifaID != 0 {
+		cotizacion, err = rt.tarifas.CotizarPlan(input.PlanTarifaID, tipo.ID, fechaEntrada, fechaSalida)
+	} else {
+		cotizacion, err = rt.tarifas.Cotizar(tipo.ID, fechaEntrada, fechaSalida)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error al cotizar la estadía: %w", err)
 	}
 
-	total := tipo.Precio * float64(noches)
+	// Recargos por huéspedes adicionales si se indicó la ocupación
+	if input.Adultos > 0 {
+		ocupacion, err := domain.NuevaOcupacion(input.Adultos, input.Ninhos, input.EdadesNinhos)
+		if err != nil {
+			return "", fmt.Errorf("ocupación inválida: %w", err)
+		}
+		if err := rt.tarifas.AgregarRecargosOcupacion(cotizacion, ocupacion); err != nil {
+			return "", err
+		}
+	}
+
+	var detalle strings.Builder
+	for _, noche := range cotizacion.Noches {
+		detalle.WriteString(fmt.Sprintf("  - %s: S/%.2f\n", noche.Fecha.Format("02/01/2006"), noche.Precio))
+	}
+	for _, recargo := range cotizacion.Recargos {
+		detalle.WriteString(fmt.Sprintf("  - %s x%d (%d noches a S/%.2f): S/%.2f\n",
+			recargo.Concepto, recargo.Cantidad, recargo.Noches, recargo.PrecioNoche, recargo.Total))
+	}
 
 	result := fmt.Sprintf("Cálculo de Precio:\n\n"+
 		"Habitación: %s\n"+
-		"Precio por noche: S/%.2f\n"+
+		"Precio promedio por noche: S/%.2f\n"+
 		"Número de noches: %d\n"+
+		"Detalle por noche:\n%s"+
 		"Total: S/%.2f\n",
-		tipo.Titulo, tipo.Precio, noches, total)
+		tipo.Titulo, cotizacion.PrecioPromedio(), len(cotizacion.Noches), detalle.String(), cotizacion.Total)
 
 	return result, nil
 }
 
-// GenerateBookingLink genera un enlace para completar la reserva en el sitio web
-/*
-func (rt *ReservationTools) GenerateBookingLink(args string) (string, error) {
-	log.Printf("GenerateBookingLink called with args: %s", args)
-
-	var input struct {
-		FechaEntrada     string  `json:"fechaEntrada"`
-		FechaSalida      string  `json:"fechaSalida"`
-		TipoHabitacionID int     `json:"tipoHabitacionId"`
-		Adultos          int     `json:"adultos"`
-		Ninos            int     `json:"ninos"`
-		Email            *string `json:"email,omitempty"`
-	}
-
-	if err := json.Unmarshal([]byte(args), &input); err != nil {
-		return "", fmt.Errorf("argumentos inválidos: %w", err)
-	}
-
-	// Validaciones básicas
-	if input.FechaEntrada == "" || input.FechaSalida == "" {
-		return "", fmt.Errorf("fechas de entrada y salida son requeridas")
-	}
-
-	if input.TipoHabitacionID < 1 {
-		return "", fmt.Errorf("tipo de habitación inválido")
-	}
-
-	if input.Adultos < 1 {
-		input.Adultos = 1
-	}
-
-	// Obtener información del tipo de habitación para el resumen
-	tipo, err := rt.habitacionRepo.GetRoomTypeByID(input.TipoHabitacionID)
-	if err != nil {
-		return "", fmt.Errorf("error al obtener tipo de habitación: %w", err)
-	}
-
-	// Calcular noches y precio estimado
-	fechaEntrada, _ := time.Parse("2006-01-02", input.FechaEntrada)
-	fechaSalida, _ := time.Parse("2006-01-02", input.FechaSalida)
-	noches := int(fechaSalida.Sub(fechaEntrada).Hours() / 24)
-	if noches < 1 {
-		noches = 1
-	}
-	precioEstimado := tipo.Precio * float64(noches)
-
-	// Construir el enlace con parámetros de query
-	// Formato: /reservas?checkIn=2025-12-20&checkOut=2025-12-27&roomTypeId=1&adults=2&children=0&email=user@email.com
-	baseURL := "/reservas"
-	params := fmt.Sprintf("?checkIn=%s&checkOut=%s&roomTypeId=%d&adults=%d&children=%d",
-		input.FechaEntrada,
-		input.FechaSalida,
-		input.TipoHabitacionID,
-		input.Adultos,
-		input.Ninos,
-	)
-
-	// Agregar email si se proporcionó
-	emailInfo := "No proporcionado"
-	if input.Email != nil && *input.Email != "" {
-		params += fmt.Sprintf("&email=%s", *input.Email)
-		emailInfo = *input.Email
-	}
-
-	bookingLink := baseURL + params
-
-	result := fmt.Sprintf("🔗 **Enlace de Reserva Generado**\n\n"+
-		"📋 **Resumen de tu reserva:**\n"+
-		"• Habitación: %s\n"+
-		"• Fecha de entrada: %s\n"+
-		"• Fecha de salida: %s\n"+
-		"• Noches: %d\n"+
-		"• Adultos: %d\n"+
-		"• Niños: %d\n"+
-		"• Precio estimado: S/%.2f\n"+
-		"• Email para ofertas: %s\n\n"+
-		"🌐 **Enlace para completar tu reserva:**\n%s\n\n"+
-		"Haz clic en el enlace o cópialo para completar tu reserva con todos los datos personales y el pago.",
-		tipo.Titulo,
-		input.FechaEntrada,
-		input.FechaSalida,
-		noches,
-		input.Adultos,
-		input.Ninos,
-		precioEstimado,
-		emailInfo,
-		bookingLink,
-	)
-
-	return result, nil
+// HabitacionesPorTipoInput representa una cantidad de habitaciones de un tipo pedida por el chatbot
+type HabitacionesPorTipoInput struct {
+	TipoHabitacionID int `json:"tipoHabitacionId"`
+	Cantidad         int `json:"cantidad"`
+	PlanTarifaID     int `json:"planTarifaId,omitempty"`
 }
-*/
 
 // CreateReservation crea una nueva reserva
 func (rt *ReservationTools) CreateReservation(args string) (string, error) {
 	log.Printf("CreateReservation called with args: %s", args)
 
 	var input struct {
-		FechaEntrada     string                   `json:"fechaEntrada"`
-		FechaSalida      string                   `json:"fechaSalida"`
-		CantidadAdultos  int                      `json:"cantidadAdultos"`
-		CantidadNinhos   int                      `json:"cantidadNinhos"`
-		TipoHabitacionID int                      `json:"tipoHabitacionId"`
-		PersonalData     domain.PersonalDataInput `json:"personalData"`
+		FechaEntrada     string                     `json:"fechaEntrada"`
+		FechaSalida      string                     `json:"fechaSalida"`
+		CantidadAdultos  int                        `json:"cantidadAdultos"`
+		CantidadNinhos   int                        `json:"cantidadNinhos"`
+		TipoHabitacionID int                        `json:"tipoHabitacionId"`
+		PlanTarifaID     int                        `json:"planTarifaId,omitempty"`
+		Habitaciones     []HabitacionesPorTipoInput `json:"habitaciones,omitempty"`
+		PersonalData     domain.PersonalDataInput   `json:"personalData"`
+		// CodigoPromocional es el código de campaña que indicó el cliente (opcional)
+		CodigoPromocional string `json:"codigoPromocional,omitempty"`
+		// EdadesNinhos son las edades de los niños para los recargos por banda de edad (opcional)
+		EdadesNinhos []int `json:"edadesNinhos,omitempty"`
 	}
 
 	if err := json.Unmarshal([]byte(args), &input); err != nil {
@@ -301,8 +297,18 @@ func (rt *ReservationTools) CreateReservation(args string) (string, error) {
 		return "", fmt.Errorf("debe haber al menos 1 adulto")
 	}
 
-	if input.TipoHabitacionID < 1 {
-		return "", fmt.Errorf("tipo de habitación inválido")
+	// Formato simple: una habitación del tipo indicado
+	if len(input.Habitaciones) == 0 {
+		input.Habitaciones = []HabitacionesPorTipoInput{{TipoHabitacionID: input.TipoHabitacionID, Cantidad: 1, PlanTarifaID: input.PlanTarifaID}}
+	}
+
+	for _, hab := range input.Habitaciones {
+		if hab.TipoHabitacionID < 1 {
+			return "", fmt.Errorf("tipo de habitación inválido")
+		}
+		if hab.Cantidad < 1 {
+			return "", fmt.Errorf("la cantidad de habitaciones debe ser al menos 1")
+		}
 	}
 
 	// Parsear fechas
@@ -321,24 +327,36 @@ func (rt *ReservationTools) CreateReservation(args string) (string, error) {
 		return "", fmt.Errorf("la fecha de entrada no puede ser en el pasado")
 	}
 
-	// Buscar una habitación disponible del tipo especificado
-	habitacionID, err := rt.habitacionRepo.FindAvailableRoomByType(input.TipoHabitacionID, fechaEntrada, fechaSalida)
-	if err != nil {
-		return "", fmt.Errorf("no hay habitaciones disponibles del tipo seleccionado para esas fechas: %w", err)
+	// Calcular noches
+	noches := int(fechaSalida.Sub(fechaEntrada).Hours() / 24)
+	if noches < 1 {
+		noches = 1
 	}
 
-	// Obtener el precio del tipo de habitación
-	tipo, err := rt.habitacionRepo.GetRoomTypeByID(input.TipoHabitacionID)
-	if err != nil {
-		return "", fmt.Errorf("error al obtener tipo de habitación: %w", err)
+	// Armar las solicitudes por tipo; el precio lo cotiza AsignarHabitaciones
+	solicitudes := make([]domain.SolicitudHabitaciones, len(input.Habitaciones))
+	resumenHabitaciones := make([]string, len(input.Habitaciones))
+	for i, hab := range input.Habitaciones {
+		tipo, err := rt.habitacionRepo.GetRoomTypeByID(hab.TipoHabitacionID)
+		if err != nil {
+			return "", fmt.Errorf("error al obtener tipo de habitación: %w", err)
+		}
+
+		solicitudes[i] = domain.SolicitudHabitaciones{
+			TipoHabitacionID: hab.TipoHabitacionID,
+			Cantidad:         hab.Cantidad,
+			FechaEntrada:     fechaEntrada,
+			FechaSalida:      fechaSalida,
+			PlanTarifaID:     hab.PlanTarifaID,
+		}
+		resumenHabitaciones[i] = fmt.Sprintf("%d × %s", hab.Cantidad, tipo.Titulo)
 	}
 
-	// Calcular noches y subtotal
-	noches := int(fechaSalida.Sub(fechaEntrada).Hours() / 24)
-	if noches < 1 {
-		noches = 1
+	// Buscar habitaciones distintas y disponibles para todos los tipos pedidos
+	habitaciones, err := rt.reservaService.AsignarHabitaciones(solicitudes)
+	if err != nil {
+		return "", fmt.Errorf("no hay suficientes habitaciones disponibles de los tipos seleccionados para esas fechas: %w", err)
 	}
-	subtotal := tipo.Precio * float64(noches)
 
 	// Crear la persona
 	person := &domain.Person{
@@ -359,18 +377,10 @@ func (rt *ReservationTools) CreateReservation(args string) (string, error) {
 		CantidadAdultos:   input.CantidadAdultos,
 		CantidadNinhos:    input.CantidadNinhos,
 		Estado:            domain.ReservaPendiente,
-		Subtotal:          subtotal,
-		Descuento:         0,
 		FechaConfirmacion: time.Now(),
-		Habitaciones: []domain.ReservaHabitacion{
-			{
-				HabitacionID: habitacionID,
-				FechaEntrada: fechaEntrada,
-				FechaSalida:  fechaSalida,
-				Precio:       tipo.Precio,
-				Estado:       1, // Activo
-			},
-		},
+		Habitaciones:      habitaciones,
+		CodigoPromocional: input.CodigoPromocional,
+		EdadesNinhos:      input.EdadesNinhos,
 	}
 
 	// Crear la reserva con el cliente
@@ -378,46 +388,75 @@ func (rt *ReservationTools) CreateReservation(args string) (string, error) {
 		return "", fmt.Errorf("error al crear la reserva: %w", err)
 	}
 
+	// Recargos por huéspedes adicionales de cada habitación
+	var recargos strings.Builder
+	for _, hab := range reserva.Habitaciones {
+		for _, recargo := range hab.Recargos {
+			if recargos.Len() == 0 {
+				recargos.WriteString("Recargos por huéspedes adicionales:\n")
+			}
+			recargos.WriteString(fmt.Sprintf("  - %s x%d: S/%.2f\n", recargo.Concepto, recargo.Cantidad, recargo.Total))
+		}
+	}
+
 	result := fmt.Sprintf("✅ Reserva creada exitosamente!\n\n"+
 		"Número de Reserva: #%d\n"+
+		"Código de Reserva: %s\n"+
 		"Cliente: %s %s\n"+
 		"Email: %s\n"+
-		"Tipo de Habitación: %s\n"+
+		"Habitaciones: %s\n"+
 		"Check-in: %s\n"+
 		"Check-out: %s\n"+
 		"Noches: %d\n"+
 		"Adultos: %d\n"+
 		"Niños: %d\n"+
+		"%s"+
+		"Descuento: S/%.2f\n"+
 		"Total: S/%.2f\n"+
-		"Estado: %s\n\n"+
+		"Estado: %s\n"+
+		"%s\n"+
 		"Se ha enviado un email de confirmación a %s",
 		reserva.ID,
+		reserva.Codigo,
 		person.Name, person.FirstSurname,
 		person.Email,
-		tipo.Titulo,
+		strings.Join(resumenHabitaciones, ", "),
 		input.FechaEntrada,
 		input.FechaSalida,
 		noches,
 		input.CantidadAdultos,
 		input.CantidadNinhos,
-		subtotal,
+		recargos.String(),
+		reserva.Descuento,
+		reserva.Subtotal-reserva.Descuento,
 		reserva.Estado,
+		mensajeRetencion(reserva),
 		person.Email,
 	)
 
 	return result, nil
 }
 
+// mensajeRetencion indica al huésped hasta cuándo se mantiene su reserva pendiente
+func mensajeRetencion(reserva *domain.Reserva) string {
+	if reserva.SegundosRetencionRestantes == nil {
+		return ""
+	}
+	minutos := (*reserva.SegundosRetencionRestantes + 59) / 60
+	return fmt.Sprintf("Las habitaciones quedan retenidas durante %d minutos; confirma el pago antes de que venza el plazo.\n", minutos)
+}
+
 func (rt *ReservationTools) GenerateBookingLink(args string) (string, error) {
 	log.Printf("GenerateBookingLink called with args: %s", args)
 
 	var input struct {
-		FechaEntrada     string `json:"fechaEntrada"`
-		FechaSalida      string `json:"fechaSalida"`
-		CantidadAdultos  int    `json:"cantidadAdultos"`
-		CantidadNinhos   int    `json:"cantidadNinhos"`
-		TipoHabitacionID int    `json:"tipoHabitacionId"`
-		Email            string `json:"email,omitempty"` // Optional for CRM tracking
+		FechaEntrada     string                     `json:"fechaEntrada"`
+		FechaSalida      string                     `json:"fechaSalida"`
+		CantidadAdultos  int                        `json:"cantidadAdultos"`
+		CantidadNinhos   int                        `json:"cantidadNinhos"`
+		TipoHabitacionID int                        `json:"tipoHabitacionId"`
+		Habitaciones     []HabitacionesPorTipoInput `json:"habitaciones,omitempty"`
+		Email            string                     `json:"email,omitempty"` // Optional for CRM tracking
 	}
 
 	if err := json.Unmarshal([]byte(args), &input); err != nil {
@@ -433,15 +472,44 @@ func (rt *ReservationTools) GenerateBookingLink(args string) (string, error) {
 		return "", fmt.Errorf("debe haber al menos 1 adulto")
 	}
 
-	if input.TipoHabitacionID < 1 {
-		return "", fmt.Errorf("tipo de habitación inválido")
+	// Formato simple: una habitación del tipo indicado
+	if len(input.Habitaciones) == 0 {
+		input.Habitaciones = []HabitacionesPorTipoInput{{TipoHabitacionID: input.TipoHabitacionID, Cantidad: 1}}
 	}
 
-	// Obtener información del tipo de habitación
-	tipo, err := rt.habitacionRepo.GetRoomTypeByID(input.TipoHabitacionID)
+	fechaEntrada, err := time.Parse("2006-01-02", input.FechaEntrada)
 	if err != nil {
-		return "", fmt.Errorf("error al obtener tipo de habitación: %w", err)
+		return "", fmt.Errorf("fecha de entrada inválida: %w", err)
+	}
+	fechaSalida, err := time.Parse("2006-01-02", input.FechaSalida)
+	if err != nil {
+		return "", fmt.Errorf("fecha de salida inválida: %w", err)
+	}
+
+	// Obtener información y cotización de cada tipo de habitación
+	tipos := make([]domain.TipoHabitacion, len(input.Habitaciones))
+	cotizaciones := make([]*domain.CotizacionEstadia, len(input.Habitaciones))
+	for i, hab := range input.Habitaciones {
+		if hab.TipoHabitacionID < 1 {
+			return "", fmt.Errorf("tipo de habitación inválido")
+		}
+		if hab.Cantidad < 1 {
+			return "", fmt.Errorf("la cantidad de habitaciones debe ser al menos 1")
+		}
+
+		tipo, err := rt.habitacionRepo.GetRoomTypeByID(hab.TipoHabitacionID)
+		if err != nil {
+			return "", fmt.Errorf("error al obtener tipo de habitación: %w", err)
+		}
+		tipos[i] = tipo
+
+		cotizacion, err := rt.tarifas.Cotizar(hab.TipoHabitacionID, fechaEntrada, fechaSalida)
+		if err != nil {
+			return "", fmt.Errorf("error al cotizar la estadía: %w", err)
+		}
+		cotizaciones[i] = cotizacion
 	}
+	tipo := tipos[0]
 
 	// Construir URL base
 	frontendURL := os.Getenv("FRONTEND_URL")
@@ -463,7 +531,16 @@ func (rt *ReservationTools) GenerateBookingLink(args string) (string, error) {
 
 	params.Add("roomTypeId", fmt.Sprintf("%d", input.TipoHabitacionID))
 	params.Add("roomType", tipo.Titulo)
-	params.Add("roomPrice", fmt.Sprintf("%.2f", tipo.Precio))
+	params.Add("roomPrice", fmt.Sprintf("%.2f", cotizaciones[0].PrecioPromedio()))
+
+	// Varias habitaciones: rooms=tipoId:cantidad,tipoId:cantidad
+	if len(input.Habitaciones) > 1 || input.Habitaciones[0].Cantidad > 1 {
+		rooms := make([]string, len(input.Habitaciones))
+		for i, hab := range input.Habitaciones {
+			rooms[i] = fmt.Sprintf("%d:%d", hab.TipoHabitacionID, hab.Cantidad)
+		}
+		params.Add("rooms", strings.Join(rooms, ","))
+	}
 
 	// Add email if provided (for CRM tracking)
 	if input.Email != "" {
@@ -477,23 +554,23 @@ func (rt *ReservationTools) GenerateBookingLink(args string) (string, error) {
 	log.Printf("Generated booking URL: %s", fullURL)
 
 	// Calcular precio total para mostrar en el mensaje
-	fechaEntrada, _ := time.Parse("2006-01-02", input.FechaEntrada)
-	fechaSalida, _ := time.Parse("2006-01-02", input.FechaSalida)
-	noches := int(fechaSalida.Sub(fechaEntrada).Hours() / 24)
-	if noches < 1 {
-		noches = 1
+	noches := len(cotizaciones[0].Noches)
+	total := 0.0
+	resumenHabitaciones := make([]string, len(input.Habitaciones))
+	for i, hab := range input.Habitaciones {
+		total += cotizaciones[i].Total * float64(hab.Cantidad)
+		resumenHabitaciones[i] = fmt.Sprintf("%d × %s", hab.Cantidad, tipos[i].Titulo)
 	}
-	total := tipo.Precio * float64(noches)
 
 	// Build response message
 	result := fmt.Sprintf("✅ ¡Perfecto! He preparado tu reserva.\n\n"+
 		"📋 **Resumen de tu reserva:**\n"+
-		"• Habitación: %s\n"+
+		"• Habitaciones: %s\n"+
 		"• Check-in: %s\n"+
 		"• Check-out: %s\n"+
 		"• Noches: %d\n"+
 		"• Huéspedes: %d adultos",
-		tipo.Titulo,
+		strings.Join(resumenHabitaciones, ", "),
 		input.FechaEntrada,
 		input.FechaSalida,
 		noches,
@@ -521,6 +598,57 @@ func (rt *ReservationTools) GenerateBookingLink(args string) (string, error) {
 	return result, nil
 }
 
+// JoinWaitlist inscribe al huésped en la lista de espera de un tipo de habitación
+func (rt *ReservationTools) JoinWaitlist(args string) (string, error) {
+	if rt.listaEspera == nil {
+		return "", fmt.Errorf("la lista de espera no está disponible")
+	}
+
+	var input struct {
+		TipoHabitacionID int    `json:"tipoHabitacionId"`
+		FechaEntrada     string `json:"fechaEntrada"`
+		FechaSalida      string `json:"fechaSalida"`
+		CantidadAdultos  int    `json:"cantidadAdultos"`
+		CantidadNinhos   int    `json:"cantidadNinhos"`
+		Nombre           string `json:"nombre"`
+		Email            string `json:"email"`
+	}
+
+	if err := json.Unmarshal([]byte(args), &input); err != nil {
+		return "", fmt.Errorf("argumentos inválidos: %w", err)
+	}
+
+	fechaEntrada, err := time.Parse("2006-01-02", input.FechaEntrada)
+	if err != nil {
+		return "", fmt.Errorf("fecha de entrada inválida: %w", err)
+	}
+
+	fechaSalida, err := time.Parse("2006-01-02", input.FechaSalida)
+	if err != nil {
+		return "", fmt.Errorf("fecha de salida inválida: %w", err)
+	}
+
+	inscripcion := &domain.InscripcionListaEspera{
+		TipoHabitacionID: input.TipoHabitacionID,
+		FechaEntrada:     fechaEntrada,
+		FechaSalida:      fechaSalida,
+		CantidadAdultos:  input.CantidadAdultos,
+		CantidadNinhos:   input.CantidadNinhos,
+		Nombre:           input.Nombre,
+		Email:            input.Email,
+	}
+
+	if err := rt.listaEspera.Inscribir(inscripcion); err != nil {
+		return "", fmt.Errorf("error al inscribir en la lista de espera: %w", err)
+	}
+
+	return fmt.Sprintf("📝 **Inscripción en lista de espera registrada**\n\n"+
+		"Fechas: %s a %s\n"+
+		"Te enviaremos un correo a %s con un enlace de reserva en cuanto se libere una habitación. "+
+		"Las personas de la lista se avisan en orden de inscripción y el enlace tiene un tiempo limitado.",
+		input.FechaEntrada, input.FechaSalida, inscripcion.Email), nil
+}
+
 // ExecuteTool ejecuta una herramienta por nombre
 func (rt *ReservationTools) ExecuteTool(toolName string, args string) (string, error) {
 	tools := rt.GetAvailableTools()
@@ -555,6 +683,11 @@ func (rt *ReservationTools) GetToolDescriptions() string {
 	sb.WriteString("   {\"fechaEntrada\":\"2025-12-27\",\"fechaSalida\":\"2026-01-04\",\"tipoHabitacionId\":6,\"cantidadAdultos\":2,\"cantidadNinhos\":0,\"email\":\"user@email.com\"}\n")
 	sb.WriteString("   [END_TOOL]\n\n")
 
+	sb.WriteString("5. join_waitlist - USA ESTA si no hay disponibilidad y el usuario quiere esperar\n")
+	sb.WriteString("   Uso: [USE_TOOL: join_waitlist]\n")
+	sb.WriteString("   {\"tipoHabitacionId\":6,\"fechaEntrada\":\"2025-12-27\",\"fechaSalida\":\"2026-01-04\",\"cantidadAdultos\":2,\"cantidadNinhos\":0,\"nombre\":\"Ana\",\"email\":\"user@email.com\"}\n")
+	sb.WriteString("   [END_TOOL]\n\n")
+
 	sb.WriteString("IMPORTANTE:\n")
 	sb.WriteString("- USA generate_booking_link cuando el usuario dice: 'confirmar', 'sí', 'adelante', 'continuar'\n")
 	sb.WriteString("- NO inventes URLs - SIEMPRE usa la herramienta\n")
@@ -0,0 +1,122 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// CodigoPromocionalService gestiona los códigos promocionales de las campañas de marketing
+type CodigoPromocionalService struct {
+	repo           domain.CodigoPromocionalRepository
+	habitacionRepo domain.HabitacionRepository
+}
+
+// NewCodigoPromocionalService crea una nueva instancia del servicio de códigos promocionales
+func NewCodigoPromocionalService(repo domain.CodigoPromocionalRepository, habitacionRepo domain.HabitacionRepository) *CodigoPromocionalService {
+	return &CodigoPromocionalService{
+		repo:           repo,
+		habitacionRepo: habitacionRepo,
+	}
+}
+
+// GetAll obtiene los códigos de una campaña (0 = todas las campañas)
+func (s *CodigoPromocionalService) GetAll(campanaID int) ([]domain.CodigoPromocional, error) {
+	return s.repo.GetAll(campanaID)
+}
+
+// GetByID obtiene un código promocional por su ID
+func (s *CodigoPromocionalService) GetByID(id int) (*domain.CodigoPromocional, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create crea un código promocional
+func (s *CodigoPromocionalService) Create(codigo *domain.CodigoPromocional) error {
+	if err := s.preparar(codigo); err != nil {
+		return err
+	}
+	return s.repo.Create(codigo)
+}
+
+// Update actualiza un código promocional
+func (s *CodigoPromocionalService) Update(codigo *domain.CodigoPromocional) error {
+	if err := s.preparar(codigo); err != nil {
+		return err
+	}
+	return s.repo.Update(codigo)
+}
+
+// GetReporteCampana resume los canjes y el descuento otorgado por los códigos de una campaña
+func (s *CodigoPromocionalService) GetReporteCampana(campanaID int) (*domain.ReporteCampana, error) {
+	return s.repo.GetReporteCampana(campanaID)
+}
+
+// CalcularDescuento busca el código ingresado por el cliente y calcula su descuento sobre las
+// habitaciones ya cotizadas de la reserva
+func (s *CodigoPromocionalService) CalcularDescuento(texto string, habitaciones []domain.ReservaHabitacion) (*domain.CodigoPromocional, float64, error) {
+	codigo, err := s.repo.GetByCodigo(texto)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.calcular(codigo, habitaciones)
+}
+
+// RecalcularDescuento calcula de nuevo el descuento del código de una reserva que se modifica.
+// El código debe seguir vigente y aplicar a las nuevas habitaciones; el canje de la propia
+// reserva no cuenta contra el límite de usos
+func (s *CodigoPromocionalService) RecalcularDescuento(texto string, habitaciones []domain.ReservaHabitacion) (*domain.CodigoPromocional, float64, error) {
+	codigo, err := s.repo.GetByCodigo(texto)
+	if err != nil {
+		return nil, 0, err
+	}
+	if codigo.Usos > 0 {
+		codigo.Usos--
+	}
+	return s.calcular(codigo, habitaciones)
+}
+
+// calcular aplica el código a las habitaciones cotizadas según su tipo
+func (s *CodigoPromocionalService) calcular(codigo *domain.CodigoPromocional, habitaciones []domain.ReservaHabitacion) (*domain.CodigoPromocional, float64, error) {
+	promocionables := make([]domain.HabitacionPromocionable, len(habitaciones))
+	for i, hab := range habitaciones {
+		habitacion, err := s.habitacionRepo.GetRoomByID(hab.HabitacionID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error al obtener habitación %d: %w", hab.HabitacionID, err)
+		}
+		promocionables[i] = domain.HabitacionPromocionable{
+			TipoHabitacionID: habitacion.TipoHabitacion.ID,
+			Noches:           hab.CantidadNoches(),
+			Importe:          hab.Importe(),
+		}
+	}
+
+	descuento, err := codigo.CalcularDescuento(promocionables, time.Now().UTC())
+	if err != nil {
+		return nil, 0, err
+	}
+	return codigo, descuento, nil
+}
+
+// preparar normaliza y valida un código antes de guardarlo
+func (s *CodigoPromocionalService) preparar(codigo *domain.CodigoPromocional) error {
+	codigo.Codigo = domain.NormalizarCodigo(codigo.Codigo)
+	codigo.Descripcion = strings.TrimSpace(codigo.Descripcion)
+	codigo.VigenteDesde = soloFecha(codigo.VigenteDesde)
+	codigo.VigenteHasta = soloFecha(codigo.VigenteHasta)
+	if codigo.TiposHabitacion == nil {
+		codigo.TiposHabitacion = make([]int, 0)
+	}
+
+	if err := codigo.Validar(); err != nil {
+		return err
+	}
+	for _, tipoID := range codigo.TiposHabitacion {
+		if _, err := s.habitacionRepo.GetRoomTypeByID(tipoID); err != nil {
+			return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrCodigoPromocionalInvalido, tipoID)
+		}
+	}
+
+	return nil
+}
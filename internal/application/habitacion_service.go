@@ -9,12 +9,16 @@ import (
 )
 
 type HabitacionService struct {
-	repo domain.HabitacionRepository
+	repo         domain.HabitacionRepository
+	availability *AvailabilityService
+	tarifas      *TarifaService
 }
 
-func NewHabitacionService(repo domain.HabitacionRepository) *HabitacionService {
+func NewHabitacionService(repo domain.HabitacionRepository, availability *AvailabilityService, tarifas *TarifaService) *HabitacionService {
 	return &HabitacionService{
-		repo: repo,
+		repo:         repo,
+		availability: availability,
+		tarifas:      tarifas,
 	}
 }
 
@@ -22,12 +26,27 @@ func (s *HabitacionService) GetAllRooms() ([]domain.Habitacion, error) {
 	return s.repo.GetAllRooms()
 }
 
-func (s *HabitacionService) GetAvailableRooms(fechaEntrada, fechaSalida time.Time) ([]domain.TipoHabitacion, error) {
-	return s.repo.GetAvailableRooms(fechaEntrada, fechaSalida)
+// GetAvailableRooms retorna los tipos de habitación libres en el rango con sus planes tarifarios
+// cotizados para esas fechas, y los tipos libres que las restricciones de estadía rechazan
+func (s *HabitacionService) GetAvailableRooms(fechaEntrada, fechaSalida time.Time) ([]domain.TipoHabitacion, []domain.RechazoEstadia, error) {
+	tipos, rechazos, err := s.availability.ConsultarDisponibilidad(fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range tipos {
+		planes, err := s.tarifas.CotizarPlanes(tipos[i].ID, fechaEntrada, fechaSalida)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error al cotizar planes del tipo %d: %w", tipos[i].ID, err)
+		}
+		tipos[i].Planes = planes
+	}
+
+	return tipos, rechazos, nil
 }
 
 func (s *HabitacionService) GetFechasBloqueadas(desde, hasta time.Time) (*domain.FechasBloqueadas, error) {
-	return s.repo.GetFechasBloqueadas(desde, hasta)
+	return s.availability.GetFechasBloqueadas(desde, hasta)
 }
 
 func (s *HabitacionService) GetRoomTypes() ([]domain.TipoHabitacion, error) {
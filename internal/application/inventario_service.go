@@ -0,0 +1,101 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// InventarioService arma la matriz de inventario por tipo de habitación y noche. Los contadores
+// salen de una sola consulta y la ocupación del hotel que usan las reglas de precio dinámico se
+// calcula a partir de la misma matriz, así que solo el precio se consulta por tipo
+type InventarioService struct {
+	repo           domain.InventarioRepository
+	habitacionRepo domain.HabitacionRepository
+	tarifas        *TarifaService
+}
+
+// NewInventarioService crea una nueva instancia del servicio de inventario
+func NewInventarioService(
+	repo domain.InventarioRepository,
+	habitacionRepo domain.HabitacionRepository,
+	tarifas *TarifaService,
+) *InventarioService {
+	return &InventarioService{
+		repo:           repo,
+		habitacionRepo: habitacionRepo,
+		tarifas:        tarifas,
+	}
+}
+
+// GetInventario retorna, para cada tipo de habitación y noche entre desde y hasta (inclusive),
+// las habitaciones totales, vendidas, retenidas, fuera de servicio y disponibles, y el precio
+func (s *InventarioService) GetInventario(desde, hasta time.Time) ([]domain.InventarioTipoFecha, error) {
+	desde, hasta = soloFecha(desde), soloFecha(hasta)
+	if hasta.Before(desde) {
+		return nil, fmt.Errorf("%w: la fecha hasta debe ser igual o posterior a la fecha desde", domain.ErrRangoInventarioInvalido)
+	}
+	if hasta.Sub(desde) >= domain.DiasMaximosInventario*24*time.Hour {
+		return nil, fmt.Errorf("%w: el rango no puede superar %d días", domain.ErrRangoInventarioInvalido, domain.DiasMaximosInventario)
+	}
+
+	inventario, err := s.repo.GetInventario(desde, hasta, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for i := range inventario {
+		inventario[i].CalcularDisponibles()
+	}
+
+	tipos, err := s.habitacionRepo.GetRoomTypes()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tipos de habitación: %w", err)
+	}
+
+	ocupacion := ocupacionInventario(inventario)
+	precios := make(map[int]map[time.Time]float64, len(tipos))
+	for _, tipo := range tipos {
+		noches, err := s.tarifas.PreciosPorNoche(tipo, desde, hasta, ocupacion)
+		if err != nil {
+			return nil, err
+		}
+		precios[tipo.ID] = make(map[time.Time]float64, len(noches))
+		for _, n := range noches {
+			precios[tipo.ID][soloFecha(n.Fecha)] = n.Precio
+		}
+	}
+
+	for i, celda := range inventario {
+		inventario[i].Precio = precios[celda.TipoHabitacionID][soloFecha(celda.Fecha)]
+	}
+
+	return inventario, nil
+}
+
+// ocupacionInventario calcula el porcentaje de ocupación del hotel de cada noche con el mismo
+// criterio que AvailabilityService.GetOcupacionFechas: habitaciones vendibles ocupadas o
+// apartadas sobre el total vendible
+func ocupacionInventario(inventario []domain.InventarioTipoFecha) map[time.Time]float64 {
+	vendibles := make(map[time.Time]int)
+	libres := make(map[time.Time]int)
+	for _, celda := range inventario {
+		fecha := soloFecha(celda.Fecha)
+		vendibles[fecha] += celda.Total - celda.FueraDeServicio
+		libres[fecha] += celda.Total - celda.FueraDeServicio - celda.Vendidas - celda.Retenidas
+	}
+
+	ocupacion := make(map[time.Time]float64, len(vendibles))
+	for fecha, total := range vendibles {
+		if total <= 0 {
+			continue
+		}
+		disponibles := libres[fecha]
+		if disponibles < 0 {
+			disponibles = 0
+		}
+		ocupacion[fecha] = math.Round(float64(total-disponibles)*10000/float64(total)) / 100
+	}
+	return ocupacion
+}
@@ -0,0 +1,220 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// LimpiezaService gestiona el estado de limpieza de las habitaciones, sus tareas y el personal
+// que las realiza. El check-out crea las tareas de salida (ReservaService.CheckOut); terminar
+// una tarea deja la habitación limpia a la espera de inspección
+type LimpiezaService struct {
+	repo domain.LimpiezaRepository
+}
+
+// NewLimpiezaService crea una nueva instancia del servicio de limpieza
+func NewLimpiezaService(repo domain.LimpiezaRepository) *LimpiezaService {
+	return &LimpiezaService{repo: repo}
+}
+
+// GetTablero obtiene el tablero de limpieza con la ocupación de la fecha indicada
+func (s *LimpiezaService) GetTablero(fecha time.Time) (*domain.TableroLimpieza, error) {
+	fecha = time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, time.UTC)
+
+	habitaciones, err := s.repo.GetTablero(fecha)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NuevoTableroLimpieza(fecha, habitaciones), nil
+}
+
+// CambiarEstadoHabitacion cambia el estado de limpieza de una habitación respetando las
+// transiciones permitidas. Poner una habitación fuera de servicio requiere un motivo
+func (s *LimpiezaService) CambiarEstadoHabitacion(habitacionID int, estado domain.EstadoLimpieza, motivo string) error {
+	motivo = strings.TrimSpace(motivo)
+	if estado == domain.HabitacionFueraDeServicio && motivo == "" {
+		return fmt.Errorf("%w: indique el motivo por el que la habitación queda fuera de servicio", domain.ErrLimpiezaInvalida)
+	}
+
+	actual, err := s.repo.GetEstadoHabitacion(habitacionID)
+	if err != nil {
+		return err
+	}
+	if err := domain.ValidarCambioLimpieza(actual, estado); err != nil {
+		return err
+	}
+
+	return s.repo.CambiarEstadoHabitacion(habitacionID, estado, motivo)
+}
+
+// GetTareas obtiene las tareas de limpieza que cumplen el filtro
+func (s *LimpiezaService) GetTareas(filtro domain.FiltroTareasLimpieza) ([]domain.TareaLimpieza, error) {
+	return s.repo.GetTareas(filtro)
+}
+
+// GetTareaByID obtiene una tarea de limpieza
+func (s *LimpiezaService) GetTareaByID(id int) (*domain.TareaLimpieza, error) {
+	return s.repo.GetTareaByID(id)
+}
+
+// CreateTarea crea una tarea manual pendiente, opcionalmente ya asignada
+func (s *LimpiezaService) CreateTarea(tarea *domain.TareaLimpieza) error {
+	if _, err := s.repo.GetEstadoHabitacion(tarea.HabitacionID); err != nil {
+		return err
+	}
+	if tarea.PersonalID != nil {
+		if err := s.verificarPersonalActivo(*tarea.PersonalID); err != nil {
+			return err
+		}
+	}
+
+	tarea.ReservaID = nil
+	tarea.Origen = domain.TareaManual
+	tarea.Estado = domain.TareaPendiente
+	tarea.CreadaEn = time.Now().UTC()
+	tarea.IniciadaEn = nil
+	tarea.TerminadaEn = nil
+
+	return s.repo.CreateTarea(tarea)
+}
+
+// AsignarTarea asigna una tarea abierta a un miembro activo del personal; nil la deja sin asignar
+func (s *LimpiezaService) AsignarTarea(id int, personalID *int) (*domain.TareaLimpieza, error) {
+	tarea, err := s.tareaAbierta(id)
+	if err != nil {
+		return nil, err
+	}
+	if personalID != nil {
+		if err := s.verificarPersonalActivo(*personalID); err != nil {
+			return nil, err
+		}
+	}
+
+	tarea.PersonalID = personalID
+	if err := s.repo.UpdateTarea(tarea); err != nil {
+		return nil, err
+	}
+
+	return tarea, nil
+}
+
+// IniciarTarea marca una tarea pendiente como en curso
+func (s *LimpiezaService) IniciarTarea(id int) (*domain.TareaLimpieza, error) {
+	tarea, err := s.tareaAbierta(id)
+	if err != nil {
+		return nil, err
+	}
+	if tarea.Estado != domain.TareaPendiente {
+		return nil, fmt.Errorf("%w: la tarea %d ya está en curso", domain.ErrLimpiezaInvalida, id)
+	}
+
+	ahora := time.Now().UTC()
+	tarea.Estado = domain.TareaEnCurso
+	tarea.IniciadaEn = &ahora
+	if err := s.repo.UpdateTarea(tarea); err != nil {
+		return nil, err
+	}
+
+	return tarea, nil
+}
+
+// CompletarTarea termina una tarea abierta y deja la habitación limpia. Las notas se agregan a
+// las de la tarea
+func (s *LimpiezaService) CompletarTarea(id int, notas string) (*domain.TareaLimpieza, error) {
+	tarea, err := s.tareaAbierta(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now().UTC()
+	tarea.Estado = domain.TareaTerminada
+	tarea.TerminadaEn = &ahora
+	tarea.Notas = agregarNotas(tarea.Notas, notas)
+	if err := s.repo.TerminarTarea(tarea); err != nil {
+		return nil, err
+	}
+
+	return tarea, nil
+}
+
+// CancelarTarea cancela una tarea abierta sin cambiar el estado de la habitación
+func (s *LimpiezaService) CancelarTarea(id int, notas string) (*domain.TareaLimpieza, error) {
+	tarea, err := s.tareaAbierta(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now().UTC()
+	tarea.Estado = domain.TareaCancelada
+	tarea.TerminadaEn = &ahora
+	tarea.Notas = agregarNotas(tarea.Notas, notas)
+	if err := s.repo.UpdateTarea(tarea); err != nil {
+		return nil, err
+	}
+
+	return tarea, nil
+}
+
+// GetPersonal obtiene el personal de limpieza
+func (s *LimpiezaService) GetPersonal() ([]domain.PersonalLimpieza, error) {
+	return s.repo.GetPersonal()
+}
+
+// CreatePersonal registra un miembro del personal de limpieza
+func (s *LimpiezaService) CreatePersonal(personal *domain.PersonalLimpieza) error {
+	personal.Nombre = strings.TrimSpace(personal.Nombre)
+	if err := personal.Validar(); err != nil {
+		return err
+	}
+	return s.repo.CreatePersonal(personal)
+}
+
+// UpdatePersonal actualiza un miembro del personal de limpieza. Desactivarlo no cambia las
+// tareas que ya tiene asignadas
+func (s *LimpiezaService) UpdatePersonal(personal *domain.PersonalLimpieza) error {
+	personal.Nombre = strings.TrimSpace(personal.Nombre)
+	if err := personal.Validar(); err != nil {
+		return err
+	}
+	return s.repo.UpdatePersonal(personal)
+}
+
+// tareaAbierta obtiene una tarea y verifica que no esté terminada ni cancelada
+func (s *LimpiezaService) tareaAbierta(id int) (*domain.TareaLimpieza, error) {
+	tarea, err := s.repo.GetTareaByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !tarea.Estado.Abierta() {
+		return nil, fmt.Errorf("%w: la tarea %d está %s", domain.ErrLimpiezaInvalida, id, tarea.Estado)
+	}
+	return tarea, nil
+}
+
+// verificarPersonalActivo verifica que el miembro del personal exista y esté activo
+func (s *LimpiezaService) verificarPersonalActivo(id int) error {
+	personal, err := s.repo.GetPersonalByID(id)
+	if err != nil {
+		return err
+	}
+	if !personal.Activo {
+		return fmt.Errorf("%w: %s no está activo", domain.ErrLimpiezaInvalida, personal.Nombre)
+	}
+	return nil
+}
+
+// agregarNotas agrega nuevas notas a las existentes en una línea aparte
+func agregarNotas(actuales, nuevas string) string {
+	nuevas = strings.TrimSpace(nuevas)
+	if nuevas == "" {
+		return actuales
+	}
+	if actuales == "" {
+		return nuevas
+	}
+	return actuales + "\n" + nuevas
+}
@@ -0,0 +1,339 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/Maxito7/hotel_backend/internal/email"
+)
+
+// ListaEsperaService gestiona la lista de espera de fechas agotadas. Cuando se libera inventario
+// avisa a los inscritos en orden de llegada (FIFO) con un enlace de reserva de vigencia limitada.
+// Mientras el enlace está vigente AvailabilityService aparta una habitación del tipo y las fechas
+// de la inscripción: no se ofrece a otros huéspedes de la lista ni a la venta general, y solo una
+// reserva hecha con el enlace (del mismo tipo y fechas) puede usarla
+type ListaEsperaService struct {
+	repo           domain.ListaEsperaRepository
+	habitacionRepo domain.HabitacionRepository
+	availability   *AvailabilityService
+	emailClient    *email.Client
+	frontendURL    string
+	duracionEnlace time.Duration
+	tarifas        *TarifaService
+	// mu evita que dos notificaciones simultáneas ofrezcan la misma habitación
+	mu sync.Mutex
+}
+
+// NewListaEsperaService crea una nueva instancia del servicio de lista de espera
+func NewListaEsperaService(
+	repo domain.ListaEsperaRepository,
+	habitacionRepo domain.HabitacionRepository,
+	availability *AvailabilityService,
+	emailClient *email.Client,
+	frontendURL string,
+	duracionEnlace time.Duration,
+	tarifas *TarifaService,
+) *ListaEsperaService {
+	return &ListaEsperaService{
+		repo:           repo,
+		habitacionRepo: habitacionRepo,
+		availability:   availability,
+		emailClient:    emailClient,
+		frontendURL:    strings.TrimRight(frontendURL, "/"),
+		duracionEnlace: duracionEnlace,
+		tarifas:        tarifas,
+	}
+}
+
+// Inscribir agrega un huésped a la lista de espera de un tipo de habitación y rango de fechas
+func (s *ListaEsperaService) Inscribir(inscripcion *domain.InscripcionListaEspera) error {
+	entrada, salida, err := normalizarRango(inscripcion.FechaEntrada, inscripcion.FechaSalida)
+	if err != nil {
+		return err
+	}
+	if entrada.Before(soloFecha(time.Now())) {
+		return fmt.Errorf("la fecha de entrada no puede ser anterior a hoy")
+	}
+	if strings.TrimSpace(inscripcion.Nombre) == "" || strings.TrimSpace(inscripcion.Email) == "" {
+		return fmt.Errorf("el nombre y el email son requeridos")
+	}
+	if inscripcion.CantidadAdultos <= 0 {
+		inscripcion.CantidadAdultos = 1
+	}
+	if inscripcion.CantidadNinhos < 0 {
+		return fmt.Errorf("la cantidad de niños no puede ser negativa")
+	}
+	if _, err := s.habitacionRepo.GetRoomTypeByID(inscripcion.TipoHabitacionID); err != nil {
+		return fmt.Errorf("tipo de habitación %d no encontrado: %w", inscripcion.TipoHabitacionID, err)
+	}
+
+	inscripcion.FechaEntrada = entrada
+	inscripcion.FechaSalida = salida
+	inscripcion.Email = strings.TrimSpace(inscripcion.Email)
+	inscripcion.Estado = domain.ListaEsperaEsperando
+	inscripcion.FechaCreacion = time.Now().UTC()
+
+	return s.repo.Create(inscripcion)
+}
+
+// GetByID obtiene una inscripción por su ID
+func (s *ListaEsperaService) GetByID(id int) (*domain.InscripcionListaEspera, error) {
+	return s.repo.GetByID(id)
+}
+
+// Cancelar retira una inscripción de la lista de espera. Si tenía un enlace vigente, la
+// habitación apartada se ofrece al siguiente de la lista
+func (s *ListaEsperaService) Cancelar(id int) error {
+	inscripcion, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if inscripcion.Estado != domain.ListaEsperaEsperando && inscripcion.Estado != domain.ListaEsperaNotificada {
+		return fmt.Errorf("la inscripción ya no está activa (estado %s)", inscripcion.Estado)
+	}
+
+	if err := s.repo.UpdateEstado(id, domain.ListaEsperaCancelada); err != nil {
+		return err
+	}
+
+	if inscripcion.Estado == domain.ListaEsperaNotificada {
+		s.NotificarEnSegundoPlano()
+	}
+
+	return nil
+}
+
+// AsociarSolicitudes marca la solicitud del tipo de habitación y las fechas de la inscripción para
+// que use la habitación apartada por su enlace. Retorna ErrEnlaceListaEsperaNoCorresponde si
+// ninguna solicitud coincide
+func (s *ListaEsperaService) AsociarSolicitudes(inscripcion *domain.InscripcionListaEspera, solicitudes []domain.SolicitudHabitaciones) error {
+	for i := range solicitudes {
+		if inscripcion.Corresponde(solicitudes[i]) {
+			solicitudes[i].ListaEsperaID = inscripcion.ID
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s al %s", domain.ErrEnlaceListaEsperaNoCorresponde,
+		inscripcion.FechaEntrada.Format("2006-01-02"), inscripcion.FechaSalida.Format("2006-01-02"))
+}
+
+// ValidarEnlace verifica que el token corresponda a un enlace de reserva vigente
+func (s *ListaEsperaService) ValidarEnlace(token string) (*domain.InscripcionListaEspera, error) {
+	inscripcion, err := s.repo.GetByToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if inscripcion.Estado != domain.ListaEsperaNotificada ||
+		inscripcion.VenceEnlace == nil || !time.Now().Before(*inscripcion.VenceEnlace) {
+		return nil, domain.ErrEnlaceListaEsperaVencido
+	}
+
+	return inscripcion, nil
+}
+
+// MarcarReservada registra que el huésped reservó con el enlace de la inscripción
+func (s *ListaEsperaService) MarcarReservada(id, reservaID int) error {
+	return s.repo.MarcarReservada(id, reservaID)
+}
+
+// VencerEnlaces marca como vencidos los enlaces cuya vigencia terminó y retorna sus IDs
+func (s *ListaEsperaService) VencerEnlaces(ahora time.Time) ([]int, error) {
+	return s.repo.VencerEnlaces(ahora)
+}
+
+// NotificarEnSegundoPlano ejecuta NotificarDisponibilidad sin bloquear a quien liberó el inventario
+func (s *ListaEsperaService) NotificarEnSegundoPlano() {
+	go func() {
+		if err := s.NotificarDisponibilidad(); err != nil {
+			log.Printf("❌ Error notificando lista de espera: %v", err)
+		}
+	}()
+}
+
+// NotificarDisponibilidad recorre la lista de espera en orden de llegada y envía un enlace de
+// reserva a cada inscrito cuyo tipo de habitación y fechas tengan una habitación disponible. Las
+// habitaciones apartadas por enlaces vigentes, incluidos los enviados en esta misma pasada, ya
+// no cuentan como disponibles
+func (s *ListaEsperaService) NotificarDisponibilidad() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ahora := time.Now().UTC()
+	if _, err := s.repo.VencerEnlaces(ahora); err != nil {
+		return err
+	}
+
+	inscripciones, err := s.repo.GetActivas(soloFecha(ahora))
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, inscripcion := range inscripciones {
+		if inscripcion.Estado != domain.ListaEsperaEsperando {
+			continue
+		}
+
+		libres, err := s.availability.ContarHabitacionesLibres(
+			inscripcion.TipoHabitacionID,
+			inscripcion.FechaEntrada,
+			inscripcion.FechaSalida,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("inscripción %d: %v", inscripcion.ID, err))
+			continue
+		}
+		if libres <= 0 {
+			continue
+		}
+
+		if err := s.notificar(&inscripcion, ahora); err != nil {
+			errs = append(errs, fmt.Sprintf("inscripción %d: %v", inscripcion.ID, err))
+			continue
+		}
+		log.Printf("✅ Lista de espera: enlace enviado a la inscripción %d", inscripcion.ID)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error al notificar lista de espera: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// notificar genera el enlace de reserva de la inscripción, lo registra y lo envía por correo.
+// Si el correo falla la inscripción vuelve a quedar en espera
+func (s *ListaEsperaService) notificar(inscripcion *domain.InscripcionListaEspera, ahora time.Time) error {
+	if s.emailClient == nil {
+		return fmt.Errorf("el envío de correos no está configurado")
+	}
+
+	tipo, err := s.habitacionRepo.GetRoomTypeByID(inscripcion.TipoHabitacionID)
+	if err != nil {
+		return fmt.Errorf("error al obtener tipo de habitación: %w", err)
+	}
+
+	cotizacion, err := s.tarifas.Cotizar(inscripcion.TipoHabitacionID, inscripcion.FechaEntrada, inscripcion.FechaSalida)
+	if err != nil {
+		return err
+	}
+
+	token, err := generarTokenListaEspera()
+	if err != nil {
+		return err
+	}
+	vence := ahora.Add(s.duracionEnlace)
+
+	if err := s.repo.MarcarNotificada(inscripcion.ID, token, ahora, vence); err != nil {
+		return err
+	}
+	inscripcion.Estado = domain.ListaEsperaNotificada
+	inscripcion.Token = &token
+	inscripcion.FechaNotificacion = &ahora
+	inscripcion.VenceEnlace = &vence
+
+	if err := s.enviarEmailDisponibilidad(inscripcion, tipo, cotizacion, s.enlaceReserva(inscripcion, tipo, cotizacion, token)); err != nil {
+		if errEstado := s.repo.UpdateEstado(inscripcion.ID, domain.ListaEsperaEsperando); errEstado != nil {
+			return errors.Join(err, errEstado)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// enlaceReserva construye el enlace pre-llenado del sitio web con el token de la inscripción
+func (s *ListaEsperaService) enlaceReserva(inscripcion *domain.InscripcionListaEspera, tipo domain.TipoHabitacion, cotizacion *domain.CotizacionEstadia, token string) string {
+	params := url.Values{}
+	params.Add("checkIn", inscripcion.FechaEntrada.Format("2006-01-02"))
+	params.Add("checkOut", inscripcion.FechaSalida.Format("2006-01-02"))
+	params.Add("adults", fmt.Sprintf("%d", inscripcion.CantidadAdultos))
+	if inscripcion.CantidadNinhos > 0 {
+		params.Add("children", fmt.Sprintf("%d", inscripcion.CantidadNinhos))
+	}
+	params.Add("roomTypeId", fmt.Sprintf("%d", tipo.ID))
+	params.Add("roomType", tipo.Titulo)
+	params.Add("roomPrice", fmt.Sprintf("%.2f", cotizacion.PrecioPromedio()))
+	params.Add("email", inscripcion.Email)
+	params.Add("waitlistToken", token)
+
+	return s.frontendURL + "/reservas/DateSelection?" + params.Encode()
+}
+
+// enviarEmailDisponibilidad avisa al inscrito que se liberó una habitación
+func (s *ListaEsperaService) enviarEmailDisponibilidad(inscripcion *domain.InscripcionListaEspera, tipo domain.TipoHabitacion, cotizacion *domain.CotizacionEstadia, enlace string) error {
+	subject := fmt.Sprintf("Se liberó una habitación %s - Hotel Inca", tipo.Titulo)
+
+	htmlBody := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #4CAF50; color: white; padding: 20px; text-align: center; }
+				.content { padding: 20px; background-color: #f9f9f9; }
+				.footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+				.details { background-color: white; padding: 15px; margin: 10px 0; border-radius: 5px; }
+				.button { display: inline-block; background-color: #4CAF50; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Hotel Inca</h1>
+					<h2>¡Hay disponibilidad para sus fechas!</h2>
+				</div>
+				<div class="content">
+					<p>Estimado/a %s,</p>
+					<p>Se liberó una habitación que estaba esperando en nuestra lista de espera:</p>
+
+					<div class="details">
+						<p><strong>Tipo de Habitación:</strong> %s</p>
+						<p><strong>Fecha de Entrada:</strong> %s</p>
+						<p><strong>Fecha de Salida:</strong> %s</p>
+						<p><strong>Precio de la estadía:</strong> S/. %.2f (promedio S/. %.2f por noche)</p>
+					</div>
+
+					<p style="text-align: center;"><a class="button" href="%s">Reservar ahora</a></p>
+					<p>El enlace es válido hasta el <strong>%s</strong> (UTC). Pasado ese plazo la habitación se ofrecerá a la siguiente persona de la lista.</p>
+				</div>
+				<div class="footer">
+					<p>Hotel Inca - Reservas</p>
+					<p>Este es un correo automático, por favor no responder.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`,
+		inscripcion.Nombre,
+		tipo.Titulo,
+		inscripcion.FechaEntrada.Format("02/01/2006"),
+		inscripcion.FechaSalida.Format("02/01/2006"),
+		cotizacion.Total,
+		cotizacion.PrecioPromedio(),
+		enlace,
+		inscripcion.VenceEnlace.Format("02/01/2006 15:04"),
+	)
+
+	return s.emailClient.SendEmail(inscripcion.Email, subject, htmlBody)
+}
+
+// generarTokenListaEspera genera un token aleatorio para el enlace de reserva
+func generarTokenListaEspera() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("error al generar token: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
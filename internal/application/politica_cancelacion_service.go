@@ -0,0 +1,34 @@
+package application
+
+import (
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type PoliticaCancelacionService struct {
+	repo domain.PoliticaCancelacionRepository
+}
+
+func NewPoliticaCancelacionService(repo domain.PoliticaCancelacionRepository) *PoliticaCancelacionService {
+	return &PoliticaCancelacionService{repo: repo}
+}
+
+// GetAll retorna todas las políticas de cancelación configuradas
+func (s *PoliticaCancelacionService) GetAll() ([]domain.PoliticaCancelacion, error) {
+	return s.repo.GetAll()
+}
+
+// Create valida y registra una nueva política
+func (s *PoliticaCancelacionService) Create(politica *domain.PoliticaCancelacion) error {
+	if err := politica.Validar(); err != nil {
+		return err
+	}
+	return s.repo.Create(politica)
+}
+
+// Update valida y actualiza una política existente
+func (s *PoliticaCancelacionService) Update(politica *domain.PoliticaCancelacion) error {
+	if err := politica.Validar(); err != nil {
+		return err
+	}
+	return s.repo.Update(politica)
+}
@@ -2,6 +2,8 @@ package application
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/Maxito7/hotel_backend/internal/domain"
@@ -16,8 +18,18 @@ type ReservaService struct {
 	clientRepo            domain.ClientRepository
 	paymentRepo           domain.PaymentRepository
 	reservationGuestRepo  domain.ReservationGuestRepository
+	politicaRepo          domain.PoliticaCancelacionRepository
+	historialRepo         domain.HistorialEstadoRepository
+	servicioRepo          domain.ServicioRepository
+	uow                   domain.UnitOfWork
+	availability          *AvailabilityService
+	holdDuration          time.Duration
 	emailClient           *email.Client
 	surveyService         *SatisfactionSurveyService
+	listaEspera           *ListaEsperaService
+	bloqueRepo            domain.BloqueGrupoRepository
+	tarifas               *TarifaService
+	promociones           *CodigoPromocionalService
 }
 
 // NewReservaService crea una nueva instancia del servicio de reservas
@@ -29,8 +41,18 @@ func NewReservaService(
 	clientRepo domain.ClientRepository,
 	paymentRepo domain.PaymentRepository,
 	reservationGuestRepo domain.ReservationGuestRepository,
+	politicaRepo domain.PoliticaCancelacionRepository,
+	historialRepo domain.HistorialEstadoRepository,
+	servicioRepo domain.ServicioRepository,
+	uow domain.UnitOfWork,
+	availability *AvailabilityService,
+	holdDuration time.Duration,
 	emailClient *email.Client,
 	surveyService *SatisfactionSurveyService,
+	listaEspera *ListaEsperaService,
+	bloqueRepo domain.BloqueGrupoRepository,
+	tarifas *TarifaService,
+	promociones *CodigoPromocionalService,
 ) *ReservaService {
 	return &ReservaService{
 		reservaRepo:           reservaRepo,
@@ -40,13 +62,31 @@ func NewReservaService(
 		clientRepo:            clientRepo,
 		paymentRepo:           paymentRepo,
 		reservationGuestRepo:  reservationGuestRepo,
+		politicaRepo:          politicaRepo,
+		historialRepo:         historialRepo,
+		servicioRepo:          servicioRepo,
+		uow:                   uow,
+		availability:          availability,
+		holdDuration:          holdDuration,
 		emailClient:           emailClient,
 		surveyService:         surveyService,
+		listaEspera:           listaEspera,
+		bloqueRepo:            bloqueRepo,
+		tarifas:               tarifas,
+		promociones:           promociones,
 	}
 }
 
 // CreateReserva crea una nueva reserva validando disponibilidad
 func (s *ReservaService) CreateReserva(reserva *domain.Reserva) error {
+	return s.uow.Do(func(repos domain.Repositories) error {
+		return s.createReserva(repos, reserva, domain.ActorAPI)
+	})
+}
+
+// createReserva valida la reserva, la persiste con los repositorios de la transacción
+// en curso y registra su estado inicial en el historial
+func (s *ReservaService) createReserva(repos domain.Repositories, reserva *domain.Reserva, actor string) error {
 	// Validar que la reserva tenga habitaciones
 	if len(reserva.Habitaciones) == 0 {
 		return fmt.Errorf("la reserva debe tener al menos una habitación")
@@ -60,7 +100,7 @@ func (s *ReservaService) CreateReserva(reserva *domain.Reserva) error {
 		}
 
 		// Verificar disponibilidad
-		disponible, err := s.reservaHabitacionRepo.VerificarDisponibilidad(
+		disponible, err := s.availability.VerificarDisponibilidad(
 			hab.HabitacionID,
 			hab.FechaEntrada,
 			hab.FechaSalida,
@@ -69,327 +109,1538 @@ func (s *ReservaService) CreateReserva(reserva *domain.Reserva) error {
 			return fmt.Errorf("error al verificar disponibilidad: %w", err)
 		}
 
-		if !disponible {
-			return fmt.Errorf("la habitación %d no está disponible para las fechas seleccionadas", hab.HabitacionID)
+		if !disponible {
+			return fmt.Errorf("%w: habitación %d", domain.ErrHabitacionNoDisponible, hab.HabitacionID)
+		}
+
+		habitacion, err := s.habitacionRepo.GetRoomByID(hab.HabitacionID)
+		if err != nil {
+			return fmt.Errorf("error al obtener habitación %d: %w", hab.HabitacionID, err)
+		}
+
+		// Las recogidas de bloques de grupo respetan las fechas pactadas del bloque
+		if reserva.BloqueGrupoID == nil {
+			if err := s.availability.VerificarRestricciones(habitacion.TipoHabitacion.ID, hab.FechaEntrada, hab.FechaSalida); err != nil {
+				return err
+			}
+		}
+
+		// Las habitaciones asignadas con AsignarHabitaciones ya vienen cotizadas
+		if hab.Total <= 0 {
+			cotizacion, err := s.cotizarEstadia(reserva.BloqueGrupoID, hab.PlanTarifaID, habitacion.TipoHabitacion.ID, hab.FechaEntrada, hab.FechaSalida)
+			if err != nil {
+				return err
+			}
+			reserva.Habitaciones[i].AplicarCotizacion(*cotizacion)
+		}
+	}
+
+	// Verificar que los huéspedes quepan y cobrar los adultos y niños adicionales
+	if err := s.aplicarRecargosHuespedes(reserva, reserva.Habitaciones); err != nil {
+		return err
+	}
+
+	// Completar precio y cantidad de los servicios adicionales
+	for i := range reserva.Servicios {
+		if err := s.prepararServicio(reserva, &reserva.Servicios[i]); err != nil {
+			return err
+		}
+	}
+
+	// Los servicios incluidos en los planes tarifarios se agregan sin costo
+	if err := s.agregarServiciosIncluidos(reserva); err != nil {
+		return err
+	}
+
+	// Calcular subtotal solo si no fue proporcionado
+	if reserva.Subtotal <= 0 {
+		reserva.Subtotal = costoHabitaciones(reserva.Habitaciones) + costoServicios(reserva.Servicios)
+	}
+
+	// Con código promocional el descuento lo calcula el servidor sobre las habitaciones cotizadas
+	var codigo *domain.CodigoPromocional
+	if strings.TrimSpace(reserva.CodigoPromocional) != "" {
+		var err error
+		codigo, reserva.Descuento, err = s.promociones.CalcularDescuento(reserva.CodigoPromocional, reserva.Habitaciones)
+		if err != nil {
+			return err
+		}
+		reserva.CodigoPromocional = codigo.Codigo
+	}
+
+	// Si no se especificó descuento, establecerlo en 0
+	if reserva.Descuento < 0 {
+		reserva.Descuento = 0
+	}
+
+	// Validar que el descuento no sea mayor al subtotal
+	if reserva.Descuento > reserva.Subtotal {
+		return fmt.Errorf("el descuento no puede ser mayor al subtotal")
+	}
+
+	// Establecer fecha de confirmación si no se proporcionó
+	if reserva.FechaConfirmacion.IsZero() {
+		reserva.FechaConfirmacion = time.Now()
+	}
+
+	// Establecer estado inicial si no se especificó
+	if reserva.Estado == "" {
+		reserva.Estado = domain.ReservaPendiente
+	}
+	if reserva.Estado != domain.ReservaPendiente && reserva.Estado != domain.ReservaConfirmada {
+		return fmt.Errorf("%w: una reserva nueva no puede crearse como %s", domain.ErrTransicionInvalida, reserva.Estado)
+	}
+
+	// Las reservas pendientes solo retienen las habitaciones durante holdDuration
+	if reserva.Estado == domain.ReservaPendiente {
+		venceRetencion := time.Now().UTC().Add(s.holdDuration)
+		reserva.VenceRetencion = &venceRetencion
+	} else {
+		reserva.VenceRetencion = nil
+	}
+
+	// Crear la reserva
+	if err := repos.Reserva.CreateReserva(reserva); err != nil {
+		return fmt.Errorf("error al crear reserva: %w", err)
+	}
+	reserva.CalcularRetencionRestante(time.Now().UTC())
+
+	// El canje vuelve a verificar el límite de usos con el código bloqueado
+	if codigo != nil {
+		canje := &domain.CanjeCodigoPromocional{
+			CodigoPromocionalID: codigo.ID,
+			ReservaID:           reserva.ID,
+			Monto:               reserva.Descuento,
+			Fecha:               time.Now().UTC(),
+		}
+		if err := repos.CodigoPromocional.RegistrarCanje(canje); err != nil {
+			return err
+		}
+	}
+
+	return repos.HistorialEstado.Create(&domain.HistorialEstadoReserva{
+		ReservaID:   reserva.ID,
+		EstadoNuevo: reserva.Estado,
+		Fecha:       time.Now().UTC(),
+		Actor:       actor,
+		Motivo:      "Reserva creada",
+	})
+}
+
+// AsignarHabitaciones convierte solicitudes por tipo y cantidad en habitaciones concretas y
+// libres, cotizadas noche a noche con el calendario de tarifas. La creación de la reserva vuelve
+// a verificar todas las habitaciones en una sola transacción, así que o se reservan todas o ninguna
+func (s *ReservaService) AsignarHabitaciones(solicitudes []domain.SolicitudHabitaciones) ([]domain.ReservaHabitacion, error) {
+	if len(solicitudes) == 0 {
+		return nil, fmt.Errorf("la reserva debe tener al menos una habitación")
+	}
+
+	cotizaciones := make([]*domain.CotizacionEstadia, len(solicitudes))
+	for i, solicitud := range solicitudes {
+		cotizacion, err := s.cotizarSolicitud(solicitud)
+		if err != nil {
+			return nil, err
+		}
+		solicitudes[i].Precio = cotizacion.PrecioPromedio()
+		cotizaciones[i] = cotizacion
+	}
+
+	habitaciones, err := s.availability.AsignarHabitaciones(solicitudes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Las habitaciones se asignan en el orden de las solicitudes, Cantidad por cada una
+	k := 0
+	for i, solicitud := range solicitudes {
+		for j := 0; j < solicitud.Cantidad; j++ {
+			habitaciones[k].AplicarCotizacion(*cotizaciones[i])
+			if solicitud.PlanTarifaID != 0 {
+				planTarifaID := solicitud.PlanTarifaID
+				habitaciones[k].PlanTarifaID = &planTarifaID
+			}
+			k++
+		}
+	}
+
+	return habitaciones, nil
+}
+
+// cotizarSolicitud cotiza una solicitud de habitaciones: un precio indicado se cobra igual todas
+// las noches y, si no, se usa la tarifa negociada del bloque o el calendario con el plan elegido
+func (s *ReservaService) cotizarSolicitud(solicitud domain.SolicitudHabitaciones) (*domain.CotizacionEstadia, error) {
+	if solicitud.Precio > 0 {
+		return s.tarifas.CotizarPrecioFijo(solicitud.TipoHabitacionID, solicitud.Precio, solicitud.FechaEntrada, solicitud.FechaSalida)
+	}
+
+	var bloqueGrupoID, planTarifaID *int
+	if solicitud.BloqueGrupoID != 0 {
+		bloqueGrupoID = &solicitud.BloqueGrupoID
+	}
+	if solicitud.PlanTarifaID != 0 {
+		planTarifaID = &solicitud.PlanTarifaID
+	}
+	return s.cotizarEstadia(bloqueGrupoID, planTarifaID, solicitud.TipoHabitacionID, solicitud.FechaEntrada, solicitud.FechaSalida)
+}
+
+// cotizarEstadia cotiza una habitación del tipo para la estadía. Las recogidas de un bloque de
+// grupo con tarifa negociada pagan ese precio todas las noches; si no, se aplica el plan tarifario
+func (s *ReservaService) cotizarEstadia(bloqueGrupoID, planTarifaID *int, tipoHabitacionID int, fechaEntrada, fechaSalida time.Time) (*domain.CotizacionEstadia, error) {
+	if bloqueGrupoID != nil && s.bloqueRepo != nil {
+		bloque, err := s.bloqueRepo.GetByID(*bloqueGrupoID)
+		if err != nil {
+			return nil, err
+		}
+		if bloque.Precio != nil {
+			return s.tarifas.CotizarPrecioFijo(tipoHabitacionID, *bloque.Precio, fechaEntrada, fechaSalida)
+		}
+	}
+	if planTarifaID != nil {
+		return s.tarifas.CotizarPlan(*planTarifaID, tipoHabitacionID, fechaEntrada, fechaSalida)
+	}
+
+	return s.tarifas.Cotizar(tipoHabitacionID, fechaEntrada, fechaSalida)
+}
+
+// aplicarRecargosHuespedes reparte los huéspedes de la reserva entre las habitaciones, verifica
+// que quepan y recalcula el recargo por adultos y niños adicionales de cada habitación
+func (s *ReservaService) aplicarRecargosHuespedes(reserva *domain.Reserva, habitaciones []domain.ReservaHabitacion) error {
+	tipos := make([]domain.TipoHabitacion, len(habitaciones))
+	for i, hab := range habitaciones {
+		habitacion, err := s.habitacionRepo.GetRoomByID(hab.HabitacionID)
+		if err != nil {
+			return fmt.Errorf("error al obtener habitación %d: %w", hab.HabitacionID, err)
+		}
+		tipos[i] = habitacion.TipoHabitacion
+	}
+
+	tarifas, err := s.tarifas.TarifasOcupacion(tipos)
+	if err != nil {
+		return err
+	}
+
+	ocupaciones, err := domain.DistribuirHuespedes(tipos, tarifas, reserva.CantidadAdultos, reserva.CantidadNinhos, reserva.EdadesNinhos)
+	if err != nil {
+		return err
+	}
+
+	for i := range habitaciones {
+		habitaciones[i].AplicarRecargos(tarifas[i].Recargos(ocupaciones[i], habitaciones[i].CantidadNoches()))
+	}
+
+	return nil
+}
+
+// agregarServiciosIncluidos agrega a la reserva, sin costo, los servicios incluidos en el plan
+// tarifario de cada habitación durante su estadía
+func (s *ReservaService) agregarServiciosIncluidos(reserva *domain.Reserva) error {
+	planes := make(map[int]*domain.PlanTarifa)
+	for _, hab := range reserva.Habitaciones {
+		if hab.PlanTarifaID == nil {
+			continue
+		}
+
+		plan, ok := planes[*hab.PlanTarifaID]
+		if !ok {
+			var err error
+			if plan, err = s.tarifas.GetPlan(*hab.PlanTarifaID); err != nil {
+				return err
+			}
+			planes[plan.ID] = plan
+		}
+
+		for _, servicioID := range plan.ServiciosIncluidos {
+			servicio := domain.ReservaServicio{
+				ServiceID: servicioID,
+				StartDate: hab.FechaEntrada,
+				EndDate:   hab.FechaSalida,
+				Quantity:  1,
+			}
+			if err := s.prepararServicio(reserva, &servicio); err != nil {
+				return err
+			}
+			servicio.UnitPrice = 0
+			reserva.Servicios = append(reserva.Servicios, servicio)
+		}
+	}
+
+	return nil
+}
+
+// FindAvailableRoomByType busca una habitación disponible de un tipo específico para las fechas dadas
+func (s *ReservaService) FindAvailableRoomByType(roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
+	return s.availability.FindAvailableRoomByType(roomTypeID, fechaEntrada, fechaSalida)
+}
+
+// CreateReservaWithClient crea una reserva buscando/creando primero el cliente.
+// Persona, cliente y reserva se registran en una sola transacción. Lo usa el chatbot,
+// por lo que el historial registra al chatbot como actor
+func (s *ReservaService) CreateReservaWithClient(person *domain.Person, reserva *domain.Reserva) error {
+	return s.uow.Do(func(repos domain.Repositories) error {
+		// 1. Buscar persona por document_number
+		existingPerson, err := repos.Person.FindByDocumentNumber(person.DocumentNumber)
+		if err != nil {
+			return fmt.Errorf("error al buscar persona: %w", err)
+		}
+
+		var personID int
+
+		// 2. Si no existe, crear la persona
+		if existingPerson == nil {
+			if err := repos.Person.Create(person); err != nil {
+				return fmt.Errorf("error al crear persona: %w", err)
+			}
+			personID = person.PersonID
+		} else {
+			personID = existingPerson.PersonID
+		}
+
+		// 3. Buscar o crear el cliente y asignarlo a la reserva
+		clientID, err := s.findOrCreateClient(repos.Client, personID, reserva.CantidadNinhos)
+		if err != nil {
+			return err
+		}
+		reserva.ClienteID = clientID
+
+		// 4. Crear la reserva con el resto de la lógica existente
+		return s.createReserva(repos, reserva, domain.ActorChatbot)
+	})
+}
+
+// CreateReservaWithClientAndPayment crea una reserva con cliente, huéspedes adicionales y pago.
+// Todos los pasos se ejecutan en una única transacción: si alguno falla no queda nada registrado
+func (s *ReservaService) CreateReservaWithClientAndPayment(
+	person *domain.Person,
+	reserva *domain.Reserva,
+	huespedes []domain.Person,
+	payment *domain.Payment,
+) error {
+	var clientID int
+
+	err := s.uow.Do(func(repos domain.Repositories) error {
+		// 1. Crear o actualizar la persona titular con los datos del JSON
+		personID, err := s.upsertPerson(repos.Person, person)
+		if err != nil {
+			return err
+		}
+
+		// 2. Buscar o crear el cliente y asignarlo a la reserva
+		clientID, err = s.findOrCreateClient(repos.Client, personID, reserva.CantidadNinhos)
+		if err != nil {
+			return err
+		}
+		reserva.ClienteID = clientID
+
+		// 3. Crear la reserva
+		if err := s.createReserva(repos, reserva, domain.ActorAPI); err != nil {
+			return err
+		}
+
+		// 4. Crear los huéspedes adicionales (si existen)
+		if len(huespedes) > 0 {
+			var personIDs []int
+
+			for i := range huespedes {
+				guestPersonID, err := s.upsertGuest(repos.Person, &huespedes[i])
+				if err != nil {
+					return fmt.Errorf("error al registrar huésped %d: %w", i+1, err)
+				}
+				personIDs = append(personIDs, guestPersonID)
+			}
+
+			// Crear las relaciones en reservation_guest
+			if err := repos.ReservationGuest.CreateMultiple(reserva.ID, personIDs); err != nil {
+				return fmt.Errorf("error al registrar huéspedes: %w", err)
+			}
+		}
+
+		// 5. Si se proporcionó pago, crearlo
+		if payment != nil {
+			payment.ReservationID = reserva.ID
+			if err := repos.Payment.Create(payment); err != nil {
+				return fmt.Errorf("error al registrar pago: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 6. Generar token de encuesta y enviar email (solo si surveyService está disponible)
+	if s.surveyService != nil {
+		s.generarYEnviarEncuesta(reserva.ID, clientID, person.Email)
+	}
+
+	return nil
+}
+
+// CreateReservaCanal registra una reserva recibida de un canal de venta (reserva.Canal y
+// reserva.ReferenciaCanal deben venir completos). El canal ya cobró o garantizó la estadía, por
+// lo que se crea confirmada salvo que se indique otro estado. Los datos de una persona existente
+// no se sobrescriben con los que envía el canal
+func (s *ReservaService) CreateReservaCanal(person *domain.Person, reserva *domain.Reserva) error {
+	if reserva.Canal == "" || reserva.ReferenciaCanal == "" {
+		return fmt.Errorf("%w: la reserva debe indicar el canal y su referencia", domain.ErrCanalInvalido)
+	}
+	if reserva.Estado == "" {
+		reserva.Estado = domain.ReservaConfirmada
+	}
+
+	return s.uow.Do(func(repos domain.Repositories) error {
+		existingPerson, err := repos.Person.FindByDocumentNumber(person.DocumentNumber)
+		if err != nil {
+			return fmt.Errorf("error al buscar persona: %w", err)
+		}
+
+		personID := 0
+		if existingPerson == nil {
+			if err := repos.Person.Create(person); err != nil {
+				return fmt.Errorf("error al crear persona: %w", err)
+			}
+			personID = person.PersonID
+		} else {
+			personID = existingPerson.PersonID
+		}
+
+		clientID, err := s.findOrCreateClient(repos.Client, personID, reserva.CantidadNinhos)
+		if err != nil {
+			return err
+		}
+		reserva.ClienteID = clientID
+
+		return s.createReserva(repos, reserva, domain.ActorCanal)
+	})
+}
+
+// GetReservaCanal obtiene la reserva registrada para una referencia de un canal
+func (s *ReservaService) GetReservaCanal(canal, referencia string) (*domain.Reserva, error) {
+	return s.reservaRepo.GetReservaByReferenciaCanal(canal, referencia)
+}
+
+// upsertPerson busca a la persona titular por documento; si no existe la crea y si
+// existe actualiza sus datos para que el email y demás información estén al día
+func (s *ReservaService) upsertPerson(personRepo domain.PersonRepository, person *domain.Person) (int, error) {
+	existingPerson, err := personRepo.FindByDocumentNumber(person.DocumentNumber)
+	if err != nil {
+		return 0, fmt.Errorf("error al buscar persona: %w", err)
+	}
+
+	if existingPerson == nil {
+		if err := personRepo.Create(person); err != nil {
+			return 0, fmt.Errorf("error al crear persona: %w", err)
+		}
+		return person.PersonID, nil
+	}
+
+	existingPerson.Name = person.Name
+	existingPerson.FirstSurname = person.FirstSurname
+	existingPerson.SecondSurname = person.SecondSurname
+	existingPerson.Gender = person.Gender
+	existingPerson.Email = person.Email // ← IMPORTANTE: Actualizar el email del JSON
+	existingPerson.Phone1 = person.Phone1
+	existingPerson.Phone2 = person.Phone2
+	existingPerson.ReferenceCity = person.ReferenceCity
+	existingPerson.ReferenceCountry = person.ReferenceCountry
+	existingPerson.BirthDate = person.BirthDate
+
+	if err := personRepo.Update(existingPerson); err != nil {
+		return 0, fmt.Errorf("error al actualizar persona: %w", err)
+	}
+
+	return existingPerson.PersonID, nil
+}
+
+// upsertGuest crea o actualiza un huésped adicional y retorna su person_id
+func (s *ReservaService) upsertGuest(personRepo domain.PersonRepository, guest *domain.Person) (int, error) {
+	existingGuest, err := personRepo.FindByDocumentNumber(guest.DocumentNumber)
+	if err != nil {
+		return 0, fmt.Errorf("error al buscar huésped: %w", err)
+	}
+
+	if existingGuest == nil {
+		if err := personRepo.Create(guest); err != nil {
+			return 0, fmt.Errorf("error al crear huésped: %w", err)
+		}
+		return guest.PersonID, nil
+	}
+
+	existingGuest.Name = guest.Name
+	existingGuest.FirstSurname = guest.FirstSurname
+	existingGuest.SecondSurname = guest.SecondSurname
+	existingGuest.Gender = guest.Gender
+	existingGuest.Email = guest.Email
+	existingGuest.Phone1 = guest.Phone1
+	existingGuest.BirthDate = guest.BirthDate
+
+	if err := personRepo.Update(existingGuest); err != nil {
+		return 0, fmt.Errorf("error al actualizar huésped: %w", err)
+	}
+
+	return existingGuest.PersonID, nil
+}
+
+// findOrCreateClient obtiene el client_id de una persona, creándolo si no existe
+func (s *ReservaService) findOrCreateClient(clientRepo domain.ClientRepository, personID int, cantidadNinhos int) (int, error) {
+	clientID, err := clientRepo.GetClientIDByPersonID(personID)
+	if err == nil {
+		return clientID, nil
+	}
+
+	clientID, err = clientRepo.Create(personID, domain.CaptureChannelWebpage, domain.CaptureStatusCliente, cantidadNinhos)
+	if err != nil {
+		return 0, fmt.Errorf("error al crear cliente: %w", err)
+	}
+
+	return clientID, nil
+}
+
+// GetReservaByID obtiene una reserva por su ID
+func (s *ReservaService) GetReservaByID(id int) (*domain.Reserva, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, err
+	}
+	reserva.CalcularRetencionRestante(time.Now().UTC())
+
+	return reserva, nil
+}
+
+// BuscarReservaPorCodigo permite a un huésped recuperar su reserva con el código de
+// confirmación y el email con que reservó. Si el email no coincide se responde igual que
+// si el código no existiera, para no revelar qué códigos son válidos
+func (s *ReservaService) BuscarReservaPorCodigo(codigo, email string) (*domain.Reserva, error) {
+	codigo = domain.NormalizarCodigoReserva(codigo)
+	email = strings.TrimSpace(email)
+	if codigo == "" || email == "" {
+		return nil, fmt.Errorf("el código y el email son requeridos")
+	}
+
+	reserva, err := s.reservaRepo.GetReservaByCodigo(codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	emailCliente, err := s.clientRepo.GetPersonEmailByClientID(reserva.ClienteID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener email del cliente: %w", err)
+	}
+	if !strings.EqualFold(strings.TrimSpace(emailCliente), email) {
+		return nil, fmt.Errorf("%w: código %s", domain.ErrReservaNoEncontrada, codigo)
+	}
+
+	reserva.CalcularRetencionRestante(time.Now().UTC())
+	return reserva, nil
+}
+
+// GetReservasCliente obtiene todas las reservas de un cliente
+func (s *ReservaService) GetReservasCliente(clienteID int) ([]domain.Reserva, error) {
+	reservas, err := s.reservaRepo.GetReservasCliente(clienteID)
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now().UTC()
+	for i := range reservas {
+		reservas[i].CalcularRetencionRestante(ahora)
+	}
+
+	return reservas, nil
+}
+
+// UpdateReservaEstado cambia el estado de una reserva respetando la máquina de estados
+// y deja constancia en el historial
+func (s *ReservaService) UpdateReservaEstado(id int, estado domain.EstadoReserva, cambio domain.CambioEstado) error {
+	if !estado.EsValido() {
+		return fmt.Errorf("estado de reserva inválido: %s", estado)
+	}
+
+	// La cancelación y el no-show siempre pasan por la política de cancelación; la llegada y la
+	// salida del huésped, por el check-in y el check-out de recepción
+	switch estado {
+	case domain.ReservaCancelada:
+		_, err := s.CancelarReserva(id, cambio)
+		return err
+	case domain.ReservaNoShow:
+		_, err := s.MarcarNoShow(id, cambio)
+		return err
+	case domain.ReservaEnCurso:
+		_, err := s.CheckIn(id, nil, cambio)
+		return err
+	case domain.ReservaCompletada:
+		_, err := s.CheckOut(id, nil, cambio)
+		return err
+	}
+
+	// Obtener la reserva actual
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return fmt.Errorf("error al obtener reserva: %w", err)
+	}
+
+	// Una reserva pendiente con la retención vencida ya no tiene habitaciones garantizadas
+	if estado == domain.ReservaConfirmada && reserva.RetencionVencida(time.Now().UTC()) {
+		return fmt.Errorf("%w: reserva %d", domain.ErrRetencionVencida, id)
+	}
+
+	return s.uow.Do(func(repos domain.Repositories) error {
+		return s.cambiarEstado(repos, reserva, estado, cambio)
+	})
+}
+
+// cambiarEstado valida la transición, actualiza la reserva y registra el cambio en el
+// historial usando los repositorios de la transacción en curso. Falla con
+// ErrTransicionInvalida si la reserva ya no está en el estado con que se leyó
+func (s *ReservaService) cambiarEstado(repos domain.Repositories, reserva *domain.Reserva, destino domain.EstadoReserva, cambio domain.CambioEstado) error {
+	if err := domain.ValidarTransicion(reserva.Estado, destino); err != nil {
+		return err
+	}
+
+	// La actualización exige el estado leído para que dos cambios concurrentes no pasen ambos
+	// la validación (por ejemplo, dos cancelaciones que registrarían dos reembolsos)
+	if err := repos.Reserva.UpdateReservaEstado(reserva.ID, reserva.Estado, destino); err != nil {
+		return err
+	}
+
+	actor := cambio.Actor
+	if actor == "" {
+		actor = domain.ActorAPI
+	}
+
+	anterior := reserva.Estado
+	if err := repos.HistorialEstado.Create(&domain.HistorialEstadoReserva{
+		ReservaID:      reserva.ID,
+		EstadoAnterior: &anterior,
+		EstadoNuevo:    destino,
+		Fecha:          time.Now().UTC(),
+		Actor:          actor,
+		Motivo:         cambio.Motivo,
+	}); err != nil {
+		return err
+	}
+
+	reserva.Estado = destino
+	return nil
+}
+
+// GetHistorialEstados obtiene los cambios de estado de una reserva en orden cronológico
+func (s *ReservaService) GetHistorialEstados(id int) ([]domain.HistorialEstadoReserva, error) {
+	if _, err := s.reservaRepo.GetReservaByID(id); err != nil {
+		return nil, err
+	}
+	return s.historialRepo.GetByReservaID(id)
+}
+
+// CancelarReserva cancela una reserva aplicando la política de cancelación de cada habitación.
+// Libera las habitaciones y registra como reembolso lo pagado que exceda la penalidad
+func (s *ReservaService) CancelarReserva(id int, cambio domain.CambioEstado) (*domain.DesgloseCancelacion, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
+	}
+
+	if err := domain.ValidarTransicion(reserva.Estado, domain.ReservaCancelada); err != nil {
+		return nil, err
+	}
+
+	desglose, err := s.CalcularCancelacion(reserva, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(func(repos domain.Repositories) error {
+		for _, hab := range reserva.Habitaciones {
+			if err := repos.ReservaHabitacion.UpdateReservaHabitacionEstado(
+				id,
+				hab.HabitacionID,
+				0, // Estado cancelado
+			); err != nil {
+				return fmt.Errorf("error al cancelar habitación: %w", err)
+			}
+		}
+
+		if err := s.cambiarEstado(repos, reserva, domain.ReservaCancelada, cambio); err != nil {
+			return err
+		}
+
+		if desglose.Pago != nil {
+			if err := repos.Payment.Create(desglose.Pago); err != nil {
+				return fmt.Errorf("error al registrar reembolso: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notificarListaEspera()
+
+	return desglose, nil
+}
+
+// ProcesarNoShows marca como no-show las reservas confirmadas sin check-in cuya fecha de entrada
+// (a las 00:00) más corte ya pasó, o cuya estadía ya terminó. Retorna los IDs procesados; una
+// reserva que falla no impide procesar las demás
+func (s *ReservaService) ProcesarNoShows(ahora time.Time, corte time.Duration) ([]int, error) {
+	ids, err := s.reservaRepo.GetNoShowCandidatos(ahora.Add(-corte), ahora)
+	if err != nil {
+		return nil, err
+	}
+
+	procesadas := make([]int, 0, len(ids))
+	var errs []string
+	for _, id := range ids {
+		if _, err := s.MarcarNoShow(id, domain.CambioEstado{
+			Actor:  domain.ActorSistema,
+			Motivo: "No-show: el huésped no se presentó",
+		}); err != nil {
+			errs = append(errs, fmt.Sprintf("reserva %d: %v", id, err))
+			continue
+		}
+		procesadas = append(procesadas, id)
+	}
+
+	if len(errs) > 0 {
+		return procesadas, fmt.Errorf("error al procesar no-shows: %s", strings.Join(errs, "; "))
+	}
+
+	return procesadas, nil
+}
+
+// MarcarNoShow marca la reserva como no-show: libera sus noches, aplica la penalidad de no-show
+// de la política de cancelación y deja una alerta para recepción
+func (s *ReservaService) MarcarNoShow(id int, cambio domain.CambioEstado) (*domain.DesgloseCancelacion, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
+	}
+
+	if err := domain.ValidarTransicion(reserva.Estado, domain.ReservaNoShow); err != nil {
+		return nil, err
+	}
+	if reserva.FechaCheckIn != nil {
+		return nil, fmt.Errorf("%w: la reserva ya registró check-in", domain.ErrTransicionInvalida)
+	}
+
+	desglose, err := s.CalcularNoShow(reserva)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(func(repos domain.Repositories) error {
+		for _, hab := range reserva.Habitaciones {
+			if err := repos.ReservaHabitacion.UpdateReservaHabitacionEstado(
+				id,
+				hab.HabitacionID,
+				0, // Se liberan las noches restantes
+			); err != nil {
+				return fmt.Errorf("error al liberar habitación: %w", err)
+			}
+		}
+
+		if err := s.cambiarEstado(repos, reserva, domain.ReservaNoShow, cambio); err != nil {
+			return err
+		}
+
+		if desglose.Pago != nil {
+			if err := repos.Payment.Create(desglose.Pago); err != nil {
+				return fmt.Errorf("error al registrar pago de no-show: %w", err)
+			}
+		}
+
+		return repos.Alerta.Create(&domain.Alerta{
+			Tipo: domain.AlertaNoShow,
+			Mensaje: fmt.Sprintf(
+				"No-show %s: penalidad S/. %.2f, pagado S/. %.2f, reembolso S/. %.2f",
+				reserva.Codigo, desglose.Penalidad, desglose.MontoPagado, desglose.Reembolso,
+			),
+			ReservaID: &reserva.ID,
+			Fecha:     time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notificarListaEspera()
+
+	return desglose, nil
+}
+
+// notificarListaEspera avisa a la lista de espera que se liberó inventario
+func (s *ReservaService) notificarListaEspera() {
+	if s.listaEspera != nil {
+		s.listaEspera.NotificarEnSegundoPlano()
+	}
+}
+
+// CalcularCancelacion calcula penalidad y reembolso de cancelar la reserva en el momento dado,
+// sin modificar nada
+func (s *ReservaService) CalcularCancelacion(reserva *domain.Reserva, ahora time.Time) (*domain.DesgloseCancelacion, error) {
+	return s.calcularDesglose(reserva, func(politica domain.PoliticaCancelacion, hab domain.ReservaHabitacion) float64 {
+		return politica.PenalidadHabitacion(hab, ahora)
+	})
+}
+
+// CalcularNoShow calcula la penalidad de no-show de la reserva, sin modificar nada. Si lo pagado
+// no cubre la penalidad, Pago es el cobro pendiente de la diferencia
+func (s *ReservaService) CalcularNoShow(reserva *domain.Reserva) (*domain.DesgloseCancelacion, error) {
+	desglose, err := s.calcularDesglose(reserva, func(politica domain.PoliticaCancelacion, hab domain.ReservaHabitacion) float64 {
+		return politica.PenalidadNoShow(hab)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if desglose.Pago == nil {
+		if pendiente := math.Round((desglose.Penalidad-desglose.MontoPagado)*100) / 100; pendiente > 0 {
+			desglose.Pago = s.pagoPorDiferencia(reserva.ID, pendiente)
+		}
+	}
+
+	return desglose, nil
+}
+
+// calcularDesglose aplica penalidadFn a cada habitación de la reserva con su política y calcula
+// el reembolso correspondiente a lo pagado
+func (s *ReservaService) calcularDesglose(
+	reserva *domain.Reserva,
+	penalidadFn func(politica domain.PoliticaCancelacion, hab domain.ReservaHabitacion) float64,
+) (*domain.DesgloseCancelacion, error) {
+	desglose := &domain.DesgloseCancelacion{
+		ReservaID:    reserva.ID,
+		Habitaciones: make([]domain.PenalidadHabitacionDetalle, 0, len(reserva.Habitaciones)),
+	}
+
+	for _, hab := range reserva.Habitaciones {
+		politica, err := s.politicaParaHabitacion(hab)
+		if err != nil {
+			return nil, err
+		}
+
+		penalidad := penalidadFn(politica, hab)
+		desglose.Habitaciones = append(desglose.Habitaciones, domain.PenalidadHabitacionDetalle{
+			HabitacionID: hab.HabitacionID,
+			Politica:     politica.Nombre,
+			Importe:      costoHabitaciones([]domain.ReservaHabitacion{hab}),
+			Penalidad:    penalidad,
+		})
+		desglose.Penalidad += penalidad
+	}
+
+	// La penalidad nunca supera lo que cuesta la reserva
+	if total := reserva.Subtotal - reserva.Descuento; desglose.Penalidad > total {
+		desglose.Penalidad = total
+	}
+
+	pagos, err := s.paymentRepo.GetAllByReservationID(reserva.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener pagos: %w", err)
+	}
+	desglose.MontoPagado = domain.MontoPagadoNeto(pagos)
+
+	if reembolso := desglose.MontoPagado - desglose.Penalidad; reembolso > 0 {
+		desglose.Reembolso = reembolso
+		desglose.Pago = &domain.Payment{
+			Amount:        reembolso,
+			Date:          time.Now(),
+			PaymentMethod: metodoPagoOriginal(pagos),
+			Status:        domain.PaymentStatusReembolso,
+			ReservationID: reserva.ID,
+		}
+	}
+
+	return desglose, nil
+}
+
+// politicaParaHabitacion resuelve la política de cancelación aplicable a una habitación reservada
+func (s *ReservaService) politicaParaHabitacion(hab domain.ReservaHabitacion) (domain.PoliticaCancelacion, error) {
+	// Un plan tarifario con condiciones propias prevalece sobre la política del tipo
+	if hab.PlanTarifaID != nil {
+		plan, err := s.tarifas.GetPlan(*hab.PlanTarifaID)
+		if err != nil {
+			return domain.PoliticaCancelacion{}, err
+		}
+		if plan.Cancelacion != nil {
+			return *plan.Cancelacion, nil
+		}
+	}
+
+	habitacion, err := s.habitacionRepo.GetRoomByID(hab.HabitacionID)
+	if err != nil {
+		return domain.PoliticaCancelacion{}, fmt.Errorf("error al obtener habitación %d: %w", hab.HabitacionID, err)
+	}
+
+	politica, err := s.politicaRepo.GetParaTipoHabitacion(habitacion.TipoHabitacion.ID)
+	if err != nil {
+		return domain.PoliticaCancelacion{}, err
+	}
+	if politica == nil {
+		return domain.PoliticaCancelacionPorDefecto, nil
+	}
+
+	return *politica, nil
+}
+
+// metodoPagoOriginal retorna el medio del primer pago aprobado, o tarjeta si no hay ninguno
+func metodoPagoOriginal(pagos []domain.Payment) domain.PaymentMethod {
+	for _, p := range pagos {
+		if p.Status == domain.PaymentStatusAprobado {
+			return p.PaymentMethod
+		}
+	}
+	return domain.PaymentMethodTarjeta
+}
+
+// ConfirmarReserva confirma una reserva pendiente y envía email de confirmación
+func (s *ReservaService) ConfirmarReserva(id int, cambio domain.CambioEstado) error {
+	return s.confirmarReservaInternal(id, cambio, true) // true = enviar email
+}
+
+// ConfirmarReservaSinEmail confirma una reserva sin enviar email
+func (s *ReservaService) ConfirmarReservaSinEmail(id int, cambio domain.CambioEstado) error {
+	return s.confirmarReservaInternal(id, cambio, false) // false = no enviar email
+}
+
+// confirmarReservaInternal es el método interno que maneja la confirmación
+func (s *ReservaService) confirmarReservaInternal(id int, cambio domain.CambioEstado, enviarEmail bool) error {
+	// Actualizar estado
+	if err := s.UpdateReservaEstado(id, domain.ReservaConfirmada, cambio); err != nil {
+		return err
+	}
+
+	// Solo enviar email si se solicita
+	if !enviarEmail {
+		return nil
+	}
+
+	// Obtener datos completos de la reserva para el email
+	reserva, err := s.GetReservaByID(id)
+	if err != nil {
+		// Log error pero no fallar si el email falla
+		fmt.Printf("Error al obtener reserva para email: %v\n", err)
+		return nil // La confirmación ya se hizo, solo falló el email
+	}
+
+	// Enviar email de confirmación
+	if s.emailClient != nil {
+		if err := s.enviarEmailConfirmacion(reserva); err != nil {
+			// Log error pero no fallar
+			fmt.Printf("Error al enviar email de confirmación: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// enviarEmailConfirmacion envía el email de confirmación de la reserva
+func (s *ReservaService) enviarEmailConfirmacion(reserva *domain.Reserva) error {
+	// Obtener el email de la persona asociada al cliente
+	email, err := s.clientRepo.GetPersonEmailByClientID(reserva.ClienteID)
+	if err != nil {
+		return fmt.Errorf("error al obtener email del cliente: %w", err)
+	}
+
+	habitacionesHTML := `
+					<div class="details">
+						<h3>Habitaciones</h3>`
+	for _, hab := range reserva.Habitaciones {
+		nombre := fmt.Sprintf("Habitación %d", hab.HabitacionID)
+		if hab.Habitacion != nil {
+			nombre = fmt.Sprintf("%s (N° %s)", hab.Habitacion.Nombre, hab.Habitacion.Numero)
+		}
+		habitacionesHTML += fmt.Sprintf(`
+						<p><strong>%s</strong> (%s al %s) - promedio S/. %.2f por noche: S/. %.2f</p>`,
+			nombre,
+			hab.FechaEntrada.Format("02/01/2006"),
+			hab.FechaSalida.Format("02/01/2006"),
+			hab.Precio,
+			hab.Importe(),
+		)
+	}
+	habitacionesHTML += `
+					</div>`
+
+	serviciosHTML := ""
+	if len(reserva.Servicios) > 0 {
+		serviciosHTML = `
+					<div class="details">
+						<h3>Servicios Adicionales</h3>`
+		for _, servicio := range reserva.Servicios {
+			serviciosHTML += fmt.Sprintf(`
+						<p><strong>%s</strong> (%s al %s) - %d x %d día(s): S/. %.2f</p>`,
+				servicio.ServiceName,
+				servicio.StartDate.Format("02/01/2006"),
+				servicio.EndDate.Format("02/01/2006"),
+				servicio.Quantity,
+				servicio.Dias(),
+				servicio.Costo(),
+			)
+		}
+		serviciosHTML += `
+					</div>`
+	}
+
+	// Construir el contenido del email
+	subject := fmt.Sprintf("Confirmación de Reserva %s - Hotel Inca", reserva.Codigo)
+
+	// Crear el cuerpo del email en HTML
+	htmlBody := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #4CAF50; color: white; padding: 20px; text-align: center; }
+				.content { padding: 20px; background-color: #f9f9f9; }
+				.footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+				.details { background-color: white; padding: 15px; margin: 10px 0; border-radius: 5px; }
+				.total { font-size: 18px; font-weight: bold; color: #4CAF50; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Hotel Inca</h1>
+					<h2>Confirmación de Reserva</h2>
+				</div>
+				<div class="content">
+					<p>Estimado/a cliente,</p>
+					<p>Su reserva ha sido confirmada exitosamente. A continuación los detalles:</p>
+					
+					<div class="details">
+						<h3>Detalles de la Reserva</h3>
+						<p><strong>Código de Reserva:</strong> %s</p>
+						<p><strong>Fecha de Confirmación:</strong> %s</p>
+						<p><strong>Cantidad de Adultos:</strong> %d</p>
+						<p><strong>Cantidad de Niños:</strong> %d</p>
+						<p><strong>Estado:</strong> %s</p>
+					</div>
+					%s
+					%s
+					<div class="details">
+						<h3>Información de Pago</h3>
+						<p><strong>Subtotal:</strong> S/. %.2f</p>
+						<p><strong>Descuento:</strong> S/. %.2f</p>
+						<p class="total">Total: S/. %.2f</p>
+					</div>
+					
+					<p>Gracias por confiar en Hotel Inca. Esperamos verle pronto.</p>
+				</div>
+				<div class="footer">
+					<p>Hotel Inca - Reservas</p>
+					<p>Este es un correo automático, por favor no responder.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`,
+		reserva.Codigo,
+		reserva.FechaConfirmacion.Format("02/01/2006 15:04"),
+		reserva.CantidadAdultos,
+		reserva.CantidadNinhos,
+		reserva.Estado,
+		habitacionesHTML,
+		serviciosHTML,
+		reserva.Subtotal,
+		reserva.Descuento,
+		reserva.Subtotal-reserva.Descuento,
+	)
+
+	// Enviar el email
+	if err := s.emailClient.SendEmail(email, subject, htmlBody); err != nil {
+		return fmt.Errorf("error al enviar email: %w", err)
+	}
+
+	return nil
+}
+
+// CompletarReserva marca una reserva como completada haciendo su check-out sin pago final
+func (s *ReservaService) CompletarReserva(id int, cambio domain.CambioEstado) error {
+	_, err := s.CheckOut(id, nil, cambio)
+	return err
+}
+
+// CheckIn registra la llegada de una reserva confirmada: confirma o reasigna las habitaciones
+// físicas, registra a todos los huéspedes de reservation_guest con la hora real de llegada y
+// pasa la reserva a EnCurso
+func (s *ReservaService) CheckIn(id int, asignaciones []domain.AsignacionHabitacion, cambio domain.CambioEstado) (*domain.RegistroCheckIn, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
+	}
+
+	if err := domain.ValidarTransicion(reserva.Estado, domain.ReservaEnCurso); err != nil {
+		return nil, err
+	}
+
+	llegada := time.Now().UTC()
+	entrada, salida := rangoEstadia(reserva.Habitaciones)
+	if hoy := soloFecha(time.Now()); hoy.Before(soloFecha(entrada)) || !hoy.Before(soloFecha(salida)) {
+		return nil, fmt.Errorf("%w (%s al %s)", domain.ErrCheckInFueraDeFecha,
+			entrada.Format("2006-01-02"), salida.Format("2006-01-02"))
+	}
+
+	reasignada, err := s.aplicarAsignaciones(reserva, asignaciones)
+	if err != nil {
+		return nil, err
+	}
+
+	if cambio.Motivo == "" {
+		cambio.Motivo = "Check-in"
+	}
+
+	registro := &domain.RegistroCheckIn{Reserva: reserva, FechaLlegada: llegada}
+	err = s.uow.Do(func(repos domain.Repositories) error {
+		// Reemplazar las habitaciones con el lock tomado vuelve a verificar su disponibilidad
+		if reasignada {
+			if err := repos.Reserva.ModificarReserva(reserva); err != nil {
+				return err
+			}
+		}
+
+		huespedes, err := repos.Estancia.RegistrarCheckIn(id, llegada)
+		if err != nil {
+			return err
+		}
+		registro.Huespedes = huespedes
+
+		return s.cambiarEstado(repos, reserva, domain.ReservaEnCurso, cambio)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reserva.FechaCheckIn = &llegada
+	reserva.CalcularRetencionRestante(llegada)
+	return registro, nil
+}
+
+// aplicarAsignaciones cambia las habitaciones de la reserva por las habitaciones físicas indicadas
+// manteniendo fechas y precio. Retorna si hubo algún cambio
+func (s *ReservaService) aplicarAsignaciones(reserva *domain.Reserva, asignaciones []domain.AsignacionHabitacion) (bool, error) {
+	reasignada := false
+	for _, asignacion := range asignaciones {
+		if asignacion.NuevaHabitacionID == 0 || asignacion.NuevaHabitacionID == asignacion.HabitacionID {
+			continue
+		}
+
+		idx := -1
+		for i, hab := range reserva.Habitaciones {
+			if hab.HabitacionID == asignacion.HabitacionID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return false, fmt.Errorf("la habitación %d no pertenece a la reserva %d", asignacion.HabitacionID, reserva.ID)
 		}
 
-		// Validar que se haya proporcionado un precio
-		if hab.Precio <= 0 {
-			return fmt.Errorf("el precio de la habitación %d debe ser mayor a 0", hab.HabitacionID)
+		hab := reserva.Habitaciones[idx]
+		disponible, err := s.availability.VerificarDisponibilidadParaReserva(reserva.ID, asignacion.NuevaHabitacionID, hab.FechaEntrada, hab.FechaSalida)
+		if err != nil {
+			return false, fmt.Errorf("error al verificar disponibilidad: %w", err)
+		}
+		if !disponible {
+			return false, fmt.Errorf("%w: habitación %d", domain.ErrHabitacionNoDisponible, asignacion.NuevaHabitacionID)
 		}
 
-		reserva.Habitaciones[i].Precio = hab.Precio
+		reserva.Habitaciones[idx].HabitacionID = asignacion.NuevaHabitacionID
+		reserva.Habitaciones[idx].Habitacion = nil
+		reasignada = true
 	}
 
-	// Calcular subtotal solo si no fue proporcionado
-	if reserva.Subtotal <= 0 {
-		subtotal := 0.0
-		for _, hab := range reserva.Habitaciones {
-			// Calcular días de estancia
-			dias := hab.FechaSalida.Sub(hab.FechaEntrada).Hours() / 24
-			if dias < 1 {
-				dias = 1
-			}
-			subtotal += hab.Precio * dias
-		}
-		reserva.Subtotal = subtotal
+	return reasignada, nil
+}
+
+// CheckOut registra la salida de una reserva en curso: cobra el pago final si se indica, cierra
+// el folio (no debe quedar saldo), marca las habitaciones como sucias y completa la reserva
+func (s *ReservaService) CheckOut(id int, pago *domain.Payment, cambio domain.CambioEstado) (*domain.CierreFolio, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
 	}
 
-	// Si no se especificó descuento, establecerlo en 0
-	if reserva.Descuento < 0 {
-		reserva.Descuento = 0
+	if reserva.Estado != domain.ReservaEnCurso {
+		return nil, fmt.Errorf("%w: la reserva %d no tiene check-in (estado %s)", domain.ErrTransicionInvalida, id, reserva.Estado)
 	}
 
-	// Validar que el descuento no sea mayor al subtotal
-	if reserva.Descuento > reserva.Subtotal {
-		return fmt.Errorf("el descuento no puede ser mayor al subtotal")
+	payments, err := s.paymentRepo.GetAllByReservationID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener pagos de la reserva: %w", err)
 	}
 
-	// Establecer fecha de confirmación si no se proporcionó
-	if reserva.FechaConfirmacion.IsZero() {
-		reserva.FechaConfirmacion = time.Now()
+	salida := time.Now().UTC()
+	cierre := &domain.CierreFolio{
+		ReservaID:   id,
+		FechaSalida: salida,
+		Total:       reserva.Subtotal - reserva.Descuento,
+		Pagado:      domain.MontoPagadoNeto(payments),
 	}
 
-	// Establecer estado inicial si no se especificó
-	if reserva.Estado == "" {
-		reserva.Estado = domain.ReservaPendiente
+	if pago != nil && pago.Amount > 0 {
+		pago.ReservationID = id
+		pago.Date = salida
+		pago.Status = domain.PaymentStatusAprobado
+		cierre.Pago = pago
+		cierre.Pagado += pago.Amount
 	}
 
-	// Crear la reserva
-	if err := s.reservaRepo.CreateReserva(reserva); err != nil {
-		return fmt.Errorf("error al crear reserva: %w", err)
+	cierre.Saldo = math.Round((cierre.Total-cierre.Pagado)*100) / 100
+	if cierre.Saldo > 0 {
+		return nil, fmt.Errorf("%w: S/. %.2f", domain.ErrSaldoPendiente, cierre.Saldo)
 	}
 
-	return nil
-}
+	cierre.HabitacionesSucias = make([]int, 0, len(reserva.Habitaciones))
+	for _, hab := range reserva.Habitaciones {
+		cierre.HabitacionesSucias = append(cierre.HabitacionesSucias, hab.HabitacionID)
+	}
 
-// FindAvailableRoomByType busca una habitación disponible de un tipo específico para las fechas dadas
-func (s *ReservaService) FindAvailableRoomByType(roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
-	return s.habitacionRepo.FindAvailableRoomByType(roomTypeID, fechaEntrada, fechaSalida)
+	if cambio.Motivo == "" {
+		cambio.Motivo = "Check-out"
+	}
+
+	err = s.uow.Do(func(repos domain.Repositories) error {
+		if cierre.Pago != nil {
+			if err := repos.Payment.Create(cierre.Pago); err != nil {
+				return fmt.Errorf("error al registrar pago: %w", err)
+			}
+		}
+
+		if err := repos.Estancia.RegistrarCheckOut(id, salida); err != nil {
+			return err
+		}
+
+		if err := repos.Limpieza.RegistrarSalidas(id, cierre.HabitacionesSucias, salida); err != nil {
+			return err
+		}
+
+		return s.cambiarEstado(repos, reserva, domain.ReservaCompletada, cambio)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cierre, nil
 }
 
-// CreateReservaWithClient crea una reserva buscando/creando primero el cliente
-func (s *ReservaService) CreateReservaWithClient(person *domain.Person, reserva *domain.Reserva) error {
-	// 1. Buscar persona por document_number
-	existingPerson, err := s.personRepo.FindByDocumentNumber(person.DocumentNumber)
+// ModificarReserva cambia fechas, habitación/tipo y cantidad de huéspedes de una reserva.
+// La disponibilidad se verifica sin contar la propia reserva, el subtotal y el descuento del
+// código promocional se recalculan y la diferencia de precio queda registrada como cobro
+// pendiente o reembolso
+func (s *ReservaService) ModificarReserva(id int, cambios domain.ModificacionReserva) (*domain.ResultadoModificacion, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
 	if err != nil {
-		return fmt.Errorf("error al buscar persona: %w", err)
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
 	}
 
-	var personID int
+	if reserva.Estado != domain.ReservaPendiente && reserva.Estado != domain.ReservaConfirmada {
+		return nil, fmt.Errorf("%w: estado %s", domain.ErrReservaNoModificable, reserva.Estado)
+	}
+	if reserva.RetencionVencida(time.Now().UTC()) {
+		return nil, fmt.Errorf("%w: reserva %d", domain.ErrRetencionVencida, id)
+	}
 
-	// 2. Si no existe, crear la persona
-	if existingPerson == nil {
-		if err := s.personRepo.Create(person); err != nil {
-			return fmt.Errorf("error al crear persona: %w", err)
+	if cambios.CantidadAdultos != nil {
+		if *cambios.CantidadAdultos < 1 {
+			return nil, fmt.Errorf("la reserva debe tener al menos un adulto")
 		}
-		personID = person.PersonID
-	} else {
-		personID = existingPerson.PersonID
+		reserva.CantidadAdultos = *cambios.CantidadAdultos
+	}
+	if cambios.CantidadNinhos != nil {
+		if *cambios.CantidadNinhos < 0 {
+			return nil, fmt.Errorf("la cantidad de niños no puede ser negativa")
+		}
+		reserva.CantidadNinhos = *cambios.CantidadNinhos
+	}
+	if cambios.EdadesNinhos != nil {
+		reserva.EdadesNinhos = cambios.EdadesNinhos
+	}
+	if len(reserva.EdadesNinhos) > reserva.CantidadNinhos {
+		reserva.EdadesNinhos = reserva.EdadesNinhos[:reserva.CantidadNinhos]
 	}
 
-	// 3. Buscar el client_id usando person_id
-	clientID, err := s.clientRepo.GetClientIDByPersonID(personID)
+	costoAnterior := costoHabitaciones(reserva.Habitaciones)
+	nuevasHabitaciones, err := s.aplicarCambiosHabitaciones(reserva, cambios.Habitaciones)
 	if err != nil {
-		// Si no existe el cliente, crearlo
-		clientID, err = s.clientRepo.Create(personID, domain.CaptureChannelWebpage, domain.CaptureStatusCliente, reserva.CantidadNinhos)
+		return nil, err
+	}
+	if err := s.aplicarRecargosHuespedes(reserva, nuevasHabitaciones); err != nil {
+		return nil, err
+	}
+
+	// El subtotal puede incluir conceptos distintos a las habitaciones: solo se
+	// reemplaza la parte correspondiente a ellas
+	subtotalAnterior := reserva.Subtotal
+	descuentoAnterior := reserva.Descuento
+	reserva.Habitaciones = nuevasHabitaciones
+	reserva.Subtotal = subtotalAnterior - costoAnterior + costoHabitaciones(nuevasHabitaciones)
+
+	// El descuento del código se recalcula sobre las nuevas habitaciones: si el código ya no
+	// aplica se rechaza el cambio en lugar de cobrar de más sin aviso
+	if strings.TrimSpace(reserva.CodigoPromocional) != "" {
+		_, descuento, err := s.promociones.RecalcularDescuento(reserva.CodigoPromocional, nuevasHabitaciones)
 		if err != nil {
-			return fmt.Errorf("error al crear cliente: %w", err)
+			return nil, fmt.Errorf("el código promocional %s no aplica a la reserva modificada: %w", reserva.CodigoPromocional, err)
 		}
+		reserva.Descuento = descuento
+	}
+	if reserva.Descuento > reserva.Subtotal {
+		return nil, fmt.Errorf("el descuento no puede ser mayor al nuevo subtotal")
 	}
 
-	// 4. Asignar el client_id a la reserva
-	reserva.ClienteID = clientID
+	resultado := &domain.ResultadoModificacion{
+		Reserva:          reserva,
+		SubtotalAnterior: subtotalAnterior,
+		SubtotalNuevo:    reserva.Subtotal,
+		Diferencia:       (reserva.Subtotal - reserva.Descuento) - (subtotalAnterior - descuentoAnterior),
+	}
 
-	// 5. Crear la reserva con el resto de la lógica existente
-	return s.CreateReserva(reserva)
-}
+	err = s.uow.Do(func(repos domain.Repositories) error {
+		if err := repos.Reserva.ModificarReserva(reserva); err != nil {
+			return err
+		}
 
-// CreateReservaWithClientAndPayment crea una reserva con cliente, huéspedes adicionales y pago
-func (s *ReservaService) CreateReservaWithClientAndPayment(
-	person *domain.Person,
-	reserva *domain.Reserva,
-	huespedes []domain.Person,
-	payment *domain.Payment,
-) error {
-	// 1. Buscar persona por document_number
-	existingPerson, err := s.personRepo.FindByDocumentNumber(person.DocumentNumber)
+		pago, err := s.registrarDiferencia(repos, reserva.ID, reserva.Subtotal-reserva.Descuento, resultado.Diferencia)
+		if err != nil {
+			return err
+		}
+		resultado.Pago = pago
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error al buscar persona: %w", err)
+		return nil, err
 	}
 
-	var personID int
+	reserva.CalcularRetencionRestante(time.Now().UTC())
 
-	// 2. Si no existe, crear la persona con los datos del JSON
-	if existingPerson == nil {
-		if err := s.personRepo.Create(person); err != nil {
-			return fmt.Errorf("error al crear persona: %w", err)
-		}
-		personID = person.PersonID
-	} else {
-		// Si existe, actualizar sus datos con la información del JSON
-		// Esto asegura que el email y otros datos estén actualizados
-		existingPerson.Name = person.Name
-		existingPerson.FirstSurname = person.FirstSurname
-		existingPerson.SecondSurname = person.SecondSurname
-		existingPerson.Gender = person.Gender
-		existingPerson.Email = person.Email // ← IMPORTANTE: Actualizar el email del JSON
-		existingPerson.Phone1 = person.Phone1
-		existingPerson.Phone2 = person.Phone2
-		existingPerson.ReferenceCity = person.ReferenceCity
-		existingPerson.ReferenceCountry = person.ReferenceCountry
-		existingPerson.BirthDate = person.BirthDate
-
-		if err := s.personRepo.Update(existingPerson); err != nil {
-			return fmt.Errorf("error al actualizar persona: %w", err)
-		}
-		personID = existingPerson.PersonID
-	}
-
-	// 3. Buscar el client_id usando person_id
-	clientID, err := s.clientRepo.GetClientIDByPersonID(personID)
-	if err != nil {
-		// Si no existe el cliente, crearlo
-		clientID, err = s.clientRepo.Create(personID, domain.CaptureChannelWebpage, domain.CaptureStatusCliente, reserva.CantidadNinhos)
-		if err != nil {
-			return fmt.Errorf("error al crear cliente: %w", err)
+	if s.emailClient != nil {
+		if err := s.enviarEmailModificacion(resultado); err != nil {
+			// Log error pero no fallar: la modificación ya se registró
+			fmt.Printf("Error al enviar email de modificación: %v\n", err)
 		}
 	}
 
-	// 4. Asignar el client_id a la reserva
-	reserva.ClienteID = clientID
+	return resultado, nil
+}
+
+// aplicarCambiosHabitaciones construye la nueva lista de habitaciones de la reserva
+// resolviendo cambios de habitación, de tipo y de fechas
+func (s *ReservaService) aplicarCambiosHabitaciones(reserva *domain.Reserva, cambios []domain.ModificacionHabitacion) ([]domain.ReservaHabitacion, error) {
+	nuevas := make([]domain.ReservaHabitacion, len(reserva.Habitaciones))
+	copy(nuevas, reserva.Habitaciones)
+
+	for _, cambio := range cambios {
+		idx := -1
+		for i, hab := range nuevas {
+			if hab.HabitacionID == cambio.HabitacionID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("la habitación %d no pertenece a la reserva %d", cambio.HabitacionID, reserva.ID)
+		}
 
-	// 5. Crear la reserva
-	if err := s.CreateReserva(reserva); err != nil {
-		return err
-	}
+		hab := nuevas[idx]
+		if cambio.FechaEntrada != nil {
+			hab.FechaEntrada = *cambio.FechaEntrada
+		}
+		if cambio.FechaSalida != nil {
+			hab.FechaSalida = *cambio.FechaSalida
+		}
+		if !hab.FechaSalida.After(hab.FechaEntrada) {
+			return nil, fmt.Errorf("la fecha de salida debe ser posterior a la fecha de entrada para la habitación %d", hab.HabitacionID)
+		}
 
-	// 6. Crear los huéspedes adicionales (si existen)
-	if len(huespedes) > 0 {
-		var personIDs []int
+		actual, err := s.habitacionRepo.GetRoomByID(hab.HabitacionID)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener habitación %d: %w", hab.HabitacionID, err)
+		}
 
-		for i := range huespedes {
-			// Buscar si el huésped ya existe por document_number
-			existingGuest, err := s.personRepo.FindByDocumentNumber(huespedes[i].DocumentNumber)
+		tipoHabitacionID := actual.TipoHabitacion.ID
+		planTarifaID := hab.PlanTarifaID
+		switch {
+		case cambio.NuevaHabitacionID != 0:
+			nueva, err := s.habitacionRepo.GetRoomByID(cambio.NuevaHabitacionID)
 			if err != nil {
-				return fmt.Errorf("error al buscar huésped %d: %w", i+1, err)
+				return nil, fmt.Errorf("error al obtener habitación %d: %w", cambio.NuevaHabitacionID, err)
 			}
+			hab.HabitacionID = nueva.ID
+			tipoHabitacionID = nueva.TipoHabitacion.ID
+		case cambio.NuevoTipoHabitacionID != 0 && cambio.NuevoTipoHabitacionID != actual.TipoHabitacion.ID:
+			habitacionID, err := s.availability.FindAvailableRoomByTypeParaReserva(reserva.ID, cambio.NuevoTipoHabitacionID, hab.FechaEntrada, hab.FechaSalida)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrHabitacionNoDisponible, err)
+			}
+			hab.HabitacionID = habitacionID
+			tipoHabitacionID = cambio.NuevoTipoHabitacionID
+		}
 
-			var guestPersonID int
+		// Al cambiar de tipo se mantiene el plan con el mismo código, si el nuevo tipo lo ofrece
+		if tipoHabitacionID != actual.TipoHabitacion.ID && planTarifaID != nil {
+			equivalente, err := s.tarifas.PlanEquivalente(*planTarifaID, tipoHabitacionID)
+			if err != nil {
+				return nil, err
+			}
+			planTarifaID = equivalente
+		}
 
-			if existingGuest == nil {
-				// Si no existe, crear la persona
-				if err := s.personRepo.Create(&huespedes[i]); err != nil {
-					return fmt.Errorf("error al crear huésped %d: %w", i+1, err)
+		// Un cambio de fechas o de tipo debe cumplir las restricciones de estadía y se vuelve a
+		// cotizar con el calendario de tarifas
+		if !hab.FechaEntrada.Equal(nuevas[idx].FechaEntrada) || !hab.FechaSalida.Equal(nuevas[idx].FechaSalida) ||
+			tipoHabitacionID != actual.TipoHabitacion.ID {
+			if reserva.BloqueGrupoID == nil {
+				if err := s.availability.VerificarRestricciones(tipoHabitacionID, hab.FechaEntrada, hab.FechaSalida); err != nil {
+					return nil, err
 				}
-				guestPersonID = huespedes[i].PersonID
-			} else {
-				// Si existe, actualizar sus datos con la información del JSON
-				existingGuest.Name = huespedes[i].Name
-				existingGuest.FirstSurname = huespedes[i].FirstSurname
-				existingGuest.SecondSurname = huespedes[i].SecondSurname
-				existingGuest.Gender = huespedes[i].Gender
-				existingGuest.Email = huespedes[i].Email   // ✅ Actualizar email
-				existingGuest.Phone1 = huespedes[i].Phone1 // ✅ Actualizar teléfono
-				existingGuest.BirthDate = huespedes[i].BirthDate
-
-				if err := s.personRepo.Update(existingGuest); err != nil {
-					return fmt.Errorf("error al actualizar huésped %d: %w", i+1, err)
-				}
-				guestPersonID = existingGuest.PersonID
 			}
-
-			personIDs = append(personIDs, guestPersonID)
+			cotizacion, err := s.cotizarEstadia(reserva.BloqueGrupoID, planTarifaID, tipoHabitacionID, hab.FechaEntrada, hab.FechaSalida)
+			if err != nil {
+				return nil, err
+			}
+			hab.AplicarCotizacion(*cotizacion)
+			hab.PlanTarifaID = planTarifaID
 		}
 
-		// Crear las relaciones en reservation_guest
-		if err := s.reservationGuestRepo.CreateMultiple(reserva.ID, personIDs); err != nil {
-			return fmt.Errorf("reserva creada pero error al registrar huéspedes: %w", err)
-		}
+		nuevas[idx] = hab
 	}
 
-	// 7. Si se proporcionó pago, crearlo
-	if payment != nil {
-		payment.ReservationID = reserva.ID
-		if err := s.paymentRepo.Create(payment); err != nil {
-			// Si falla el pago, la reserva ya fue creada
-			// Puedes decidir si quieres rollback o solo registrar el error
-			return fmt.Errorf("reserva creada pero error al registrar pago: %w", err)
+	// Verificar cada habitación resultante sin contar la propia reserva
+	for i, hab := range nuevas {
+		for j := 0; j < i; j++ {
+			if nuevas[j].HabitacionID == hab.HabitacionID {
+				return nil, fmt.Errorf("la habitación %d aparece más de una vez en la reserva", hab.HabitacionID)
+			}
 		}
-	}
 
-	// 8. Generar token de encuesta y enviar email (solo si surveyService está disponible)
-	if s.surveyService != nil {
-		s.generarYEnviarEncuesta(reserva.ID, clientID, person.Email)
+		disponible, err := s.availability.VerificarDisponibilidadParaReserva(reserva.ID, hab.HabitacionID, hab.FechaEntrada, hab.FechaSalida)
+		if err != nil {
+			return nil, fmt.Errorf("error al verificar disponibilidad: %w", err)
+		}
+		if !disponible {
+			return nil, fmt.Errorf("%w: habitación %d", domain.ErrHabitacionNoDisponible, hab.HabitacionID)
+		}
 	}
 
-	return nil
-}
-
-// GetReservaByID obtiene una reserva por su ID
-func (s *ReservaService) GetReservaByID(id int) (*domain.Reserva, error) {
-	return s.reservaRepo.GetReservaByID(id)
-}
-
-// GetReservasCliente obtiene todas las reservas de un cliente
-func (s *ReservaService) GetReservasCliente(clienteID int) ([]domain.Reserva, error) {
-	return s.reservaRepo.GetReservasCliente(clienteID)
+	return nuevas, nil
 }
 
-// UpdateReservaEstado actualiza el estado de una reserva
-func (s *ReservaService) UpdateReservaEstado(id int, estado domain.EstadoReserva) error {
-	// Validar que el estado sea válido
-	validEstados := map[domain.EstadoReserva]bool{
-		domain.ReservaPendiente:  true,
-		domain.ReservaConfirmada: true,
-		domain.ReservaCancelada:  true,
-		domain.ReservaCompletada: true,
-	}
-
-	if !validEstados[estado] {
-		return fmt.Errorf("estado de reserva inválido: %s", estado)
+// registrarDiferencia registra con los repositorios de la transacción en curso el cambio de precio
+// de una modificación y retorna el cobro pendiente o el reembolso creado (nil si no hay). Un
+// aumento se registra como cobro pendiente. Una rebaja primero reduce o anula los cobros
+// pendientes y solo reembolsa lo pagado que exceda el nuevo total
+func (s *ReservaService) registrarDiferencia(repos domain.Repositories, reservaID int, total, diferencia float64) (*domain.Payment, error) {
+	if diferencia >= 0 {
+		pago := s.pagoPorDiferencia(reservaID, diferencia)
+		if pago != nil {
+			if err := repos.Payment.Create(pago); err != nil {
+				return nil, fmt.Errorf("error al registrar diferencia de pago: %w", err)
+			}
+		}
+		return pago, nil
 	}
 
-	// Obtener la reserva actual
-	reserva, err := s.reservaRepo.GetReservaByID(id)
+	pagos, err := repos.Payment.GetAllByReservationID(reservaID)
 	if err != nil {
-		return fmt.Errorf("error al obtener reserva: %w", err)
+		return nil, fmt.Errorf("error al obtener pagos: %w", err)
 	}
 
-	// Si se está cancelando, actualizar el estado de las habitaciones
-	if estado == domain.ReservaCancelada {
-		for _, hab := range reserva.Habitaciones {
-			if err := s.reservaHabitacionRepo.UpdateReservaHabitacionEstado(
-				id,
-				hab.HabitacionID,
-				0, // Estado cancelado
-			); err != nil {
-				return fmt.Errorf("error al cancelar habitación: %w", err)
+	rebaja := -diferencia
+	for i := len(pagos) - 1; i >= 0 && rebaja >= 0.005; i-- {
+		p := pagos[i]
+		if p.Status != domain.PaymentStatusPendiente {
+			continue
+		}
+		if p.Amount <= rebaja+0.005 {
+			// El cobro ya no corresponde: se anula
+			if err := repos.Payment.UpdateStatus(p.PaymentID, domain.PaymentStatusRechazado); err != nil {
+				return nil, fmt.Errorf("error al anular cobro pendiente: %w", err)
 			}
+			rebaja -= p.Amount
+			continue
+		}
+		if err := repos.Payment.UpdateAmount(p.PaymentID, math.Round((p.Amount-rebaja)*100)/100); err != nil {
+			return nil, fmt.Errorf("error al reducir cobro pendiente: %w", err)
 		}
+		rebaja = 0
 	}
 
-	return s.reservaRepo.UpdateReservaEstado(id, estado)
-}
-
-// CancelarReserva cancela una reserva completa
-func (s *ReservaService) CancelarReserva(id int) error {
-	return s.UpdateReservaEstado(id, domain.ReservaCancelada)
-}
-
-// ConfirmarReserva confirma una reserva pendiente y envía email de confirmación
-func (s *ReservaService) ConfirmarReserva(id int) error {
-	return s.confirmarReservaInternal(id, true) // true = enviar email
-}
-
-// ConfirmarReservaSinEmail confirma una reserva sin enviar email
-func (s *ReservaService) ConfirmarReservaSinEmail(id int) error {
-	return s.confirmarReservaInternal(id, false) // false = no enviar email
-}
+	// Solo se devuelve lo pagado por encima del nuevo total, nunca más que la rebaja
+	excedente := domain.MontoPagadoNeto(pagos) - total
+	reembolso := math.Round(math.Min(rebaja, excedente)*100) / 100
+	if reembolso < 0.005 {
+		return nil, nil
+	}
 
-// confirmarReservaInternal es el método interno que maneja la confirmación
-func (s *ReservaService) confirmarReservaInternal(id int, enviarEmail bool) error {
-	// Actualizar estado
-	if err := s.UpdateReservaEstado(id, domain.ReservaConfirmada); err != nil {
-		return err
+	pago := s.pagoPorDiferencia(reservaID, -reembolso)
+	if err := repos.Payment.Create(pago); err != nil {
+		return nil, fmt.Errorf("error al registrar reembolso: %w", err)
 	}
+	return pago, nil
+}
 
-	// Solo enviar email si se solicita
-	if !enviarEmail {
+// pagoPorDiferencia arma el cobro pendiente (diferencia > 0) o el reembolso (diferencia < 0)
+// de una modificación, usando el medio de pago original cuando existe
+func (s *ReservaService) pagoPorDiferencia(reservaID int, diferencia float64) *domain.Payment {
+	if diferencia > -0.005 && diferencia < 0.005 {
 		return nil
 	}
 
-	// Obtener datos completos de la reserva para el email
-	reserva, err := s.GetReservaByID(id)
-	if err != nil {
-		// Log error pero no fallar si el email falla
-		fmt.Printf("Error al obtener reserva para email: %v\n", err)
-		return nil // La confirmación ya se hizo, solo falló el email
+	metodo := domain.PaymentMethodTarjeta
+	if pagos, err := s.paymentRepo.GetAllByReservationID(reservaID); err == nil {
+		metodo = metodoPagoOriginal(pagos)
 	}
 
-	// Enviar email de confirmación
-	if s.emailClient != nil {
-		if err := s.enviarEmailConfirmacion(reserva); err != nil {
-			// Log error pero no fallar
-			fmt.Printf("Error al enviar email de confirmación: %v\n", err)
-		}
+	pago := &domain.Payment{
+		Amount:        diferencia,
+		Date:          time.Now(),
+		PaymentMethod: metodo,
+		Status:        domain.PaymentStatusPendiente,
+		ReservationID: reservaID,
+	}
+	if diferencia < 0 {
+		pago.Amount = -diferencia
+		pago.Status = domain.PaymentStatusReembolso
 	}
 
-	return nil
+	return pago
 }
 
-// enviarEmailConfirmacion envía el email de confirmación de la reserva
-func (s *ReservaService) enviarEmailConfirmacion(reserva *domain.Reserva) error {
-	// Obtener el email de la persona asociada al cliente
+// enviarEmailModificacion notifica al huésped los nuevos datos de su reserva
+func (s *ReservaService) enviarEmailModificacion(resultado *domain.ResultadoModificacion) error {
+	reserva := resultado.Reserva
+
 	email, err := s.clientRepo.GetPersonEmailByClientID(reserva.ClienteID)
 	if err != nil {
 		return fmt.Errorf("error al obtener email del cliente: %w", err)
 	}
 
-	// Construir el contenido del email
-	subject := fmt.Sprintf("Confirmación de Reserva #%d - Hotel Inca", reserva.ID)
+	habitacionesHTML := ""
+	for _, hab := range reserva.Habitaciones {
+		habitacionesHTML += fmt.Sprintf(`
+						<p><strong>Habitación %d:</strong> %s al %s</p>`,
+			hab.HabitacionID,
+			hab.FechaEntrada.Format("02/01/2006"),
+			hab.FechaSalida.Format("02/01/2006"),
+		)
+	}
+
+	diferenciaHTML := "<p>La modificación no cambia el importe de su reserva.</p>"
+	if resultado.Pago != nil && resultado.Pago.Status == domain.PaymentStatusReembolso {
+		diferenciaHTML = fmt.Sprintf("<p>Le reembolsaremos <strong>S/. %.2f</strong>.</p>", resultado.Pago.Amount)
+	} else if resultado.Pago != nil {
+		diferenciaHTML = fmt.Sprintf("<p>Queda pendiente un pago adicional de <strong>S/. %.2f</strong>.</p>", resultado.Pago.Amount)
+	} else if resultado.Diferencia < 0 {
+		diferenciaHTML = fmt.Sprintf("<p>El importe de su reserva baja <strong>S/. %.2f</strong> y se descuenta de su saldo pendiente.</p>", -resultado.Diferencia)
+	}
+
+	subject := fmt.Sprintf("Modificación de Reserva %s - Hotel Inca", reserva.Codigo)
 
-	// Crear el cuerpo del email en HTML
 	htmlBody := fmt.Sprintf(`
 		<!DOCTYPE html>
 		<html>
@@ -397,40 +1648,40 @@ func (s *ReservaService) enviarEmailConfirmacion(reserva *domain.Reserva) error
 			<style>
 				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
 				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-				.header { background-color: #4CAF50; color: white; padding: 20px; text-align: center; }
+				.header { background-color: #2196F3; color: white; padding: 20px; text-align: center; }
 				.content { padding: 20px; background-color: #f9f9f9; }
 				.footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
 				.details { background-color: white; padding: 15px; margin: 10px 0; border-radius: 5px; }
-				.total { font-size: 18px; font-weight: bold; color: #4CAF50; }
+				.total { font-size: 18px; font-weight: bold; color: #2196F3; }
 			</style>
 		</head>
 		<body>
 			<div class="container">
 				<div class="header">
 					<h1>Hotel Inca</h1>
-					<h2>Confirmación de Reserva</h2>
+					<h2>Modificación de Reserva</h2>
 				</div>
 				<div class="content">
 					<p>Estimado/a cliente,</p>
-					<p>Su reserva ha sido confirmada exitosamente. A continuación los detalles:</p>
-					
+					<p>Su reserva ha sido modificada. Estos son los nuevos detalles:</p>
+
 					<div class="details">
 						<h3>Detalles de la Reserva</h3>
-						<p><strong>Número de Reserva:</strong> #%d</p>
-						<p><strong>Fecha de Confirmación:</strong> %s</p>
+						<p><strong>Código de Reserva:</strong> %s</p>
 						<p><strong>Cantidad de Adultos:</strong> %d</p>
 						<p><strong>Cantidad de Niños:</strong> %d</p>
-						<p><strong>Estado:</strong> %s</p>
+						%s
 					</div>
-					
+
 					<div class="details">
 						<h3>Información de Pago</h3>
-						<p><strong>Subtotal:</strong> S/. %.2f</p>
-						<p><strong>Descuento:</strong> S/. %.2f</p>
+						<p><strong>Subtotal anterior:</strong> S/. %.2f</p>
+						<p><strong>Nuevo subtotal:</strong> S/. %.2f</p>
 						<p class="total">Total: S/. %.2f</p>
+						%s
 					</div>
-					
-					<p>Gracias por confiar en Hotel Inca. Esperamos verle pronto.</p>
+
+					<p>Si usted no solicitó este cambio, por favor contáctenos.</p>
 				</div>
 				<div class="footer">
 					<p>Hotel Inca - Reservas</p>
@@ -440,17 +1691,16 @@ func (s *ReservaService) enviarEmailConfirmacion(reserva *domain.Reserva) error
 		</body>
 		</html>
 	`,
-		reserva.ID,
-		reserva.FechaConfirmacion.Format("02/01/2006 15:04"),
+		reserva.Codigo,
 		reserva.CantidadAdultos,
 		reserva.CantidadNinhos,
-		reserva.Estado,
-		reserva.Subtotal,
-		reserva.Descuento,
+		habitacionesHTML,
+		resultado.SubtotalAnterior,
+		resultado.SubtotalNuevo,
 		reserva.Subtotal-reserva.Descuento,
+		diferenciaHTML,
 	)
 
-	// Enviar el email
 	if err := s.emailClient.SendEmail(email, subject, htmlBody); err != nil {
 		return fmt.Errorf("error al enviar email: %w", err)
 	}
@@ -458,9 +1708,140 @@ func (s *ReservaService) enviarEmailConfirmacion(reserva *domain.Reserva) error
 	return nil
 }
 
-// CompletarReserva marca una reserva como completada
-func (s *ReservaService) CompletarReserva(id int) error {
-	return s.UpdateReservaEstado(id, domain.ReservaCompletada)
+// GetServiciosReserva obtiene los servicios adicionales activos de una reserva
+func (s *ReservaService) GetServiciosReserva(id int) ([]domain.ReservaServicio, error) {
+	if _, err := s.reservaRepo.GetReservaByID(id); err != nil {
+		return nil, err
+	}
+	return s.reservaRepo.GetReservaServicios(id)
+}
+
+// AgregarServicio agrega un servicio adicional a la reserva y suma su costo al subtotal.
+// Las fechas del servicio deben estar dentro de la estadía
+func (s *ReservaService) AgregarServicio(id int, servicio domain.ReservaServicio) (*domain.Reserva, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
+	}
+	if err := validarServiciosModificables(reserva); err != nil {
+		return nil, err
+	}
+
+	servicio.ReservaID = id
+	servicio.Status = 1 // Activo
+	if err := s.prepararServicio(reserva, &servicio); err != nil {
+		return nil, err
+	}
+
+	if err := s.reservaRepo.AddReservaServicio(&servicio); err != nil {
+		return nil, err
+	}
+
+	return s.GetReservaByID(id)
+}
+
+// QuitarServicio quita un servicio adicional de la reserva y descuenta su costo del subtotal
+func (s *ReservaService) QuitarServicio(id, reservaServicioID int) (*domain.Reserva, error) {
+	reserva, err := s.reservaRepo.GetReservaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reserva: %w", err)
+	}
+	if err := validarServiciosModificables(reserva); err != nil {
+		return nil, err
+	}
+
+	for _, servicio := range reserva.Servicios {
+		if servicio.ID == reservaServicioID && reserva.Descuento > reserva.Subtotal-servicio.Costo() {
+			return nil, fmt.Errorf("el descuento no puede ser mayor al nuevo subtotal")
+		}
+	}
+
+	if err := s.reservaRepo.RemoveReservaServicio(id, reservaServicioID); err != nil {
+		return nil, err
+	}
+
+	return s.GetReservaByID(id)
+}
+
+// validarServiciosModificables verifica que la reserva siga activa: los servicios se pueden
+// agregar o quitar antes de la llegada y durante la estadía
+func validarServiciosModificables(reserva *domain.Reserva) error {
+	switch reserva.Estado {
+	case domain.ReservaPendiente, domain.ReservaConfirmada, domain.ReservaEnCurso:
+	default:
+		return fmt.Errorf("%w: estado %s", domain.ErrReservaNoModificable, reserva.Estado)
+	}
+	if reserva.RetencionVencida(time.Now().UTC()) {
+		return fmt.Errorf("%w: reserva %d", domain.ErrRetencionVencida, reserva.ID)
+	}
+	return nil
+}
+
+// prepararServicio valida cantidad y fechas del servicio y fija su precio unitario actual.
+// Sin fechas, el servicio cubre toda la estadía
+func (s *ReservaService) prepararServicio(reserva *domain.Reserva, servicio *domain.ReservaServicio) error {
+	entrada, salida := rangoEstadia(reserva.Habitaciones)
+	if servicio.StartDate.IsZero() {
+		servicio.StartDate = entrada
+	}
+	if servicio.EndDate.IsZero() {
+		servicio.EndDate = salida
+	}
+	if servicio.Quantity == 0 {
+		servicio.Quantity = 1
+	}
+
+	if servicio.Quantity < 0 {
+		return fmt.Errorf("la cantidad del servicio debe ser mayor a 0")
+	}
+	if servicio.EndDate.Before(servicio.StartDate) {
+		return fmt.Errorf("la fecha de fin del servicio debe ser igual o posterior a la de inicio")
+	}
+	if soloFecha(servicio.StartDate).Before(soloFecha(entrada)) || soloFecha(servicio.EndDate).After(soloFecha(salida)) {
+		return fmt.Errorf("las fechas del servicio deben estar dentro de la estadía (%s al %s)",
+			entrada.Format("2006-01-02"), salida.Format("2006-01-02"))
+	}
+
+	catalogo, err := s.servicioRepo.GetByID(servicio.ServiceID)
+	if err != nil {
+		return fmt.Errorf("error al obtener servicio: %w", err)
+	}
+	servicio.UnitPrice = catalogo.Price
+	servicio.ServiceName = catalogo.Name
+
+	return nil
+}
+
+// rangoEstadia retorna la primera fecha de entrada y la última de salida de las habitaciones
+func rangoEstadia(habitaciones []domain.ReservaHabitacion) (time.Time, time.Time) {
+	var entrada, salida time.Time
+	for i, hab := range habitaciones {
+		if i == 0 || hab.FechaEntrada.Before(entrada) {
+			entrada = hab.FechaEntrada
+		}
+		if i == 0 || hab.FechaSalida.After(salida) {
+			salida = hab.FechaSalida
+		}
+	}
+	return entrada, salida
+}
+
+// costoServicios suma el costo de los servicios adicionales
+func costoServicios(servicios []domain.ReservaServicio) float64 {
+	total := 0.0
+	for _, servicio := range servicios {
+		total += servicio.Costo()
+	}
+	return total
+}
+
+// costoHabitaciones suma el importe de la estadía de cada habitación
+func costoHabitaciones(habitaciones []domain.ReservaHabitacion) float64 {
+	total := 0.0
+	for _, hab := range habitaciones {
+		total += hab.Importe()
+	}
+	return total
 }
 
 // VerificarDisponibilidad verifica si una habitación está disponible
@@ -469,7 +1850,7 @@ func (s *ReservaService) VerificarDisponibilidad(habitacionID int, fechaEntrada,
 		return false, fmt.Errorf("la fecha de salida debe ser posterior a la fecha de entrada")
 	}
 
-	return s.reservaHabitacionRepo.VerificarDisponibilidad(habitacionID, fechaEntrada, fechaSalida)
+	return s.availability.VerificarDisponibilidad(habitacionID, fechaEntrada, fechaSalida)
 }
 
 // GetReservasEnRango obtiene todas las reservas en un rango de fechas
@@ -481,15 +1862,6 @@ func (s *ReservaService) GetReservasEnRango(fechaInicio, fechaFin time.Time) ([]
 	return s.reservaHabitacionRepo.GetReservasEnRango(fechaInicio, fechaFin)
 }
 
-// GetRoomTypePrice obtiene el precio de un tipo de habitación por su ID
-func (s *ReservaService) GetRoomTypePrice(roomTypeID int) (float64, error) {
-	roomType, err := s.habitacionRepo.GetRoomTypeByID(roomTypeID)
-	if err != nil {
-		return 0, fmt.Errorf("error al obtener tipo de habitación: %w", err)
-	}
-	return roomType.Precio, nil
-}
-
 // generarYEnviarEncuesta genera un token de encuesta y envía el email al cliente
 func (s *ReservaService) generarYEnviarEncuesta(reservaID, clienteID int, email string) {
 	// Generar token de encuesta
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+func TestRegistrarDiferencia(t *testing.T) {
+	const reservaID = 20
+	pago := func(monto float64, estado domain.PaymentStatus) domain.Payment {
+		return domain.Payment{Amount: monto, Status: estado, PaymentMethod: domain.PaymentMethodTarjeta, ReservationID: reservaID}
+	}
+
+	tests := []struct {
+		name       string
+		pagos      []domain.Payment
+		total      float64 // Total con descuento después de la modificación
+		diferencia float64
+		// registrado es el pago creado por la modificación (nil si no hay)
+		registrado *domain.Payment
+		// pendiente es la suma de los cobros pendientes después de la modificación
+		pendiente float64
+		pagado    float64
+	}{
+		{
+			name:       "aumento sin pagos registra un cobro pendiente",
+			total:      700,
+			diferencia: 200,
+			registrado: &domain.Payment{Amount: 200, Status: domain.PaymentStatusPendiente},
+			pendiente:  200,
+		},
+		{
+			name:       "rebaja sin pagos no reembolsa",
+			total:      300,
+			diferencia: -200,
+		},
+		{
+			name:       "rebaja con pago parcial menor al nuevo total no reembolsa",
+			pagos:      []domain.Payment{pago(100, domain.PaymentStatusAprobado)},
+			total:      300,
+			diferencia: -200,
+			pagado:     100,
+		},
+		{
+			name:       "rebaja con la reserva pagada reembolsa la diferencia",
+			pagos:      []domain.Payment{pago(500, domain.PaymentStatusAprobado)},
+			total:      300,
+			diferencia: -200,
+			registrado: &domain.Payment{Amount: 200, Status: domain.PaymentStatusReembolso},
+			pagado:     300,
+		},
+		{
+			name:       "rebaja con pago parcial solo reembolsa el excedente",
+			pagos:      []domain.Payment{pago(400, domain.PaymentStatusAprobado)},
+			total:      300,
+			diferencia: -200,
+			registrado: &domain.Payment{Amount: 100, Status: domain.PaymentStatusReembolso},
+			pagado:     300,
+		},
+		{
+			name:       "rebaja menor al cobro pendiente lo reduce",
+			pagos:      []domain.Payment{pago(500, domain.PaymentStatusAprobado), pago(300, domain.PaymentStatusPendiente)},
+			total:      600,
+			diferencia: -200,
+			pendiente:  100,
+			pagado:     500,
+		},
+		{
+			name:       "rebaja mayor al cobro pendiente lo anula y reembolsa el excedente",
+			pagos:      []domain.Payment{pago(500, domain.PaymentStatusAprobado), pago(150, domain.PaymentStatusPendiente)},
+			total:      450,
+			diferencia: -200,
+			registrado: &domain.Payment{Amount: 50, Status: domain.PaymentStatusReembolso},
+			pagado:     450,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagos := &paymentRepoFake{}
+			for _, p := range tt.pagos {
+				p := p
+				pagos.Create(&p)
+			}
+			s := &ReservaService{paymentRepo: pagos}
+
+			registrado, err := s.registrarDiferencia(domain.Repositories{Payment: pagos}, reservaID, tt.total, tt.diferencia)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+
+			switch {
+			case tt.registrado == nil && registrado != nil:
+				t.Errorf("se registró %+v, no se esperaba ningún pago", *registrado)
+			case tt.registrado != nil && registrado == nil:
+				t.Errorf("no se registró ningún pago, se esperaba %+v", *tt.registrado)
+			case tt.registrado != nil && (registrado.Amount != tt.registrado.Amount || registrado.Status != tt.registrado.Status):
+				t.Errorf("se registró %.2f %s, se esperaba %.2f %s", registrado.Amount, registrado.Status, tt.registrado.Amount, tt.registrado.Status)
+			}
+
+			pendiente := 0.0
+			for _, p := range pagos.pagos {
+				if p.Status == domain.PaymentStatusPendiente {
+					pendiente += p.Amount
+				}
+			}
+			if math.Abs(pendiente-tt.pendiente) > 0.001 {
+				t.Errorf("cobros pendientes = %.2f, se esperaba %.2f", pendiente, tt.pendiente)
+			}
+			if pagado := domain.MontoPagadoNeto(pagos.pagos); math.Abs(pagado-tt.pagado) > 0.001 {
+				t.Errorf("pagado neto = %.2f, se esperaba %.2f", pagado, tt.pagado)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// RestriccionService gestiona las restricciones de estadía. Quien las aplica al vender es
+// AvailabilityService
+type RestriccionService struct {
+	repo           domain.RestriccionEstadiaRepository
+	habitacionRepo domain.HabitacionRepository
+}
+
+// NewRestriccionService crea una nueva instancia del servicio de restricciones de estadía
+func NewRestriccionService(repo domain.RestriccionEstadiaRepository, habitacionRepo domain.HabitacionRepository) *RestriccionService {
+	return &RestriccionService{
+		repo:           repo,
+		habitacionRepo: habitacionRepo,
+	}
+}
+
+// GetAll obtiene las restricciones de un tipo de habitación con las de todo el hotel (0 = todas)
+func (s *RestriccionService) GetAll(tipoHabitacionID int) ([]domain.RestriccionEstadia, error) {
+	return s.repo.GetAll(tipoHabitacionID)
+}
+
+// GetByID obtiene una restricción por su ID
+func (s *RestriccionService) GetByID(id int) (*domain.RestriccionEstadia, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create crea una restricción de estadía
+func (s *RestriccionService) Create(restriccion *domain.RestriccionEstadia) error {
+	if err := s.preparar(restriccion); err != nil {
+		return err
+	}
+	return s.repo.Create(restriccion)
+}
+
+// Update actualiza una restricción de estadía. Las reservas ya hechas no se ven afectadas
+func (s *RestriccionService) Update(restriccion *domain.RestriccionEstadia) error {
+	if err := s.preparar(restriccion); err != nil {
+		return err
+	}
+	return s.repo.Update(restriccion)
+}
+
+// Delete elimina una restricción de estadía
+func (s *RestriccionService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// preparar normaliza y valida una restricción antes de guardarla
+func (s *RestriccionService) preparar(restriccion *domain.RestriccionEstadia) error {
+	restriccion.Motivo = strings.TrimSpace(restriccion.Motivo)
+	restriccion.FechaInicio = soloFecha(restriccion.FechaInicio)
+	restriccion.FechaFin = soloFecha(restriccion.FechaFin)
+	if err := restriccion.Validar(); err != nil {
+		return err
+	}
+	if restriccion.TipoHabitacionID != nil {
+		if _, err := s.habitacionRepo.GetRoomTypeByID(*restriccion.TipoHabitacionID); err != nil {
+			return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrRestriccionInvalida, *restriccion.TipoHabitacionID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,532 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// TarifaService gestiona el calendario de tarifas y los planes tarifarios, y cotiza el precio
+// por noche de las estadías. Todos los cálculos de precio (chatbot, creación de reservas,
+// correos) pasan por Cotizar para que los totales coincidan siempre. Cotizar también aplica las
+// reglas de precio dinámico con la ocupación vigente de cada noche
+type TarifaService struct {
+	repo           domain.TarifaRepository
+	planRepo       domain.PlanTarifaRepository
+	habitacionRepo domain.HabitacionRepository
+	servicioRepo   domain.ServicioRepository
+	reglaRepo      domain.ReglaPrecioRepository
+	disponibilidad *AvailabilityService
+}
+
+// NewTarifaService crea una nueva instancia del servicio de tarifas
+func NewTarifaService(
+	repo domain.TarifaRepository,
+	planRepo domain.PlanTarifaRepository,
+	habitacionRepo domain.HabitacionRepository,
+	servicioRepo domain.ServicioRepository,
+	reglaRepo domain.ReglaPrecioRepository,
+	disponibilidad *AvailabilityService,
+) *TarifaService {
+	return &TarifaService{
+		repo:           repo,
+		planRepo:       planRepo,
+		habitacionRepo: habitacionRepo,
+		servicioRepo:   servicioRepo,
+		reglaRepo:      reglaRepo,
+		disponibilidad: disponibilidad,
+	}
+}
+
+// Cotizar calcula el precio de cada noche de la estadía para un tipo de habitación con el
+// calendario de tarifas y lo ajusta con las reglas de precio dinámico que se cumplan
+func (s *TarifaService) Cotizar(tipoHabitacionID int, fechaEntrada, fechaSalida time.Time) (*domain.CotizacionEstadia, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, err
+	}
+
+	tipo, err := s.habitacionRepo.GetRoomTypeByID(tipoHabitacionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tipo de habitación %d: %w", tipoHabitacionID, err)
+	}
+
+	temporadas, err := s.repo.GetTemporadasEnRango(tipoHabitacionID, entrada, salida)
+	if err != nil {
+		return nil, err
+	}
+
+	precios, err := s.repo.GetPreciosFecha(tipoHabitacionID, entrada, salida)
+	if err != nil {
+		return nil, err
+	}
+
+	cotizacion := domain.CotizarEstadia(tipo, temporadas, precios, entrada, salida)
+	if err := s.aplicarReglasPrecio(&cotizacion); err != nil {
+		return nil, err
+	}
+	return &cotizacion, nil
+}
+
+// aplicarReglasPrecio ajusta la cotización con las reglas activas del tipo de habitación según la
+// ocupación de cada noche y los días que faltan para la llegada
+func (s *TarifaService) aplicarReglasPrecio(cotizacion *domain.CotizacionEstadia) error {
+	reglas, err := s.reglaRepo.GetActivas(cotizacion.TipoHabitacionID)
+	if err != nil {
+		return err
+	}
+	if len(reglas) == 0 {
+		return nil
+	}
+
+	ocupacion, err := s.ocupacionPorNoche(cotizacion.FechaEntrada, cotizacion.FechaSalida.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	domain.AplicarReglasPrecio(cotizacion, reglas, ocupacion, diasAntelacion(cotizacion.FechaEntrada))
+	return nil
+}
+
+// ocupacionPorNoche retorna el porcentaje de ocupación de cada noche entre desde y hasta (inclusive)
+func (s *TarifaService) ocupacionPorNoche(desde, hasta time.Time) (map[time.Time]float64, error) {
+	ocupaciones, err := s.disponibilidad.GetOcupacionFechas(desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	porNoche := make(map[time.Time]float64, len(ocupaciones))
+	for _, o := range ocupaciones {
+		porNoche[o.Fecha] = o.Porcentaje
+	}
+	return porNoche, nil
+}
+
+// SimularReglasPrecio muestra, sin crear reservas, el precio de cada noche entre desde y hasta
+// (inclusive) de un tipo de habitación tratándola como llegada de una noche: el precio del
+// calendario, la ocupación y la regla que se aplicaría. ocupacion, si se indica, reemplaza la
+// ocupación real de todas las noches para probar escenarios
+func (s *TarifaService) SimularReglasPrecio(tipoHabitacionID int, desde, hasta time.Time, ocupacion *float64) ([]domain.SimulacionPrecioNoche, error) {
+	desde, hasta = soloFecha(desde), soloFecha(hasta)
+	if hasta.Before(desde) {
+		return nil, fmt.Errorf("la fecha hasta debe ser igual o posterior a la fecha desde")
+	}
+
+	tipo, err := s.habitacionRepo.GetRoomTypeByID(tipoHabitacionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tipo de habitación %d", domain.ErrTarifaNoEncontrada, tipoHabitacionID)
+	}
+
+	var porNoche map[time.Time]float64
+	if ocupacion == nil {
+		if porNoche, err = s.ocupacionPorNoche(desde, hasta); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.simularPrecios(tipo, desde, hasta, porNoche, ocupacion)
+}
+
+// PreciosPorNoche retorna el precio de cada noche entre desde y hasta (inclusive) de un tipo de
+// habitación como llegada de una noche, con la ocupación de cada noche ya calculada por quien
+// llama. Lo usa la matriz de inventario para no recalcular la ocupación por cada tipo
+func (s *TarifaService) PreciosPorNoche(tipo domain.TipoHabitacion, desde, hasta time.Time, ocupacion map[time.Time]float64) ([]domain.SimulacionPrecioNoche, error) {
+	desde, hasta = soloFecha(desde), soloFecha(hasta)
+	if hasta.Before(desde) {
+		return nil, fmt.Errorf("la fecha hasta debe ser igual o posterior a la fecha desde")
+	}
+
+	return s.simularPrecios(tipo, desde, hasta, ocupacion, nil)
+}
+
+// simularPrecios aplica las reglas de precio dinámico a cada noche del calendario de tarifas.
+// ocupacionFija, si se indica, reemplaza la ocupación de porNoche
+func (s *TarifaService) simularPrecios(tipo domain.TipoHabitacion, desde, hasta time.Time, porNoche map[time.Time]float64, ocupacionFija *float64) ([]domain.SimulacionPrecioNoche, error) {
+	salida := hasta.AddDate(0, 0, 1)
+	temporadas, err := s.repo.GetTemporadasEnRango(tipo.ID, desde, salida)
+	if err != nil {
+		return nil, err
+	}
+	precios, err := s.repo.GetPreciosFecha(tipo.ID, desde, salida)
+	if err != nil {
+		return nil, err
+	}
+	reglas, err := s.reglaRepo.GetActivas(tipo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	base := domain.CotizarEstadia(tipo, temporadas, precios, desde, salida)
+	simulacion := make([]domain.SimulacionPrecioNoche, len(base.Noches))
+	for i, noche := range base.Noches {
+		resultado := domain.SimulacionPrecioNoche{
+			Fecha:      noche.Fecha,
+			Antelacion: diasAntelacion(noche.Fecha),
+			PrecioBase: noche.Precio,
+			Precio:     noche.Precio,
+		}
+		if ocupacionFija != nil {
+			resultado.Ocupacion = *ocupacionFija
+		} else {
+			resultado.Ocupacion = porNoche[noche.Fecha]
+		}
+
+		if regla := domain.ReglaPrecioNoche(reglas, tipo.ID, noche, resultado.Ocupacion, resultado.Antelacion); regla != nil {
+			reglaID := regla.ID
+			resultado.Precio = regla.Ajustar(noche.Precio)
+			resultado.ReglaID = &reglaID
+			resultado.Regla = regla.Nombre
+		}
+		simulacion[i] = resultado
+	}
+
+	return simulacion, nil
+}
+
+// GetReglasPrecio obtiene las reglas de precio dinámico que aplican a un tipo (0 = todas)
+func (s *TarifaService) GetReglasPrecio(tipoHabitacionID int) ([]domain.ReglaPrecio, error) {
+	return s.reglaRepo.GetAll(tipoHabitacionID)
+}
+
+// GetReglaPrecio obtiene una regla de precio dinámico por su ID
+func (s *TarifaService) GetReglaPrecio(id int) (*domain.ReglaPrecio, error) {
+	return s.reglaRepo.GetByID(id)
+}
+
+// CreateReglaPrecio crea una regla de precio dinámico
+func (s *TarifaService) CreateReglaPrecio(regla *domain.ReglaPrecio) error {
+	if err := s.prepararReglaPrecio(regla); err != nil {
+		return err
+	}
+	return s.reglaRepo.Create(regla)
+}
+
+// UpdateReglaPrecio actualiza una regla de precio dinámico. Las reservas ya hechas conservan
+// el precio cotizado
+func (s *TarifaService) UpdateReglaPrecio(regla *domain.ReglaPrecio) error {
+	if err := s.prepararReglaPrecio(regla); err != nil {
+		return err
+	}
+	return s.reglaRepo.Update(regla)
+}
+
+// DeleteReglaPrecio elimina una regla de precio dinámico
+func (s *TarifaService) DeleteReglaPrecio(id int) error {
+	return s.reglaRepo.Delete(id)
+}
+
+// prepararReglaPrecio normaliza y valida una regla antes de guardarla
+func (s *TarifaService) prepararReglaPrecio(regla *domain.ReglaPrecio) error {
+	regla.Nombre = strings.TrimSpace(regla.Nombre)
+	if regla.TipoModificador == "" {
+		regla.TipoModificador = domain.ModificadorPorcentaje
+	}
+	if err := regla.Validar(); err != nil {
+		return err
+	}
+	if regla.TipoHabitacionID != nil {
+		if _, err := s.habitacionRepo.GetRoomTypeByID(*regla.TipoHabitacionID); err != nil {
+			return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrReglaPrecioInvalida, *regla.TipoHabitacionID)
+		}
+	}
+	return nil
+}
+
+// diasAntelacion retorna los días que faltan desde hoy hasta la fecha (0 si ya pasó)
+func diasAntelacion(fecha time.Time) int {
+	hoy := soloFecha(time.Now().UTC())
+	return max(0, int(soloFecha(fecha).Sub(hoy).Hours()/24))
+}
+
+// CotizarPlan cotiza la estadía con el calendario de tarifas y le aplica el plan tarifario.
+// El plan debe estar activo y pertenecer al tipo de habitación
+func (s *TarifaService) CotizarPlan(planTarifaID, tipoHabitacionID int, fechaEntrada, fechaSalida time.Time) (*domain.CotizacionEstadia, error) {
+	plan, err := s.planParaTipo(planTarifaID, tipoHabitacionID)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := s.Cotizar(tipoHabitacionID, fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, err
+	}
+
+	cotizacion := plan.Aplicar(*base)
+	return &cotizacion, nil
+}
+
+// CotizarPlanes cotiza la estadía con cada plan activo del tipo de habitación
+func (s *TarifaService) CotizarPlanes(tipoHabitacionID int, fechaEntrada, fechaSalida time.Time) ([]domain.PlanCotizado, error) {
+	planes, err := s.planRepo.GetActivos(tipoHabitacionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(planes) == 0 {
+		return nil, nil
+	}
+
+	base, err := s.Cotizar(tipoHabitacionID, fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, err
+	}
+
+	cotizados := make([]domain.PlanCotizado, len(planes))
+	for i, plan := range planes {
+		cotizacion := plan.Aplicar(*base)
+		cotizados[i] = domain.PlanCotizado{
+			Plan:           plan,
+			Cotizacion:     cotizacion,
+			PrecioPromedio: cotizacion.PrecioPromedio(),
+		}
+	}
+
+	return cotizados, nil
+}
+
+// CotizarPrecioFijo arma la cotización de una estadía con un precio por noche acordado
+// (tarifa negociada de grupo o precio fijado por el hotel)
+func (s *TarifaService) CotizarPrecioFijo(tipoHabitacionID int, precio float64, fechaEntrada, fechaSalida time.Time) (*domain.CotizacionEstadia, error) {
+	entrada, salida, err := normalizarRango(fechaEntrada, fechaSalida)
+	if err != nil {
+		return nil, err
+	}
+
+	cotizacion := domain.CotizarPrecioFijo(tipoHabitacionID, precio, entrada, salida)
+	return &cotizacion, nil
+}
+
+// AgregarRecargosOcupacion verifica que los huéspedes quepan en una habitación del tipo
+// cotizado y suma a la cotización los recargos por adultos y niños adicionales
+func (s *TarifaService) AgregarRecargosOcupacion(cotizacion *domain.CotizacionEstadia, ocupacion domain.OcupacionHabitacion) error {
+	tipo, err := s.habitacionRepo.GetRoomTypeByID(cotizacion.TipoHabitacionID)
+	if err != nil {
+		return fmt.Errorf("error al obtener tipo de habitación %d: %w", cotizacion.TipoHabitacionID, err)
+	}
+	if err := domain.ValidarOcupacion(tipo, ocupacion); err != nil {
+		return err
+	}
+
+	tarifas, err := s.TarifasOcupacion([]domain.TipoHabitacion{tipo})
+	if err != nil {
+		return err
+	}
+	cotizacion.AgregarRecargos(tarifas[0].Recargos(ocupacion, len(cotizacion.Noches)))
+	return nil
+}
+
+// GetTarifaOcupacion obtiene los recargos por huéspedes de un tipo de habitación. Un tipo sin
+// recargos configurados incluye toda su capacidad en el precio
+func (s *TarifaService) GetTarifaOcupacion(tipoHabitacionID int) (*domain.TarifaOcupacion, error) {
+	tipo, err := s.habitacionRepo.GetRoomTypeByID(tipoHabitacionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tipo de habitación %d", domain.ErrTarifaNoEncontrada, tipoHabitacionID)
+	}
+
+	tarifas, err := s.TarifasOcupacion([]domain.TipoHabitacion{tipo})
+	if err != nil {
+		return nil, err
+	}
+	return &tarifas[0], nil
+}
+
+// TarifasOcupacion obtiene los recargos por huéspedes de cada tipo, en el mismo orden
+func (s *TarifaService) TarifasOcupacion(tipos []domain.TipoHabitacion) ([]domain.TarifaOcupacion, error) {
+	tarifas := make([]domain.TarifaOcupacion, len(tipos))
+	for i, tipo := range tipos {
+		tarifa, err := s.repo.GetTarifaOcupacion(tipo.ID)
+		if err != nil {
+			return nil, err
+		}
+		if tarifa == nil {
+			tarifas[i] = domain.TarifaOcupacionPorDefecto(tipo)
+			continue
+		}
+		tarifas[i] = *tarifa
+	}
+	return tarifas, nil
+}
+
+// GuardarTarifaOcupacion crea o reemplaza los recargos por huéspedes de un tipo de habitación
+func (s *TarifaService) GuardarTarifaOcupacion(tarifa *domain.TarifaOcupacion) error {
+	if tarifa.BandasNinhos == nil {
+		tarifa.BandasNinhos = make([]domain.BandaEdadNinho, 0)
+	}
+	if err := tarifa.Validar(); err != nil {
+		return err
+	}
+
+	tipo, err := s.habitacionRepo.GetRoomTypeByID(tarifa.TipoHabitacionID)
+	if err != nil {
+		return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrTarifaInvalida, tarifa.TipoHabitacionID)
+	}
+	if tarifa.AdultosIncluidos > tipo.CapacidadAdultos {
+		return fmt.Errorf("%w: %s admite %d adultos", domain.ErrTarifaInvalida, tipo.Titulo, tipo.CapacidadAdultos)
+	}
+
+	return s.repo.GuardarTarifaOcupacion(tarifa)
+}
+
+// GetTemporadas obtiene las temporadas de un tipo de habitación (0 = todos los tipos)
+func (s *TarifaService) GetTemporadas(tipoHabitacionID int) ([]domain.TemporadaTarifa, error) {
+	return s.repo.GetTemporadas(tipoHabitacionID)
+}
+
+// CreateTemporada crea una nueva temporada de tarifa
+func (s *TarifaService) CreateTemporada(temporada *domain.TemporadaTarifa) error {
+	if err := s.prepararTemporada(temporada); err != nil {
+		return err
+	}
+	return s.repo.CreateTemporada(temporada)
+}
+
+// UpdateTemporada actualiza una temporada de tarifa
+func (s *TarifaService) UpdateTemporada(temporada *domain.TemporadaTarifa) error {
+	if err := s.prepararTemporada(temporada); err != nil {
+		return err
+	}
+	return s.repo.UpdateTemporada(temporada)
+}
+
+// DeleteTemporada elimina una temporada de tarifa
+func (s *TarifaService) DeleteTemporada(id int) error {
+	return s.repo.DeleteTemporada(id)
+}
+
+// GetPreciosFecha obtiene los precios por fecha en [desde, hasta) (tipo 0 = todos los tipos)
+func (s *TarifaService) GetPreciosFecha(tipoHabitacionID int, desde, hasta time.Time) ([]domain.PrecioFecha, error) {
+	desde, hasta, err := normalizarRango(desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetPreciosFecha(tipoHabitacionID, desde, hasta)
+}
+
+// GuardarPrecioFecha fija el precio de un tipo de habitación para una fecha
+func (s *TarifaService) GuardarPrecioFecha(precio *domain.PrecioFecha) error {
+	precio.Fecha = soloFecha(precio.Fecha)
+	precio.Motivo = strings.TrimSpace(precio.Motivo)
+	if err := precio.Validar(); err != nil {
+		return err
+	}
+	if err := s.verificarTipoHabitacion(precio.TipoHabitacionID); err != nil {
+		return err
+	}
+	return s.repo.GuardarPrecioFecha(precio)
+}
+
+// DeletePrecioFecha elimina el precio fijado para una fecha
+func (s *TarifaService) DeletePrecioFecha(tipoHabitacionID int, fecha time.Time) error {
+	return s.repo.DeletePrecioFecha(tipoHabitacionID, soloFecha(fecha))
+}
+
+// GetPlanes obtiene los planes tarifarios de un tipo de habitación (0 = todos los tipos)
+func (s *TarifaService) GetPlanes(tipoHabitacionID int) ([]domain.PlanTarifa, error) {
+	return s.planRepo.GetAll(tipoHabitacionID)
+}
+
+// GetPlan obtiene un plan tarifario por su ID
+func (s *TarifaService) GetPlan(id int) (*domain.PlanTarifa, error) {
+	return s.planRepo.GetByID(id)
+}
+
+// CreatePlan crea un plan tarifario
+func (s *TarifaService) CreatePlan(plan *domain.PlanTarifa) error {
+	if err := s.prepararPlan(plan); err != nil {
+		return err
+	}
+	return s.planRepo.Create(plan)
+}
+
+// UpdatePlan actualiza un plan tarifario. Las reservas ya hechas conservan el precio cotizado
+func (s *TarifaService) UpdatePlan(plan *domain.PlanTarifa) error {
+	if err := s.prepararPlan(plan); err != nil {
+		return err
+	}
+	return s.planRepo.Update(plan)
+}
+
+// PlanEquivalente busca, para otro tipo de habitación, el plan activo con el mismo código.
+// Se usa al cambiar el tipo de una habitación reservada; retorna nil si no hay equivalente
+func (s *TarifaService) PlanEquivalente(planTarifaID, tipoHabitacionID int) (*int, error) {
+	plan, err := s.planRepo.GetByID(planTarifaID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.TipoHabitacionID == tipoHabitacionID {
+		return &plan.ID, nil
+	}
+
+	equivalente, err := s.planRepo.GetByCodigo(tipoHabitacionID, plan.Codigo)
+	if err != nil || equivalente == nil {
+		return nil, err
+	}
+	return &equivalente.ID, nil
+}
+
+// planParaTipo obtiene el plan y verifica que esté activo y sea del tipo de habitación
+func (s *TarifaService) planParaTipo(planTarifaID, tipoHabitacionID int) (*domain.PlanTarifa, error) {
+	plan, err := s.planRepo.GetByID(planTarifaID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Activo {
+		return nil, fmt.Errorf("%w: el plan %s no está disponible", domain.ErrPlanTarifaInvalido, plan.Nombre)
+	}
+	if plan.TipoHabitacionID != tipoHabitacionID {
+		return nil, fmt.Errorf("%w: el plan %s no corresponde al tipo de habitación %d", domain.ErrPlanTarifaInvalido, plan.Nombre, tipoHabitacionID)
+	}
+	return plan, nil
+}
+
+// prepararPlan normaliza y valida un plan antes de guardarlo
+func (s *TarifaService) prepararPlan(plan *domain.PlanTarifa) error {
+	plan.Codigo = strings.ToUpper(strings.TrimSpace(plan.Codigo))
+	plan.Nombre = strings.TrimSpace(plan.Nombre)
+	plan.Descripcion = strings.TrimSpace(plan.Descripcion)
+	if plan.TipoModificador == "" {
+		plan.TipoModificador = domain.ModificadorPorcentaje
+	}
+	if plan.Cancelacion != nil {
+		plan.Cancelacion.Nombre = plan.Nombre
+		plan.Cancelacion.TipoHabitacionID = &plan.TipoHabitacionID
+		plan.Cancelacion.Activa = true
+	}
+	if plan.ServiciosIncluidos == nil {
+		plan.ServiciosIncluidos = make([]int, 0)
+	}
+
+	if err := plan.Validar(); err != nil {
+		return err
+	}
+	if _, err := s.habitacionRepo.GetRoomTypeByID(plan.TipoHabitacionID); err != nil {
+		return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrPlanTarifaInvalido, plan.TipoHabitacionID)
+	}
+	for _, servicioID := range plan.ServiciosIncluidos {
+		if _, err := s.servicioRepo.GetByID(servicioID); err != nil {
+			return fmt.Errorf("%w: servicio %d no encontrado", domain.ErrPlanTarifaInvalido, servicioID)
+		}
+	}
+
+	return nil
+}
+
+// prepararTemporada normaliza y valida una temporada antes de guardarla
+func (s *TarifaService) prepararTemporada(temporada *domain.TemporadaTarifa) error {
+	temporada.Nombre = strings.TrimSpace(temporada.Nombre)
+	temporada.FechaInicio = soloFecha(temporada.FechaInicio)
+	temporada.FechaFin = soloFecha(temporada.FechaFin)
+	if err := temporada.Validar(); err != nil {
+		return err
+	}
+	return s.verificarTipoHabitacion(temporada.TipoHabitacionID)
+}
+
+// verificarTipoHabitacion comprueba que el tipo de habitación exista
+func (s *TarifaService) verificarTipoHabitacion(tipoHabitacionID int) error {
+	if _, err := s.habitacionRepo.GetRoomTypeByID(tipoHabitacionID); err != nil {
+		return fmt.Errorf("%w: tipo de habitación %d no encontrado", domain.ErrTarifaInvalida, tipoHabitacionID)
+	}
+	return nil
+}
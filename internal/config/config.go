@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -23,6 +25,18 @@ type Config struct {
 	SMTPFromName  string
 	SMTPFromEmail string
 	HotelLocation string `env:"HOTEL_LOCATION" json:"hotel_location"`
+	// ReservationHoldMinutes es el tiempo que una reserva pendiente retiene sus habitaciones
+	ReservationHoldMinutes int
+	// NoShowCutoffHours son las horas, contadas desde las 00:00 de la fecha de entrada, tras las
+	// cuales una reserva confirmada sin check-in se marca como no-show
+	NoShowCutoffHours int
+	// WaitlistLinkMinutes es la vigencia del enlace de reserva enviado a la lista de espera
+	WaitlistLinkMinutes int
+	// FrontendURL es la URL base del sitio web, usada en los enlaces enviados por correo
+	FrontendURL string
+	// ChannelFileDir es el directorio del canal de venta simulado con archivos JSON (código
+	// "archivo"); vacío lo deshabilita
+	ChannelFileDir string
 }
 
 func LoadConfig() (*Config, error) {
@@ -46,6 +60,12 @@ func LoadConfig() (*Config, error) {
 		SMTPFromName:  getEnv("SMTP_FROM_NAME", "Hotel Reservas"),
 		SMTPFromEmail: getEnv("SMTP_FROM_EMAIL", ""),
 		HotelLocation: getEnv("HOTEL_LOCATION", ""),
+
+		ReservationHoldMinutes: getEnvInt("RESERVATION_HOLD_MINUTES", 30),
+		NoShowCutoffHours:      getEnvInt("NO_SHOW_CUTOFF_HOURS", 30),
+		WaitlistLinkMinutes:    getEnvInt("WAITLIST_LINK_MINUTES", 120),
+		FrontendURL:            getEnv("FRONTEND_URL", "http://localhost:3000"),
+		ChannelFileDir:         getEnv("CHANNEL_FILE_DIR", ""),
 	}
 
 	// Validar que las variables requeridas no estén vacías
@@ -62,6 +82,22 @@ func (c *Config) GetDBConnString() string {
 		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName)
 }
 
+// ReservationHoldDuration retorna el plazo de retención de las reservas pendientes
+func (c *Config) ReservationHoldDuration() time.Duration {
+	return time.Duration(c.ReservationHoldMinutes) * time.Minute
+}
+
+// NoShowCutoff retorna el plazo, desde las 00:00 de la fecha de entrada, tras el cual una
+// reserva confirmada sin check-in se considera no-show
+func (c *Config) NoShowCutoff() time.Duration {
+	return time.Duration(c.NoShowCutoffHours) * time.Hour
+}
+
+// WaitlistLinkDuration retorna la vigencia del enlace de reserva de la lista de espera
+func (c *Config) WaitlistLinkDuration() time.Duration {
+	return time.Duration(c.WaitlistLinkMinutes) * time.Minute
+}
+
 // String implementa la interfaz Stringer para evitar que se impriman datos sensibles en logs
 func (c Config) String() string {
 	return fmt.Sprintf("Config{DBHost: %s, DBPort: %s, DBUser: %s, DBPassword: [HIDDEN], DBName: %s, ServerPort: %s, HotelLocation: %s}",
@@ -76,3 +112,13 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt obtiene una variable de entorno numérica o devuelve un valor por defecto
+// si no existe o no es un entero positivo
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
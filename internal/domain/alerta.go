@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// LongitudMaximaMensajeAlerta es la cantidad máxima de caracteres del mensaje de una alerta
+const LongitudMaximaMensajeAlerta = 100
+
+// TipoAlerta clasifica las alertas dirigidas a recepción
+type TipoAlerta string
+
+const (
+	// AlertaNoShow avisa que una reserva confirmada fue marcada como no-show
+	AlertaNoShow TipoAlerta = "no_show"
+)
+
+// Alerta es un aviso para el personal de recepción
+type Alerta struct {
+	ID        int        `json:"id"`
+	Tipo      TipoAlerta `json:"tipo"`
+	Mensaje   string     `json:"mensaje"`
+	ReservaID *int       `json:"reservaId,omitempty"`
+	Fecha     time.Time  `json:"fecha"`
+	Resuelta  bool       `json:"resuelta"`
+}
+
+// RecortarMensajeAlerta ajusta el mensaje a LongitudMaximaMensajeAlerta caracteres, marcando
+// con "…" los que se recortan
+func RecortarMensajeAlerta(mensaje string) string {
+	if utf8.RuneCountInString(mensaje) <= LongitudMaximaMensajeAlerta {
+		return mensaje
+	}
+	runas := []rune(mensaje)
+	return string(runas[:LongitudMaximaMensajeAlerta-1]) + "…"
+}
+
+// AlertaRepository define las operaciones con alertas
+type AlertaRepository interface {
+	// Create registra una nueva alerta
+	Create(alerta *Alerta) error
+}
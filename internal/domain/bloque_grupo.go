@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrBloqueGrupoNoEncontrado indica que el bloque de grupo no existe
+	ErrBloqueGrupoNoEncontrado = errors.New("bloque de grupo no encontrado")
+	// ErrBloqueGrupoInvalido indica que los datos del bloque no son coherentes
+	ErrBloqueGrupoInvalido = errors.New("bloque de grupo inválido")
+	// ErrBloqueGrupoSinCupo indica que el bloque no admite más reservas (sin habitaciones
+	// pendientes, liberado o fuera de sus fechas)
+	ErrBloqueGrupoSinCupo = errors.New("el bloque de grupo no tiene cupo disponible")
+)
+
+// EstadoBloqueGrupo representa la situación de un bloque de habitaciones de grupo
+type EstadoBloqueGrupo string
+
+const (
+	// BloqueActivo aparta las habitaciones no recogidas de la venta general
+	BloqueActivo EstadoBloqueGrupo = "Activo"
+	// BloqueLiberado devolvió las habitaciones no recogidas a la venta general
+	BloqueLiberado EstadoBloqueGrupo = "Liberado"
+)
+
+// BloqueGrupo aparta Cantidad habitaciones de un tipo para un rango de fechas a nombre de un
+// grupo (por ejemplo, un operador turístico). Las reservas individuales del grupo se "recogen"
+// contra el bloque; en FechaLiberacion las habitaciones no recogidas vuelven a la venta general
+type BloqueGrupo struct {
+	ID               int       `json:"id"`
+	Nombre           string    `json:"nombre"`
+	Contacto         string    `json:"contacto"`
+	Email            *string   `json:"email,omitempty"`
+	TipoHabitacionID int       `json:"tipoHabitacionId"`
+	Cantidad         int       `json:"cantidad"`
+	FechaEntrada     time.Time `json:"fechaEntrada"`
+	FechaSalida      time.Time `json:"fechaSalida"`
+	FechaLiberacion  time.Time `json:"fechaLiberacion"`
+	// Precio es la tarifa por noche negociada con el grupo; nil usa el precio del tipo
+	Precio *float64          `json:"precio,omitempty"`
+	Estado EstadoBloqueGrupo `json:"estado"`
+	// Recogidas es la cantidad de habitaciones ya reservadas contra el bloque
+	Recogidas     int        `json:"recogidas"`
+	FechaCreacion time.Time  `json:"fechaCreacion"`
+	FechaLiberado *time.Time `json:"fechaLiberado,omitempty"`
+}
+
+// Pendientes retorna cuántas habitaciones del bloque siguen apartadas sin recoger
+func (b BloqueGrupo) Pendientes() int {
+	if b.Estado != BloqueActivo || b.Recogidas >= b.Cantidad {
+		return 0
+	}
+	return b.Cantidad - b.Recogidas
+}
+
+// RetieneNoche indica si el bloque aparta inventario la noche que empieza en la fecha dada
+func (b BloqueGrupo) RetieneNoche(noche time.Time) bool {
+	return b.Pendientes() > 0 && !noche.Before(b.FechaEntrada) && noche.Before(b.FechaSalida)
+}
+
+// Validar verifica que el bloque tenga valores coherentes
+func (b BloqueGrupo) Validar() error {
+	if strings.TrimSpace(b.Nombre) == "" {
+		return fmt.Errorf("%w: el nombre del grupo es requerido", ErrBloqueGrupoInvalido)
+	}
+	if b.TipoHabitacionID <= 0 {
+		return fmt.Errorf("%w: el tipo de habitación es requerido", ErrBloqueGrupoInvalido)
+	}
+	if b.Cantidad < 1 {
+		return fmt.Errorf("%w: la cantidad de habitaciones debe ser mayor a 0", ErrBloqueGrupoInvalido)
+	}
+	if !b.FechaSalida.After(b.FechaEntrada) {
+		return fmt.Errorf("%w: la fecha de salida debe ser posterior a la fecha de entrada", ErrBloqueGrupoInvalido)
+	}
+	if b.FechaLiberacion.After(b.FechaEntrada) {
+		return fmt.Errorf("%w: la fecha de liberación no puede ser posterior a la fecha de entrada", ErrBloqueGrupoInvalido)
+	}
+	if b.Precio != nil && *b.Precio <= 0 {
+		return fmt.Errorf("%w: el precio negociado debe ser mayor a 0", ErrBloqueGrupoInvalido)
+	}
+	return nil
+}
+
+// BloqueGrupoRepository define las operaciones con bloques de grupo
+type BloqueGrupoRepository interface {
+	// GetAll obtiene todos los bloques, los más recientes primero
+	GetAll() ([]BloqueGrupo, error)
+	// GetByID obtiene un bloque por su ID
+	GetByID(id int) (*BloqueGrupo, error)
+	// GetActivos obtiene los bloques activos cuyas fechas se solapan con [desde, hasta)
+	GetActivos(desde, hasta time.Time) ([]BloqueGrupo, error)
+	// Create registra un nuevo bloque
+	Create(bloque *BloqueGrupo) error
+	// Update actualiza los datos de un bloque
+	Update(bloque *BloqueGrupo) error
+	// Liberar devuelve a la venta general las habitaciones no recogidas del bloque
+	Liberar(id int, ahora time.Time) error
+	// LiberarVencidos libera los bloques activos cuya fecha de liberación es hoy o anterior
+	// y retorna sus IDs
+	LiberarVencidos(hoy, ahora time.Time) ([]int, error)
+}
@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCalendarioNoEncontrado indica que la habitación o el tipo no tiene un feed iCal
+	ErrCalendarioNoEncontrado = errors.New("feed de calendario no encontrado")
+	// ErrTokenCalendarioInvalido indica que el token no corresponde al feed solicitado
+	ErrTokenCalendarioInvalido = errors.New("token de calendario inválido")
+	// ErrICalInvalido indica que el contenido no es un calendario iCal válido
+	ErrICalInvalido = errors.New("calendario iCal inválido")
+)
+
+// AlcanceCalendario indica si un feed iCal publica una habitación o un tipo de habitación
+type AlcanceCalendario string
+
+const (
+	// CalendarioHabitacion publica las ocupaciones de una habitación
+	CalendarioHabitacion AlcanceCalendario = "habitacion"
+	// CalendarioTipoHabitacion publica las ocupaciones de todas las habitaciones de un tipo
+	CalendarioTipoHabitacion AlcanceCalendario = "tipo_habitacion"
+)
+
+// longitudTokenCalendario es la cantidad de bytes aleatorios del token (64 caracteres hex)
+const longitudTokenCalendario = 32
+
+// CalendarioFeed es el feed iCal de una habitación o de un tipo de habitación. El Token es el
+// secreto que los propietarios y OTAs incluyen en la URL para leer el feed
+type CalendarioFeed struct {
+	ID        int               `json:"id"`
+	Alcance   AlcanceCalendario `json:"alcance"`
+	RecursoID int               `json:"recursoId"`
+	Token     string            `json:"token"`
+	CreadoEn  time.Time         `json:"creadoEn"`
+}
+
+// GenerarTokenCalendario genera un token aleatorio para un feed iCal
+func GenerarTokenCalendario() (string, error) {
+	b := make([]byte, longitudTokenCalendario)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error al generar token de calendario: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// EventoCalendario es un rango ocupado [Inicio, Fin) publicado como VEVENT de día completo.
+// El UID se deriva de la reserva y la habitación, así los cambios de fechas actualizan el
+// mismo evento en el calendario del consumidor
+type EventoCalendario struct {
+	UID     string
+	Inicio  time.Time
+	Fin     time.Time
+	Resumen string
+}
+
+// UIDEventoCalendario retorna el UID estable de la ocupación de una habitación por una reserva
+func UIDEventoCalendario(reservaID, habitacionID int) string {
+	return fmt.Sprintf("reserva-%d-habitacion-%d@hotel-backend", reservaID, habitacionID)
+}
+
+// GenerarICal genera un VCALENDAR (RFC 5545) con un VEVENT por evento. generadoEn se usa
+// como DTSTAMP de todos los eventos
+func GenerarICal(nombre string, eventos []EventoCalendario, generadoEn time.Time) string {
+	var sb strings.Builder
+	linea := func(contenido string) {
+		sb.WriteString(plegarLineaICal(contenido))
+		sb.WriteString("\r\n")
+	}
+
+	linea("BEGIN:VCALENDAR")
+	linea("VERSION:2.0")
+	linea("PRODID:-//Hotel Backend//Calendario de ocupación//ES")
+	linea("CALSCALE:GREGORIAN")
+	linea("METHOD:PUBLISH")
+	linea("X-WR-CALNAME:" + escaparTextoICal(nombre))
+
+	dtstamp := generadoEn.UTC().Format("20060102T150405Z")
+	for _, e := range eventos {
+		linea("BEGIN:VEVENT")
+		linea("UID:" + escaparTextoICal(e.UID))
+		linea("DTSTAMP:" + dtstamp)
+		linea("DTSTART;VALUE=DATE:" + e.Inicio.Format("20060102"))
+		linea("DTEND;VALUE=DATE:" + e.Fin.Format("20060102"))
+		linea("SUMMARY:" + escaparTextoICal(e.Resumen))
+		linea("TRANSP:OPAQUE")
+		linea("END:VEVENT")
+	}
+
+	linea("END:VCALENDAR")
+	return sb.String()
+}
+
+// escaparTextoICal escapa los caracteres reservados de un valor TEXT de iCalendar
+func escaparTextoICal(texto string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(texto)
+}
+
+// plegarLineaICal divide las líneas de más de 75 octetos como exige RFC 5545, sin cortar
+// caracteres UTF-8
+func plegarLineaICal(linea string) string {
+	const maximo = 75
+	if len(linea) <= maximo {
+		return linea
+	}
+
+	var sb strings.Builder
+	largo := 0
+	for _, r := range linea {
+		tam := len(string(r))
+		if largo+tam > maximo {
+			sb.WriteString("\r\n ")
+			largo = 1
+		}
+		sb.WriteRune(r)
+		largo += tam
+	}
+	return sb.String()
+}
+
+// ParsearICal lee los VEVENT de un calendario iCal (RFC 5545) como rangos de días [Inicio, Fin).
+// Las fechas con hora se reducen a su día; sin DTEND el evento dura una noche. Los eventos
+// cancelados o transparentes (que no ocupan) se omiten. Las reglas de repetición (RRULE) no se
+// expanden: solo se toma la primera ocurrencia
+func ParsearICal(contenido string) ([]EventoCalendario, error) {
+	lineas := desplegarLineasICal(contenido)
+
+	eventos := make([]EventoCalendario, 0)
+	enCalendario, enEvento := false, false
+	var evento EventoCalendario
+	var omitir bool
+	for _, l := range lineas {
+		nombre, valor, ok := propiedadICal(l)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case nombre == "BEGIN" && strings.EqualFold(valor, "VCALENDAR"):
+			enCalendario = true
+		case !enCalendario:
+			continue
+		case nombre == "BEGIN" && strings.EqualFold(valor, "VEVENT"):
+			enEvento, omitir = true, false
+			evento = EventoCalendario{}
+		case nombre == "END" && strings.EqualFold(valor, "VEVENT"):
+			enEvento = false
+			if omitir {
+				continue
+			}
+			if evento.Inicio.IsZero() {
+				return nil, fmt.Errorf("%w: evento %q sin DTSTART", ErrICalInvalido, evento.UID)
+			}
+			if !evento.Fin.After(evento.Inicio) {
+				evento.Fin = evento.Inicio.AddDate(0, 0, 1)
+			}
+			eventos = append(eventos, evento)
+		case !enEvento:
+			continue
+		case nombre == "UID":
+			evento.UID = desescaparTextoICal(valor)
+		case nombre == "SUMMARY":
+			evento.Resumen = desescaparTextoICal(valor)
+		case nombre == "DTSTART" || nombre == "DTEND":
+			fecha, err := fechaICal(valor)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s inválido en el evento %q", ErrICalInvalido, nombre, evento.UID)
+			}
+			if nombre == "DTSTART" {
+				evento.Inicio = fecha
+			} else {
+				evento.Fin = fecha
+			}
+		case nombre == "STATUS" && strings.EqualFold(valor, "CANCELLED"),
+			nombre == "TRANSP" && strings.EqualFold(valor, "TRANSPARENT"):
+			omitir = true
+		}
+	}
+
+	if !enCalendario {
+		return nil, fmt.Errorf("%w: falta BEGIN:VCALENDAR", ErrICalInvalido)
+	}
+	return eventos, nil
+}
+
+// desplegarLineasICal une las líneas plegadas (las que empiezan con espacio o tabulación
+// continúan la anterior)
+func desplegarLineasICal(contenido string) []string {
+	crudas := strings.Split(strings.ReplaceAll(contenido, "\r\n", "\n"), "\n")
+	lineas := make([]string, 0, len(crudas))
+	for _, l := range crudas {
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lineas) > 0 {
+			lineas[len(lineas)-1] += l[1:]
+			continue
+		}
+		lineas = append(lineas, strings.TrimRight(l, "\r"))
+	}
+	return lineas
+}
+
+// propiedadICal separa una línea en el nombre de la propiedad (sin parámetros, en mayúsculas)
+// y su valor. Los ":" dentro de parámetros entre comillas no cortan la línea
+func propiedadICal(linea string) (string, string, bool) {
+	entreComillas := false
+	for i, r := range linea {
+		switch {
+		case r == '"':
+			entreComillas = !entreComillas
+		case r == ':' && !entreComillas:
+			nombre, _, _ := strings.Cut(linea[:i], ";")
+			return strings.ToUpper(strings.TrimSpace(nombre)), strings.TrimSpace(linea[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// fechaICal convierte un valor DATE (20261020) o DATE-TIME (20261020T140000[Z]) en el día
+// correspondiente a las 00:00 UTC
+func fechaICal(valor string) (time.Time, error) {
+	if len(valor) < 8 {
+		return time.Time{}, fmt.Errorf("fecha iCal inválida: %q", valor)
+	}
+	return time.Parse("20060102", valor[:8])
+}
+
+// desescaparTextoICal revierte escaparTextoICal
+func desescaparTextoICal(texto string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(texto)
+}
+
+// CalendarioFeedRepository define las operaciones con los feeds iCal
+type CalendarioFeedRepository interface {
+	// Get obtiene el feed de una habitación o tipo. Retorna ErrCalendarioNoEncontrado si no existe
+	Get(alcance AlcanceCalendario, recursoID int) (*CalendarioFeed, error)
+	// Save crea el feed o reemplaza su token si ya existe
+	Save(feed *CalendarioFeed) error
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCalendarioExternoNoEncontrado indica que el calendario externo no existe
+	ErrCalendarioExternoNoEncontrado = errors.New("calendario externo no encontrado")
+	// ErrCalendarioExternoInvalido indica que el calendario externo no es coherente
+	ErrCalendarioExternoInvalido = errors.New("calendario externo inválido")
+)
+
+// AlertaConflictoCalendario avisa que un bloqueo importado se solapa con una reserva del hotel
+const AlertaConflictoCalendario TipoAlerta = "conflicto_calendario"
+
+// CalendarioExterno es un calendario iCal de otra plataforma (OTA, propietario) cuyas
+// ocupaciones bloquean una habitación. Se lee de una URL o de un archivo .ics subido
+type CalendarioExterno struct {
+	ID           int    `json:"id"`
+	HabitacionID int    `json:"habitacionId"`
+	Nombre       string `json:"nombre"`
+	// URL del feed iCal; vacía si el calendario se subió como archivo
+	URL string `json:"url,omitempty"`
+	// Contenido es el archivo .ics subido (no se expone en la API)
+	Contenido            string     `json:"-"`
+	Activo               bool       `json:"activo"`
+	UltimaSincronizacion *time.Time `json:"ultimaSincronizacion,omitempty"`
+	// UltimoError es el error de la última sincronización; vacío si fue exitosa
+	UltimoError string    `json:"ultimoError,omitempty"`
+	CreadoEn    time.Time `json:"creadoEn"`
+}
+
+// Validar verifica que el calendario tenga valores coherentes. Las URLs webcal:// se
+// normalizan a https://
+func (c *CalendarioExterno) Validar() error {
+	if strings.TrimSpace(c.Nombre) == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrCalendarioExternoInvalido)
+	}
+	if c.HabitacionID <= 0 {
+		return fmt.Errorf("%w: la habitación es requerida", ErrCalendarioExternoInvalido)
+	}
+	if (c.URL == "") == (c.Contenido == "") {
+		return fmt.Errorf("%w: indique una URL o suba un archivo .ics", ErrCalendarioExternoInvalido)
+	}
+	if c.URL == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(strings.ToLower(c.URL), "webcal://") {
+		c.URL = "https://" + c.URL[len("webcal://"):]
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: la URL debe ser http(s) o webcal", ErrCalendarioExternoInvalido)
+	}
+	return nil
+}
+
+// BloqueoExterno es un rango [FechaInicio, FechaFin) en que una habitación está ocupada según
+// un calendario externo. UID identifica el evento dentro del calendario
+type BloqueoExterno struct {
+	ID                  int       `json:"id"`
+	CalendarioExternoID int       `json:"calendarioExternoId"`
+	HabitacionID        int       `json:"habitacionId"`
+	UID                 string    `json:"uid"`
+	FechaInicio         time.Time `json:"fechaInicio"`
+	FechaFin            time.Time `json:"fechaFin"`
+	Resumen             string    `json:"resumen,omitempty"`
+}
+
+// BloqueosDesdeEventos convierte los eventos de un calendario externo en bloqueos de su
+// habitación, descartando los que terminan antes de desde. Los UID vacíos o repetidos
+// (ocurrencias de un mismo evento) se completan con la fecha de inicio
+func BloqueosDesdeEventos(calendario CalendarioExterno, eventos []EventoCalendario, desde time.Time) []BloqueoExterno {
+	bloqueos := make([]BloqueoExterno, 0, len(eventos))
+	vistos := make(map[string]bool, len(eventos))
+	for _, e := range eventos {
+		if !e.Fin.After(desde) {
+			continue
+		}
+
+		uid := e.UID
+		if uid == "" || vistos[uid] {
+			uid = fmt.Sprintf("%s#%s", uid, e.Inicio.Format("20060102"))
+		}
+		vistos[uid] = true
+
+		bloqueos = append(bloqueos, BloqueoExterno{
+			CalendarioExternoID: calendario.ID,
+			HabitacionID:        calendario.HabitacionID,
+			UID:                 uid,
+			FechaInicio:         e.Inicio,
+			FechaFin:            e.Fin,
+			Resumen:             e.Resumen,
+		})
+	}
+	return bloqueos
+}
+
+// ConflictoCalendario es un bloqueo importado que se solapa con una reserva del hotel en la
+// misma habitación: la habitación quedó vendida en dos plataformas
+type ConflictoCalendario struct {
+	Bloqueo      BloqueoExterno `json:"bloqueo"`
+	ReservaID    int            `json:"reservaId"`
+	FechaEntrada time.Time      `json:"fechaEntrada"`
+	FechaSalida  time.Time      `json:"fechaSalida"`
+}
+
+// ResultadoSincronizacion resume la importación de un calendario externo
+type ResultadoSincronizacion struct {
+	CalendarioExternoID int                   `json:"calendarioExternoId"`
+	Bloqueos            int                   `json:"bloqueos"`
+	Conflictos          []ConflictoCalendario `json:"conflictos"`
+	// Error es el motivo por el que no se pudo sincronizar; se conservan los bloqueos anteriores
+	Error string `json:"error,omitempty"`
+}
+
+// CalendarioExternoRepository define las operaciones con los calendarios externos y sus bloqueos
+type CalendarioExternoRepository interface {
+	// GetAll obtiene los calendarios de una habitación (0 = todos)
+	GetAll(habitacionID int) ([]CalendarioExterno, error)
+	// GetActivos obtiene los calendarios activos, con su contenido, para sincronizarlos
+	GetActivos() ([]CalendarioExterno, error)
+	// GetByID obtiene un calendario por su ID. Retorna ErrCalendarioExternoNoEncontrado si no existe
+	GetByID(id int) (*CalendarioExterno, error)
+	// Create crea un nuevo calendario
+	Create(calendario *CalendarioExterno) error
+	// Update actualiza un calendario existente
+	Update(calendario *CalendarioExterno) error
+	// Delete elimina un calendario y sus bloqueos
+	Delete(id int) error
+	// GuardarSincronizacion reemplaza los bloqueos del calendario y registra la sincronización exitosa
+	GuardarSincronizacion(calendarioID int, bloqueos []BloqueoExterno, fecha time.Time) error
+	// RegistrarErrorSincronizacion registra una sincronización fallida sin tocar los bloqueos
+	RegistrarErrorSincronizacion(calendarioID int, fecha time.Time, mensaje string) error
+	// GetBloqueos obtiene los bloqueos de un calendario
+	GetBloqueos(calendarioID int) ([]BloqueoExterno, error)
+	// GetBloqueosEnRango obtiene los bloqueos de calendarios activos que se solapan con [desde, hasta)
+	GetBloqueosEnRango(desde, hasta time.Time) ([]BloqueoExterno, error)
+}
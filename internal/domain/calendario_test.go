@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fechaPrueba(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func leerICalPrueba(t *testing.T, archivo string) string {
+	t.Helper()
+	contenido, err := os.ReadFile(filepath.Join("testdata", archivo))
+	if err != nil {
+		t.Fatalf("no se pudo leer %s: %v", archivo, err)
+	}
+	return string(contenido)
+}
+
+func TestParsearICal(t *testing.T) {
+	tests := []struct {
+		name    string
+		archivo string
+		eventos []EventoCalendario
+	}{
+		{
+			name:    "eventos de día completo",
+			archivo: "todo_el_dia.ics",
+			eventos: []EventoCalendario{
+				{UID: "reserva-a@airbnb.com", Inicio: fechaPrueba("2030-10-10"), Fin: fechaPrueba("2030-10-13"), Resumen: "Reserved"},
+				// Sin DTEND el evento dura una noche; el cancelado y el transparente se omiten
+				{UID: "bloqueo-b@airbnb.com", Inicio: fechaPrueba("2030-10-20"), Fin: fechaPrueba("2030-10-21"), Resumen: "Not available"},
+			},
+		},
+		{
+			name:    "eventos con hora se reducen a su día",
+			archivo: "con_hora.ics",
+			eventos: []EventoCalendario{
+				{UID: "utc-1@booking.com", Inicio: fechaPrueba("2030-10-10"), Fin: fechaPrueba("2030-10-12"), Resumen: "CLOSED - Not available"},
+				{UID: "tzid-2@booking.com", Inicio: fechaPrueba("2030-12-15"), Fin: fechaPrueba("2030-12-18"), Resumen: "Huésped Booking"},
+			},
+		},
+		{
+			name:    "líneas plegadas",
+			archivo: "lineas_plegadas.ics",
+			eventos: []EventoCalendario{{
+				UID:     "evento-con-un-identificador-muy-largo-que-supera-los-setenta-y-cinco-octetos@propietario.example",
+				Inicio:  fechaPrueba("2030-03-01"),
+				Fin:     fechaPrueba("2030-03-05"),
+				Resumen: "Estadía larga reservada directamente con el propietario por una familia de cuatro personas",
+			}},
+		},
+		{
+			name:    "texto escapado",
+			archivo: "texto_escapado.ics",
+			eventos: []EventoCalendario{{
+				UID:     "escapado-1@propietario.example",
+				Inicio:  fechaPrueba("2030-04-01"),
+				Fin:     fechaPrueba("2030-04-03"),
+				Resumen: "Pérez, Juan; llegada tarde\nPago en efectivo \\ sin factura",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventos, err := ParsearICal(leerICalPrueba(t, tt.archivo))
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(eventos, tt.eventos) {
+				t.Errorf("eventos = %+v, se esperaba %+v", eventos, tt.eventos)
+			}
+		})
+	}
+}
+
+func TestParsearICalInvalido(t *testing.T) {
+	tests := []struct {
+		name      string
+		contenido string
+		mensaje   string
+	}{
+		{"evento sin DTSTART", leerICalPrueba(t, "sin_dtstart.ics"), "sin-inicio-2@propietario.example"},
+		{"sin VCALENDAR", "BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20300101\r\nEND:VEVENT\r\n", "VCALENDAR"},
+		{"fecha ilegible", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\nDTSTART:2030\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", "DTSTART"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsearICal(tt.contenido)
+			if !errors.Is(err, ErrICalInvalido) {
+				t.Fatalf("error = %v, se esperaba ErrICalInvalido", err)
+			}
+			if !strings.Contains(err.Error(), tt.mensaje) {
+				t.Errorf("el error %q no menciona %q", err, tt.mensaje)
+			}
+		})
+	}
+}
+
+func TestGenerarICalSeLeeConParsearICal(t *testing.T) {
+	eventos := []EventoCalendario{{
+		UID:     UIDEventoCalendario(12, 3),
+		Inicio:  fechaPrueba("2030-06-01"),
+		Fin:     fechaPrueba("2030-06-04"),
+		Resumen: "Reserva RES-0012; habitación 103, con un resumen lo bastante largo para plegarse",
+	}}
+
+	leidos, err := ParsearICal(GenerarICal("Habitación 103", eventos, fechaPrueba("2030-05-01")))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if !reflect.DeepEqual(leidos, eventos) {
+		t.Errorf("eventos = %+v, se esperaba %+v", leidos, eventos)
+	}
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCanalNoEncontrado indica que el canal de venta no existe
+	ErrCanalNoEncontrado = errors.New("canal no encontrado")
+	// ErrCanalInvalido indica que el canal de venta no es coherente
+	ErrCanalInvalido = errors.New("canal inválido")
+)
+
+// AlertaReservaCanalRechazada avisa que una reserva recibida de un canal no se pudo registrar
+// (por ejemplo, el canal vendió una habitación que el hotel ya no tenía libre)
+const AlertaReservaCanalRechazada TipoAlerta = "reserva_canal_rechazada"
+
+// ChannelAdapter es el punto de integración con un canal de venta (OTA o channel manager).
+// Cada implementación traduce estos mensajes a la API del canal; los tipos de habitación se
+// identifican con el código externo de MapeoCanal
+type ChannelAdapter interface {
+	// Codigo identifica al canal y coincide con Canal.Codigo
+	Codigo() string
+	// PushInventario publica las habitaciones disponibles por tipo y noche
+	PushInventario(inventario []InventarioCanal) error
+	// PushTarifas publica el precio por noche de cada tipo
+	PushTarifas(tarifas []TarifaCanal) error
+	// PullReservas obtiene las reservas nuevas o canceladas que el canal aún no recibió confirmadas
+	PullReservas() ([]ReservaCanal, error)
+	// AckReservas confirma al canal las reservas procesadas, con el código del hotel o el error
+	AckReservas(confirmaciones []ConfirmacionCanal) error
+}
+
+// Canal es un canal de venta configurado. Su Codigo selecciona el ChannelAdapter registrado y
+// Mapeos traduce los tipos de habitación a los códigos del canal
+type Canal struct {
+	ID                   int          `json:"id"`
+	Codigo               string       `json:"codigo"`
+	Nombre               string       `json:"nombre"`
+	Activo               bool         `json:"activo"`
+	Mapeos               []MapeoCanal `json:"mapeos"`
+	UltimaSincronizacion *time.Time   `json:"ultimaSincronizacion,omitempty"`
+	// UltimoError es el error de la última sincronización; vacío si fue exitosa
+	UltimoError string `json:"ultimoError,omitempty"`
+}
+
+// MapeoCanal relaciona un tipo de habitación con su código en el canal
+type MapeoCanal struct {
+	TipoHabitacionID int    `json:"tipoHabitacionId"`
+	CodigoExterno    string `json:"codigoExterno"`
+}
+
+// Validar verifica que el canal tenga valores coherentes
+func (c Canal) Validar() error {
+	if strings.TrimSpace(c.Codigo) == "" {
+		return fmt.Errorf("%w: el código es requerido", ErrCanalInvalido)
+	}
+	if strings.TrimSpace(c.Nombre) == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrCanalInvalido)
+	}
+	return ValidarMapeosCanal(c.Mapeos)
+}
+
+// ValidarMapeosCanal verifica que cada tipo y cada código externo aparezcan una sola vez
+func ValidarMapeosCanal(mapeos []MapeoCanal) error {
+	tipos := make(map[int]bool, len(mapeos))
+	codigos := make(map[string]bool, len(mapeos))
+	for _, m := range mapeos {
+		if m.TipoHabitacionID <= 0 || strings.TrimSpace(m.CodigoExterno) == "" {
+			return fmt.Errorf("%w: cada mapeo requiere tipo de habitación y código externo", ErrCanalInvalido)
+		}
+		if tipos[m.TipoHabitacionID] || codigos[m.CodigoExterno] {
+			return fmt.Errorf("%w: el tipo %d o el código %q está mapeado más de una vez", ErrCanalInvalido, m.TipoHabitacionID, m.CodigoExterno)
+		}
+		tipos[m.TipoHabitacionID] = true
+		codigos[m.CodigoExterno] = true
+	}
+	return nil
+}
+
+// TipoHabitacion retorna el tipo de habitación mapeado al código externo
+func (c Canal) TipoHabitacion(codigoExterno string) (int, bool) {
+	for _, m := range c.Mapeos {
+		if m.CodigoExterno == codigoExterno {
+			return m.TipoHabitacionID, true
+		}
+	}
+	return 0, false
+}
+
+// InventarioCanal son las habitaciones disponibles de un tipo en una noche
+type InventarioCanal struct {
+	CodigoHabitacion string    `json:"codigoHabitacion"`
+	Fecha            time.Time `json:"fecha"`
+	Disponibles      int       `json:"disponibles"`
+}
+
+// TarifaCanal es el precio de una noche de un tipo de habitación
+type TarifaCanal struct {
+	CodigoHabitacion string    `json:"codigoHabitacion"`
+	Fecha            time.Time `json:"fecha"`
+	Precio           float64   `json:"precio"`
+}
+
+// ReservaCanal es una reserva recibida de un canal. Referencia la identifica en el canal
+type ReservaCanal struct {
+	Referencia       string    `json:"referencia"`
+	CodigoHabitacion string    `json:"codigoHabitacion"`
+	Habitaciones     int       `json:"habitaciones"` // Por defecto 1
+	FechaEntrada     time.Time `json:"fechaEntrada"`
+	FechaSalida      time.Time `json:"fechaSalida"`
+	Adultos          int       `json:"adultos"`
+	Ninhos           int       `json:"ninhos"`
+	// Total es el importe de la estadía cobrado por el canal; 0 cotiza con el calendario de tarifas
+	Total     float64      `json:"total"`
+	Cancelada bool         `json:"cancelada"`
+	Huesped   HuespedCanal `json:"huesped"`
+}
+
+// HuespedCanal son los datos del titular que envía el canal
+type HuespedCanal struct {
+	Nombre    string `json:"nombre"`
+	Apellido  string `json:"apellido"`
+	Email     string `json:"email"`
+	Telefono  string `json:"telefono"`
+	Documento string `json:"documento"`
+	Pais      string `json:"pais"`
+}
+
+// ConfirmacionCanal es la respuesta del hotel a una reserva del canal: el código de la reserva
+// creada o cancelada, o el motivo por el que no se pudo procesar
+type ConfirmacionCanal struct {
+	Referencia    string `json:"referencia"`
+	CodigoReserva string `json:"codigoReserva,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ResultadoSincronizacionCanal resume una sincronización con un canal
+type ResultadoSincronizacionCanal struct {
+	CanalID            int                 `json:"canalId"`
+	Codigo             string              `json:"codigo"`
+	Inventario         int                 `json:"inventario"`
+	Tarifas            int                 `json:"tarifas"`
+	ReservasCreadas    []string            `json:"reservasCreadas"`
+	ReservasCanceladas []string            `json:"reservasCanceladas"`
+	Rechazadas         []ConfirmacionCanal `json:"rechazadas"`
+	// Error es el motivo por el que la sincronización no terminó
+	Error string `json:"error,omitempty"`
+}
+
+// CanalRepository define las operaciones con los canales de venta y sus mapeos
+type CanalRepository interface {
+	// GetAll obtiene todos los canales con sus mapeos
+	GetAll() ([]Canal, error)
+	// GetByID obtiene un canal con sus mapeos. Retorna ErrCanalNoEncontrado si no existe
+	GetByID(id int) (*Canal, error)
+	// Create crea un canal con sus mapeos
+	Create(canal *Canal) error
+	// Update actualiza un canal y reemplaza sus mapeos
+	Update(canal *Canal) error
+	// RegistrarSincronizacion guarda la fecha y el error (vacío si fue exitosa) de la última sincronización
+	RegistrarSincronizacion(id int, fecha time.Time, mensaje string) error
+}
@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrCodigoPromocionalNoEncontrado indica que el código promocional no existe
+	ErrCodigoPromocionalNoEncontrado = errors.New("código promocional no encontrado")
+	// ErrCodigoPromocionalInvalido indica que los datos del código promocional no son coherentes
+	ErrCodigoPromocionalInvalido = errors.New("código promocional inválido")
+	// ErrCodigoPromocionalNoAplicable indica que el código existe pero no aplica a la reserva
+	// (inactivo, fuera de vigencia, pocas noches o tipo de habitación no incluido)
+	ErrCodigoPromocionalNoAplicable = errors.New("el código promocional no aplica a esta reserva")
+	// ErrCodigoPromocionalAgotado indica que el código alcanzó su límite de usos
+	ErrCodigoPromocionalAgotado = errors.New("el código promocional alcanzó su límite de usos")
+	// ErrCampanaNoEncontrada indica que la campaña de marketing no existe
+	ErrCampanaNoEncontrada = errors.New("campaña no encontrada")
+)
+
+// CodigoPromocional es un código de descuento ligado a una campaña de marketing
+type CodigoPromocional struct {
+	ID          int    `json:"id"`
+	CampanaID   int    `json:"campanaId"`
+	Codigo      string `json:"codigo"`
+	Descripcion string `json:"descripcion"`
+	// TipoDescuento indica si Valor es un porcentaje o un monto fijo sobre las habitaciones
+	TipoDescuento TipoModificador `json:"tipoDescuento"`
+	Valor         float64         `json:"valor"`
+	// VigenteDesde y VigenteHasta delimitan (inclusive) los días en que se puede reservar con el código
+	VigenteDesde  time.Time `json:"vigenteDesde"`
+	VigenteHasta  time.Time `json:"vigenteHasta"`
+	NochesMinimas int       `json:"nochesMinimas"`
+	// UsosMaximos es el límite de reservas con el código; nil significa sin límite
+	UsosMaximos *int `json:"usosMaximos,omitempty"`
+	// Usos cuenta los canjes de reservas que no fueron canceladas ni quedaron como no-show
+	Usos int `json:"usos"`
+	// TiposHabitacion restringe el código a esos tipos; vacío aplica a todos
+	TiposHabitacion []int `json:"tiposHabitacion"`
+	Activo          bool  `json:"activo"`
+}
+
+// NormalizarCodigo unifica el formato de un código ingresado por el cliente
+func NormalizarCodigo(codigo string) string {
+	return strings.ToUpper(strings.TrimSpace(codigo))
+}
+
+// Validar verifica que el código promocional tenga valores coherentes
+func (c CodigoPromocional) Validar() error {
+	if c.CampanaID <= 0 {
+		return fmt.Errorf("%w: la campaña es requerida", ErrCodigoPromocionalInvalido)
+	}
+	if NormalizarCodigo(c.Codigo) == "" {
+		return fmt.Errorf("%w: el código es requerido", ErrCodigoPromocionalInvalido)
+	}
+	switch c.TipoDescuento {
+	case ModificadorPorcentaje:
+		if c.Valor <= 0 || c.Valor > 100 {
+			return fmt.Errorf("%w: el porcentaje debe estar entre 0 y 100", ErrCodigoPromocionalInvalido)
+		}
+	case ModificadorMonto:
+		if c.Valor <= 0 {
+			return fmt.Errorf("%w: el monto debe ser mayor a 0", ErrCodigoPromocionalInvalido)
+		}
+	default:
+		return fmt.Errorf("%w: tipo de descuento desconocido", ErrCodigoPromocionalInvalido)
+	}
+	if c.VigenteHasta.Before(c.VigenteDesde) {
+		return fmt.Errorf("%w: la vigencia termina antes de empezar", ErrCodigoPromocionalInvalido)
+	}
+	if c.NochesMinimas < 0 {
+		return fmt.Errorf("%w: las noches mínimas no pueden ser negativas", ErrCodigoPromocionalInvalido)
+	}
+	if c.UsosMaximos != nil && *c.UsosMaximos <= 0 {
+		return fmt.Errorf("%w: el límite de usos debe ser mayor a 0", ErrCodigoPromocionalInvalido)
+	}
+	return nil
+}
+
+// Vigente indica si el código se puede usar en el momento dado
+func (c CodigoPromocional) Vigente(ahora time.Time) bool {
+	dia := ahora.UTC().Truncate(24 * time.Hour)
+	return c.Activo && !dia.Before(c.VigenteDesde) && !dia.After(c.VigenteHasta)
+}
+
+// Agotado indica si el código ya alcanzó su límite de usos
+func (c CodigoPromocional) Agotado() bool {
+	return c.UsosMaximos != nil && c.Usos >= *c.UsosMaximos
+}
+
+// AplicaTipo indica si el código se puede usar con el tipo de habitación
+func (c CodigoPromocional) AplicaTipo(tipoHabitacionID int) bool {
+	if len(c.TiposHabitacion) == 0 {
+		return true
+	}
+	for _, id := range c.TiposHabitacion {
+		if id == tipoHabitacionID {
+			return true
+		}
+	}
+	return false
+}
+
+// HabitacionPromocionable es una habitación cotizada con su tipo, para calcular el descuento
+type HabitacionPromocionable struct {
+	TipoHabitacionID int
+	Noches           int
+	Importe          float64
+}
+
+// CalcularDescuento retorna el descuento del código sobre las habitaciones que cumplen sus
+// condiciones. Los servicios adicionales no se descuentan y el monto fijo nunca supera el
+// importe de las habitaciones elegibles
+func (c CodigoPromocional) CalcularDescuento(habitaciones []HabitacionPromocionable, ahora time.Time) (float64, error) {
+	if !c.Vigente(ahora) {
+		return 0, fmt.Errorf("%w: el código %s no está vigente", ErrCodigoPromocionalNoAplicable, c.Codigo)
+	}
+	if c.Agotado() {
+		return 0, fmt.Errorf("%w: %s", ErrCodigoPromocionalAgotado, c.Codigo)
+	}
+
+	elegible := 0.0
+	hayElegibles := false
+	for _, hab := range habitaciones {
+		if !c.AplicaTipo(hab.TipoHabitacionID) || hab.Noches < c.NochesMinimas {
+			continue
+		}
+		hayElegibles = true
+		elegible += hab.Importe
+	}
+	if !hayElegibles {
+		return 0, fmt.Errorf("%w: se requieren al menos %d noches en un tipo de habitación incluido",
+			ErrCodigoPromocionalNoAplicable, c.NochesMinimas)
+	}
+
+	descuento := c.Valor
+	if c.TipoDescuento == ModificadorPorcentaje {
+		descuento = elegible * c.Valor / 100
+	}
+	return redondearMonto(math.Min(descuento, elegible)), nil
+}
+
+// CanjeCodigoPromocional registra el uso de un código en una reserva
+type CanjeCodigoPromocional struct {
+	ID                  int       `json:"id"`
+	CodigoPromocionalID int       `json:"codigoPromocionalId"`
+	ReservaID           int       `json:"reservaId"`
+	Monto               float64   `json:"monto"`
+	Fecha               time.Time `json:"fecha"`
+}
+
+// ResumenCodigoPromocional resume los canjes de un código de la campaña
+type ResumenCodigoPromocional struct {
+	CodigoPromocionalID int    `json:"codigoPromocionalId"`
+	Codigo              string `json:"codigo"`
+	// Canjes cuenta las reservas no canceladas que usaron el código
+	Canjes int `json:"canjes"`
+	// Cancelados cuenta las reservas con el código que luego se cancelaron
+	Cancelados      int     `json:"cancelados"`
+	MontoDescontado float64 `json:"montoDescontado"`
+	// Ingresos es el total neto (subtotal - descuento) de las reservas no canceladas
+	Ingresos float64 `json:"ingresos"`
+}
+
+// ReporteCampana resume el rendimiento de los códigos promocionales de una campaña
+type ReporteCampana struct {
+	CampanaID       int                        `json:"campanaId"`
+	Nombre          string                     `json:"nombre"`
+	FechaInicio     time.Time                  `json:"fechaInicio"`
+	FechaFin        time.Time                  `json:"fechaFin"`
+	Codigos         []ResumenCodigoPromocional `json:"codigos"`
+	Canjes          int                        `json:"canjes"`
+	Cancelados      int                        `json:"cancelados"`
+	MontoDescontado float64                    `json:"montoDescontado"`
+	Ingresos        float64                    `json:"ingresos"`
+}
+
+// Totalizar suma los resúmenes de los códigos en los totales de la campaña
+func (r *ReporteCampana) Totalizar() {
+	r.Canjes, r.Cancelados, r.MontoDescontado, r.Ingresos = 0, 0, 0, 0
+	for _, codigo := range r.Codigos {
+		r.Canjes += codigo.Canjes
+		r.Cancelados += codigo.Cancelados
+		r.MontoDescontado += codigo.MontoDescontado
+		r.Ingresos += codigo.Ingresos
+	}
+	r.MontoDescontado = redondearMonto(r.MontoDescontado)
+	r.Ingresos = redondearMonto(r.Ingresos)
+}
+
+// CodigoPromocionalRepository define las operaciones con los códigos promocionales
+type CodigoPromocionalRepository interface {
+	// GetAll obtiene los códigos de una campaña (0 = todas las campañas)
+	GetAll(campanaID int) ([]CodigoPromocional, error)
+	// GetByID obtiene un código por su ID. Retorna ErrCodigoPromocionalNoEncontrado si no existe
+	GetByID(id int) (*CodigoPromocional, error)
+	// GetByCodigo obtiene un código por su texto normalizado. Retorna ErrCodigoPromocionalNoEncontrado si no existe
+	GetByCodigo(codigo string) (*CodigoPromocional, error)
+	// Create crea un código con sus tipos de habitación. Retorna ErrCodigoPromocionalInvalido
+	// si la campaña no existe o el código ya está en uso
+	Create(codigo *CodigoPromocional) error
+	// Update actualiza un código y reemplaza sus tipos de habitación
+	Update(codigo *CodigoPromocional) error
+	// RegistrarCanje registra el uso del código en una reserva. Bloquea el código y vuelve a
+	// verificar el límite de usos para que dos reservas concurrentes no lo excedan
+	RegistrarCanje(canje *CanjeCodigoPromocional) error
+	// GetReporteCampana resume los canjes de los códigos de una campaña. Retorna
+	// ErrCampanaNoEncontrada si la campaña no existe
+	GetReporteCampana(campanaID int) (*ReporteCampana, error)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrReservaNoEncontrada indica que no existe una reserva con los datos de búsqueda
+var ErrReservaNoEncontrada = errors.New("reserva no encontrada")
+
+const (
+	// PrefijoCodigoReserva identifica al hotel en los códigos de reserva
+	PrefijoCodigoReserva = "INCA-"
+	// longitudCodigoReserva es la cantidad de caracteres aleatorios del código
+	longitudCodigoReserva = 6
+	// alfabetoCodigoReserva omite caracteres fáciles de confundir (0/O, 1/I/L)
+	alfabetoCodigoReserva = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+)
+
+// GenerarCodigoReserva genera un código de confirmación corto e impredecible (p. ej. INCA-7KQ2X9)
+func GenerarCodigoReserva() (string, error) {
+	var sb strings.Builder
+	sb.WriteString(PrefijoCodigoReserva)
+
+	limite := big.NewInt(int64(len(alfabetoCodigoReserva)))
+	for i := 0; i < longitudCodigoReserva; i++ {
+		n, err := rand.Int(rand.Reader, limite)
+		if err != nil {
+			return "", fmt.Errorf("error al generar código de reserva: %w", err)
+		}
+		sb.WriteByte(alfabetoCodigoReserva[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// NormalizarCodigoReserva limpia el código ingresado por el huésped para compararlo
+func NormalizarCodigoReserva(codigo string) string {
+	return strings.ToUpper(strings.TrimSpace(codigo))
+}
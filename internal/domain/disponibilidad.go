@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// EstadoHabitacionDisponible es el valor de room.status para habitaciones que se pueden vender
+const EstadoHabitacionDisponible = "Disponible"
+
+// EstadosQueRetienenInventario lista los estados de reserva que ocupan habitaciones.
+// Las reservas canceladas, completadas o no-show liberan el inventario
+var EstadosQueRetienenInventario = []EstadoReserva{
+	ReservaPendiente,
+	ReservaConfirmada,
+	ReservaEnCurso,
+}
+
+// RetieneInventario indica si una reserva en este estado ocupa sus habitaciones
+func (e EstadoReserva) RetieneInventario() bool {
+	for _, estado := range EstadosQueRetienenInventario {
+		if e == estado {
+			return true
+		}
+	}
+	return false
+}
+
+// Ocupacion representa el rango [FechaEntrada, FechaSalida) en que una habitación está tomada
+type Ocupacion struct {
+	HabitacionID  int           `json:"habitacionId"`
+	ReservaID     int           `json:"reservaId"`
+	EstadoReserva EstadoReserva `json:"estadoReserva"`
+	FechaEntrada  time.Time     `json:"fechaEntrada"`
+	FechaSalida   time.Time     `json:"fechaSalida"`
+	// VenceRetencion solo aplica a reservas pendientes; nil significa sin plazo
+	VenceRetencion *time.Time `json:"venceRetencion,omitempty"`
+	// BloqueoExternoID identifica la ocupación importada de un calendario externo (ReservaID = 0)
+	BloqueoExternoID int `json:"bloqueoExternoId,omitempty"`
+}
+
+// RetieneInventario indica si la ocupación sigue bloqueando la habitación en el momento dado.
+// Una reserva pendiente con la retención vencida ya no bloquea aunque el scheduler
+// todavía no la haya cancelado
+func (o Ocupacion) RetieneInventario(ahora time.Time) bool {
+	if !o.EstadoReserva.RetieneInventario() {
+		return false
+	}
+	if o.EstadoReserva == ReservaPendiente && o.VenceRetencion != nil && !ahora.Before(*o.VenceRetencion) {
+		return false
+	}
+	return true
+}
+
+// SolicitudHabitaciones pide Cantidad habitaciones distintas de un tipo para el mismo rango
+type SolicitudHabitaciones struct {
+	TipoHabitacionID int       `json:"tipoHabitacionId"`
+	Cantidad         int       `json:"cantidad"`
+	FechaEntrada     time.Time `json:"fechaEntrada"`
+	FechaSalida      time.Time `json:"fechaSalida"`
+	// Precio por noche acordado para toda la estadía; 0 cotiza con el calendario de tarifas
+	Precio float64 `json:"precio"`
+	// BloqueGrupoID recoge las habitaciones contra un bloque de grupo (0 = venta general)
+	BloqueGrupoID int `json:"bloqueGrupoId,omitempty"`
+	// ListaEsperaID usa la habitación apartada por el enlace de esa inscripción de la lista de espera
+	ListaEsperaID int `json:"listaEsperaId,omitempty"`
+	// PlanTarifaID es el plan tarifario elegido (0 = tarifa del calendario sin plan)
+	PlanTarifaID int `json:"planTarifaId,omitempty"`
+}
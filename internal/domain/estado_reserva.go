@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTransicionInvalida indica que la reserva no puede pasar del estado actual al solicitado
+var ErrTransicionInvalida = errors.New("transición de estado no permitida")
+
+// Actores predefinidos para los cambios de estado que no inicia una persona
+const (
+	ActorSistema = "sistema"
+	ActorChatbot = "chatbot"
+	ActorAPI     = "api"
+	ActorCanal   = "canal"
+)
+
+// transicionesReserva define la máquina de estados de una reserva.
+// Cancelada, Completada y NoShow son estados finales; Completada solo se alcanza con el
+// check-out de una estadía en curso
+var transicionesReserva = map[EstadoReserva][]EstadoReserva{
+	ReservaPendiente:  {ReservaConfirmada, ReservaCancelada},
+	ReservaConfirmada: {ReservaEnCurso, ReservaCancelada, ReservaNoShow},
+	ReservaEnCurso:    {ReservaCompletada},
+	ReservaCancelada:  {},
+	ReservaCompletada: {},
+	ReservaNoShow:     {},
+}
+
+// EsValido indica si el estado pertenece a la máquina de estados
+func (e EstadoReserva) EsValido() bool {
+	_, ok := transicionesReserva[e]
+	return ok
+}
+
+// PuedeTransicionarA indica si una reserva en este estado puede pasar al destino
+func (e EstadoReserva) PuedeTransicionarA(destino EstadoReserva) bool {
+	for _, permitido := range transicionesReserva[e] {
+		if permitido == destino {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidarTransicion retorna ErrTransicionInvalida si el cambio no está permitido
+func ValidarTransicion(origen, destino EstadoReserva) error {
+	if !destino.EsValido() {
+		return fmt.Errorf("estado de reserva inválido: %s", destino)
+	}
+	if !origen.PuedeTransicionarA(destino) {
+		return fmt.Errorf("%w: de %s a %s", ErrTransicionInvalida, origen, destino)
+	}
+	return nil
+}
+
+// CambioEstado identifica quién solicita un cambio de estado y por qué
+type CambioEstado struct {
+	Actor  string `json:"actor"`
+	Motivo string `json:"motivo,omitempty"`
+}
+
+// HistorialEstadoReserva es un registro de la tabla reservation_status_history
+type HistorialEstadoReserva struct {
+	ID        int `json:"id"`
+	ReservaID int `json:"reservaId"`
+	// EstadoAnterior es nil para el registro de creación de la reserva
+	EstadoAnterior *EstadoReserva `json:"estadoAnterior,omitempty"`
+	EstadoNuevo    EstadoReserva  `json:"estadoNuevo"`
+	Fecha          time.Time      `json:"fecha"`
+	Actor          string         `json:"actor"`
+	Motivo         string         `json:"motivo,omitempty"`
+}
+
+// HistorialEstadoRepository define las operaciones con el historial de estados
+type HistorialEstadoRepository interface {
+	// Create registra un cambio de estado
+	Create(historial *HistorialEstadoReserva) error
+	// GetByReservaID obtiene el historial de una reserva en orden cronológico
+	GetByReservaID(reservaID int) ([]HistorialEstadoReserva, error)
+}
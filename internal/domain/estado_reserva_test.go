@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidarTransicion(t *testing.T) {
+	tests := []struct {
+		origen, destino EstadoReserva
+		permitida       bool
+	}{
+		{ReservaPendiente, ReservaConfirmada, true},
+		{ReservaPendiente, ReservaEnCurso, false},
+		{ReservaConfirmada, ReservaEnCurso, true},
+		{ReservaConfirmada, ReservaNoShow, true},
+		// Completada solo se alcanza con el check-out de una estadía en curso
+		{ReservaConfirmada, ReservaCompletada, false},
+		{ReservaEnCurso, ReservaCompletada, true},
+		{ReservaCompletada, ReservaCancelada, false},
+		{ReservaNoShow, ReservaConfirmada, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.origen)+"->"+string(tt.destino), func(t *testing.T) {
+			err := ValidarTransicion(tt.origen, tt.destino)
+			if tt.permitida && err != nil {
+				t.Errorf("error inesperado: %v", err)
+			}
+			if !tt.permitida && !errors.Is(err, ErrTransicionInvalida) {
+				t.Errorf("error = %v, se esperaba ErrTransicionInvalida", err)
+			}
+		})
+	}
+}
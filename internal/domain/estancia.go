@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCheckInFueraDeFecha indica que la fecha actual no está dentro de la estadía reservada
+	ErrCheckInFueraDeFecha = errors.New("el check-in solo puede hacerse entre la fecha de entrada y la de salida")
+	// ErrSaldoPendiente indica que el folio no se puede cerrar porque quedan cargos sin pagar
+	ErrSaldoPendiente = errors.New("la reserva tiene saldo pendiente de pago")
+)
+
+// AsignacionHabitacion cambia, al hacer check-in, la habitación reservada por otra habitación física
+type AsignacionHabitacion struct {
+	HabitacionID      int `json:"habitacionId"`
+	NuevaHabitacionID int `json:"nuevaHabitacionId"`
+}
+
+// RegistroCheckIn es el resultado de registrar la llegada de una reserva
+type RegistroCheckIn struct {
+	Reserva      *Reserva  `json:"reserva"`
+	FechaLlegada time.Time `json:"fechaLlegada"`
+	// Huespedes son los person_id registrados desde reservation_guest
+	Huespedes []int `json:"huespedes"`
+}
+
+// CierreFolio es el resumen económico con que se cierra la cuenta de la reserva al check-out
+type CierreFolio struct {
+	ReservaID   int       `json:"reservaId"`
+	FechaSalida time.Time `json:"fechaSalida"`
+	Total       float64   `json:"total"`
+	Pagado      float64   `json:"pagado"`
+	Saldo       float64   `json:"saldo"`
+	// Pago es el pago registrado al momento del check-out (nil si no hubo)
+	Pago *Payment `json:"pago,omitempty"`
+	// HabitacionesSucias son las habitaciones que quedan pendientes de limpieza
+	HabitacionesSucias []int `json:"habitacionesSucias"`
+}
+
+// EstanciaRepository define las operaciones de recepción sobre una reserva en curso
+type EstanciaRepository interface {
+	// RegistrarCheckIn guarda la hora real de llegada de la reserva y de todos sus huéspedes.
+	// Retorna los person_id registrados
+	RegistrarCheckIn(reservaID int, llegada time.Time) ([]int, error)
+	// RegistrarCheckOut guarda la hora real de salida, cierra el folio de la reserva y, si sale
+	// antes de lo previsto, libera las noches restantes de sus habitaciones
+	RegistrarCheckOut(reservaID int, salida time.Time) error
+}
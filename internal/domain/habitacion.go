@@ -16,6 +16,8 @@ type TipoHabitacion struct {
 	Amenities []Amenity `json:"amenities,omitempty"`
 	// Images related to this room type
 	Images []RoomImage `json:"images,omitempty"`
+	// Planes son los planes tarifarios cotizados para las fechas consultadas (solo en disponibilidad)
+	Planes []PlanCotizado `json:"planes,omitempty"`
 }
 
 // Amenity represents an amenity that can be assigned to a room type
@@ -45,11 +47,23 @@ type Habitacion struct {
 	DescripcionGeneral string         `json:"descripcionGeneral"`
 	TipoHabitacion     TipoHabitacion `json:"tipoHabitacion"`
 	MediaID            int            `json:"-"` // El tag "-" hace que este campo se omita en la serialización JSON
+	// EstadoLimpieza es el estado de limpieza; una habitación fuera de servicio no se vende
+	EstadoLimpieza EstadoLimpieza `json:"estadoLimpieza"`
+}
+
+// Vendible indica si la habitación se puede vender: su estado la habilita y no está fuera de servicio
+func (h Habitacion) Vendible() bool {
+	return h.Estado == EstadoHabitacionDisponible && h.EstadoLimpieza != HabitacionFueraDeServicio
 }
 
 // FechasBloqueadas representa las fechas donde no hay disponibilidad
 type FechasBloqueadas struct {
 	FechasNoDisponibles []time.Time `json:"fechasNoDisponibles"`
+	// FechasSinLlegada y FechasSinSalida son las fechas cerradas a llegadas o salidas en todo el hotel
+	FechasSinLlegada []time.Time `json:"fechasSinLlegada"`
+	FechasSinSalida  []time.Time `json:"fechasSinSalida"`
+	// Restricciones son las restricciones de estadía vigentes en el rango, de todo el hotel o por tipo
+	Restricciones []RestriccionEstadia `json:"restricciones"`
 }
 
 // DisponibilidadFecha representa la disponibilidad de habitaciones para una fecha específica
@@ -63,14 +77,6 @@ type DisponibilidadFecha struct {
 type HabitacionRepository interface {
 	// GetAllRooms returns all rooms in the system
 	GetAllRooms() ([]Habitacion, error)
-	// GetAvailableRooms returns room types that are available for the given date range
-	GetAvailableRooms(fechaEntrada, fechaSalida time.Time) ([]TipoHabitacion, error)
-	// FindAvailableRoomByType finds an available room of a specific type for the given date range
-	FindAvailableRoomByType(roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error)
-	// GetFechasBloqueadas returns dates where there are no rooms available
-	GetFechasBloqueadas(desde time.Time, hasta time.Time) (*FechasBloqueadas, error)
-	// GetDisponibilidadFechas returns the availability status for each date in the given range
-	GetDisponibilidadFechas(desde time.Time, hasta time.Time) ([]DisponibilidadFecha, error)
 	// GetRoomTypes returns all room types in the system
 	GetRoomTypes() ([]TipoHabitacion, error)
 	// CRUD for room types
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRangoInventarioInvalido indica que el rango de fechas pedido para la matriz no es válido
+var ErrRangoInventarioInvalido = errors.New("rango de inventario inválido")
+
+// DiasMaximosInventario es la cantidad máxima de noches de una consulta de la matriz de inventario
+const DiasMaximosInventario = 366
+
+// InventarioTipoFecha es una celda de la matriz de inventario: el estado de las habitaciones de
+// un tipo en una noche. Total = FueraDeServicio + Vendidas + Retenidas + Disponibles, salvo que
+// los bloques de grupo aparten más habitaciones de las que quedan libres
+type InventarioTipoFecha struct {
+	TipoHabitacionID int       `json:"tipoHabitacionId"`
+	TipoHabitacion   string    `json:"tipoHabitacion"`
+	Fecha            time.Time `json:"fecha"`
+	Total            int       `json:"total"`
+	// Vendidas son las habitaciones vendibles ocupadas por reservas confirmadas o en curso
+	Vendidas int `json:"vendidas"`
+	// Retenidas son las reservas pendientes con retención vigente, los bloqueos de calendarios
+	// externos y las habitaciones de bloques de grupo aún sin recoger
+	Retenidas int `json:"retenidas"`
+	// FueraDeServicio son las habitaciones cuyo estado no permite venderlas o que limpieza marcó
+	// fuera de servicio
+	FueraDeServicio int `json:"fueraDeServicio"`
+	Disponibles     int `json:"disponibles"`
+	// Precio es la tarifa de la noche para una llegada de una noche, con las reglas de precio dinámico
+	Precio float64 `json:"precio"`
+}
+
+// CalcularDisponibles completa Disponibles a partir del resto de los contadores
+func (i *InventarioTipoFecha) CalcularDisponibles() {
+	i.Disponibles = i.Total - i.FueraDeServicio - i.Vendidas - i.Retenidas
+	if i.Disponibles < 0 {
+		i.Disponibles = 0
+	}
+}
+
+// InventarioRepository define la consulta de la matriz de inventario
+type InventarioRepository interface {
+	// GetInventario cuenta, para cada tipo de habitación y noche entre desde y hasta (inclusive),
+	// las habitaciones totales, vendidas, retenidas y fuera de servicio. ahora define qué
+	// retenciones de reservas pendientes siguen vigentes
+	GetInventario(desde, hasta, ahora time.Time) ([]InventarioTipoFecha, error)
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTareaLimpiezaNoEncontrada indica que la tarea de limpieza no existe
+	ErrTareaLimpiezaNoEncontrada = errors.New("tarea de limpieza no encontrada")
+	// ErrPersonalLimpiezaNoEncontrado indica que el miembro del personal de limpieza no existe
+	ErrPersonalLimpiezaNoEncontrado = errors.New("personal de limpieza no encontrado")
+	// ErrHabitacionNoEncontrada indica que la habitación no existe
+	ErrHabitacionNoEncontrada = errors.New("habitación no encontrada")
+	// ErrLimpiezaInvalida indica un cambio de estado de limpieza o una tarea no permitidos
+	ErrLimpiezaInvalida = errors.New("operación de limpieza inválida")
+)
+
+// EstadoLimpieza es el estado de limpieza de una habitación física, independiente de room.status.
+// Una habitación sucia se puede seguir vendiendo; una fuera de servicio no
+type EstadoLimpieza string
+
+const (
+	HabitacionLimpia          EstadoLimpieza = "Limpia"
+	HabitacionSucia           EstadoLimpieza = "Sucia"
+	HabitacionInspeccionada   EstadoLimpieza = "Inspeccionada"
+	HabitacionFueraDeServicio EstadoLimpieza = "FueraDeServicio"
+)
+
+// transicionesLimpieza define los cambios de estado de limpieza permitidos. Una habitación que
+// vuelve al servicio queda sucia hasta que se limpie
+var transicionesLimpieza = map[EstadoLimpieza][]EstadoLimpieza{
+	HabitacionSucia:           {HabitacionLimpia, HabitacionFueraDeServicio},
+	HabitacionLimpia:          {HabitacionInspeccionada, HabitacionSucia, HabitacionFueraDeServicio},
+	HabitacionInspeccionada:   {HabitacionSucia, HabitacionFueraDeServicio},
+	HabitacionFueraDeServicio: {HabitacionSucia},
+}
+
+// ValidarCambioLimpieza verifica que la habitación pueda pasar del estado actual al nuevo
+func ValidarCambioLimpieza(actual, nuevo EstadoLimpieza) error {
+	for _, permitido := range transicionesLimpieza[actual] {
+		if permitido == nuevo {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: la habitación no puede pasar de %s a %s", ErrLimpiezaInvalida, actual, nuevo)
+}
+
+// EstadoTareaLimpieza es el avance de una tarea de limpieza
+type EstadoTareaLimpieza string
+
+const (
+	TareaPendiente EstadoTareaLimpieza = "Pendiente"
+	TareaEnCurso   EstadoTareaLimpieza = "EnCurso"
+	TareaTerminada EstadoTareaLimpieza = "Terminada"
+	TareaCancelada EstadoTareaLimpieza = "Cancelada"
+)
+
+// Abierta indica si la tarea aún no terminó ni se canceló
+func (e EstadoTareaLimpieza) Abierta() bool {
+	return e == TareaPendiente || e == TareaEnCurso
+}
+
+// OrigenTareaLimpieza indica por qué se creó una tarea de limpieza
+type OrigenTareaLimpieza string
+
+const (
+	// TareaPorSalida se crea automáticamente al hacer check-out
+	TareaPorSalida OrigenTareaLimpieza = "Salida"
+	// TareaManual la crea el personal (repaso, limpieza profunda, etc.)
+	TareaManual OrigenTareaLimpieza = "Manual"
+)
+
+// TareaLimpieza es una limpieza pendiente o realizada de una habitación. Al terminarla la
+// habitación queda limpia, lista para inspeccionarse
+type TareaLimpieza struct {
+	ID           int                 `json:"id"`
+	HabitacionID int                 `json:"habitacionId"`
+	ReservaID    *int                `json:"reservaId,omitempty"`
+	Origen       OrigenTareaLimpieza `json:"origen"`
+	Estado       EstadoTareaLimpieza `json:"estado"`
+	// PersonalID es el miembro del personal asignado; nil si la tarea no está asignada
+	PersonalID  *int       `json:"personalId,omitempty"`
+	Notas       string     `json:"notas,omitempty"`
+	CreadaEn    time.Time  `json:"creadaEn"`
+	IniciadaEn  *time.Time `json:"iniciadaEn,omitempty"`
+	TerminadaEn *time.Time `json:"terminadaEn,omitempty"`
+}
+
+// PersonalLimpieza es un miembro del personal al que se asignan tareas de limpieza
+type PersonalLimpieza struct {
+	ID     int    `json:"id"`
+	Nombre string `json:"nombre"`
+	Activo bool   `json:"activo"`
+}
+
+// Validar verifica que el miembro del personal tenga valores coherentes
+func (p PersonalLimpieza) Validar() error {
+	if strings.TrimSpace(p.Nombre) == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrLimpiezaInvalida)
+	}
+	return nil
+}
+
+// FiltroTareasLimpieza filtra el listado de tareas; los valores cero no filtran
+type FiltroTareasLimpieza struct {
+	Estado       EstadoTareaLimpieza
+	HabitacionID int
+	PersonalID   int
+}
+
+// EstadoHabitacionTablero es una fila del tablero de limpieza: el estado de la habitación, su
+// ocupación del día y la tarea abierta, si la hay
+type EstadoHabitacionTablero struct {
+	HabitacionID   int            `json:"habitacionId"`
+	Numero         string         `json:"numero"`
+	TipoHabitacion string         `json:"tipoHabitacion"`
+	Estado         string         `json:"estado"`
+	EstadoLimpieza EstadoLimpieza `json:"estadoLimpieza"`
+	// MotivoFueraDeServicio explica por qué la habitación no se vende
+	MotivoFueraDeServicio string `json:"motivoFueraDeServicio,omitempty"`
+	// Ocupada indica que hay huéspedes alojados (reserva en curso)
+	Ocupada bool `json:"ocupada"`
+	// LlegadaHoy y SalidaHoy marcan las habitaciones con entrada o salida prevista en el día
+	LlegadaHoy bool           `json:"llegadaHoy"`
+	SalidaHoy  bool           `json:"salidaHoy"`
+	Tarea      *TareaLimpieza `json:"tarea,omitempty"`
+	// Personal es el nombre de quien tiene asignada la tarea abierta
+	Personal string `json:"personal,omitempty"`
+}
+
+// TableroLimpieza es el estado de limpieza de todas las habitaciones en una fecha
+type TableroLimpieza struct {
+	Fecha        time.Time                 `json:"fecha"`
+	Resumen      map[EstadoLimpieza]int    `json:"resumen"`
+	Habitaciones []EstadoHabitacionTablero `json:"habitaciones"`
+}
+
+// NuevoTableroLimpieza arma el tablero y cuenta las habitaciones por estado de limpieza
+func NuevoTableroLimpieza(fecha time.Time, habitaciones []EstadoHabitacionTablero) *TableroLimpieza {
+	resumen := map[EstadoLimpieza]int{
+		HabitacionSucia:           0,
+		HabitacionLimpia:          0,
+		HabitacionInspeccionada:   0,
+		HabitacionFueraDeServicio: 0,
+	}
+	for _, h := range habitaciones {
+		resumen[h.EstadoLimpieza]++
+	}
+	return &TableroLimpieza{Fecha: fecha, Resumen: resumen, Habitaciones: habitaciones}
+}
+
+// LimpiezaRepository define las operaciones de limpieza: estado de las habitaciones, tareas y personal
+type LimpiezaRepository interface {
+	// GetTablero obtiene el estado de limpieza de todas las habitaciones con su ocupación en la fecha
+	GetTablero(fecha time.Time) ([]EstadoHabitacionTablero, error)
+	// GetEstadoHabitacion obtiene el estado de limpieza de una habitación
+	GetEstadoHabitacion(habitacionID int) (EstadoLimpieza, error)
+	// CambiarEstadoHabitacion cambia el estado de limpieza de una habitación; motivo se guarda
+	// solo para FueraDeServicio
+	CambiarEstadoHabitacion(habitacionID int, estado EstadoLimpieza, motivo string) error
+	// RegistrarSalidas marca sucias las habitaciones que dejó una reserva (salvo las que están
+	// fuera de servicio) y les crea una tarea de limpieza si no tienen una abierta
+	RegistrarSalidas(reservaID int, habitacionIDs []int, fecha time.Time) error
+	// GetTareas obtiene las tareas que cumplen el filtro, las abiertas primero
+	GetTareas(filtro FiltroTareasLimpieza) ([]TareaLimpieza, error)
+	// GetTareaByID obtiene una tarea. Retorna ErrTareaLimpiezaNoEncontrada si no existe
+	GetTareaByID(id int) (*TareaLimpieza, error)
+	// CreateTarea crea una tarea manual
+	CreateTarea(tarea *TareaLimpieza) error
+	// UpdateTarea guarda el estado, la asignación y las fechas de una tarea
+	UpdateTarea(tarea *TareaLimpieza) error
+	// TerminarTarea marca la tarea como terminada y deja la habitación limpia en una sola transacción
+	TerminarTarea(tarea *TareaLimpieza) error
+	// GetPersonal obtiene el personal de limpieza
+	GetPersonal() ([]PersonalLimpieza, error)
+	// GetPersonalByID obtiene un miembro del personal. Retorna ErrPersonalLimpiezaNoEncontrado si no existe
+	GetPersonalByID(id int) (*PersonalLimpieza, error)
+	// CreatePersonal registra un miembro del personal
+	CreatePersonal(personal *PersonalLimpieza) error
+	// UpdatePersonal actualiza un miembro del personal
+	UpdatePersonal(personal *PersonalLimpieza) error
+}
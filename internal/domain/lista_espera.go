@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrListaEsperaNoEncontrada indica que la inscripción o el enlace no existen
+	ErrListaEsperaNoEncontrada = errors.New("inscripción en lista de espera no encontrada")
+	// ErrEnlaceListaEsperaVencido indica que el enlace de reserva enviado ya no es válido
+	ErrEnlaceListaEsperaVencido = errors.New("el enlace de reserva de la lista de espera venció o ya fue usado")
+	// ErrEnlaceListaEsperaNoCorresponde indica que la reserva no es del tipo de habitación y las
+	// fechas para las que se envió el enlace
+	ErrEnlaceListaEsperaNoCorresponde = errors.New("la reserva no corresponde al tipo de habitación y las fechas del enlace de la lista de espera")
+)
+
+// EstadoListaEspera representa la situación de una inscripción en la lista de espera
+type EstadoListaEspera string
+
+const (
+	// ListaEsperaEsperando indica que el huésped aún espera que se libere inventario
+	ListaEsperaEsperando EstadoListaEspera = "Esperando"
+	// ListaEsperaNotificada indica que se envió un enlace de reserva que todavía no vence
+	ListaEsperaNotificada EstadoListaEspera = "Notificada"
+	// ListaEsperaReservada indica que el huésped reservó con el enlace
+	ListaEsperaReservada EstadoListaEspera = "Reservada"
+	// ListaEsperaVencida indica que el enlace venció sin usarse
+	ListaEsperaVencida EstadoListaEspera = "Vencida"
+	// ListaEsperaCancelada indica que el huésped dejó la lista de espera
+	ListaEsperaCancelada EstadoListaEspera = "Cancelada"
+)
+
+// InscripcionListaEspera es la solicitud de un huésped para ser avisado cuando se libere
+// una habitación del tipo y las fechas indicadas
+type InscripcionListaEspera struct {
+	ID               int               `json:"id"`
+	TipoHabitacionID int               `json:"tipoHabitacionId"`
+	FechaEntrada     time.Time         `json:"fechaEntrada"`
+	FechaSalida      time.Time         `json:"fechaSalida"`
+	CantidadAdultos  int               `json:"cantidadAdultos"`
+	CantidadNinhos   int               `json:"cantidadNinhos"`
+	Nombre           string            `json:"nombre"`
+	Email            string            `json:"email"`
+	Telefono         *string           `json:"telefono,omitempty"`
+	Estado           EstadoListaEspera `json:"estado"`
+	// Token identifica el enlace de reserva enviado al notificar; no se expone en la API
+	Token             *string    `json:"-"`
+	FechaNotificacion *time.Time `json:"fechaNotificacion,omitempty"`
+	VenceEnlace       *time.Time `json:"venceEnlace,omitempty"`
+	ReservaID         *int       `json:"reservaId,omitempty"`
+	FechaCreacion     time.Time  `json:"fechaCreacion"`
+}
+
+// ApartaNoche indica si la inscripción aparta una habitación la noche que empieza en la fecha
+// dada: solo mientras su enlace de reserva está vigente
+func (i InscripcionListaEspera) ApartaNoche(noche, ahora time.Time) bool {
+	return i.Estado == ListaEsperaNotificada && i.VenceEnlace != nil && ahora.Before(*i.VenceEnlace) &&
+		!noche.Before(i.FechaEntrada) && noche.Before(i.FechaSalida)
+}
+
+// Corresponde indica si la solicitud es del tipo de habitación y las fechas de la inscripción
+func (i InscripcionListaEspera) Corresponde(solicitud SolicitudHabitaciones) bool {
+	return solicitud.TipoHabitacionID == i.TipoHabitacionID &&
+		solicitud.FechaEntrada.Format("2006-01-02") == i.FechaEntrada.Format("2006-01-02") &&
+		solicitud.FechaSalida.Format("2006-01-02") == i.FechaSalida.Format("2006-01-02")
+}
+
+// ListaEsperaRepository define las operaciones con la lista de espera
+type ListaEsperaRepository interface {
+	// Create registra una nueva inscripción
+	Create(inscripcion *InscripcionListaEspera) error
+	// GetByID obtiene una inscripción por su ID
+	GetByID(id int) (*InscripcionListaEspera, error)
+	// GetByToken obtiene la inscripción a la que se envió el enlace con el token dado
+	GetByToken(token string) (*InscripcionListaEspera, error)
+	// GetActivas obtiene las inscripciones esperando o notificadas cuya fecha de entrada es
+	// igual o posterior a desde, en orden de llegada (FIFO)
+	GetActivas(desde time.Time) ([]InscripcionListaEspera, error)
+	// GetEnlacesVigentes obtiene las inscripciones notificadas cuyo enlace sigue vigente en ahora
+	// y cuyas fechas se solapan con [desde, hasta)
+	GetEnlacesVigentes(desde, hasta, ahora time.Time) ([]InscripcionListaEspera, error)
+	// MarcarNotificada registra el envío del enlace de reserva y su vencimiento
+	MarcarNotificada(id int, token string, notificada, vence time.Time) error
+	// UpdateEstado cambia el estado de una inscripción
+	UpdateEstado(id int, estado EstadoListaEspera) error
+	// MarcarReservada registra la reserva creada con el enlace
+	MarcarReservada(id, reservaID int) error
+	// VencerEnlaces marca como vencidas las inscripciones notificadas cuyo enlace venció
+	// antes de ahora y retorna sus IDs
+	VencerEnlaces(ahora time.Time) ([]int, error)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrReservaNoModificable indica que la reserva está en un estado que no admite cambios
+var ErrReservaNoModificable = errors.New("la reserva no se puede modificar en su estado actual")
+
+// ModificacionReserva describe los cambios solicitados sobre una reserva existente.
+// Los campos nil o en cero se mantienen como están
+type ModificacionReserva struct {
+	CantidadAdultos *int                     `json:"cantidadAdultos,omitempty"`
+	CantidadNinhos  *int                     `json:"cantidadNinhos,omitempty"`
+	Habitaciones    []ModificacionHabitacion `json:"habitaciones,omitempty"`
+	// EdadesNinhos reemplaza las edades declaradas de los niños
+	EdadesNinhos []int `json:"edadesNinhos,omitempty"`
+}
+
+// ModificacionHabitacion describe los cambios sobre una de las habitaciones de la reserva
+type ModificacionHabitacion struct {
+	// HabitacionID identifica la habitación actual de la reserva que se modifica
+	HabitacionID int `json:"habitacionId"`
+	// NuevaHabitacionID cambia a una habitación concreta
+	NuevaHabitacionID int `json:"nuevaHabitacionId,omitempty"`
+	// NuevoTipoHabitacionID cambia a cualquier habitación libre de ese tipo
+	NuevoTipoHabitacionID int        `json:"nuevoTipoHabitacionId,omitempty"`
+	FechaEntrada          *time.Time `json:"fechaEntrada,omitempty"`
+	FechaSalida           *time.Time `json:"fechaSalida,omitempty"`
+}
+
+// ResultadoModificacion resume el efecto económico de una modificación
+type ResultadoModificacion struct {
+	Reserva          *Reserva `json:"reserva"`
+	SubtotalAnterior float64  `json:"subtotalAnterior"`
+	SubtotalNuevo    float64  `json:"subtotalNuevo"`
+	// Diferencia es el cambio del total con descuento: positiva si el huésped debe pagar más y
+	// negativa si corresponde reembolso
+	Diferencia float64 `json:"diferencia"`
+	// Pago es el cobro pendiente o el reembolso registrado por la diferencia (nil si no hay)
+	Pago *Payment `json:"pago,omitempty"`
+}
@@ -34,6 +34,24 @@ type PaymentRepository interface {
 	Create(payment *Payment) error
 	// GetByReservationID obtiene el pago de una reserva
 	GetByReservationID(reservationID int) (*Payment, error)
+	// GetAllByReservationID obtiene todos los pagos y reembolsos de una reserva
+	GetAllByReservationID(reservationID int) ([]Payment, error)
 	// UpdateStatus actualiza el estado de un pago
 	UpdateStatus(paymentID int, status PaymentStatus) error
+	// UpdateAmount actualiza el monto de un pago
+	UpdateAmount(paymentID int, amount float64) error
+}
+
+// MontoPagadoNeto suma los pagos aprobados y descuenta los reembolsos ya registrados
+func MontoPagadoNeto(payments []Payment) float64 {
+	total := 0.0
+	for _, p := range payments {
+		switch p.Status {
+		case PaymentStatusAprobado:
+			total += p.Amount
+		case PaymentStatusReembolso:
+			total -= p.Amount
+		}
+	}
+	return total
 }
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrPlanTarifaNoEncontrado indica que el plan tarifario no existe
+	ErrPlanTarifaNoEncontrado = errors.New("plan tarifario no encontrado")
+	// ErrPlanTarifaInvalido indica que el plan no es coherente o no aplica a la habitación pedida
+	ErrPlanTarifaInvalido = errors.New("plan tarifario inválido")
+)
+
+// TipoModificador define cómo un plan tarifario ajusta el precio de cada noche
+type TipoModificador string
+
+const (
+	// ModificadorPorcentaje ajusta cada noche en ValorModificador % (negativo = descuento)
+	ModificadorPorcentaje TipoModificador = "porcentaje"
+	// ModificadorMonto suma ValorModificador a cada noche (negativo = descuento)
+	ModificadorMonto TipoModificador = "monto"
+)
+
+// PlanTarifa es un producto que se vende sobre un tipo de habitación (flexible, no reembolsable,
+// con desayuno...). Ajusta el precio del calendario de tarifas, puede tener sus propias
+// condiciones de cancelación e incluye servicios sin costo adicional
+type PlanTarifa struct {
+	ID               int             `json:"id"`
+	TipoHabitacionID int             `json:"tipoHabitacionId"`
+	Codigo           string          `json:"codigo"`
+	Nombre           string          `json:"nombre"`
+	Descripcion      string          `json:"descripcion"`
+	TipoModificador  TipoModificador `json:"tipoModificador"`
+	ValorModificador float64         `json:"valorModificador"`
+	// Cancelacion son las condiciones propias del plan; nil usa la política del tipo de habitación
+	Cancelacion *PoliticaCancelacion `json:"cancelacion,omitempty"`
+	// ServiciosIncluidos son los IDs de servicios que se agregan a la reserva sin costo
+	ServiciosIncluidos []int `json:"serviciosIncluidos"`
+	Activo             bool  `json:"activo"`
+}
+
+// Validar verifica que el plan tenga valores coherentes
+func (p PlanTarifa) Validar() error {
+	if strings.TrimSpace(p.Codigo) == "" || strings.TrimSpace(p.Nombre) == "" {
+		return fmt.Errorf("%w: el código y el nombre son requeridos", ErrPlanTarifaInvalido)
+	}
+	if p.TipoHabitacionID <= 0 {
+		return fmt.Errorf("%w: el tipo de habitación es requerido", ErrPlanTarifaInvalido)
+	}
+	switch p.TipoModificador {
+	case ModificadorPorcentaje:
+		if p.ValorModificador <= -100 {
+			return fmt.Errorf("%w: el descuento debe ser menor al 100%%", ErrPlanTarifaInvalido)
+		}
+	case ModificadorMonto:
+	default:
+		return fmt.Errorf("%w: tipo de modificador desconocido", ErrPlanTarifaInvalido)
+	}
+	if p.Cancelacion != nil {
+		if err := p.Cancelacion.Validar(); err != nil {
+			return fmt.Errorf("%w: %v", ErrPlanTarifaInvalido, err)
+		}
+	}
+	return nil
+}
+
+// Aplicar ajusta cada noche de la cotización con el modificador del plan. Ninguna noche
+// queda con precio negativo
+func (p PlanTarifa) Aplicar(cotizacion CotizacionEstadia) CotizacionEstadia {
+	resultado := cotizacion
+	resultado.Noches = make([]PrecioNoche, len(cotizacion.Noches))
+	resultado.Total = 0
+	planID := p.ID
+	resultado.PlanTarifaID = &planID
+
+	for i, noche := range cotizacion.Noches {
+		switch p.TipoModificador {
+		case ModificadorPorcentaje:
+			noche.Precio += noche.Precio * p.ValorModificador / 100
+		case ModificadorMonto:
+			noche.Precio += p.ValorModificador
+		}
+		noche.Precio = redondearMonto(math.Max(0, noche.Precio))
+		if noche.Detalle != "" {
+			noche.Detalle += " · "
+		}
+		noche.Detalle += p.Nombre
+
+		resultado.Noches[i] = noche
+		resultado.Total += noche.Precio
+	}
+
+	resultado.Total = redondearMonto(resultado.Total + TotalRecargos(resultado.Recargos))
+	return resultado
+}
+
+// IncluyeServicio indica si el servicio está incluido en el plan
+func (p PlanTarifa) IncluyeServicio(servicioID int) bool {
+	for _, id := range p.ServiciosIncluidos {
+		if id == servicioID {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanCotizado es un plan tarifario con el precio de la estadía consultada
+type PlanCotizado struct {
+	Plan           PlanTarifa        `json:"plan"`
+	Cotizacion     CotizacionEstadia `json:"cotizacion"`
+	PrecioPromedio float64           `json:"precioPromedio"`
+}
+
+// PlanTarifaRepository define las operaciones con los planes tarifarios
+type PlanTarifaRepository interface {
+	// GetAll obtiene los planes de un tipo de habitación (0 = todos los tipos)
+	GetAll(tipoHabitacionID int) ([]PlanTarifa, error)
+	// GetActivos obtiene los planes activos de un tipo de habitación
+	GetActivos(tipoHabitacionID int) ([]PlanTarifa, error)
+	// GetByID obtiene un plan por su ID. Retorna ErrPlanTarifaNoEncontrado si no existe
+	GetByID(id int) (*PlanTarifa, error)
+	// GetByCodigo obtiene el plan activo con el código dado para el tipo de habitación, o nil
+	GetByCodigo(tipoHabitacionID int, codigo string) (*PlanTarifa, error)
+	// Create crea un nuevo plan con sus servicios incluidos
+	Create(plan *PlanTarifa) error
+	// Update actualiza un plan y reemplaza sus servicios incluidos
+	Update(plan *PlanTarifa) error
+}
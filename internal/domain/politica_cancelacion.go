@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrPoliticaCancelacionInvalida indica que la configuración de la política no es coherente
+var ErrPoliticaCancelacionInvalida = errors.New("política de cancelación inválida")
+
+// TipoPenalidad define cómo se calcula la penalidad fuera del plazo gratuito
+type TipoPenalidad string
+
+const (
+	// PenalidadPorcentaje cobra ValorPenalidad % del importe de la habitación
+	PenalidadPorcentaje TipoPenalidad = "porcentaje"
+	// PenalidadNoches cobra ValorPenalidad noches al precio de la habitación
+	PenalidadNoches TipoPenalidad = "noches"
+)
+
+// PoliticaCancelacion define las condiciones de cancelación de un tipo de habitación.
+// Una política sin TipoHabitacionID es la política general del hotel
+type PoliticaCancelacion struct {
+	ID               int    `json:"id"`
+	Nombre           string `json:"nombre"`
+	TipoHabitacionID *int   `json:"tipoHabitacionId,omitempty"`
+	// HorasCancelacionGratuita es la anticipación mínima (respecto a la fecha de entrada)
+	// con la que se puede cancelar sin penalidad
+	HorasCancelacionGratuita int           `json:"horasCancelacionGratuita"`
+	TipoPenalidad            TipoPenalidad `json:"tipoPenalidad"`
+	ValorPenalidad           float64       `json:"valorPenalidad"`
+	// NoReembolsable cobra el importe completo sin importar la anticipación
+	NoReembolsable bool `json:"noReembolsable"`
+	Activa         bool `json:"activa"`
+}
+
+// PoliticaCancelacionPorDefecto se aplica cuando no hay ninguna política configurada:
+// cancelación gratuita hasta 48 horas antes y, después, una noche de penalidad
+var PoliticaCancelacionPorDefecto = PoliticaCancelacion{
+	Nombre:                   "Flexible 48 horas",
+	HorasCancelacionGratuita: 48,
+	TipoPenalidad:            PenalidadNoches,
+	ValorPenalidad:           1,
+	Activa:                   true,
+}
+
+// Validar verifica que la política tenga valores coherentes
+func (p PoliticaCancelacion) Validar() error {
+	if p.Nombre == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrPoliticaCancelacionInvalida)
+	}
+	if p.HorasCancelacionGratuita < 0 {
+		return fmt.Errorf("%w: las horas de cancelación gratuita no pueden ser negativas", ErrPoliticaCancelacionInvalida)
+	}
+	if p.NoReembolsable {
+		return nil
+	}
+	switch p.TipoPenalidad {
+	case PenalidadPorcentaje:
+		if p.ValorPenalidad < 0 || p.ValorPenalidad > 100 {
+			return fmt.Errorf("%w: el porcentaje de penalidad debe estar entre 0 y 100", ErrPoliticaCancelacionInvalida)
+		}
+	case PenalidadNoches:
+		if p.ValorPenalidad < 0 {
+			return fmt.Errorf("%w: las noches de penalidad no pueden ser negativas", ErrPoliticaCancelacionInvalida)
+		}
+	default:
+		return fmt.Errorf("%w: tipo de penalidad desconocido", ErrPoliticaCancelacionInvalida)
+	}
+	return nil
+}
+
+// PenalidadHabitacion calcula la penalidad por cancelar una habitación en el momento dado
+func (p PoliticaCancelacion) PenalidadHabitacion(hab ReservaHabitacion, ahora time.Time) float64 {
+	noches := math.Max(1, math.Round(hab.FechaSalida.Sub(hab.FechaEntrada).Hours()/24))
+	importe := hab.Importe()
+
+	if p.NoReembolsable {
+		return importe
+	}
+	if hab.FechaEntrada.Sub(ahora).Hours() >= float64(p.HorasCancelacionGratuita) {
+		return 0
+	}
+
+	return p.penalidad(hab, importe, noches)
+}
+
+// PenalidadNoShow calcula la penalidad de una habitación cuyo huésped no se presentó:
+// se aplica la penalidad de la política sin considerar el plazo de cancelación gratuita
+func (p PoliticaCancelacion) PenalidadNoShow(hab ReservaHabitacion) float64 {
+	noches := math.Max(1, math.Round(hab.FechaSalida.Sub(hab.FechaEntrada).Hours()/24))
+	importe := hab.Importe()
+
+	if p.NoReembolsable {
+		return importe
+	}
+
+	return p.penalidad(hab, importe, noches)
+}
+
+// penalidad aplica el tipo y valor de penalidad de la política al importe de la habitación
+func (p PoliticaCancelacion) penalidad(hab ReservaHabitacion, importe, noches float64) float64 {
+	switch p.TipoPenalidad {
+	case PenalidadPorcentaje:
+		return importe * p.ValorPenalidad / 100
+	case PenalidadNoches:
+		return hab.Precio * math.Min(p.ValorPenalidad, noches)
+	}
+	return 0
+}
+
+// PenalidadHabitacionDetalle es la penalidad aplicada a una habitación de la reserva
+type PenalidadHabitacionDetalle struct {
+	HabitacionID int     `json:"habitacionId"`
+	Politica     string  `json:"politica"`
+	Importe      float64 `json:"importe"`
+	Penalidad    float64 `json:"penalidad"`
+}
+
+// DesgloseCancelacion es el resultado económico de cancelar una reserva
+type DesgloseCancelacion struct {
+	ReservaID    int                          `json:"reservaId"`
+	Habitaciones []PenalidadHabitacionDetalle `json:"habitaciones"`
+	// Penalidad es el total retenido por el hotel (nunca mayor al total de la reserva)
+	Penalidad float64 `json:"penalidad"`
+	// MontoPagado es lo cobrado neto de reembolsos previos
+	MontoPagado float64 `json:"montoPagado"`
+	// Reembolso es lo que se devuelve al huésped: MontoPagado - Penalidad, mínimo 0
+	Reembolso float64 `json:"reembolso"`
+	// Pago es el reembolso registrado (nil si no corresponde devolver nada). En un no-show
+	// puede ser, en cambio, el cobro pendiente de la penalidad no cubierta por lo pagado
+	Pago *Payment `json:"pago,omitempty"`
+}
+
+// PoliticaCancelacionRepository define las operaciones con políticas de cancelación
+type PoliticaCancelacionRepository interface {
+	// GetAll obtiene todas las políticas configuradas
+	GetAll() ([]PoliticaCancelacion, error)
+	// GetParaTipoHabitacion obtiene la política activa del tipo de habitación o, si no
+	// tiene, la política general del hotel. Retorna nil si no hay ninguna
+	GetParaTipoHabitacion(tipoHabitacionID int) (*PoliticaCancelacion, error)
+	// Create crea una nueva política
+	Create(politica *PoliticaCancelacion) error
+	// Update actualiza una política existente
+	Update(politica *PoliticaCancelacion) error
+}
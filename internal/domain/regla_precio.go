@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrReglaPrecioNoEncontrada indica que la regla de precio dinámico no existe
+	ErrReglaPrecioNoEncontrada = errors.New("regla de precio no encontrada")
+	// ErrReglaPrecioInvalida indica que la regla de precio dinámico no es coherente
+	ErrReglaPrecioInvalida = errors.New("regla de precio inválida")
+)
+
+// ReglaPrecio ajusta el precio de las noches según la ocupación del hotel y la antelación de la
+// llegada, p. ej. "con ocupación sobre 80%, +15%" o "a 3 días o menos de la llegada y ocupación
+// bajo 40%, -10%". Las condiciones nil no se evalúan; se exige al menos una. Si varias reglas se
+// cumplen en una noche se aplica solo la de mayor Prioridad (a igual prioridad, la más reciente)
+type ReglaPrecio struct {
+	ID     int    `json:"id"`
+	Nombre string `json:"nombre"`
+	// TipoHabitacionID limita la regla a un tipo de habitación; nil la aplica a todos
+	TipoHabitacionID *int `json:"tipoHabitacionId,omitempty"`
+	// OcupacionMinima y OcupacionMaxima son porcentajes (0-100, inclusive) de ocupación de la noche
+	OcupacionMinima *float64 `json:"ocupacionMinima,omitempty"`
+	OcupacionMaxima *float64 `json:"ocupacionMaxima,omitempty"`
+	// AntelacionMinima y AntelacionMaxima son los días (inclusive) entre hoy y la fecha de llegada
+	AntelacionMinima *int            `json:"antelacionMinima,omitempty"`
+	AntelacionMaxima *int            `json:"antelacionMaxima,omitempty"`
+	TipoModificador  TipoModificador `json:"tipoModificador"`
+	ValorModificador float64         `json:"valorModificador"`
+	Prioridad        int             `json:"prioridad"`
+	Activa           bool            `json:"activa"`
+}
+
+// Validar verifica que la regla tenga valores coherentes
+func (r ReglaPrecio) Validar() error {
+	if strings.TrimSpace(r.Nombre) == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrReglaPrecioInvalida)
+	}
+	if r.OcupacionMinima == nil && r.OcupacionMaxima == nil && r.AntelacionMinima == nil && r.AntelacionMaxima == nil {
+		return fmt.Errorf("%w: indique al menos una condición de ocupación o antelación", ErrReglaPrecioInvalida)
+	}
+	for _, ocupacion := range []*float64{r.OcupacionMinima, r.OcupacionMaxima} {
+		if ocupacion != nil && (*ocupacion < 0 || *ocupacion > 100) {
+			return fmt.Errorf("%w: la ocupación debe estar entre 0 y 100", ErrReglaPrecioInvalida)
+		}
+	}
+	if r.OcupacionMinima != nil && r.OcupacionMaxima != nil && *r.OcupacionMaxima < *r.OcupacionMinima {
+		return fmt.Errorf("%w: la ocupación máxima no puede ser menor a la mínima", ErrReglaPrecioInvalida)
+	}
+	for _, antelacion := range []*int{r.AntelacionMinima, r.AntelacionMaxima} {
+		if antelacion != nil && *antelacion < 0 {
+			return fmt.Errorf("%w: la antelación no puede ser negativa", ErrReglaPrecioInvalida)
+		}
+	}
+	if r.AntelacionMinima != nil && r.AntelacionMaxima != nil && *r.AntelacionMaxima < *r.AntelacionMinima {
+		return fmt.Errorf("%w: la antelación máxima no puede ser menor a la mínima", ErrReglaPrecioInvalida)
+	}
+	switch r.TipoModificador {
+	case ModificadorPorcentaje:
+		if r.ValorModificador <= -100 {
+			return fmt.Errorf("%w: el descuento debe ser menor al 100%%", ErrReglaPrecioInvalida)
+		}
+	case ModificadorMonto:
+	default:
+		return fmt.Errorf("%w: tipo de modificador desconocido", ErrReglaPrecioInvalida)
+	}
+	return nil
+}
+
+// Aplica indica si la regla se cumple para una noche del tipo de habitación con la ocupación
+// (porcentaje) y la antelación (días hasta la llegada) dadas
+func (r ReglaPrecio) Aplica(tipoHabitacionID int, ocupacion float64, antelacion int) bool {
+	switch {
+	case !r.Activa:
+		return false
+	case r.TipoHabitacionID != nil && *r.TipoHabitacionID != tipoHabitacionID:
+		return false
+	case r.OcupacionMinima != nil && ocupacion < *r.OcupacionMinima:
+		return false
+	case r.OcupacionMaxima != nil && ocupacion > *r.OcupacionMaxima:
+		return false
+	case r.AntelacionMinima != nil && antelacion < *r.AntelacionMinima:
+		return false
+	case r.AntelacionMaxima != nil && antelacion > *r.AntelacionMaxima:
+		return false
+	}
+	return true
+}
+
+// Ajustar aplica el modificador de la regla al precio de una noche, sin dejarlo negativo
+func (r ReglaPrecio) Ajustar(precio float64) float64 {
+	switch r.TipoModificador {
+	case ModificadorPorcentaje:
+		precio += precio * r.ValorModificador / 100
+	case ModificadorMonto:
+		precio += r.ValorModificador
+	}
+	return redondearMonto(math.Max(0, precio))
+}
+
+// ReglaPrecioNoche retorna la regla de mayor prioridad que se cumple para la noche, o nil.
+// Los precios fijados para una fecha y los precios fijos acordados no se ajustan
+func ReglaPrecioNoche(reglas []ReglaPrecio, tipoHabitacionID int, noche PrecioNoche, ocupacion float64, antelacion int) *ReglaPrecio {
+	if noche.Origen == OrigenPrecioFecha || noche.Origen == OrigenPrecioFijo {
+		return nil
+	}
+
+	var elegida *ReglaPrecio
+	for i := range reglas {
+		r := &reglas[i]
+		if !r.Aplica(tipoHabitacionID, ocupacion, antelacion) {
+			continue
+		}
+		if elegida == nil || r.Prioridad > elegida.Prioridad ||
+			(r.Prioridad == elegida.Prioridad && r.ID > elegida.ID) {
+			elegida = r
+		}
+	}
+	return elegida
+}
+
+// OcupacionFecha es la ocupación del hotel en una noche: habitaciones vendibles ocupadas o
+// apartadas por bloques de grupo sobre el total de habitaciones vendibles
+type OcupacionFecha struct {
+	Fecha                time.Time `json:"fecha"`
+	HabitacionesTotales  int       `json:"habitacionesTotales"`
+	HabitacionesOcupadas int       `json:"habitacionesOcupadas"`
+	Porcentaje           float64   `json:"porcentaje"`
+}
+
+// AplicarReglasPrecio ajusta cada noche de la cotización con la regla aplicable según la
+// ocupación de esa noche (porcentaje por fecha) y la antelación de la llegada
+func AplicarReglasPrecio(cotizacion *CotizacionEstadia, reglas []ReglaPrecio, ocupacion map[time.Time]float64, antelacion int) {
+	total := 0.0
+	for i, noche := range cotizacion.Noches {
+		if regla := ReglaPrecioNoche(reglas, cotizacion.TipoHabitacionID, noche, ocupacion[noche.Fecha], antelacion); regla != nil {
+			noche.Precio = regla.Ajustar(noche.Precio)
+			if noche.Detalle != "" {
+				noche.Detalle += " · "
+			}
+			noche.Detalle += regla.Nombre
+			cotizacion.Noches[i] = noche
+		}
+		total += noche.Precio
+	}
+
+	cotizacion.Total = redondearMonto(total + TotalRecargos(cotizacion.Recargos))
+}
+
+// SimulacionPrecioNoche es el resultado de evaluar las reglas de precio para una noche
+// (como llegada de una noche), sin crear ninguna reserva
+type SimulacionPrecioNoche struct {
+	Fecha      time.Time `json:"fecha"`
+	Ocupacion  float64   `json:"ocupacion"`
+	Antelacion int       `json:"antelacion"`
+	PrecioBase float64   `json:"precioBase"`
+	Precio     float64   `json:"precio"`
+	// ReglaID y Regla identifican la regla aplicada (nil si ninguna se cumple)
+	ReglaID *int   `json:"reglaId,omitempty"`
+	Regla   string `json:"regla,omitempty"`
+}
+
+// ReglaPrecioRepository define las operaciones con las reglas de precio dinámico
+type ReglaPrecioRepository interface {
+	// GetAll obtiene las reglas que aplican a un tipo de habitación, incluidas las generales
+	// (0 = todas las reglas)
+	GetAll(tipoHabitacionID int) ([]ReglaPrecio, error)
+	// GetActivas obtiene las reglas activas que aplican a un tipo de habitación
+	GetActivas(tipoHabitacionID int) ([]ReglaPrecio, error)
+	// GetByID obtiene una regla por su ID. Retorna ErrReglaPrecioNoEncontrada si no existe
+	GetByID(id int) (*ReglaPrecio, error)
+	// Create crea una nueva regla
+	Create(regla *ReglaPrecio) error
+	// Update actualiza una regla existente
+	Update(regla *ReglaPrecio) error
+	// Delete elimina una regla
+	Delete(id int) error
+}
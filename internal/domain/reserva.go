@@ -1,9 +1,14 @@
 package domain
 
 import (
+	"errors"
+	"math"
 	"time"
 )
 
+// ErrRetencionVencida indica que la reserva pendiente ya no retiene sus habitaciones
+var ErrRetencionVencida = errors.New("el plazo de retención de la reserva ha vencido")
+
 type EstadoReserva string
 
 const (
@@ -11,11 +16,16 @@ const (
 	ReservaConfirmada EstadoReserva = "Confirmada"
 	ReservaCancelada  EstadoReserva = "Cancelada"
 	ReservaCompletada EstadoReserva = "Completada"
+	// ReservaEnCurso indica que el huésped ya hizo check-in
+	ReservaEnCurso EstadoReserva = "EnCurso"
+	// ReservaNoShow indica que el huésped no llegó en la fecha de entrada
+	ReservaNoShow EstadoReserva = "NoShow"
 )
 
 // Reserva representa una reserva principal
 type Reserva struct {
 	ID                int                 `json:"id"`
+	Codigo            string              `json:"codigo"`
 	CantidadAdultos   int                 `json:"cantidadAdultos"`
 	CantidadNinhos    int                 `json:"cantidadNinhos"`
 	Estado            EstadoReserva       `json:"estado"`
@@ -25,29 +35,104 @@ type Reserva struct {
 	FechaConfirmacion time.Time           `json:"fechaConfirmacion"`
 	Habitaciones      []ReservaHabitacion `json:"habitaciones"`
 	Servicios         []ReservaServicio   `json:"servicios,omitempty"`
+	// VenceRetencion es el momento (UTC) en que una reserva pendiente deja de retener sus habitaciones
+	VenceRetencion *time.Time `json:"venceRetencion,omitempty"`
+	// SegundosRetencionRestantes se calcula al consultar la reserva para mostrar la cuenta regresiva
+	SegundosRetencionRestantes *int64 `json:"segundosRetencionRestantes,omitempty"`
+	// FechaCheckIn y FechaCheckOut son las horas reales (UTC) de llegada y salida
+	FechaCheckIn  *time.Time `json:"fechaCheckIn,omitempty"`
+	FechaCheckOut *time.Time `json:"fechaCheckOut,omitempty"`
+	// BloqueGrupoID es el bloque de grupo contra el que se recogió la reserva, si corresponde
+	BloqueGrupoID *int `json:"bloqueGrupoId,omitempty"`
+	// CodigoPromocional es el código de campaña canjeado; el servidor calcula Descuento con él
+	CodigoPromocional string `json:"codigoPromocional,omitempty"`
+	// EdadesNinhos son las edades declaradas de los niños; definen su recargo por banda de edad
+	EdadesNinhos []int `json:"edadesNinhos,omitempty"`
+	// Canal es el código del canal (OTA) por el que llegó la reserva; vacío en la venta directa
+	Canal string `json:"canal,omitempty"`
+	// ReferenciaCanal es el identificador de la reserva en el canal
+	ReferenciaCanal string `json:"referenciaCanal,omitempty"`
+}
+
+// RetencionVencida indica si la reserva está pendiente y su plazo de retención ya pasó
+func (r *Reserva) RetencionVencida(ahora time.Time) bool {
+	return r.Estado == ReservaPendiente && r.VenceRetencion != nil && !ahora.Before(*r.VenceRetencion)
+}
+
+// CalcularRetencionRestante completa SegundosRetencionRestantes para reservas pendientes con plazo
+func (r *Reserva) CalcularRetencionRestante(ahora time.Time) {
+	r.SegundosRetencionRestantes = nil
+	if r.Estado != ReservaPendiente || r.VenceRetencion == nil {
+		return
+	}
+
+	restantes := int64(r.VenceRetencion.Sub(ahora).Seconds())
+	if restantes < 0 {
+		restantes = 0
+	}
+	r.SegundosRetencionRestantes = &restantes
 }
 
 // ReservaServicio representa la relación entre una reserva y un servicio
 type ReservaServicio struct {
+	ID        int       `json:"id"`
 	ReservaID int       `json:"reservaId"`
 	ServiceID int       `json:"serviceId"`
 	StartDate time.Time `json:"startDate"`
 	EndDate   time.Time `json:"endDate"`
-	Status    int       `json:"status"` // 1: Activo, 0: Inactivo
+	Quantity  int       `json:"quantity"`
+	// UnitPrice es el precio por día del servicio al momento de agregarlo
+	UnitPrice   float64 `json:"unitPrice"`
+	ServiceName string  `json:"serviceName,omitempty"`
+	Status      int     `json:"status"` // 1: Activo, 0: Inactivo
+}
+
+// Dias retorna los días cobrados del servicio: los días entre inicio y fin, mínimo uno
+func (rs ReservaServicio) Dias() int {
+	dias := int(math.Round(rs.EndDate.Sub(rs.StartDate).Hours() / 24))
+	if dias < 1 {
+		dias = 1
+	}
+	return dias
+}
+
+// Costo retorna el importe del servicio: precio unitario × cantidad × días
+func (rs ReservaServicio) Costo() float64 {
+	return rs.UnitPrice * float64(rs.Quantity) * float64(rs.Dias())
 }
 
 // ReservaRepository define las operaciones disponibles con las reservas
 type ReservaRepository interface {
 	// GetReservaByID obtiene una reserva por su ID
 	GetReservaByID(id int) (*Reserva, error)
+	// GetReservaByCodigo obtiene una reserva por su código de confirmación.
+	// Retorna ErrReservaNoEncontrada si no existe
+	GetReservaByCodigo(codigo string) (*Reserva, error)
+	// GetReservaByReferenciaCanal obtiene una reserva por su identificador en un canal.
+	// Retorna ErrReservaNoEncontrada si no existe
+	GetReservaByReferenciaCanal(canal, referencia string) (*Reserva, error)
 	// CreateReserva crea una nueva reserva
 	CreateReserva(reserva *Reserva) error
-	// UpdateReservaEstado actualiza el estado de una reserva
-	UpdateReservaEstado(id int, estado EstadoReserva) error
+	// UpdateReservaEstado actualiza el estado de una reserva solo si sigue en el estado anterior.
+	// Retorna ErrTransicionInvalida si otra operación ya lo cambió
+	UpdateReservaEstado(id int, anterior, estado EstadoReserva) error
 	// GetReservasCliente obtiene todas las reservas de un cliente
 	GetReservasCliente(clienteID int) ([]Reserva, error)
 	// CreateReservaServicios crea los servicios asociados a una reserva
 	CreateReservaServicios(reservaID int, servicios []ReservaServicio) error
-	// UpdateExpiredReservations actualiza reservas confirmadas a completadas cuando la fecha de checkout ha pasado
-	UpdateExpiredReservations() error
+	// GetReservaServicios obtiene los servicios activos de una reserva
+	GetReservaServicios(reservaID int) ([]ReservaServicio, error)
+	// AddReservaServicio agrega un servicio a la reserva y suma su costo al subtotal
+	AddReservaServicio(servicio *ReservaServicio) error
+	// RemoveReservaServicio desactiva un servicio de la reserva y descuenta su costo del subtotal
+	RemoveReservaServicio(reservaID, reservaServicioID int) error
+	// ModificarReserva actualiza huéspedes, subtotal y descuento de la reserva y reemplaza sus
+	// habitaciones activas por reserva.Habitaciones, verificando disponibilidad
+	ModificarReserva(reserva *Reserva) error
+	// ReleaseExpiredHolds cancela las reservas pendientes cuya retención venció antes de ahora
+	// y libera sus habitaciones. Retorna los IDs de las reservas liberadas
+	ReleaseExpiredHolds(ahora time.Time) ([]int, error)
+	// GetNoShowCandidatos obtiene las reservas confirmadas sin check-in cuya primera fecha de
+	// entrada es anterior o igual a limite, o cuya última fecha de salida ya llegó a hoy
+	GetNoShowCandidatos(limite, hoy time.Time) ([]int, error)
 }
@@ -1,18 +1,65 @@
 package domain
 
 import (
+	"errors"
+	"math"
 	"time"
 )
 
+// ErrHabitacionNoDisponible indica que la habitación ya está tomada para las fechas solicitadas
+var ErrHabitacionNoDisponible = errors.New("la habitación ya está reservada para las fechas seleccionadas")
+
 // ReservaHabitacion representa la relación entre una reserva y una habitación
 type ReservaHabitacion struct {
 	ReservaID    int         `json:"reservaId"`
 	HabitacionID int         `json:"habitacionId"`
-	Precio       float64     `json:"precio"`
+	Precio       float64     `json:"precio"` // Precio promedio por noche
+	Total        float64     `json:"total"`  // Importe de la estadía según el calendario de tarifas
 	FechaEntrada time.Time   `json:"fechaEntrada"`
 	FechaSalida  time.Time   `json:"fechaSalida"`
 	Estado       int         `json:"estado"` // 1: Activa, 0: Cancelada
 	Habitacion   *Habitacion `json:"habitacion,omitempty"`
+	// PlanTarifaID es el plan tarifario con el que se reservó la habitación
+	PlanTarifaID *int `json:"planTarifaId,omitempty"`
+	// Noches es el desglose por noche calculado al cotizar (no se persiste)
+	Noches []PrecioNoche `json:"noches,omitempty"`
+	// Recargo es la parte de Total cobrada por huéspedes adicionales
+	Recargo float64 `json:"recargo"`
+	// Recargos es el detalle de Recargo calculado al cotizar (no se persiste)
+	Recargos []RecargoHuesped `json:"recargos,omitempty"`
+}
+
+// Importe retorna el importe de la estadía en la habitación. Las reservas anteriores al
+// calendario de tarifas no tienen Total y se calculan con el precio por noche
+func (rh ReservaHabitacion) Importe() float64 {
+	if rh.Total > 0 {
+		return rh.Total
+	}
+	return rh.Precio * float64(rh.CantidadNoches())
+}
+
+// CantidadNoches retorna las noches de la estadía en la habitación (al menos una)
+func (rh ReservaHabitacion) CantidadNoches() int {
+	return int(math.Max(1, math.Round(rh.FechaSalida.Sub(rh.FechaEntrada).Hours()/24)))
+}
+
+// AplicarCotizacion fija el precio de la habitación a partir de la cotización de la estadía
+func (rh *ReservaHabitacion) AplicarCotizacion(cotizacion CotizacionEstadia) {
+	rh.Precio = cotizacion.PrecioPromedio()
+	rh.Total = cotizacion.Total
+	rh.Noches = cotizacion.Noches
+	rh.Recargo = TotalRecargos(cotizacion.Recargos)
+	rh.Recargos = cotizacion.Recargos
+}
+
+// AplicarRecargos reemplaza los recargos por huéspedes adicionales de la habitación y
+// recalcula el importe y el precio promedio por noche
+func (rh *ReservaHabitacion) AplicarRecargos(recargos []RecargoHuesped) {
+	base := rh.Importe() - rh.Recargo
+	rh.Recargo = TotalRecargos(recargos)
+	rh.Recargos = recargos
+	rh.Total = redondearMonto(base + rh.Recargo)
+	rh.Precio = redondearMonto(rh.Total / float64(rh.CantidadNoches()))
 }
 
 // ReservaHabitacionRepository define las operaciones disponibles con las reservas de habitaciones
@@ -23,8 +70,12 @@ type ReservaHabitacionRepository interface {
 	GetReservaHabitacionesByReservaID(reservaID int) ([]ReservaHabitacion, error)
 	// UpdateReservaHabitacionEstado actualiza el estado de una reserva de habitación
 	UpdateReservaHabitacionEstado(reservaID, habitacionID int, estado int) error
-	// VerificarDisponibilidad verifica si una habitación está disponible para las fechas dadas
-	VerificarDisponibilidad(habitacionID int, fechaEntrada, fechaSalida time.Time) (bool, error)
+	// GetOcupaciones obtiene las habitaciones activas cuyas fechas se solapan con [desde, hasta).
+	// Las reglas de qué estados retienen inventario se aplican en AvailabilityService
+	GetOcupaciones(desde, hasta time.Time) ([]Ocupacion, error)
 	// GetReservasEnRango obtiene todas las reservas activas en un rango de fechas
 	GetReservasEnRango(fechaInicio, fechaFin time.Time) ([]ReservaHabitacion, error)
+	// GetOcupacionesHabitaciones obtiene las habitaciones activas de las habitaciones indicadas
+	// cuya salida es posterior a desde, ordenadas por fecha de entrada
+	GetOcupacionesHabitaciones(habitacionIDs []int, desde time.Time) ([]Ocupacion, error)
 }
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrRestriccionNoEncontrada indica que la restricción de estadía no existe
+	ErrRestriccionNoEncontrada = errors.New("restricción de estadía no encontrada")
+	// ErrRestriccionInvalida indica que la restricción de estadía no es coherente
+	ErrRestriccionInvalida = errors.New("restricción de estadía inválida")
+	// ErrEstadiaRestringida indica que las fechas de la estadía no cumplen las restricciones del hotel
+	ErrEstadiaRestringida = errors.New("la estadía no cumple las restricciones del hotel")
+)
+
+// TipoRestriccion define qué limita una restricción de estadía
+type TipoRestriccion string
+
+const (
+	// RestriccionEstadiaMinima exige al menos Noches noches a las llegadas dentro del rango
+	RestriccionEstadiaMinima TipoRestriccion = "estadia_minima"
+	// RestriccionEstadiaMaxima permite como máximo Noches noches a las llegadas dentro del rango
+	RestriccionEstadiaMaxima TipoRestriccion = "estadia_maxima"
+	// RestriccionCerradoLlegada no admite llegadas dentro del rango
+	RestriccionCerradoLlegada TipoRestriccion = "cerrado_llegada"
+	// RestriccionCerradoSalida no admite salidas dentro del rango
+	RestriccionCerradoSalida TipoRestriccion = "cerrado_salida"
+	// RestriccionBloqueo no vende ninguna noche dentro del rango
+	RestriccionBloqueo TipoRestriccion = "bloqueo"
+)
+
+// RestriccionEstadia limita las estadías que se pueden vender entre FechaInicio y FechaFin
+// (inclusive), para un tipo de habitación o para todo el hotel
+type RestriccionEstadia struct {
+	ID int `json:"id"`
+	// TipoHabitacionID limita la restricción a un tipo de habitación; nil la aplica a todo el hotel
+	TipoHabitacionID *int            `json:"tipoHabitacionId,omitempty"`
+	Tipo             TipoRestriccion `json:"tipo"`
+	FechaInicio      time.Time       `json:"fechaInicio"`
+	FechaFin         time.Time       `json:"fechaFin"`
+	// Noches es el mínimo o máximo de noches (solo estadía mínima y máxima)
+	Noches *int   `json:"noches,omitempty"`
+	Motivo string `json:"motivo,omitempty"`
+	Activa bool   `json:"activa"`
+}
+
+// Validar verifica que la restricción tenga valores coherentes
+func (r RestriccionEstadia) Validar() error {
+	if r.FechaInicio.IsZero() || r.FechaFin.IsZero() {
+		return fmt.Errorf("%w: las fechas de inicio y fin son requeridas", ErrRestriccionInvalida)
+	}
+	if r.FechaFin.Before(r.FechaInicio) {
+		return fmt.Errorf("%w: la fecha fin no puede ser anterior a la fecha inicio", ErrRestriccionInvalida)
+	}
+
+	switch r.Tipo {
+	case RestriccionEstadiaMinima, RestriccionEstadiaMaxima:
+		if r.Noches == nil || *r.Noches < 1 {
+			return fmt.Errorf("%w: indique la cantidad de noches (mayor a 0)", ErrRestriccionInvalida)
+		}
+	case RestriccionCerradoLlegada, RestriccionCerradoSalida, RestriccionBloqueo:
+		if r.Noches != nil {
+			return fmt.Errorf("%w: la cantidad de noches solo aplica a estadías mínimas y máximas", ErrRestriccionInvalida)
+		}
+	default:
+		return fmt.Errorf("%w: tipo de restricción desconocido", ErrRestriccionInvalida)
+	}
+	return nil
+}
+
+// Cubre indica si la fecha está dentro del rango de la restricción
+func (r RestriccionEstadia) Cubre(fecha time.Time) bool {
+	return !fecha.Before(r.FechaInicio) && !fecha.After(r.FechaFin)
+}
+
+// Rechazo explica por qué la restricción no admite la estadía [entrada, salida) del tipo de
+// habitación, o retorna "" si no la afecta. Las fechas deben ser días de calendario (00:00 UTC)
+func (r RestriccionEstadia) Rechazo(tipoHabitacionID int, entrada, salida time.Time) string {
+	if !r.Activa || (r.TipoHabitacionID != nil && *r.TipoHabitacionID != tipoHabitacionID) {
+		return ""
+	}
+
+	noches := int(salida.Sub(entrada).Hours() / 24)
+	var rechazo string
+	switch r.Tipo {
+	case RestriccionEstadiaMinima:
+		if r.Cubre(entrada) && noches < *r.Noches {
+			rechazo = fmt.Sprintf("las llegadas del %s requieren una estadía mínima de %d noches", entrada.Format("02/01/2006"), *r.Noches)
+		}
+	case RestriccionEstadiaMaxima:
+		if r.Cubre(entrada) && noches > *r.Noches {
+			rechazo = fmt.Sprintf("las llegadas del %s admiten una estadía máxima de %d noches", entrada.Format("02/01/2006"), *r.Noches)
+		}
+	case RestriccionCerradoLlegada:
+		if r.Cubre(entrada) {
+			rechazo = fmt.Sprintf("no se admiten llegadas el %s", entrada.Format("02/01/2006"))
+		}
+	case RestriccionCerradoSalida:
+		if r.Cubre(salida) {
+			rechazo = fmt.Sprintf("no se admiten salidas el %s", salida.Format("02/01/2006"))
+		}
+	case RestriccionBloqueo:
+		primera := entrada
+		if primera.Before(r.FechaInicio) {
+			primera = r.FechaInicio
+		}
+		if primera.Before(salida) && r.Cubre(primera) {
+			rechazo = fmt.Sprintf("la noche del %s no está a la venta", primera.Format("02/01/2006"))
+		}
+	}
+
+	if rechazo != "" && r.Motivo != "" {
+		rechazo += " (" + r.Motivo + ")"
+	}
+	return rechazo
+}
+
+// MotivosRechazo retorna la explicación de cada restricción que no admite la estadía del tipo
+func MotivosRechazo(restricciones []RestriccionEstadia, tipoHabitacionID int, entrada, salida time.Time) []string {
+	motivos := make([]string, 0)
+	for _, r := range restricciones {
+		if rechazo := r.Rechazo(tipoHabitacionID, entrada, salida); rechazo != "" {
+			motivos = append(motivos, rechazo)
+		}
+	}
+	return motivos
+}
+
+// VerificarRestricciones retorna ErrEstadiaRestringida con los motivos si alguna restricción no
+// admite la estadía del tipo de habitación
+func VerificarRestricciones(restricciones []RestriccionEstadia, tipoHabitacionID int, entrada, salida time.Time) error {
+	if motivos := MotivosRechazo(restricciones, tipoHabitacionID, entrada, salida); len(motivos) > 0 {
+		return fmt.Errorf("%w: %s", ErrEstadiaRestringida, strings.Join(motivos, "; "))
+	}
+	return nil
+}
+
+// RechazoEstadia es un tipo de habitación con inventario libre que las restricciones no permiten
+// vender para las fechas consultadas
+type RechazoEstadia struct {
+	TipoHabitacionID int      `json:"tipoHabitacionId"`
+	Titulo           string   `json:"titulo"`
+	Motivos          []string `json:"motivos"`
+}
+
+// RestriccionEstadiaRepository define las operaciones con las restricciones de estadía
+type RestriccionEstadiaRepository interface {
+	// GetAll obtiene las restricciones de un tipo de habitación, incluidas las de todo el hotel
+	// (0 = todas las restricciones)
+	GetAll(tipoHabitacionID int) ([]RestriccionEstadia, error)
+	// GetEnRango obtiene las restricciones activas de todos los tipos que se solapan con
+	// [desde, hasta] (inclusive)
+	GetEnRango(desde, hasta time.Time) ([]RestriccionEstadia, error)
+	// GetByID obtiene una restricción por su ID. Retorna ErrRestriccionNoEncontrada si no existe
+	GetByID(id int) (*RestriccionEstadia, error)
+	// Create crea una nueva restricción
+	Create(restriccion *RestriccionEstadia) error
+	// Update actualiza una restricción existente
+	Update(restriccion *RestriccionEstadia) error
+	// Delete elimina una restricción
+	Delete(id int) error
+}
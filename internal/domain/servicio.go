@@ -12,4 +12,6 @@ type Servicio struct {
 type ServicioRepository interface {
 	// GetAllServices retorna todos los servicios disponibles
 	GetAllServices() ([]Servicio, error)
+	// GetByID retorna un servicio por su ID
+	GetByID(id int) (*Servicio, error)
 }
@@ -0,0 +1,243 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTarifaInvalida indica que la configuración de una temporada o precio por fecha no es coherente
+	ErrTarifaInvalida = errors.New("tarifa inválida")
+	// ErrTarifaNoEncontrada indica que la temporada o el precio por fecha no existe
+	ErrTarifaNoEncontrada = errors.New("tarifa no encontrada")
+)
+
+// TemporadaTarifa fija el precio por noche de un tipo de habitación entre dos fechas (inclusive).
+// PrecioFinSemana, si se indica, se cobra las noches de viernes y sábado. Si varias temporadas
+// cubren la misma noche se aplica la de mayor Prioridad (a igual prioridad, la más reciente)
+type TemporadaTarifa struct {
+	ID               int       `json:"id"`
+	TipoHabitacionID int       `json:"tipoHabitacionId"`
+	Nombre           string    `json:"nombre"`
+	FechaInicio      time.Time `json:"fechaInicio"`
+	FechaFin         time.Time `json:"fechaFin"`
+	PrecioNoche      float64   `json:"precioNoche"`
+	PrecioFinSemana  *float64  `json:"precioFinSemana,omitempty"`
+	Prioridad        int       `json:"prioridad"`
+	Activa           bool      `json:"activa"`
+}
+
+// Validar verifica que la temporada tenga valores coherentes
+func (t TemporadaTarifa) Validar() error {
+	if strings.TrimSpace(t.Nombre) == "" {
+		return fmt.Errorf("%w: el nombre de la temporada es requerido", ErrTarifaInvalida)
+	}
+	if t.TipoHabitacionID <= 0 {
+		return fmt.Errorf("%w: el tipo de habitación es requerido", ErrTarifaInvalida)
+	}
+	if t.FechaFin.Before(t.FechaInicio) {
+		return fmt.Errorf("%w: la fecha fin no puede ser anterior a la fecha inicio", ErrTarifaInvalida)
+	}
+	if t.PrecioNoche <= 0 {
+		return fmt.Errorf("%w: el precio por noche debe ser mayor a 0", ErrTarifaInvalida)
+	}
+	if t.PrecioFinSemana != nil && *t.PrecioFinSemana <= 0 {
+		return fmt.Errorf("%w: el precio de fin de semana debe ser mayor a 0", ErrTarifaInvalida)
+	}
+	return nil
+}
+
+// Aplica indica si la temporada fija el precio de la noche que empieza en la fecha dada
+func (t TemporadaTarifa) Aplica(noche time.Time) bool {
+	return t.Activa && !noche.Before(t.FechaInicio) && !noche.After(t.FechaFin)
+}
+
+// Precio retorna el precio de la temporada para la noche dada
+func (t TemporadaTarifa) Precio(noche time.Time) float64 {
+	if t.PrecioFinSemana != nil && EsNocheFinSemana(noche) {
+		return *t.PrecioFinSemana
+	}
+	return t.PrecioNoche
+}
+
+// PrecioFecha reemplaza el precio de un tipo de habitación para una noche concreta
+// (feriados, eventos); tiene prioridad sobre cualquier temporada
+type PrecioFecha struct {
+	ID               int       `json:"id"`
+	TipoHabitacionID int       `json:"tipoHabitacionId"`
+	Fecha            time.Time `json:"fecha"`
+	Precio           float64   `json:"precio"`
+	Motivo           string    `json:"motivo,omitempty"`
+}
+
+// Validar verifica que el precio por fecha tenga valores coherentes
+func (p PrecioFecha) Validar() error {
+	if p.TipoHabitacionID <= 0 {
+		return fmt.Errorf("%w: el tipo de habitación es requerido", ErrTarifaInvalida)
+	}
+	if p.Fecha.IsZero() {
+		return fmt.Errorf("%w: la fecha es requerida", ErrTarifaInvalida)
+	}
+	if p.Precio <= 0 {
+		return fmt.Errorf("%w: el precio debe ser mayor a 0", ErrTarifaInvalida)
+	}
+	return nil
+}
+
+// OrigenPrecio indica de dónde sale el precio de una noche
+type OrigenPrecio string
+
+const (
+	// OrigenPrecioBase es el precio del tipo de habitación
+	OrigenPrecioBase OrigenPrecio = "base"
+	// OrigenPrecioTemporada es el precio de una temporada
+	OrigenPrecioTemporada OrigenPrecio = "temporada"
+	// OrigenPrecioFecha es un precio fijado para esa fecha
+	OrigenPrecioFecha OrigenPrecio = "fecha"
+	// OrigenPrecioFijo es un precio acordado para toda la estadía (p. ej. tarifa de grupo)
+	OrigenPrecioFijo OrigenPrecio = "fijo"
+)
+
+// PrecioNoche es el precio cobrado por una noche de la estadía
+type PrecioNoche struct {
+	Fecha   time.Time    `json:"fecha"`
+	Precio  float64      `json:"precio"`
+	Origen  OrigenPrecio `json:"origen"`
+	Detalle string       `json:"detalle,omitempty"`
+}
+
+// CotizacionEstadia es el desglose por noche del precio de una habitación para una estadía
+type CotizacionEstadia struct {
+	TipoHabitacionID int           `json:"tipoHabitacionId"`
+	FechaEntrada     time.Time     `json:"fechaEntrada"`
+	FechaSalida      time.Time     `json:"fechaSalida"`
+	Noches           []PrecioNoche `json:"noches"`
+	Total            float64       `json:"total"`
+	// PlanTarifaID es el plan tarifario aplicado, si corresponde
+	PlanTarifaID *int `json:"planTarifaId,omitempty"`
+	// Recargos son las líneas por huéspedes adicionales, ya incluidas en Total
+	Recargos []RecargoHuesped `json:"recargos,omitempty"`
+}
+
+// AgregarRecargos suma las líneas de recargo por huéspedes adicionales al total de la estadía
+func (c *CotizacionEstadia) AgregarRecargos(recargos []RecargoHuesped) {
+	c.Recargos = append(c.Recargos, recargos...)
+	c.Total = redondearMonto(c.Total + TotalRecargos(recargos))
+}
+
+// PrecioPromedio retorna el precio promedio por noche, redondeado a céntimos
+func (c CotizacionEstadia) PrecioPromedio() float64 {
+	if len(c.Noches) == 0 {
+		return 0
+	}
+	return redondearMonto(c.Total / float64(len(c.Noches)))
+}
+
+// EsNocheFinSemana indica si la noche que empieza en la fecha dada es de viernes o sábado
+func EsNocheFinSemana(noche time.Time) bool {
+	return noche.Weekday() == time.Friday || noche.Weekday() == time.Saturday
+}
+
+// CotizarEstadia calcula el precio de cada noche de [entrada, salida) para el tipo de habitación:
+// un precio por fecha tiene prioridad, luego la temporada aplicable y, si no hay, el precio base.
+// Las fechas deben ser días de calendario (00:00 UTC)
+func CotizarEstadia(tipo TipoHabitacion, temporadas []TemporadaTarifa, precios []PrecioFecha, entrada, salida time.Time) CotizacionEstadia {
+	cotizacion := CotizacionEstadia{
+		TipoHabitacionID: tipo.ID,
+		FechaEntrada:     entrada,
+		FechaSalida:      salida,
+		Noches:           make([]PrecioNoche, 0),
+	}
+
+	porFecha := make(map[time.Time]PrecioFecha, len(precios))
+	for _, p := range precios {
+		porFecha[p.Fecha] = p
+	}
+
+	for noche := entrada; noche.Before(salida); noche = noche.AddDate(0, 0, 1) {
+		precio := PrecioNoche{Fecha: noche, Precio: tipo.Precio, Origen: OrigenPrecioBase}
+
+		if p, ok := porFecha[noche]; ok {
+			precio.Precio, precio.Origen, precio.Detalle = p.Precio, OrigenPrecioFecha, p.Motivo
+		} else if t := temporadaAplicable(temporadas, noche); t != nil {
+			precio.Precio, precio.Origen, precio.Detalle = t.Precio(noche), OrigenPrecioTemporada, t.Nombre
+		}
+
+		precio.Precio = redondearMonto(precio.Precio)
+		cotizacion.Noches = append(cotizacion.Noches, precio)
+		cotizacion.Total += precio.Precio
+	}
+
+	cotizacion.Total = redondearMonto(cotizacion.Total)
+	return cotizacion
+}
+
+// CotizarPrecioFijo arma la cotización de una estadía con el mismo precio todas las noches
+func CotizarPrecioFijo(tipoHabitacionID int, precio float64, entrada, salida time.Time) CotizacionEstadia {
+	cotizacion := CotizacionEstadia{
+		TipoHabitacionID: tipoHabitacionID,
+		FechaEntrada:     entrada,
+		FechaSalida:      salida,
+		Noches:           make([]PrecioNoche, 0),
+	}
+
+	for noche := entrada; noche.Before(salida); noche = noche.AddDate(0, 0, 1) {
+		cotizacion.Noches = append(cotizacion.Noches, PrecioNoche{
+			Fecha:  noche,
+			Precio: redondearMonto(precio),
+			Origen: OrigenPrecioFijo,
+		})
+		cotizacion.Total += redondearMonto(precio)
+	}
+
+	cotizacion.Total = redondearMonto(cotizacion.Total)
+	return cotizacion
+}
+
+// temporadaAplicable retorna la temporada de mayor prioridad que cubre la noche, o nil
+func temporadaAplicable(temporadas []TemporadaTarifa, noche time.Time) *TemporadaTarifa {
+	var elegida *TemporadaTarifa
+	for i := range temporadas {
+		t := &temporadas[i]
+		if !t.Aplica(noche) {
+			continue
+		}
+		if elegida == nil || t.Prioridad > elegida.Prioridad ||
+			(t.Prioridad == elegida.Prioridad && t.ID > elegida.ID) {
+			elegida = t
+		}
+	}
+	return elegida
+}
+
+// redondearMonto redondea un importe a céntimos
+func redondearMonto(monto float64) float64 {
+	return math.Round(monto*100) / 100
+}
+
+// TarifaRepository define las operaciones con el calendario de tarifas
+type TarifaRepository interface {
+	// GetTemporadas obtiene las temporadas de un tipo de habitación (0 = todos los tipos)
+	GetTemporadas(tipoHabitacionID int) ([]TemporadaTarifa, error)
+	// GetTemporadasEnRango obtiene las temporadas activas del tipo que se solapan con [desde, hasta)
+	GetTemporadasEnRango(tipoHabitacionID int, desde, hasta time.Time) ([]TemporadaTarifa, error)
+	// CreateTemporada crea una nueva temporada
+	CreateTemporada(temporada *TemporadaTarifa) error
+	// UpdateTemporada actualiza una temporada existente
+	UpdateTemporada(temporada *TemporadaTarifa) error
+	// DeleteTemporada elimina una temporada
+	DeleteTemporada(id int) error
+	// GetPreciosFecha obtiene los precios por fecha del tipo en [desde, hasta)
+	GetPreciosFecha(tipoHabitacionID int, desde, hasta time.Time) ([]PrecioFecha, error)
+	// GuardarPrecioFecha crea o reemplaza el precio de una fecha para el tipo de habitación
+	GuardarPrecioFecha(precio *PrecioFecha) error
+	// DeletePrecioFecha elimina el precio de una fecha para el tipo de habitación
+	DeletePrecioFecha(tipoHabitacionID int, fecha time.Time) error
+	// GetTarifaOcupacion obtiene los recargos por huéspedes del tipo, o nil si no tiene configurados
+	GetTarifaOcupacion(tipoHabitacionID int) (*TarifaOcupacion, error)
+	// GuardarTarifaOcupacion crea o reemplaza los recargos por huéspedes del tipo con sus bandas de edad
+	GuardarTarifaOcupacion(tarifa *TarifaOcupacion) error
+}
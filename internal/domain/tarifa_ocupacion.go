@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrCapacidadExcedida indica que los huéspedes no caben en las habitaciones de la reserva
+var ErrCapacidadExcedida = errors.New("los huéspedes exceden la capacidad de las habitaciones")
+
+// BandaEdadNinho es el precio por noche de los niños cuya edad está en [EdadMinima, EdadMaxima]
+type BandaEdadNinho struct {
+	EdadMinima  int     `json:"edadMinima"`
+	EdadMaxima  int     `json:"edadMaxima"`
+	PrecioNoche float64 `json:"precioNoche"`
+}
+
+// TarifaOcupacion define los recargos de un tipo de habitación por huéspedes adicionales.
+// El precio de la habitación cubre AdultosIncluidos; cada adulto extra paga PrecioAdultoExtra
+// por noche y cada niño paga según su banda de edad (una banda con precio 0 lo deja gratis)
+type TarifaOcupacion struct {
+	TipoHabitacionID  int              `json:"tipoHabitacionId"`
+	AdultosIncluidos  int              `json:"adultosIncluidos"`
+	PrecioAdultoExtra float64          `json:"precioAdultoExtra"`
+	BandasNinhos      []BandaEdadNinho `json:"bandasNinhos"`
+}
+
+// TarifaOcupacionPorDefecto es la tarifa de un tipo sin recargos configurados: la capacidad
+// completa está incluida en el precio
+func TarifaOcupacionPorDefecto(tipo TipoHabitacion) TarifaOcupacion {
+	return TarifaOcupacion{
+		TipoHabitacionID: tipo.ID,
+		AdultosIncluidos: tipo.CapacidadAdultos,
+		BandasNinhos:     make([]BandaEdadNinho, 0),
+	}
+}
+
+// Validar verifica que la tarifa tenga valores coherentes y bandas sin solapamientos
+func (t TarifaOcupacion) Validar() error {
+	if t.TipoHabitacionID <= 0 {
+		return fmt.Errorf("%w: el tipo de habitación es requerido", ErrTarifaInvalida)
+	}
+	if t.AdultosIncluidos < 1 {
+		return fmt.Errorf("%w: el precio debe incluir al menos un adulto", ErrTarifaInvalida)
+	}
+	if t.PrecioAdultoExtra < 0 {
+		return fmt.Errorf("%w: el precio por adulto adicional no puede ser negativo", ErrTarifaInvalida)
+	}
+
+	bandas := t.bandasOrdenadas()
+	for i, banda := range bandas {
+		if banda.EdadMinima < 0 || banda.EdadMaxima < banda.EdadMinima {
+			return fmt.Errorf("%w: banda de edad %d-%d inválida", ErrTarifaInvalida, banda.EdadMinima, banda.EdadMaxima)
+		}
+		if banda.PrecioNoche < 0 {
+			return fmt.Errorf("%w: el precio de la banda %d-%d no puede ser negativo", ErrTarifaInvalida, banda.EdadMinima, banda.EdadMaxima)
+		}
+		if i > 0 && banda.EdadMinima <= bandas[i-1].EdadMaxima {
+			return fmt.Errorf("%w: las bandas de edad %d-%d y %d-%d se solapan", ErrTarifaInvalida,
+				bandas[i-1].EdadMinima, bandas[i-1].EdadMaxima, banda.EdadMinima, banda.EdadMaxima)
+		}
+	}
+	return nil
+}
+
+// bandasOrdenadas retorna una copia de las bandas ordenadas por edad
+func (t TarifaOcupacion) bandasOrdenadas() []BandaEdadNinho {
+	bandas := make([]BandaEdadNinho, len(t.BandasNinhos))
+	copy(bandas, t.BandasNinhos)
+	sort.Slice(bandas, func(i, j int) bool { return bandas[i].EdadMinima < bandas[j].EdadMinima })
+	return bandas
+}
+
+// bandaNinho retorna la banda de la edad, o nil si ninguna la cubre (edad desconocida = -1)
+func (t TarifaOcupacion) bandaNinho(edad int) *BandaEdadNinho {
+	for i, banda := range t.BandasNinhos {
+		if edad >= banda.EdadMinima && edad <= banda.EdadMaxima {
+			return &t.BandasNinhos[i]
+		}
+	}
+	return nil
+}
+
+// OcupacionHabitacion son los huéspedes asignados a una habitación. Las edades de los niños
+// que no se indicaron se registran como -1
+type OcupacionHabitacion struct {
+	Adultos      int   `json:"adultos"`
+	EdadesNinhos []int `json:"edadesNinhos"`
+}
+
+// NuevaOcupacion arma la ocupación de adultos y niños con las edades indicadas, que pueden
+// ser menos que los niños: las faltantes quedan en -1
+func NuevaOcupacion(adultos, ninhos int, edadesNinhos []int) (OcupacionHabitacion, error) {
+	if adultos < 1 {
+		return OcupacionHabitacion{}, fmt.Errorf("debe haber al menos 1 adulto")
+	}
+	if ninhos < 0 || len(edadesNinhos) > ninhos {
+		return OcupacionHabitacion{}, fmt.Errorf("se indicaron %d edades para %d niños", len(edadesNinhos), ninhos)
+	}
+
+	edades := make([]int, ninhos)
+	for i := range edades {
+		edades[i] = -1
+	}
+	for i, edad := range edadesNinhos {
+		if edad < 0 {
+			return OcupacionHabitacion{}, fmt.Errorf("edad de niño inválida: %d", edad)
+		}
+		edades[i] = edad
+	}
+
+	return OcupacionHabitacion{Adultos: adultos, EdadesNinhos: edades}, nil
+}
+
+// RecargoHuesped es una línea de recargo por huéspedes adicionales en una estadía
+type RecargoHuesped struct {
+	Concepto    string  `json:"concepto"`
+	Cantidad    int     `json:"cantidad"`
+	PrecioNoche float64 `json:"precioNoche"`
+	Noches      int     `json:"noches"`
+	Total       float64 `json:"total"`
+}
+
+// Recargos calcula las líneas de recargo de una habitación para la cantidad de noches.
+// Sin bandas configuradas los niños no pagan; con bandas, un niño cuya edad no está en
+// ninguna (o no se indicó) paga como adulto adicional
+func (t TarifaOcupacion) Recargos(ocupacion OcupacionHabitacion, noches int) []RecargoHuesped {
+	recargos := make([]RecargoHuesped, 0)
+	agregar := func(concepto string, cantidad int, precio float64) {
+		recargos = append(recargos, RecargoHuesped{
+			Concepto:    concepto,
+			Cantidad:    cantidad,
+			PrecioNoche: precio,
+			Noches:      noches,
+			Total:       redondearMonto(float64(cantidad*noches) * precio),
+		})
+	}
+
+	if extra := ocupacion.Adultos - t.AdultosIncluidos; extra > 0 {
+		agregar("Adulto adicional", extra, t.PrecioAdultoExtra)
+	}
+	if len(t.BandasNinhos) == 0 {
+		return recargos
+	}
+
+	porBanda := make(map[BandaEdadNinho]int)
+	sinBanda := 0
+	for _, edad := range ocupacion.EdadesNinhos {
+		if banda := t.bandaNinho(edad); banda != nil {
+			porBanda[*banda]++
+		} else {
+			sinBanda++
+		}
+	}
+	for _, banda := range t.bandasOrdenadas() {
+		if cantidad := porBanda[banda]; cantidad > 0 {
+			agregar(fmt.Sprintf("Niño de %d a %d años", banda.EdadMinima, banda.EdadMaxima), cantidad, banda.PrecioNoche)
+		}
+	}
+	if sinBanda > 0 {
+		agregar("Niño con tarifa de adulto adicional", sinBanda, t.PrecioAdultoExtra)
+	}
+
+	return recargos
+}
+
+// TotalRecargos suma el importe de las líneas de recargo
+func TotalRecargos(recargos []RecargoHuesped) float64 {
+	total := 0.0
+	for _, recargo := range recargos {
+		total += recargo.Total
+	}
+	return redondearMonto(total)
+}
+
+// ValidarOcupacion verifica que los huéspedes quepan en una habitación del tipo: los niños
+// pueden ocupar lugares de adulto libres pero no al revés
+func ValidarOcupacion(tipo TipoHabitacion, ocupacion OcupacionHabitacion) error {
+	ninhos := len(ocupacion.EdadesNinhos)
+	if ocupacion.Adultos > tipo.CapacidadAdultos || ocupacion.Adultos+ninhos > tipo.CapacidadAdultos+tipo.CapacidadNinhos {
+		return fmt.Errorf("%w: %s admite %d adultos y %d niños", ErrCapacidadExcedida,
+			tipo.Titulo, tipo.CapacidadAdultos, tipo.CapacidadNinhos)
+	}
+	return nil
+}
+
+// DistribuirHuespedes reparte los huéspedes de la reserva entre sus habitaciones (en el mismo
+// orden que tipos). Primero llena los lugares de adulto incluidos en el precio, después los
+// lugares de adulto restantes y finalmente ubica a los niños en sus lugares y en los de adulto
+// que sobren. edadesNinhos puede tener menos edades que ninhos; las faltantes quedan en -1
+func DistribuirHuespedes(tipos []TipoHabitacion, tarifas []TarifaOcupacion, adultos, ninhos int, edadesNinhos []int) ([]OcupacionHabitacion, error) {
+	huespedes, err := NuevaOcupacion(adultos, ninhos, edadesNinhos)
+	if err != nil {
+		return nil, err
+	}
+	capacidadAdultos, capacidadTotal := 0, 0
+	for _, tipo := range tipos {
+		capacidadAdultos += tipo.CapacidadAdultos
+		capacidadTotal += tipo.CapacidadAdultos + tipo.CapacidadNinhos
+	}
+	if adultos > capacidadAdultos || adultos+ninhos > capacidadTotal {
+		return nil, fmt.Errorf("%w: las habitaciones admiten %d adultos y %d huéspedes en total",
+			ErrCapacidadExcedida, capacidadAdultos, capacidadTotal)
+	}
+
+	ocupaciones := make([]OcupacionHabitacion, len(tipos))
+	for i := range ocupaciones {
+		ocupaciones[i].EdadesNinhos = make([]int, 0)
+	}
+
+	// Adultos cubiertos por el precio y después los que pagan recargo
+	for i, tipo := range tipos {
+		incluidos := min(tarifas[i].AdultosIncluidos, tipo.CapacidadAdultos)
+		asignados := min(incluidos, adultos)
+		ocupaciones[i].Adultos = asignados
+		adultos -= asignados
+	}
+	for i, tipo := range tipos {
+		asignados := min(tipo.CapacidadAdultos-ocupaciones[i].Adultos, adultos)
+		ocupaciones[i].Adultos += asignados
+		adultos -= asignados
+	}
+
+	// Niños primero en sus lugares y después en los lugares de adulto libres
+	edades := huespedes.EdadesNinhos
+	for i, tipo := range tipos {
+		libres := min(tipo.CapacidadNinhos, len(edades))
+		ocupaciones[i].EdadesNinhos = append(ocupaciones[i].EdadesNinhos, edades[:libres]...)
+		edades = edades[libres:]
+	}
+	for i, tipo := range tipos {
+		libres := tipo.CapacidadAdultos + tipo.CapacidadNinhos - ocupaciones[i].Adultos - len(ocupaciones[i].EdadesNinhos)
+		libres = min(libres, len(edades))
+		ocupaciones[i].EdadesNinhos = append(ocupaciones[i].EdadesNinhos, edades[:libres]...)
+		edades = edades[libres:]
+	}
+
+	return ocupaciones, nil
+}
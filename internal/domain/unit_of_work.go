@@ -0,0 +1,23 @@
+package domain
+
+// Repositories agrupa los repositorios que comparten una misma transacción
+type Repositories struct {
+	Person            PersonRepository
+	Client            ClientRepository
+	Reserva           ReservaRepository
+	ReservaHabitacion ReservaHabitacionRepository
+	ReservationGuest  ReservationGuestRepository
+	Payment           PaymentRepository
+	HistorialEstado   HistorialEstadoRepository
+	Estancia          EstanciaRepository
+	Alerta            AlertaRepository
+	CodigoPromocional CodigoPromocionalRepository
+	Limpieza          LimpiezaRepository
+}
+
+// UnitOfWork permite ejecutar varias operaciones de repositorio de forma atómica
+type UnitOfWork interface {
+	// Do ejecuta fn dentro de una transacción: si fn retorna error se revierte todo,
+	// en caso contrario se confirma
+	Do(fn func(repos Repositories) error) error
+}
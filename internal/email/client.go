@@ -105,13 +105,14 @@ type HabitacionInfo struct {
 	Numero       string
 	FechaEntrada time.Time
 	FechaSalida  time.Time
-	Precio       float64
+	Precio       float64 // Precio promedio por noche
+	Total        float64 // Importe de la estadía según el calendario de tarifas
 	Noches       int
 }
 
 // SendReservaConfirmacion envía un correo de confirmación de reserva
 func (c *Client) SendReservaConfirmacion(reserva ReservaInfo) error {
-	subject := fmt.Sprintf("Confirmación de Reserva #%d - %s", reserva.ID, c.fromName)
+	subject := fmt.Sprintf("Confirmación de Reserva %s - %s", reserva.CodigoReserva, c.fromName)
 	htmlBody := generarHTMLConfirmacion(reserva)
 
 	return c.SendEmail(reserva.ClienteEmail, subject, htmlBody)
@@ -147,7 +148,7 @@ func generarHTMLConfirmacion(reserva ReservaInfo) string {
 			hab.FechaEntrada.Format("02/01/2006"),
 			hab.FechaSalida.Format("02/01/2006"),
 			hab.Noches,
-			hab.Precio*float64(hab.Noches),
+			hab.Total,
 		)
 	}
 
@@ -182,8 +183,8 @@ func generarHTMLConfirmacion(reserva ReservaInfo) string {
 								<h2 style="margin: 0 0 15px 0; color: #333; font-size: 20px;">Detalles de la Reserva</h2>
 								<table width="100%%" cellpadding="0" cellspacing="0">
 									<tr>
-										<td style="padding: 8px 0;"><strong>ID de Reserva:</strong></td>
-										<td style="padding: 8px 0; text-align: right;">#%d</td>
+										<td style="padding: 8px 0;"><strong>Código de Reserva:</strong></td>
+										<td style="padding: 8px 0; text-align: right;">%s</td>
 									</tr>
 									<tr>
 										<td style="padding: 8px 0;"><strong>Fecha de Confirmación:</strong></td>
@@ -261,7 +262,7 @@ func generarHTMLConfirmacion(reserva ReservaInfo) string {
 </body>
 </html>
 	`,
-		reserva.ID,
+		reserva.CodigoReserva,
 		reserva.FechaConfirmacion.Format("02/01/2006 15:04"),
 		reserva.CantidadAdultos,
 		func() string {
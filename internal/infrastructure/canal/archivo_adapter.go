@@ -0,0 +1,139 @@
+package canal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// Archivos que componen un canal simulado dentro de su directorio
+const (
+	archivoReservas       = "reservas.json"
+	archivoInventario     = "inventario.json"
+	archivoTarifas        = "tarifas.json"
+	archivoConfirmaciones = "confirmaciones.json"
+)
+
+// ArchivoAdapter es un ChannelAdapter que simula un canal con archivos JSON en un directorio,
+// para pruebas y desarrollo sin depender de una OTA real:
+//   - reservas.json es la cola de reservas pendientes ([]domain.ReservaCanal) que se edita a mano.
+//   - AckReservas quita de la cola las reservas confirmadas y agrega las confirmaciones a
+//     confirmaciones.json.
+//   - PushInventario y PushTarifas reemplazan inventario.json y tarifas.json.
+type ArchivoAdapter struct {
+	codigo     string
+	directorio string
+	mu         sync.Mutex
+}
+
+// NewArchivoAdapter crea un canal simulado con el código dado sobre el directorio indicado
+func NewArchivoAdapter(codigo, directorio string) (*ArchivoAdapter, error) {
+	if err := os.MkdirAll(directorio, 0o755); err != nil {
+		return nil, fmt.Errorf("error al crear el directorio del canal %s: %w", codigo, err)
+	}
+	return &ArchivoAdapter{codigo: codigo, directorio: directorio}, nil
+}
+
+// Codigo identifica al canal
+func (a *ArchivoAdapter) Codigo() string {
+	return a.codigo
+}
+
+// PushInventario reemplaza inventario.json
+func (a *ArchivoAdapter) PushInventario(inventario []domain.InventarioCanal) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.escribir(archivoInventario, inventario)
+}
+
+// PushTarifas reemplaza tarifas.json
+func (a *ArchivoAdapter) PushTarifas(tarifas []domain.TarifaCanal) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.escribir(archivoTarifas, tarifas)
+}
+
+// PullReservas retorna las reservas pendientes de reservas.json
+func (a *ArchivoAdapter) PullReservas() ([]domain.ReservaCanal, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var reservas []domain.ReservaCanal
+	if err := a.leer(archivoReservas, &reservas); err != nil {
+		return nil, err
+	}
+	return reservas, nil
+}
+
+// AckReservas quita de la cola las reservas confirmadas y registra las confirmaciones
+func (a *ArchivoAdapter) AckReservas(confirmaciones []domain.ConfirmacionCanal) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var reservas []domain.ReservaCanal
+	if err := a.leer(archivoReservas, &reservas); err != nil {
+		return err
+	}
+	var registradas []domain.ConfirmacionCanal
+	if err := a.leer(archivoConfirmaciones, &registradas); err != nil {
+		return err
+	}
+
+	confirmadas := make(map[string]bool, len(confirmaciones))
+	for _, c := range confirmaciones {
+		confirmadas[c.Referencia] = true
+	}
+	pendientes := make([]domain.ReservaCanal, 0, len(reservas))
+	for _, r := range reservas {
+		if !confirmadas[r.Referencia] {
+			pendientes = append(pendientes, r)
+		}
+	}
+
+	if err := a.escribir(archivoConfirmaciones, append(registradas, confirmaciones...)); err != nil {
+		return err
+	}
+	return a.escribir(archivoReservas, pendientes)
+}
+
+// leer decodifica un archivo del canal; un archivo inexistente se trata como vacío
+func (a *ArchivoAdapter) leer(nombre string, destino interface{}) error {
+	contenido, err := os.ReadFile(filepath.Join(a.directorio, nombre))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error al leer %s: %w", nombre, err)
+	}
+	if len(contenido) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(contenido, destino); err != nil {
+		return fmt.Errorf("error al decodificar %s: %w", nombre, err)
+	}
+	return nil
+}
+
+// escribir reemplaza un archivo del canal; se escribe en un temporal y se renombra para no
+// dejar archivos a medio escribir
+func (a *ArchivoAdapter) escribir(nombre string, datos interface{}) error {
+	contenido, err := json.MarshalIndent(datos, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al codificar %s: %w", nombre, err)
+	}
+
+	ruta := filepath.Join(a.directorio, nombre)
+	temporal := ruta + ".tmp"
+	if err := os.WriteFile(temporal, contenido, 0o644); err != nil {
+		return fmt.Errorf("error al escribir %s: %w", nombre, err)
+	}
+	if err := os.Rename(temporal, ruta); err != nil {
+		return fmt.Errorf("error al escribir %s: %w", nombre, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// codigosTipoAlerta traduce los tipos de alerta al entero de alert.alert_type
+var codigosTipoAlerta = map[domain.TipoAlerta]int{
+	domain.AlertaNoShow:                1,
+	domain.AlertaConflictoCalendario:   2,
+	domain.AlertaReservaCanalRechazada: 3,
+}
+
+type alertaRepository struct {
+	db dbtx
+}
+
+// NewAlertaRepository crea una nueva instancia del repositorio de alertas
+func NewAlertaRepository(db *sql.DB) domain.AlertaRepository {
+	return &alertaRepository{db: db}
+}
+
+// Create registra una nueva alerta sin leer. El mensaje se recorta al largo de alert.message
+func (r *alertaRepository) Create(alerta *domain.Alerta) error {
+	codigo, ok := codigosTipoAlerta[alerta.Tipo]
+	if !ok {
+		return fmt.Errorf("tipo de alerta desconocido: %s", alerta.Tipo)
+	}
+
+	query := `
+		INSERT INTO alert (
+			alert_type,
+			message,
+			reservation_id,
+			registration_date,
+			was_read,
+			status
+		) VALUES ($1, $2, $3, $4, $5, 1)
+		RETURNING alert_id
+	`
+
+	err := r.db.QueryRow(
+		query,
+		codigo,
+		domain.RecortarMensajeAlerta(alerta.Mensaje),
+		alerta.ReservaID,
+		alerta.Fecha,
+		alerta.Resuelta,
+	).Scan(&alerta.ID)
+	if err != nil {
+		return fmt.Errorf("error al registrar alerta: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+func TestCreateAlertaColumnasExistentes(t *testing.T) {
+	db, consultas := nuevaDBRegistro(t)
+	repo := &alertaRepository{db: db}
+
+	reservaID := 20
+	alerta := &domain.Alerta{
+		Tipo:      domain.AlertaConflictoCalendario,
+		Mensaje:   strings.Repeat("habitación vendida dos veces ", 10),
+		ReservaID: &reservaID,
+		Fecha:     time.Date(2030, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	if err := repo.Create(alerta); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(*consultas) != 1 {
+		t.Fatalf("consultas = %d, se esperaba 1", len(*consultas))
+	}
+
+	consulta := (*consultas)[0]
+	verificarPlaceholders(t, consulta)
+	if tipo, ok := consulta.args[0].(int64); !ok || tipo != 2 {
+		t.Errorf("alert_type = %v, se esperaba el entero 2", consulta.args[0])
+	}
+	mensaje, _ := consulta.args[1].(string)
+	if n := utf8.RuneCountInString(mensaje); n > domain.LongitudMaximaMensajeAlerta {
+		t.Errorf("el mensaje tiene %d caracteres, el máximo es %d", n, domain.LongitudMaximaMensajeAlerta)
+	}
+}
+
+func TestCreateAlertaTipoDesconocido(t *testing.T) {
+	db, consultas := nuevaDBRegistro(t)
+	repo := &alertaRepository{db: db}
+
+	if err := repo.Create(&domain.Alerta{Tipo: "otro", Mensaje: "x"}); err == nil {
+		t.Fatal("se esperaba un error por el tipo de alerta desconocido")
+	}
+	if len(*consultas) != 0 {
+		t.Errorf("no se debía ejecutar ninguna consulta")
+	}
+}
@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+// advisoryLockBloqueGrupo es el espacio de nombres de los advisory locks por bloque de grupo
+const advisoryLockBloqueGrupo = 1002
+
+// advisoryLockTipoHabitacion es el espacio de nombres de los advisory locks por tipo de habitación
+const advisoryLockTipoHabitacion = 1003
+
+type bloqueGrupoRepository struct {
+	db dbtx
+}
+
+// NewBloqueGrupoRepository crea una nueva instancia del repositorio de bloques de grupo
+func NewBloqueGrupoRepository(db *sql.DB) domain.BloqueGrupoRepository {
+	return &bloqueGrupoRepository{db: db}
+}
+
+// selectBloqueGrupo incluye las habitaciones recogidas: habitaciones activas de reservas
+// del bloque que no fueron canceladas
+const selectBloqueGrupo = `
+	SELECT
+		gb.group_block_id,
+		gb.name,
+		gb.contact_name,
+		gb.contact_email,
+		gb.room_type_id,
+		gb.quantity,
+		gb.check_in_date,
+		gb.check_out_date,
+		gb.release_date,
+		gb.negotiated_price,
+		gb.status,
+		gb.created_at,
+		gb.released_at,
+		(
+			SELECT COUNT(*)
+			FROM reservation_room rr
+			INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+			WHERE r.group_block_id = gb.group_block_id
+			AND rr.status = 1
+			AND r.status NOT IN ('Cancelada', 'NoShow')
+		) AS picked_up
+	FROM group_block gb
+`
+
+// scanBloqueGrupo lee una fila de group_block en un bloque
+func scanBloqueGrupo(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.BloqueGrupo, error) {
+	var (
+		bloque   domain.BloqueGrupo
+		email    sql.NullString
+		precio   sql.NullFloat64
+		liberado sql.NullTime
+	)
+
+	err := row.Scan(
+		&bloque.ID,
+		&bloque.Nombre,
+		&bloque.Contacto,
+		&email,
+		&bloque.TipoHabitacionID,
+		&bloque.Cantidad,
+		&bloque.FechaEntrada,
+		&bloque.FechaSalida,
+		&bloque.FechaLiberacion,
+		&precio,
+		&bloque.Estado,
+		&bloque.FechaCreacion,
+		&liberado,
+		&bloque.Recogidas,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if email.Valid {
+		bloque.Email = &email.String
+	}
+	if precio.Valid {
+		bloque.Precio = &precio.Float64
+	}
+	if liberado.Valid {
+		bloque.FechaLiberado = &liberado.Time
+	}
+
+	return &bloque, nil
+}
+
+// queryBloquesGrupo ejecuta una consulta de bloques y los escanea
+func queryBloquesGrupo(db dbtx, query string, args ...interface{}) ([]domain.BloqueGrupo, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener bloques de grupo: %w", err)
+	}
+	defer rows.Close()
+
+	bloques := make([]domain.BloqueGrupo, 0)
+	for rows.Next() {
+		bloque, err := scanBloqueGrupo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear bloque de grupo: %w", err)
+		}
+		bloques = append(bloques, *bloque)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar bloques de grupo: %w", err)
+	}
+
+	return bloques, nil
+}
+
+// GetAll obtiene todos los bloques, los más recientes primero
+func (r *bloqueGrupoRepository) GetAll() ([]domain.BloqueGrupo, error) {
+	return queryBloquesGrupo(r.db, selectBloqueGrupo+` ORDER BY gb.check_in_date DESC, gb.group_block_id DESC`)
+}
+
+// GetByID obtiene un bloque por su ID
+func (r *bloqueGrupoRepository) GetByID(id int) (*domain.BloqueGrupo, error) {
+	return getBloqueGrupo(r.db, id)
+}
+
+// getBloqueGrupo obtiene un bloque por su ID con la conexión o transacción dada
+func getBloqueGrupo(db dbtx, id int) (*domain.BloqueGrupo, error) {
+	bloque, err := scanBloqueGrupo(db.QueryRow(selectBloqueGrupo+` WHERE gb.group_block_id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: ID %d", domain.ErrBloqueGrupoNoEncontrado, id)
+		}
+		return nil, fmt.Errorf("error al obtener bloque de grupo: %w", err)
+	}
+
+	return bloque, nil
+}
+
+// GetActivos obtiene los bloques activos cuyas fechas se solapan con [desde, hasta)
+func (r *bloqueGrupoRepository) GetActivos(desde, hasta time.Time) ([]domain.BloqueGrupo, error) {
+	query := selectBloqueGrupo + `
+		WHERE gb.status = 'Activo'
+		AND gb.check_in_date < $2
+		AND gb.check_out_date > $1
+		ORDER BY gb.group_block_id
+	`
+
+	return queryBloquesGrupo(r.db, query, desde, hasta)
+}
+
+// Create registra un nuevo bloque
+func (r *bloqueGrupoRepository) Create(bloque *domain.BloqueGrupo) error {
+	query := `
+		INSERT INTO group_block (
+			name,
+			contact_name,
+			contact_email,
+			room_type_id,
+			quantity,
+			check_in_date,
+			check_out_date,
+			release_date,
+			negotiated_price,
+			status,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING group_block_id
+	`
+
+	err := r.db.QueryRow(
+		query,
+		bloque.Nombre,
+		bloque.Contacto,
+		bloque.Email,
+		bloque.TipoHabitacionID,
+		bloque.Cantidad,
+		bloque.FechaEntrada,
+		bloque.FechaSalida,
+		bloque.FechaLiberacion,
+		bloque.Precio,
+		bloque.Estado,
+		bloque.FechaCreacion,
+	).Scan(&bloque.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear bloque de grupo: %w", err)
+	}
+
+	return nil
+}
+
+// Update actualiza los datos de un bloque
+func (r *bloqueGrupoRepository) Update(bloque *domain.BloqueGrupo) error {
+	query := `
+		UPDATE group_block
+		SET name = $1,
+			contact_name = $2,
+			contact_email = $3,
+			quantity = $4,
+			check_in_date = $5,
+			check_out_date = $6,
+			release_date = $7,
+			negotiated_price = $8
+		WHERE group_block_id = $9
+	`
+
+	result, err := r.db.Exec(
+		query,
+		bloque.Nombre,
+		bloque.Contacto,
+		bloque.Email,
+		bloque.Cantidad,
+		bloque.FechaEntrada,
+		bloque.FechaSalida,
+		bloque.FechaLiberacion,
+		bloque.Precio,
+		bloque.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar bloque de grupo: %w", err)
+	}
+
+	if filas, err := result.RowsAffected(); err == nil && filas == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrBloqueGrupoNoEncontrado, bloque.ID)
+	}
+
+	return nil
+}
+
+// Liberar devuelve a la venta general las habitaciones no recogidas del bloque
+func (r *bloqueGrupoRepository) Liberar(id int, ahora time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE group_block SET status = $1, released_at = $2 WHERE group_block_id = $3 AND status = $4`,
+		domain.BloqueLiberado, ahora, id, domain.BloqueActivo,
+	)
+	if err != nil {
+		return fmt.Errorf("error al liberar bloque de grupo: %w", err)
+	}
+
+	if filas, err := result.RowsAffected(); err == nil && filas == 0 {
+		return fmt.Errorf("%w: el bloque %d no existe o ya fue liberado", domain.ErrBloqueGrupoNoEncontrado, id)
+	}
+
+	return nil
+}
+
+// LiberarVencidos libera los bloques activos cuya fecha de liberación es hoy o anterior
+func (r *bloqueGrupoRepository) LiberarVencidos(hoy, ahora time.Time) ([]int, error) {
+	query := `
+		UPDATE group_block
+		SET status = $1, released_at = $2
+		WHERE status = $3
+		AND release_date <= $4::date
+		RETURNING group_block_id
+	`
+
+	rows, err := r.db.Query(query, domain.BloqueLiberado, ahora, domain.BloqueActivo, hoy)
+	if err != nil {
+		return nil, fmt.Errorf("error al liberar bloques de grupo vencidos: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al escanear bloque liberado: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar bloques liberados: %w", err)
+	}
+
+	return ids, nil
+}
+
+// verificarCupoBloque serializa las recogidas del bloque y verifica, ya con el lock tomado, que
+// siga activo y tenga cupo para las habitaciones de la reserva, del tipo y fechas del bloque
+func verificarCupoBloque(tx dbtx, bloqueID int, habitaciones []domain.ReservaHabitacion) error {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, advisoryLockBloqueGrupo, bloqueID); err != nil {
+		return fmt.Errorf("error al bloquear bloque de grupo %d: %w", bloqueID, err)
+	}
+
+	bloque, err := getBloqueGrupo(tx, bloqueID)
+	if err != nil {
+		return err
+	}
+
+	if bloque.Pendientes() < len(habitaciones) {
+		return fmt.Errorf("%w: quedan %d de %d habitaciones en el bloque %s",
+			domain.ErrBloqueGrupoSinCupo, bloque.Pendientes(), bloque.Cantidad, bloque.Nombre)
+	}
+
+	var otroTipo int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM room WHERE room_id = ANY($1) AND room_type_id <> $2`,
+		pq.Array(idsHabitaciones(habitaciones)), bloque.TipoHabitacionID,
+	).Scan(&otroTipo)
+	if err != nil {
+		return fmt.Errorf("error al verificar tipo de habitación del bloque: %w", err)
+	}
+	if otroTipo > 0 {
+		return fmt.Errorf("%w: las habitaciones deben ser del tipo del bloque", domain.ErrBloqueGrupoSinCupo)
+	}
+
+	for _, hab := range habitaciones {
+		if hab.FechaEntrada.Before(bloque.FechaEntrada) || hab.FechaSalida.After(bloque.FechaSalida) {
+			return fmt.Errorf("%w: las fechas deben estar entre %s y %s",
+				domain.ErrBloqueGrupoSinCupo,
+				bloque.FechaEntrada.Format("2006-01-02"),
+				bloque.FechaSalida.Format("2006-01-02"),
+			)
+		}
+	}
+
+	return nil
+}
+
+// verificarRetencionBloques serializa por tipo de habitación las reservas que toman habitaciones y
+// verifica, ya con el lock tomado y con las habitaciones de la reserva insertadas, que cada noche
+// queden libres (sin reserva ni bloqueo de un calendario externo) al menos las habitaciones que
+// los bloques de grupo activos aún no recogieron.
+// El lock por habitación no basta: dos reservas de habitaciones distintas del mismo tipo podían
+// comerse juntas el cupo de un bloque
+func verificarRetencionBloques(tx dbtx, habitaciones []domain.ReservaHabitacion) error {
+	rangos, err := rangosPorTipo(tx, habitaciones)
+	if err != nil {
+		return err
+	}
+
+	tipos := make([]int, 0, len(rangos))
+	for tipo := range rangos {
+		tipos = append(tipos, tipo)
+	}
+	sort.Ints(tipos)
+
+	for _, tipo := range tipos {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, advisoryLockTipoHabitacion, tipo); err != nil {
+			return fmt.Errorf("error al bloquear tipo de habitación %d: %w", tipo, err)
+		}
+	}
+
+	query := `
+		SELECT n.noche, libres.cantidad, apartadas.cantidad
+		FROM generate_series($2::date, $3::date - 1, interval '1 day') AS n(noche)
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS cantidad
+			FROM room h
+			WHERE h.room_type_id = $1
+			AND h.status::text = $4
+			AND h.housekeeping_status <> $5
+			AND NOT EXISTS (
+				SELECT 1
+				FROM reservation_room rh
+				INNER JOIN reservation r ON r.reservation_id = rh.reservation_id
+				WHERE rh.room_id = h.room_id
+				AND rh.status = 1
+				AND r.status::text = ANY($6)
+				AND (r.status::text <> 'Pendiente' OR r.hold_expires_at IS NULL OR r.hold_expires_at > $7)
+				AND rh.check_in_date::date <= n.noche
+				AND rh.check_out_date::date > n.noche
+			)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM external_block eb
+				INNER JOIN external_calendar ec ON ec.external_calendar_id = eb.external_calendar_id
+				WHERE ec.active
+				AND eb.room_id = h.room_id
+				AND eb.start_date <= n.noche
+				AND eb.end_date > n.noche
+			)
+		) libres
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM(GREATEST(gb.quantity - (
+				SELECT COUNT(*)
+				FROM reservation_room rr
+				INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+				WHERE r.group_block_id = gb.group_block_id
+				AND rr.status = 1
+				AND r.status NOT IN ('Cancelada', 'NoShow')
+			), 0)), 0) AS cantidad
+			FROM group_block gb
+			WHERE gb.room_type_id = $1
+			AND gb.status = $8
+			AND gb.check_in_date <= n.noche
+			AND gb.check_out_date > n.noche
+		) apartadas
+		WHERE libres.cantidad < apartadas.cantidad
+		ORDER BY n.noche
+		LIMIT 1
+	`
+
+	for _, tipo := range tipos {
+		rango := rangos[tipo]
+		var noche time.Time
+		var libres, apartadas int
+		err := tx.QueryRow(query,
+			tipo,
+			rango[0],
+			rango[1],
+			domain.EstadoHabitacionDisponible,
+			domain.HabitacionFueraDeServicio,
+			pq.Array(estadosQueRetienenInventario()),
+			time.Now().UTC(),
+			domain.BloqueActivo,
+		).Scan(&noche, &libres, &apartadas)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al verificar habitaciones apartadas por bloques: %w", err)
+		}
+
+		return fmt.Errorf("%w: la noche del %s las habitaciones libres del tipo %d están apartadas por bloques de grupo",
+			domain.ErrHabitacionNoDisponible, noche.Format("2006-01-02"), tipo)
+	}
+
+	return nil
+}
+
+// rangosPorTipo agrupa las habitaciones por tipo y retorna, para cada uno, la primera entrada y
+// la última salida
+func rangosPorTipo(tx dbtx, habitaciones []domain.ReservaHabitacion) (map[int][2]time.Time, error) {
+	rows, err := tx.Query(`SELECT room_id, room_type_id FROM room WHERE room_id = ANY($1)`, pq.Array(idsHabitaciones(habitaciones)))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tipos de habitación: %w", err)
+	}
+	defer rows.Close()
+
+	tipoHabitacion := make(map[int]int)
+	for rows.Next() {
+		var habitacionID, tipoID int
+		if err := rows.Scan(&habitacionID, &tipoID); err != nil {
+			return nil, fmt.Errorf("error al escanear tipo de habitación: %w", err)
+		}
+		tipoHabitacion[habitacionID] = tipoID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar tipos de habitación: %w", err)
+	}
+
+	rangos := make(map[int][2]time.Time)
+	for _, hab := range habitaciones {
+		tipo := tipoHabitacion[hab.HabitacionID]
+		rango, ok := rangos[tipo]
+		if !ok || hab.FechaEntrada.Before(rango[0]) {
+			rango[0] = hab.FechaEntrada
+		}
+		if !ok || hab.FechaSalida.After(rango[1]) {
+			rango[1] = hab.FechaSalida
+		}
+		rangos[tipo] = rango
+	}
+
+	return rangos, nil
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type calendarioExternoRepository struct {
+	db dbtx
+}
+
+// NewCalendarioExternoRepository crea una nueva instancia del repositorio de calendarios externos
+func NewCalendarioExternoRepository(db *sql.DB) domain.CalendarioExternoRepository {
+	return &calendarioExternoRepository{db: db}
+}
+
+const calendarioExternoColumns = `
+	external_calendar_id,
+	room_id,
+	name,
+	COALESCE(url, ''),
+	COALESCE(ics_content, ''),
+	active,
+	last_synced_at,
+	last_error,
+	created_at`
+
+const bloqueoExternoColumns = `
+	eb.external_block_id,
+	eb.external_calendar_id,
+	eb.room_id,
+	eb.uid,
+	eb.start_date,
+	eb.end_date,
+	eb.summary`
+
+// GetAll obtiene los calendarios de una habitación (0 = todos)
+func (r *calendarioExternoRepository) GetAll(habitacionID int) ([]domain.CalendarioExterno, error) {
+	query := `SELECT ` + calendarioExternoColumns + `
+		FROM external_calendar
+		WHERE ($1 = 0 OR room_id = $1)
+		ORDER BY room_id, external_calendar_id`
+
+	return r.queryCalendarios(query, habitacionID)
+}
+
+// GetActivos obtiene los calendarios activos para sincronizarlos
+func (r *calendarioExternoRepository) GetActivos() ([]domain.CalendarioExterno, error) {
+	query := `SELECT ` + calendarioExternoColumns + `
+		FROM external_calendar
+		WHERE active
+		ORDER BY external_calendar_id`
+
+	return r.queryCalendarios(query)
+}
+
+// GetByID obtiene un calendario por su ID
+func (r *calendarioExternoRepository) GetByID(id int) (*domain.CalendarioExterno, error) {
+	query := `SELECT ` + calendarioExternoColumns + `
+		FROM external_calendar
+		WHERE external_calendar_id = $1`
+
+	calendario, err := scanCalendarioExterno(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrCalendarioExternoNoEncontrado, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return calendario, nil
+}
+
+// queryCalendarios ejecuta una consulta que retorna las columnas de calendarioExternoColumns
+func (r *calendarioExternoRepository) queryCalendarios(query string, args ...interface{}) ([]domain.CalendarioExterno, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener calendarios externos: %w", err)
+	}
+	defer rows.Close()
+
+	calendarios := make([]domain.CalendarioExterno, 0)
+	for rows.Next() {
+		calendario, err := scanCalendarioExterno(rows)
+		if err != nil {
+			return nil, err
+		}
+		calendarios = append(calendarios, *calendario)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar calendarios externos: %w", err)
+	}
+
+	return calendarios, nil
+}
+
+// Create crea un nuevo calendario
+func (r *calendarioExternoRepository) Create(calendario *domain.CalendarioExterno) error {
+	query := `
+		INSERT INTO external_calendar (
+			room_id,
+			name,
+			url,
+			ics_content,
+			active
+		) VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		RETURNING external_calendar_id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		calendario.HabitacionID,
+		calendario.Nombre,
+		calendario.URL,
+		calendario.Contenido,
+		calendario.Activo,
+	).Scan(&calendario.ID, &calendario.CreadoEn)
+	if err != nil {
+		return fmt.Errorf("error al crear calendario externo: %w", err)
+	}
+
+	return nil
+}
+
+// Update actualiza un calendario existente
+func (r *calendarioExternoRepository) Update(calendario *domain.CalendarioExterno) error {
+	query := `
+		UPDATE external_calendar
+		SET room_id = $1,
+			name = $2,
+			url = NULLIF($3, ''),
+			ics_content = NULLIF($4, ''),
+			active = $5
+		WHERE external_calendar_id = $6`
+
+	result, err := r.db.Exec(
+		query,
+		calendario.HabitacionID,
+		calendario.Nombre,
+		calendario.URL,
+		calendario.Contenido,
+		calendario.Activo,
+		calendario.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar calendario externo: %w", err)
+	}
+
+	return verificarCalendarioExternoAfectado(result, calendario.ID)
+}
+
+// Delete elimina un calendario y sus bloqueos
+func (r *calendarioExternoRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM external_calendar WHERE external_calendar_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar calendario externo: %w", err)
+	}
+
+	return verificarCalendarioExternoAfectado(result, id)
+}
+
+// GuardarSincronizacion reemplaza los bloqueos del calendario y registra la sincronización
+// exitosa en una sola transacción
+func (r *calendarioExternoRepository) GuardarSincronizacion(calendarioID int, bloqueos []domain.BloqueoExterno, fecha time.Time) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		result, err := tx.Exec(`
+			UPDATE external_calendar
+			SET last_synced_at = $1,
+				last_error = ''
+			WHERE external_calendar_id = $2`, fecha, calendarioID)
+		if err != nil {
+			return fmt.Errorf("error al registrar sincronización: %w", err)
+		}
+		if err := verificarCalendarioExternoAfectado(result, calendarioID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM external_block WHERE external_calendar_id = $1`, calendarioID); err != nil {
+			return fmt.Errorf("error al eliminar bloqueos externos: %w", err)
+		}
+
+		stmt, err := tx.Prepare(`
+			INSERT INTO external_block (
+				external_calendar_id,
+				room_id,
+				uid,
+				start_date,
+				end_date,
+				summary
+			) VALUES ($1, $2, $3, $4, $5, $6)`)
+		if err != nil {
+			return fmt.Errorf("error al preparar bloqueos externos: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, b := range bloqueos {
+			if _, err := stmt.Exec(calendarioID, b.HabitacionID, b.UID, b.FechaInicio, b.FechaFin, b.Resumen); err != nil {
+				return fmt.Errorf("error al guardar bloqueo externo %q: %w", b.UID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RegistrarErrorSincronizacion registra una sincronización fallida sin tocar los bloqueos
+func (r *calendarioExternoRepository) RegistrarErrorSincronizacion(calendarioID int, fecha time.Time, mensaje string) error {
+	result, err := r.db.Exec(`
+		UPDATE external_calendar
+		SET last_synced_at = $1,
+			last_error = $2
+		WHERE external_calendar_id = $3`, fecha, mensaje, calendarioID)
+	if err != nil {
+		return fmt.Errorf("error al registrar error de sincronización: %w", err)
+	}
+
+	return verificarCalendarioExternoAfectado(result, calendarioID)
+}
+
+// GetBloqueos obtiene los bloqueos de un calendario
+func (r *calendarioExternoRepository) GetBloqueos(calendarioID int) ([]domain.BloqueoExterno, error) {
+	query := `SELECT ` + bloqueoExternoColumns + `
+		FROM external_block eb
+		WHERE eb.external_calendar_id = $1
+		ORDER BY eb.start_date, eb.external_block_id`
+
+	return r.queryBloqueos(query, calendarioID)
+}
+
+// GetBloqueosEnRango obtiene los bloqueos de calendarios activos que se solapan con [desde, hasta)
+func (r *calendarioExternoRepository) GetBloqueosEnRango(desde, hasta time.Time) ([]domain.BloqueoExterno, error) {
+	query := `SELECT ` + bloqueoExternoColumns + `
+		FROM external_block eb
+		INNER JOIN external_calendar ec ON ec.external_calendar_id = eb.external_calendar_id
+		WHERE ec.active
+		AND eb.start_date < $2
+		AND eb.end_date > $1
+		ORDER BY eb.room_id, eb.start_date`
+
+	return r.queryBloqueos(query, desde, hasta)
+}
+
+// queryBloqueos ejecuta una consulta que retorna las columnas de bloqueoExternoColumns
+func (r *calendarioExternoRepository) queryBloqueos(query string, args ...interface{}) ([]domain.BloqueoExterno, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener bloqueos externos: %w", err)
+	}
+	defer rows.Close()
+
+	bloqueos := make([]domain.BloqueoExterno, 0)
+	for rows.Next() {
+		var b domain.BloqueoExterno
+		err := rows.Scan(&b.ID, &b.CalendarioExternoID, &b.HabitacionID, &b.UID, &b.FechaInicio, &b.FechaFin, &b.Resumen)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear bloqueo externo: %w", err)
+		}
+		b.FechaInicio = b.FechaInicio.UTC()
+		b.FechaFin = b.FechaFin.UTC()
+		bloqueos = append(bloqueos, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar bloqueos externos: %w", err)
+	}
+
+	return bloqueos, nil
+}
+
+// verificarCalendarioExternoAfectado retorna ErrCalendarioExternoNoEncontrado si la operación
+// no afectó ninguna fila
+func verificarCalendarioExternoAfectado(result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrCalendarioExternoNoEncontrado, id)
+	}
+
+	return nil
+}
+
+// scanCalendarioExterno escanea una fila con las columnas de calendarioExternoColumns
+func scanCalendarioExterno(row rowScanner) (*domain.CalendarioExterno, error) {
+	var calendario domain.CalendarioExterno
+	var sincronizado sql.NullTime
+
+	err := row.Scan(
+		&calendario.ID,
+		&calendario.HabitacionID,
+		&calendario.Nombre,
+		&calendario.URL,
+		&calendario.Contenido,
+		&calendario.Activo,
+		&sincronizado,
+		&calendario.UltimoError,
+		&calendario.CreadoEn,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear calendario externo: %w", err)
+	}
+
+	if sincronizado.Valid {
+		calendario.UltimaSincronizacion = &sincronizado.Time
+	}
+
+	return &calendario, nil
+}
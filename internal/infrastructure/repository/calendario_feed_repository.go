@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type calendarioFeedRepository struct {
+	db dbtx
+}
+
+// NewCalendarioFeedRepository crea una nueva instancia del repositorio de feeds iCal
+func NewCalendarioFeedRepository(db *sql.DB) domain.CalendarioFeedRepository {
+	return &calendarioFeedRepository{db: db}
+}
+
+// columnaCalendarioFeed retorna la columna que identifica el recurso del feed según su alcance
+func columnaCalendarioFeed(alcance domain.AlcanceCalendario) (string, error) {
+	switch alcance {
+	case domain.CalendarioHabitacion:
+		return "room_id", nil
+	case domain.CalendarioTipoHabitacion:
+		return "room_type_id", nil
+	}
+	return "", fmt.Errorf("alcance de calendario desconocido: %s", alcance)
+}
+
+// Get obtiene el feed de una habitación o tipo de habitación
+func (r *calendarioFeedRepository) Get(alcance domain.AlcanceCalendario, recursoID int) (*domain.CalendarioFeed, error) {
+	columna, err := columnaCalendarioFeed(alcance)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT calendar_feed_id, token, created_at
+		FROM calendar_feed
+		WHERE ` + columna + ` = $1`
+
+	feed := domain.CalendarioFeed{Alcance: alcance, RecursoID: recursoID}
+	err = r.db.QueryRow(query, recursoID).Scan(&feed.ID, &feed.Token, &feed.CreadoEn)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s %d", domain.ErrCalendarioNoEncontrado, alcance, recursoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener feed de calendario: %w", err)
+	}
+
+	return &feed, nil
+}
+
+// Save crea el feed o reemplaza su token si ya existe
+func (r *calendarioFeedRepository) Save(feed *domain.CalendarioFeed) error {
+	columna, err := columnaCalendarioFeed(feed.Alcance)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO calendar_feed (` + columna + `, token)
+		VALUES ($1, $2)
+		ON CONFLICT (` + columna + `) WHERE ` + columna + ` IS NOT NULL
+		DO UPDATE SET token = EXCLUDED.token,
+			created_at = NOW() AT TIME ZONE 'UTC'
+		RETURNING calendar_feed_id, created_at`
+
+	err = r.db.QueryRow(query, feed.RecursoID, feed.Token).Scan(&feed.ID, &feed.CreadoEn)
+	if err != nil {
+		return fmt.Errorf("error al guardar feed de calendario: %w", err)
+	}
+
+	return nil
+}
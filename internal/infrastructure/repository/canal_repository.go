@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type canalRepository struct {
+	db dbtx
+}
+
+// NewCanalRepository crea una nueva instancia del repositorio de canales de venta
+func NewCanalRepository(db *sql.DB) domain.CanalRepository {
+	return &canalRepository{db: db}
+}
+
+// Los mapeos se leen como dos arreglos paralelos ordenados por tipo de habitación
+const canalColumns = `
+	c.channel_id,
+	c.code,
+	c.name,
+	c.active,
+	c.last_synced_at,
+	c.last_error,
+	COALESCE(ARRAY(
+		SELECT m.room_type_id FROM channel_room_mapping m
+		WHERE m.channel_id = c.channel_id
+		ORDER BY m.room_type_id
+	), '{}'),
+	COALESCE(ARRAY(
+		SELECT m.external_code FROM channel_room_mapping m
+		WHERE m.channel_id = c.channel_id
+		ORDER BY m.room_type_id
+	), '{}')`
+
+// GetAll obtiene todos los canales con sus mapeos
+func (r *canalRepository) GetAll() ([]domain.Canal, error) {
+	query := `SELECT ` + canalColumns + `
+		FROM channel c
+		ORDER BY c.channel_id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener canales: %w", err)
+	}
+	defer rows.Close()
+
+	canales := make([]domain.Canal, 0)
+	for rows.Next() {
+		canal, err := scanCanal(rows)
+		if err != nil {
+			return nil, err
+		}
+		canales = append(canales, *canal)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar canales: %w", err)
+	}
+
+	return canales, nil
+}
+
+// GetByID obtiene un canal por su ID
+func (r *canalRepository) GetByID(id int) (*domain.Canal, error) {
+	query := `SELECT ` + canalColumns + `
+		FROM channel c
+		WHERE c.channel_id = $1`
+
+	canal, err := scanCanal(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrCanalNoEncontrado, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return canal, nil
+}
+
+// Create crea un canal con sus mapeos
+func (r *canalRepository) Create(canal *domain.Canal) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		err := tx.QueryRow(`
+			INSERT INTO channel (code, name, active)
+			VALUES ($1, $2, $3)
+			RETURNING channel_id`,
+			canal.Codigo,
+			canal.Nombre,
+			canal.Activo,
+		).Scan(&canal.ID)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: el código %s ya existe", domain.ErrCanalInvalido, canal.Codigo)
+		}
+		if err != nil {
+			return fmt.Errorf("error al crear canal: %w", err)
+		}
+
+		return guardarMapeosCanal(tx, canal)
+	})
+}
+
+// Update actualiza un canal y reemplaza sus mapeos
+func (r *canalRepository) Update(canal *domain.Canal) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		result, err := tx.Exec(`
+			UPDATE channel
+			SET code = $1,
+				name = $2,
+				active = $3
+			WHERE channel_id = $4`,
+			canal.Codigo,
+			canal.Nombre,
+			canal.Activo,
+			canal.ID,
+		)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: el código %s ya existe", domain.ErrCanalInvalido, canal.Codigo)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar canal: %w", err)
+		}
+		if err := verificarCanalAfectado(result, canal.ID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM channel_room_mapping WHERE channel_id = $1`, canal.ID); err != nil {
+			return fmt.Errorf("error al actualizar mapeos del canal: %w", err)
+		}
+
+		return guardarMapeosCanal(tx, canal)
+	})
+}
+
+// RegistrarSincronizacion guarda la fecha y el error de la última sincronización
+func (r *canalRepository) RegistrarSincronizacion(id int, fecha time.Time, mensaje string) error {
+	result, err := r.db.Exec(`
+		UPDATE channel
+		SET last_synced_at = $1,
+			last_error = $2
+		WHERE channel_id = $3`, fecha, mensaje, id)
+	if err != nil {
+		return fmt.Errorf("error al registrar sincronización del canal: %w", err)
+	}
+
+	return verificarCanalAfectado(result, id)
+}
+
+// guardarMapeosCanal registra los códigos externos de los tipos de habitación del canal
+func guardarMapeosCanal(tx dbtx, canal *domain.Canal) error {
+	if len(canal.Mapeos) == 0 {
+		return nil
+	}
+
+	tipos := make([]int64, len(canal.Mapeos))
+	codigos := make([]string, len(canal.Mapeos))
+	for i, m := range canal.Mapeos {
+		tipos[i] = int64(m.TipoHabitacionID)
+		codigos[i] = m.CodigoExterno
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO channel_room_mapping (channel_id, room_type_id, external_code)
+		SELECT $1, t.room_type_id, t.external_code
+		FROM UNNEST($2::int[], $3::text[]) AS t(room_type_id, external_code)
+	`, canal.ID, pq.Array(tipos), pq.Array(codigos))
+	if err != nil {
+		return fmt.Errorf("error al guardar mapeos del canal: %w", err)
+	}
+
+	return nil
+}
+
+// verificarCanalAfectado retorna ErrCanalNoEncontrado si la operación no afectó ninguna fila
+func verificarCanalAfectado(result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrCanalNoEncontrado, id)
+	}
+
+	return nil
+}
+
+// scanCanal escanea una fila con las columnas de canalColumns
+func scanCanal(row rowScanner) (*domain.Canal, error) {
+	var canal domain.Canal
+	var sincronizado sql.NullTime
+	var tipos pq.Int64Array
+	var codigos pq.StringArray
+
+	err := row.Scan(
+		&canal.ID,
+		&canal.Codigo,
+		&canal.Nombre,
+		&canal.Activo,
+		&sincronizado,
+		&canal.UltimoError,
+		&tipos,
+		&codigos,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear canal: %w", err)
+	}
+
+	if sincronizado.Valid {
+		canal.UltimaSincronizacion = &sincronizado.Time
+	}
+	canal.Mapeos = make([]domain.MapeoCanal, 0, len(tipos))
+	for i := range tipos {
+		canal.Mapeos = append(canal.Mapeos, domain.MapeoCanal{
+			TipoHabitacionID: int(tipos[i]),
+			CodigoExterno:    codigos[i],
+		})
+	}
+
+	return &canal, nil
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type clientRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewClientRepository crea una nueva instancia del repositorio de clientes
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type codigoPromocionalRepository struct {
+	db dbtx
+}
+
+// NewCodigoPromocionalRepository crea una nueva instancia del repositorio de códigos promocionales
+func NewCodigoPromocionalRepository(db *sql.DB) domain.CodigoPromocionalRepository {
+	return &codigoPromocionalRepository{db: db}
+}
+
+// Los canjes de reservas canceladas liberan el uso del código
+const codigoPromocionalColumns = `
+	pc.promo_code_id,
+	pc.campaign_id,
+	pc.code,
+	pc.description,
+	pc.discount_type,
+	pc.discount_value,
+	pc.valid_from,
+	pc.valid_to,
+	pc.min_nights,
+	pc.max_uses,
+	(
+		SELECT COUNT(*) FROM promo_code_redemption pr
+		INNER JOIN reservation r ON r.reservation_id = pr.reservation_id
+		WHERE pr.promo_code_id = pc.promo_code_id AND r.status NOT IN ('Cancelada', 'NoShow')
+	),
+	COALESCE(ARRAY(
+		SELECT pt.room_type_id FROM promo_code_room_type pt
+		WHERE pt.promo_code_id = pc.promo_code_id
+		ORDER BY pt.room_type_id
+	), '{}'),
+	pc.active`
+
+// GetAll obtiene los códigos de una campaña (0 = todas las campañas)
+func (r *codigoPromocionalRepository) GetAll(campanaID int) ([]domain.CodigoPromocional, error) {
+	query := `SELECT ` + codigoPromocionalColumns + `
+		FROM promo_code pc
+		WHERE ($1 = 0 OR pc.campaign_id = $1)
+		ORDER BY pc.campaign_id, pc.promo_code_id`
+
+	rows, err := r.db.Query(query, campanaID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener códigos promocionales: %w", err)
+	}
+	defer rows.Close()
+
+	codigos := make([]domain.CodigoPromocional, 0)
+	for rows.Next() {
+		codigo, err := scanCodigoPromocional(rows)
+		if err != nil {
+			return nil, err
+		}
+		codigos = append(codigos, *codigo)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar códigos promocionales: %w", err)
+	}
+
+	return codigos, nil
+}
+
+// GetByID obtiene un código por su ID
+func (r *codigoPromocionalRepository) GetByID(id int) (*domain.CodigoPromocional, error) {
+	query := `SELECT ` + codigoPromocionalColumns + `
+		FROM promo_code pc
+		WHERE pc.promo_code_id = $1`
+
+	codigo, err := scanCodigoPromocional(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrCodigoPromocionalNoEncontrado, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return codigo, nil
+}
+
+// GetByCodigo obtiene un código por su texto normalizado
+func (r *codigoPromocionalRepository) GetByCodigo(texto string) (*domain.CodigoPromocional, error) {
+	query := `SELECT ` + codigoPromocionalColumns + `
+		FROM promo_code pc
+		WHERE pc.code = $1`
+
+	codigo, err := scanCodigoPromocional(r.db.QueryRow(query, domain.NormalizarCodigo(texto)))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCodigoPromocionalNoEncontrado, texto)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return codigo, nil
+}
+
+// Create crea un código con sus tipos de habitación
+func (r *codigoPromocionalRepository) Create(codigo *domain.CodigoPromocional) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		if err := verificarCampana(tx, codigo.CampanaID); err != nil {
+			return err
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO promo_code (
+				campaign_id,
+				code,
+				description,
+				discount_type,
+				discount_value,
+				valid_from,
+				valid_to,
+				min_nights,
+				max_uses,
+				active
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING promo_code_id`,
+			codigo.CampanaID,
+			codigo.Codigo,
+			codigo.Descripcion,
+			codigo.TipoDescuento,
+			codigo.Valor,
+			codigo.VigenteDesde,
+			codigo.VigenteHasta,
+			codigo.NochesMinimas,
+			codigo.UsosMaximos,
+			codigo.Activo,
+		).Scan(&codigo.ID)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: el código %s ya existe", domain.ErrCodigoPromocionalInvalido, codigo.Codigo)
+		}
+		if err != nil {
+			return fmt.Errorf("error al crear código promocional: %w", err)
+		}
+
+		return guardarTiposCodigo(tx, codigo)
+	})
+}
+
+// Update actualiza un código y reemplaza sus tipos de habitación
+func (r *codigoPromocionalRepository) Update(codigo *domain.CodigoPromocional) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		if err := verificarCampana(tx, codigo.CampanaID); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE promo_code
+			SET campaign_id = $1,
+				code = $2,
+				description = $3,
+				discount_type = $4,
+				discount_value = $5,
+				valid_from = $6,
+				valid_to = $7,
+				min_nights = $8,
+				max_uses = $9,
+				active = $10
+			WHERE promo_code_id = $11`,
+			codigo.CampanaID,
+			codigo.Codigo,
+			codigo.Descripcion,
+			codigo.TipoDescuento,
+			codigo.Valor,
+			codigo.VigenteDesde,
+			codigo.VigenteHasta,
+			codigo.NochesMinimas,
+			codigo.UsosMaximos,
+			codigo.Activo,
+			codigo.ID,
+		)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: el código %s ya existe", domain.ErrCodigoPromocionalInvalido, codigo.Codigo)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar código promocional: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error al verificar filas afectadas: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("%w: ID %d", domain.ErrCodigoPromocionalNoEncontrado, codigo.ID)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM promo_code_room_type WHERE promo_code_id = $1`, codigo.ID); err != nil {
+			return fmt.Errorf("error al actualizar tipos de habitación del código: %w", err)
+		}
+
+		return guardarTiposCodigo(tx, codigo)
+	})
+}
+
+// RegistrarCanje registra el uso del código en una reserva
+func (r *codigoPromocionalRepository) RegistrarCanje(canje *domain.CanjeCodigoPromocional) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		// Bloquear el código serializa los canjes concurrentes hasta el fin de la transacción
+		var usosMaximos sql.NullInt64
+		err := tx.QueryRow(`
+			SELECT max_uses FROM promo_code WHERE promo_code_id = $1 FOR UPDATE
+		`, canje.CodigoPromocionalID).Scan(&usosMaximos)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: ID %d", domain.ErrCodigoPromocionalNoEncontrado, canje.CodigoPromocionalID)
+		}
+		if err != nil {
+			return fmt.Errorf("error al bloquear código promocional: %w", err)
+		}
+
+		if usosMaximos.Valid {
+			var usos int64
+			err := tx.QueryRow(`
+				SELECT COUNT(*) FROM promo_code_redemption pr
+				INNER JOIN reservation r ON r.reservation_id = pr.reservation_id
+				WHERE pr.promo_code_id = $1 AND r.status NOT IN ('Cancelada', 'NoShow')
+			`, canje.CodigoPromocionalID).Scan(&usos)
+			if err != nil {
+				return fmt.Errorf("error al contar usos del código promocional: %w", err)
+			}
+			if usos >= usosMaximos.Int64 {
+				return fmt.Errorf("%w: ID %d", domain.ErrCodigoPromocionalAgotado, canje.CodigoPromocionalID)
+			}
+		}
+
+		err = tx.QueryRow(`
+			INSERT INTO promo_code_redemption (promo_code_id, reservation_id, amount, redeemed_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING redemption_id`,
+			canje.CodigoPromocionalID,
+			canje.ReservaID,
+			canje.Monto,
+			canje.Fecha,
+		).Scan(&canje.ID)
+		if err != nil {
+			return fmt.Errorf("error al registrar canje del código promocional: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetReporteCampana resume los canjes de los códigos de una campaña
+func (r *codigoPromocionalRepository) GetReporteCampana(campanaID int) (*domain.ReporteCampana, error) {
+	reporte := &domain.ReporteCampana{CampanaID: campanaID}
+	err := r.db.QueryRow(`
+		SELECT name, start_date, end_date FROM campaign WHERE campaign_id = $1
+	`, campanaID).Scan(&reporte.Nombre, &reporte.FechaInicio, &reporte.FechaFin)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrCampanaNoEncontrada, campanaID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener campaña: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			pc.promo_code_id,
+			pc.code,
+			COUNT(r.reservation_id) FILTER (WHERE r.status <> 'Cancelada'),
+			COUNT(r.reservation_id) FILTER (WHERE r.status = 'Cancelada'),
+			COALESCE(SUM(pr.amount) FILTER (WHERE r.status <> 'Cancelada'), 0),
+			COALESCE(SUM(r.subtotal - r.discount) FILTER (WHERE r.status <> 'Cancelada'), 0)
+		FROM promo_code pc
+		LEFT JOIN promo_code_redemption pr ON pr.promo_code_id = pc.promo_code_id
+		LEFT JOIN reservation r ON r.reservation_id = pr.reservation_id
+		WHERE pc.campaign_id = $1
+		GROUP BY pc.promo_code_id, pc.code
+		ORDER BY pc.promo_code_id
+	`, campanaID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener canjes de la campaña: %w", err)
+	}
+	defer rows.Close()
+
+	reporte.Codigos = make([]domain.ResumenCodigoPromocional, 0)
+	for rows.Next() {
+		var resumen domain.ResumenCodigoPromocional
+		if err := rows.Scan(
+			&resumen.CodigoPromocionalID,
+			&resumen.Codigo,
+			&resumen.Canjes,
+			&resumen.Cancelados,
+			&resumen.MontoDescontado,
+			&resumen.Ingresos,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear canjes de la campaña: %w", err)
+		}
+		reporte.Codigos = append(reporte.Codigos, resumen)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar canjes de la campaña: %w", err)
+	}
+
+	reporte.Totalizar()
+	return reporte, nil
+}
+
+// verificarCampana retorna ErrCampanaNoEncontrada si la campaña no existe
+func verificarCampana(tx dbtx, campanaID int) error {
+	var existe bool
+	err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM campaign WHERE campaign_id = $1)`, campanaID).Scan(&existe)
+	if err != nil {
+		return fmt.Errorf("error al verificar campaña: %w", err)
+	}
+	if !existe {
+		return fmt.Errorf("%w: ID %d", domain.ErrCampanaNoEncontrada, campanaID)
+	}
+	return nil
+}
+
+// guardarTiposCodigo registra los tipos de habitación a los que se restringe el código
+func guardarTiposCodigo(tx dbtx, codigo *domain.CodigoPromocional) error {
+	if len(codigo.TiposHabitacion) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO promo_code_room_type (promo_code_id, room_type_id)
+		SELECT $1, UNNEST($2::int[])
+		ON CONFLICT DO NOTHING
+	`, codigo.ID, pq.Array(codigo.TiposHabitacion))
+	if err != nil {
+		return fmt.Errorf("error al guardar tipos de habitación del código: %w", err)
+	}
+
+	return nil
+}
+
+// isUniqueViolation indica si el error proviene de una restricción UNIQUE
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// scanCodigoPromocional escanea una fila con las columnas de codigoPromocionalColumns
+func scanCodigoPromocional(row rowScanner) (*domain.CodigoPromocional, error) {
+	var codigo domain.CodigoPromocional
+	var usosMaximos sql.NullInt64
+	var tipos pq.Int64Array
+
+	err := row.Scan(
+		&codigo.ID,
+		&codigo.CampanaID,
+		&codigo.Codigo,
+		&codigo.Descripcion,
+		&codigo.TipoDescuento,
+		&codigo.Valor,
+		&codigo.VigenteDesde,
+		&codigo.VigenteHasta,
+		&codigo.NochesMinimas,
+		&usosMaximos,
+		&codigo.Usos,
+		&tipos,
+		&codigo.Activo,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear código promocional: %w", err)
+	}
+
+	if usosMaximos.Valid {
+		usos := int(usosMaximos.Int64)
+		codigo.UsosMaximos = &usos
+	}
+	codigo.VigenteDesde = codigo.VigenteDesde.UTC()
+	codigo.VigenteHasta = codigo.VigenteHasta.UTC()
+
+	codigo.TiposHabitacion = make([]int, len(tipos))
+	for i, id := range tipos {
+		codigo.TiposHabitacion[i] = int(id)
+	}
+
+	return &codigo, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type estanciaRepository struct {
+	db dbtx
+}
+
+// NewEstanciaRepository crea una nueva instancia del repositorio de check-in/check-out
+func NewEstanciaRepository(db *sql.DB) domain.EstanciaRepository {
+	return &estanciaRepository{db: db}
+}
+
+// RegistrarCheckIn guarda la hora de llegada de la reserva y registra a todos sus huéspedes
+func (r *estanciaRepository) RegistrarCheckIn(reservaID int, llegada time.Time) ([]int, error) {
+	result, err := r.db.Exec(
+		`UPDATE reservation SET checked_in_at = $1 WHERE reservation_id = $2`,
+		llegada, reservaID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al registrar check-in: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("reserva con ID %d no encontrada", reservaID)
+	}
+
+	rows, err := r.db.Query(`
+		UPDATE reservation_guest
+		SET checked_in_at = $1
+		WHERE reservation_id = $2
+		RETURNING person_id
+	`, llegada, reservaID)
+	if err != nil {
+		return nil, fmt.Errorf("error al registrar huéspedes: %w", err)
+	}
+	defer rows.Close()
+
+	huespedes := make([]int, 0)
+	for rows.Next() {
+		var personID int
+		if err := rows.Scan(&personID); err != nil {
+			return nil, fmt.Errorf("error al escanear huésped: %w", err)
+		}
+		huespedes = append(huespedes, personID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar huéspedes: %w", err)
+	}
+
+	return huespedes, nil
+}
+
+// RegistrarCheckOut guarda la hora de salida, cierra el folio de la reserva y recorta sus
+// habitaciones al día de salida
+func (r *estanciaRepository) RegistrarCheckOut(reservaID int, salida time.Time) error {
+	result, err := r.db.Exec(`
+		UPDATE reservation
+		SET checked_out_at = $1, folio_closed_at = $1
+		WHERE reservation_id = $2
+	`, salida, reservaID)
+	if err != nil {
+		return fmt.Errorf("error al registrar check-out: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("reserva con ID %d no encontrada", reservaID)
+	}
+
+	// En una salida anticipada las noches restantes vuelven al inventario: la reserva completada
+	// ya no las retiene y la restricción reservation_room_no_overlap no debe seguir bloqueándolas.
+	// El precio no cambia, las noches no usadas no se reembolsan
+	hoy := time.Date(salida.Year(), salida.Month(), salida.Day(), 0, 0, 0, 0, time.UTC)
+	_, err = r.db.Exec(`
+		UPDATE reservation_room
+		SET check_out_date = GREATEST(check_in_date, $1)
+		WHERE reservation_id = $2
+		AND status = 1
+		AND check_out_date > $1
+	`, hoy, reservaID)
+	if err != nil {
+		return fmt.Errorf("error al liberar noches restantes: %w", err)
+	}
+
+	return nil
+}
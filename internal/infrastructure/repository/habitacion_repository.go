@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/Maxito7/hotel_backend/internal/domain"
 )
@@ -30,6 +29,7 @@ func (r *habitacionRepository) GetAllRooms() ([]domain.Habitacion, error) {
 			h.capacity,
 			h.status,
 			h.general_description,
+			h.housekeeping_status,
 			t.room_type_id,
 			t.title,
 			t.description,
@@ -61,6 +61,7 @@ func (r *habitacionRepository) GetAllRooms() ([]domain.Habitacion, error) {
 			&h.Capacidad,
 			&h.Estado,
 			&h.DescripcionGeneral,
+			&h.EstadoLimpieza,
 			&h.TipoHabitacion.ID,
 			&h.TipoHabitacion.Titulo,
 			&h.TipoHabitacion.Descripcion,
@@ -105,246 +106,6 @@ func (r *habitacionRepository) GetAllRooms() ([]domain.Habitacion, error) {
 	return habitaciones, nil
 }
 
-// GetDisponibilidadFechas implementa domain.HabitacionRepository
-func (r *habitacionRepository) GetDisponibilidadFechas(desde, hasta time.Time) ([]domain.DisponibilidadFecha, error) {
-	query := `
-		WITH RECURSIVE fechas AS (
-			SELECT date(cast($1 as timestamp)) as fecha
-			UNION ALL
-			SELECT fecha + interval '1 day'
-			FROM fechas
-			WHERE fecha < date(cast($2 as timestamp))
-		),
-		habitaciones_totales AS (
-			SELECT COUNT(*) as total
-			FROM room
-			WHERE status = 'Disponible'
-		),
-		habitaciones_ocupadas AS (
-			SELECT date(f.fecha) as fecha, 
-				   COUNT(DISTINCT rh.room_id) as ocupadas
-			FROM fechas f
-			LEFT JOIN reservation_room rh ON 
-				date(f.fecha) BETWEEN date(rh.check_in_date) AND date(rh.check_out_date)
-			LEFT JOIN reservation r ON r.reservation_id = rh.reservation_id
-				AND rh.status = 1
-				AND r.status = 'Confirmada'
-			GROUP BY date(f.fecha)
-		)
-		SELECT 
-			f.fecha,
-			CASE 
-				WHEN (ht.total - COALESCE(ho.ocupadas, 0)) > 0 THEN true 
-				ELSE false 
-			END as disponible,
-			(ht.total - COALESCE(ho.ocupadas, 0)) as habitaciones_disponibles
-		FROM fechas f
-		CROSS JOIN habitaciones_totales ht
-		LEFT JOIN habitaciones_ocupadas ho ON date(f.fecha) = date(ho.fecha)
-		ORDER BY f.fecha;`
-
-	rows, err := r.db.Query(query, desde, hasta)
-	if err != nil {
-		return nil, fmt.Errorf("error querying disponibilidad: %w", err)
-	}
-	defer rows.Close()
-
-	var disponibilidades []domain.DisponibilidadFecha
-	for rows.Next() {
-		var d domain.DisponibilidadFecha
-		err := rows.Scan(&d.Fecha, &d.Disponible, &d.Habitaciones)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning disponibilidad: %w", err)
-		}
-		disponibilidades = append(disponibilidades, d)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating disponibilidad rows: %w", err)
-	}
-
-	return disponibilidades, nil
-}
-
-// GetFechasBloqueadas implementa domain.HabitacionRepository
-func (r *habitacionRepository) GetFechasBloqueadas(desde, hasta time.Time) (*domain.FechasBloqueadas, error) {
-	query := `
-		WITH RECURSIVE fechas AS (
-			SELECT cast($1 as date) as fecha
-			UNION ALL
-			SELECT (fecha + interval '1 day')::date
-			FROM fechas
-			WHERE fecha < cast($2 as date)
-		),
-		habitaciones_totales AS (
-			SELECT COUNT(*) as total
-			FROM room h
-			WHERE h.status = 'Disponible'
-		),
-		habitaciones_ocupadas AS (
-			SELECT date(f.fecha) as fecha, 
-				   COUNT(DISTINCT rh.room_id) as habitaciones_ocupadas
-			FROM fechas f
-			LEFT JOIN reservation_room rh ON 
-				f.fecha BETWEEN cast(rh.check_in_date as date) AND cast(rh.check_out_date as date)
-			LEFT JOIN reservation r ON r.reservation_id = rh.reservation_id
-				AND rh.status = 1
-				AND r.status = 'Confirmada'
-			GROUP BY f.fecha
-			HAVING COUNT(DISTINCT rh.room_id) >= (SELECT total FROM habitaciones_totales)
-		)
-		SELECT fecha::date
-		FROM habitaciones_ocupadas
-		ORDER BY fecha;`
-
-	rows, err := r.db.Query(query, desde, hasta)
-	if err != nil {
-		return nil, fmt.Errorf("error querying fechas bloqueadas: %w", err)
-	}
-	defer rows.Close()
-
-	fechasBloqueadas := &domain.FechasBloqueadas{
-		FechasNoDisponibles: make([]time.Time, 0),
-	}
-
-	for rows.Next() {
-		var fecha time.Time
-		if err := rows.Scan(&fecha); err != nil {
-			return nil, fmt.Errorf("error scanning fecha bloqueada: %w", err)
-		}
-		fechasBloqueadas.FechasNoDisponibles = append(fechasBloqueadas.FechasNoDisponibles, fecha)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating fechas bloqueadas: %w", err)
-	}
-
-	return fechasBloqueadas, nil
-}
-
-// GetAvailableRooms implements domain.HabitacionRepository
-func (r *habitacionRepository) GetAvailableRooms(fechaEntrada, fechaSalida time.Time) ([]domain.TipoHabitacion, error) {
-	query := `
-		SELECT DISTINCT
-			t.room_type_id,
-			t.title,
-			t.description,
-			t.adult_capacity,
-			t.children_capacity,
-			t.beds_count,
-			t.area,
-			t.price
-		FROM 
-			room_type t
-		WHERE EXISTS (
-			SELECT 1 FROM room h
-			WHERE h.room_type_id = t.room_type_id
-			AND h.status = 'Disponible'
-			AND NOT EXISTS (
-				SELECT 1 FROM reservation_room rh
-				JOIN reservation r ON r.reservation_id = rh.reservation_id
-				WHERE rh.room_id = h.room_id
-				AND rh.status = 1
-				AND r.status = 'Confirmada'
-				AND (
-					(rh.check_in_date <= $1 AND rh.check_out_date >= $1)
-					OR (rh.check_in_date <= $2 AND rh.check_out_date >= $2)
-					OR (rh.check_in_date >= $1 AND rh.check_out_date <= $2)
-				)
-			)
-		)
-		ORDER BY 
-			t.room_type_id;`
-
-	rows, err := r.db.Query(query, fechaEntrada, fechaSalida)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var roomTypes []domain.TipoHabitacion
-	for rows.Next() {
-		var rt domain.TipoHabitacion
-		err := rows.Scan(
-			&rt.ID,
-			&rt.Titulo,
-			&rt.Descripcion,
-			&rt.CapacidadAdultos,
-			&rt.CapacidadNinhos,
-			&rt.CantidadCamas,
-			&rt.Area,
-			&rt.Precio,
-		)
-		if err != nil {
-			return nil, err
-		}
-		roomTypes = append(roomTypes, rt)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	// Collect room type IDs
-	var typeIDs []int
-	for _, rt := range roomTypes {
-		typeIDs = append(typeIDs, rt.ID)
-	}
-
-	// Attach amenities and images for room types
-	amenitiesMap, err := r.getAmenitiesForRoomTypeIDs(typeIDs)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching amenities: %w", err)
-	}
-
-	imagesMap, err := r.getImagesForRoomTypeIDs(typeIDs)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching images: %w", err)
-	}
-
-	for i := range roomTypes {
-		if a, ok := amenitiesMap[roomTypes[i].ID]; ok {
-			roomTypes[i].Amenities = a
-		}
-		if imgs, ok := imagesMap[roomTypes[i].ID]; ok {
-			roomTypes[i].Images = imgs
-		}
-	}
-
-	return roomTypes, nil
-}
-
-// FindAvailableRoomByType implements domain.HabitacionRepository
-func (r *habitacionRepository) FindAvailableRoomByType(roomTypeID int, fechaEntrada, fechaSalida time.Time) (int, error) {
-	query := `
-		SELECT h.room_id
-		FROM room h
-		WHERE h.room_type_id = $1
-		AND h.status = 'Disponible'
-		AND NOT EXISTS (
-			SELECT 1 FROM reservation_room rh
-			JOIN reservation r ON r.reservation_id = rh.reservation_id
-			WHERE rh.room_id = h.room_id
-			AND rh.status = 1
-			AND r.status = 'Confirmada'
-			AND (
-				(rh.check_in_date <= $2 AND rh.check_out_date >= $2)
-				OR (rh.check_in_date <= $3 AND rh.check_out_date >= $3)
-				OR (rh.check_in_date >= $2 AND rh.check_out_date <= $3)
-			)
-		)
-		ORDER BY h.room_id
-		LIMIT 1;`
-
-	var roomID int
-	err := r.db.QueryRow(query, roomTypeID, fechaEntrada, fechaSalida).Scan(&roomID)
-	if err != nil {
-		return 0, fmt.Errorf("no se encontró habitación disponible del tipo %d: %w", roomTypeID, err)
-	}
-
-	return roomID, nil
-}
-
 // GetRoomTypes implements domain.HabitacionRepository
 func (r *habitacionRepository) GetRoomTypes() ([]domain.TipoHabitacion, error) {
 	query := `
@@ -503,13 +264,13 @@ func (r *habitacionRepository) DeleteRoom(id int) error {
 // GetRoomByID retorna una habitación con su tipo y relaciones del tipo
 func (r *habitacionRepository) GetRoomByID(id int) (domain.Habitacion, error) {
 	query := `
-		SELECT room_id, name, number, capacity, status, general_description, room_type_id
+		SELECT room_id, name, number, capacity, status, general_description, housekeeping_status, room_type_id
 		FROM room
 		WHERE room_id = $1;`
 
 	var h domain.Habitacion
 	var roomTypeID int
-	err := r.db.QueryRow(query, id).Scan(&h.ID, &h.Nombre, &h.Numero, &h.Capacidad, &h.Estado, &h.DescripcionGeneral, &roomTypeID)
+	err := r.db.QueryRow(query, id).Scan(&h.ID, &h.Nombre, &h.Numero, &h.Capacidad, &h.Estado, &h.DescripcionGeneral, &h.EstadoLimpieza, &roomTypeID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return h, fmt.Errorf("room not found: %w", err)
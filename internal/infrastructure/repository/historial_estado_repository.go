@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type historialEstadoRepository struct {
+	db dbtx
+}
+
+// NewHistorialEstadoRepository crea una nueva instancia del repositorio de historial de estados
+func NewHistorialEstadoRepository(db *sql.DB) domain.HistorialEstadoRepository {
+	return &historialEstadoRepository{db: db}
+}
+
+// Create registra un cambio de estado
+func (r *historialEstadoRepository) Create(historial *domain.HistorialEstadoReserva) error {
+	query := `
+		INSERT INTO reservation_status_history (
+			reservation_id,
+			previous_status,
+			new_status,
+			changed_at,
+			actor,
+			reason
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+		RETURNING history_id
+	`
+
+	err := r.db.QueryRow(
+		query,
+		historial.ReservaID,
+		historial.EstadoAnterior,
+		historial.EstadoNuevo,
+		historial.Fecha,
+		historial.Actor,
+		historial.Motivo,
+	).Scan(&historial.ID)
+	if err != nil {
+		return fmt.Errorf("error al registrar historial de estado: %w", err)
+	}
+
+	return nil
+}
+
+// GetByReservaID obtiene el historial de una reserva en orden cronológico
+func (r *historialEstadoRepository) GetByReservaID(reservaID int) ([]domain.HistorialEstadoReserva, error) {
+	query := `
+		SELECT
+			history_id,
+			reservation_id,
+			previous_status,
+			new_status,
+			changed_at,
+			actor,
+			COALESCE(reason, '')
+		FROM reservation_status_history
+		WHERE reservation_id = $1
+		ORDER BY changed_at, history_id
+	`
+
+	rows, err := r.db.Query(query, reservaID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener historial de estados: %w", err)
+	}
+	defer rows.Close()
+
+	historial := make([]domain.HistorialEstadoReserva, 0)
+	for rows.Next() {
+		var h domain.HistorialEstadoReserva
+		var estadoAnterior sql.NullString
+
+		err := rows.Scan(
+			&h.ID,
+			&h.ReservaID,
+			&estadoAnterior,
+			&h.EstadoNuevo,
+			&h.Fecha,
+			&h.Actor,
+			&h.Motivo,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear historial de estado: %w", err)
+		}
+
+		if estadoAnterior.Valid {
+			anterior := domain.EstadoReserva(estadoAnterior.String)
+			h.EstadoAnterior = &anterior
+		}
+		historial = append(historial, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar historial de estados: %w", err)
+	}
+
+	return historial, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type inventarioRepository struct {
+	db dbtx
+}
+
+// NewInventarioRepository crea una nueva instancia del repositorio de la matriz de inventario
+func NewInventarioRepository(db *sql.DB) domain.InventarioRepository {
+	return &inventarioRepository{db: db}
+}
+
+// GetInventario calcula toda la matriz tipo × noche en una sola consulta: las reservas, los
+// bloqueos externos y los bloques de grupo se expanden a sus noches con un join contra la serie
+// de fechas y se agregan por habitación antes de contarlos por tipo, de modo que una habitación
+// con varias ocupaciones la misma noche cuenta una sola vez (vendida si alguna no es una
+// retención pendiente). Los filtros por rango de cada fuente permiten usar los índices por fechas
+func (r *inventarioRepository) GetInventario(desde, hasta, ahora time.Time) ([]domain.InventarioTipoFecha, error) {
+	query := `
+		WITH noches AS (
+			SELECT n::date AS fecha
+			FROM generate_series($1::date, $2::date, INTERVAL '1 day') AS n
+		),
+		ocupaciones AS (
+			SELECT n.fecha, rr.room_id, r.status <> 'Pendiente' AS vendida
+			FROM reservation_room rr
+			INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+			INNER JOIN noches n ON n.fecha >= rr.check_in_date::date AND n.fecha < rr.check_out_date::date
+			WHERE rr.status = 1
+			AND rr.check_in_date < $2::date + 1
+			AND rr.check_out_date > $1::date
+			AND r.status = ANY($3)
+			AND (r.status <> 'Pendiente' OR r.hold_expires_at IS NULL OR r.hold_expires_at > $4)
+			UNION ALL
+			SELECT n.fecha, eb.room_id, FALSE
+			FROM external_block eb
+			INNER JOIN external_calendar ec ON ec.external_calendar_id = eb.external_calendar_id
+			INNER JOIN noches n ON n.fecha >= eb.start_date::date AND n.fecha < eb.end_date::date
+			WHERE ec.active
+			AND eb.start_date < $2::date + 1
+			AND eb.end_date > $1::date
+		),
+		ocupadas AS (
+			SELECT fecha, room_id, BOOL_OR(vendida) AS vendida
+			FROM ocupaciones
+			GROUP BY fecha, room_id
+		),
+		recogidas AS (
+			SELECT r.group_block_id, COUNT(*) AS cantidad
+			FROM reservation_room rr
+			INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+			WHERE r.group_block_id IS NOT NULL
+			AND rr.status = 1
+			AND r.status NOT IN ('Cancelada', 'NoShow')
+			GROUP BY r.group_block_id
+		),
+		bloques AS (
+			SELECT n.fecha, gb.room_type_id, SUM(gb.quantity - COALESCE(rc.cantidad, 0)) AS pendientes
+			FROM group_block gb
+			LEFT JOIN recogidas rc ON rc.group_block_id = gb.group_block_id
+			INNER JOIN noches n ON n.fecha >= gb.check_in_date::date AND n.fecha < gb.check_out_date::date
+			WHERE gb.status = 'Activo'
+			AND gb.check_in_date < $2::date + 1
+			AND gb.check_out_date > $1::date
+			AND gb.quantity > COALESCE(rc.cantidad, 0)
+			GROUP BY n.fecha, gb.room_type_id
+		)
+		SELECT
+			t.room_type_id,
+			t.title,
+			n.fecha,
+			COUNT(h.room_id),
+			COUNT(h.room_id) FILTER (WHERE h.status::text <> $5 OR h.housekeeping_status = $6),
+			COUNT(h.room_id) FILTER (WHERE h.status::text = $5 AND h.housekeeping_status <> $6 AND o.vendida),
+			COUNT(h.room_id) FILTER (WHERE h.status::text = $5 AND h.housekeeping_status <> $6 AND NOT o.vendida),
+			COALESCE(MAX(b.pendientes), 0)
+		FROM room_type t
+		CROSS JOIN noches n
+		LEFT JOIN room h ON h.room_type_id = t.room_type_id
+		LEFT JOIN ocupadas o ON o.room_id = h.room_id AND o.fecha = n.fecha
+		LEFT JOIN bloques b ON b.room_type_id = t.room_type_id AND b.fecha = n.fecha
+		GROUP BY t.room_type_id, t.title, n.fecha
+		ORDER BY t.room_type_id, n.fecha
+	`
+
+	rows, err := r.db.Query(query, desde, hasta, pq.Array(estadosQueRetienenInventario()), ahora, domain.EstadoHabitacionDisponible, domain.HabitacionFueraDeServicio)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener inventario: %w", err)
+	}
+	defer rows.Close()
+
+	inventario := make([]domain.InventarioTipoFecha, 0)
+	for rows.Next() {
+		var celda domain.InventarioTipoFecha
+		var retenidas, pendientesBloques int
+		if err := rows.Scan(
+			&celda.TipoHabitacionID,
+			&celda.TipoHabitacion,
+			&celda.Fecha,
+			&celda.Total,
+			&celda.FueraDeServicio,
+			&celda.Vendidas,
+			&retenidas,
+			&pendientesBloques,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear inventario: %w", err)
+		}
+		celda.Fecha = celda.Fecha.UTC()
+		celda.Retenidas = retenidas + pendientesBloques
+		inventario = append(inventario, celda)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar inventario: %w", err)
+	}
+
+	return inventario, nil
+}
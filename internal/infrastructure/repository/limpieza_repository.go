@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type limpiezaRepository struct {
+	db dbtx
+}
+
+// NewLimpiezaRepository crea una nueva instancia del repositorio de limpieza
+func NewLimpiezaRepository(db *sql.DB) domain.LimpiezaRepository {
+	return &limpiezaRepository{db: db}
+}
+
+const tareaLimpiezaColumns = `
+	k.task_id,
+	k.room_id,
+	k.reservation_id,
+	k.origin,
+	k.status,
+	k.staff_id,
+	k.notes,
+	k.created_at,
+	k.started_at,
+	k.finished_at`
+
+// GetTablero obtiene una fila por habitación con su estado, la ocupación del día y la tarea
+// abierta. La ocupación se toma de las reservas activas: en curso (huéspedes alojados), con
+// llegada prevista en la fecha y en curso con salida en la fecha
+func (r *limpiezaRepository) GetTablero(fecha time.Time) ([]domain.EstadoHabitacionTablero, error) {
+	query := `
+		SELECT
+			h.room_id,
+			h.number,
+			t.title,
+			h.status::text,
+			h.housekeeping_status,
+			COALESCE(h.out_of_service_reason, ''),
+			EXISTS (
+				SELECT 1 FROM reservation_room rr
+				INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+				WHERE rr.room_id = h.room_id AND rr.status = 1 AND r.status::text = $2
+			),
+			EXISTS (
+				SELECT 1 FROM reservation_room rr
+				INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+				WHERE rr.room_id = h.room_id AND rr.status = 1
+				AND r.status::text = ANY($3)
+				AND rr.check_in_date::date = $1::date
+			),
+			EXISTS (
+				SELECT 1 FROM reservation_room rr
+				INNER JOIN reservation r ON r.reservation_id = rr.reservation_id
+				WHERE rr.room_id = h.room_id AND rr.status = 1 AND r.status::text = $2
+				AND rr.check_out_date::date = $1::date
+			),
+			COALESCE(s.name, ''),
+			k.task_id,
+			k.room_id,
+			k.reservation_id,
+			k.origin,
+			k.status,
+			k.staff_id,
+			k.notes,
+			k.created_at,
+			k.started_at,
+			k.finished_at
+		FROM room h
+		INNER JOIN room_type t ON t.room_type_id = h.room_type_id
+		LEFT JOIN housekeeping_task k ON k.room_id = h.room_id AND k.status = ANY($4)
+		LEFT JOIN housekeeping_staff s ON s.staff_id = k.staff_id
+		ORDER BY h.number, h.room_id
+	`
+
+	llegadas := []string{string(domain.ReservaPendiente), string(domain.ReservaConfirmada)}
+	abiertas := []string{string(domain.TareaPendiente), string(domain.TareaEnCurso)}
+	rows, err := r.db.Query(query, fecha, domain.ReservaEnCurso, pq.Array(llegadas), pq.Array(abiertas))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tablero de limpieza: %w", err)
+	}
+	defer rows.Close()
+
+	tablero := make([]domain.EstadoHabitacionTablero, 0)
+	for rows.Next() {
+		var fila domain.EstadoHabitacionTablero
+		var (
+			tareaID, habitacionID, reservaID, personalID sql.NullInt64
+			origen, estado, notas                        sql.NullString
+			creada, iniciada, terminada                  sql.NullTime
+		)
+		if err := rows.Scan(
+			&fila.HabitacionID,
+			&fila.Numero,
+			&fila.TipoHabitacion,
+			&fila.Estado,
+			&fila.EstadoLimpieza,
+			&fila.MotivoFueraDeServicio,
+			&fila.Ocupada,
+			&fila.LlegadaHoy,
+			&fila.SalidaHoy,
+			&fila.Personal,
+			&tareaID,
+			&habitacionID,
+			&reservaID,
+			&origen,
+			&estado,
+			&personalID,
+			&notas,
+			&creada,
+			&iniciada,
+			&terminada,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear tablero de limpieza: %w", err)
+		}
+
+		if tareaID.Valid {
+			tarea := domain.TareaLimpieza{
+				ID:           int(tareaID.Int64),
+				HabitacionID: int(habitacionID.Int64),
+				Origen:       domain.OrigenTareaLimpieza(origen.String),
+				Estado:       domain.EstadoTareaLimpieza(estado.String),
+				Notas:        notas.String,
+				CreadaEn:     creada.Time,
+			}
+			completarTareaLimpieza(&tarea, reservaID, personalID, iniciada, terminada)
+			fila.Tarea = &tarea
+		}
+		tablero = append(tablero, fila)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar tablero de limpieza: %w", err)
+	}
+
+	return tablero, nil
+}
+
+// GetEstadoHabitacion obtiene el estado de limpieza de una habitación
+func (r *limpiezaRepository) GetEstadoHabitacion(habitacionID int) (domain.EstadoLimpieza, error) {
+	var estado domain.EstadoLimpieza
+	err := r.db.QueryRow(`SELECT housekeeping_status FROM room WHERE room_id = $1`, habitacionID).Scan(&estado)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: ID %d", domain.ErrHabitacionNoEncontrada, habitacionID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error al obtener estado de limpieza: %w", err)
+	}
+
+	return estado, nil
+}
+
+// CambiarEstadoHabitacion cambia el estado de limpieza; el motivo se borra al salir de FueraDeServicio
+func (r *limpiezaRepository) CambiarEstadoHabitacion(habitacionID int, estado domain.EstadoLimpieza, motivo string) error {
+	if estado != domain.HabitacionFueraDeServicio {
+		motivo = ""
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE room
+		SET housekeeping_status = $1,
+			out_of_service_reason = NULLIF($2, '')
+		WHERE room_id = $3`, estado, motivo, habitacionID)
+	if err != nil {
+		return fmt.Errorf("error al cambiar estado de limpieza: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrHabitacionNoEncontrada, habitacionID)
+	}
+
+	return nil
+}
+
+// RegistrarSalidas marca sucias las habitaciones y crea sus tareas de limpieza. Las habitaciones
+// fuera de servicio no cambian; el índice de una tarea abierta por habitación evita duplicarlas
+func (r *limpiezaRepository) RegistrarSalidas(reservaID int, habitacionIDs []int, fecha time.Time) error {
+	if len(habitacionIDs) == 0 {
+		return nil
+	}
+
+	return runInTx(r.db, func(tx dbtx) error {
+		_, err := tx.Exec(`
+			UPDATE room
+			SET housekeeping_status = $1
+			WHERE room_id = ANY($2)
+			AND housekeeping_status <> $3`,
+			domain.HabitacionSucia, pq.Array(habitacionIDs), domain.HabitacionFueraDeServicio,
+		)
+		if err != nil {
+			return fmt.Errorf("error al actualizar estado de limpieza: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO housekeeping_task (room_id, reservation_id, origin, status, created_at)
+			SELECT h.room_id, $1, $2, $3, $4
+			FROM room h
+			WHERE h.room_id = ANY($5)
+			AND h.housekeeping_status <> $6
+			ON CONFLICT DO NOTHING`,
+			reservaID, domain.TareaPorSalida, domain.TareaPendiente, fecha,
+			pq.Array(habitacionIDs), domain.HabitacionFueraDeServicio,
+		)
+		if err != nil {
+			return fmt.Errorf("error al crear tareas de limpieza: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetTareas obtiene las tareas que cumplen el filtro, las abiertas primero y luego las más recientes
+func (r *limpiezaRepository) GetTareas(filtro domain.FiltroTareasLimpieza) ([]domain.TareaLimpieza, error) {
+	query := `SELECT ` + tareaLimpiezaColumns + `
+		FROM housekeeping_task k
+		WHERE ($1 = '' OR k.status = $1)
+		AND ($2 = 0 OR k.room_id = $2)
+		AND ($3 = 0 OR k.staff_id = $3)
+		ORDER BY k.status NOT IN ($4, $5), k.created_at DESC, k.task_id DESC`
+
+	rows, err := r.db.Query(query,
+		string(filtro.Estado), filtro.HabitacionID, filtro.PersonalID,
+		domain.TareaPendiente, domain.TareaEnCurso,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tareas de limpieza: %w", err)
+	}
+	defer rows.Close()
+
+	tareas := make([]domain.TareaLimpieza, 0)
+	for rows.Next() {
+		tarea, err := scanTareaLimpieza(rows)
+		if err != nil {
+			return nil, err
+		}
+		tareas = append(tareas, *tarea)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar tareas de limpieza: %w", err)
+	}
+
+	return tareas, nil
+}
+
+// GetTareaByID obtiene una tarea por su ID
+func (r *limpiezaRepository) GetTareaByID(id int) (*domain.TareaLimpieza, error) {
+	query := `SELECT ` + tareaLimpiezaColumns + `
+		FROM housekeeping_task k
+		WHERE k.task_id = $1`
+
+	tarea, err := scanTareaLimpieza(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrTareaLimpiezaNoEncontrada, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tarea, nil
+}
+
+// CreateTarea crea una tarea. Si la habitación ya tiene una tarea abierta retorna ErrLimpiezaInvalida
+func (r *limpiezaRepository) CreateTarea(tarea *domain.TareaLimpieza) error {
+	err := r.db.QueryRow(`
+		INSERT INTO housekeeping_task (room_id, reservation_id, origin, status, staff_id, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING task_id`,
+		tarea.HabitacionID,
+		tarea.ReservaID,
+		tarea.Origen,
+		tarea.Estado,
+		tarea.PersonalID,
+		tarea.Notas,
+		tarea.CreadaEn,
+	).Scan(&tarea.ID)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: la habitación %d ya tiene una tarea abierta", domain.ErrLimpiezaInvalida, tarea.HabitacionID)
+	}
+	if err != nil {
+		return fmt.Errorf("error al crear tarea de limpieza: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTarea guarda el estado, la asignación, las notas y las fechas de una tarea
+func (r *limpiezaRepository) UpdateTarea(tarea *domain.TareaLimpieza) error {
+	return actualizarTareaLimpieza(r.db, tarea)
+}
+
+// TerminarTarea cierra la tarea y deja limpia la habitación, salvo que esté fuera de servicio
+func (r *limpiezaRepository) TerminarTarea(tarea *domain.TareaLimpieza) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		if err := actualizarTareaLimpieza(tx, tarea); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(`
+			UPDATE room
+			SET housekeeping_status = $1
+			WHERE room_id = $2
+			AND housekeeping_status <> $3`,
+			domain.HabitacionLimpia, tarea.HabitacionID, domain.HabitacionFueraDeServicio,
+		)
+		if err != nil {
+			return fmt.Errorf("error al actualizar estado de limpieza: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetPersonal obtiene el personal de limpieza ordenado por nombre
+func (r *limpiezaRepository) GetPersonal() ([]domain.PersonalLimpieza, error) {
+	rows, err := r.db.Query(`SELECT staff_id, name, active FROM housekeeping_staff ORDER BY name, staff_id`)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener personal de limpieza: %w", err)
+	}
+	defer rows.Close()
+
+	personal := make([]domain.PersonalLimpieza, 0)
+	for rows.Next() {
+		var p domain.PersonalLimpieza
+		if err := rows.Scan(&p.ID, &p.Nombre, &p.Activo); err != nil {
+			return nil, fmt.Errorf("error al escanear personal de limpieza: %w", err)
+		}
+		personal = append(personal, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar personal de limpieza: %w", err)
+	}
+
+	return personal, nil
+}
+
+// GetPersonalByID obtiene un miembro del personal por su ID
+func (r *limpiezaRepository) GetPersonalByID(id int) (*domain.PersonalLimpieza, error) {
+	var p domain.PersonalLimpieza
+	err := r.db.QueryRow(`SELECT staff_id, name, active FROM housekeeping_staff WHERE staff_id = $1`, id).
+		Scan(&p.ID, &p.Nombre, &p.Activo)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrPersonalLimpiezaNoEncontrado, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener personal de limpieza: %w", err)
+	}
+
+	return &p, nil
+}
+
+// CreatePersonal registra un miembro del personal
+func (r *limpiezaRepository) CreatePersonal(personal *domain.PersonalLimpieza) error {
+	err := r.db.QueryRow(`
+		INSERT INTO housekeeping_staff (name, active)
+		VALUES ($1, $2)
+		RETURNING staff_id`,
+		personal.Nombre,
+		personal.Activo,
+	).Scan(&personal.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear personal de limpieza: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePersonal actualiza un miembro del personal
+func (r *limpiezaRepository) UpdatePersonal(personal *domain.PersonalLimpieza) error {
+	result, err := r.db.Exec(`
+		UPDATE housekeeping_staff
+		SET name = $1,
+			active = $2
+		WHERE staff_id = $3`,
+		personal.Nombre,
+		personal.Activo,
+		personal.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar personal de limpieza: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrPersonalLimpiezaNoEncontrado, personal.ID)
+	}
+
+	return nil
+}
+
+// actualizarTareaLimpieza guarda los campos modificables de una tarea
+func actualizarTareaLimpieza(db dbtx, tarea *domain.TareaLimpieza) error {
+	result, err := db.Exec(`
+		UPDATE housekeeping_task
+		SET status = $1,
+			staff_id = $2,
+			notes = $3,
+			started_at = $4,
+			finished_at = $5
+		WHERE task_id = $6`,
+		tarea.Estado,
+		tarea.PersonalID,
+		tarea.Notas,
+		tarea.IniciadaEn,
+		tarea.TerminadaEn,
+		tarea.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar tarea de limpieza: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrTareaLimpiezaNoEncontrada, tarea.ID)
+	}
+
+	return nil
+}
+
+// scanTareaLimpieza escanea una fila con las columnas de tareaLimpiezaColumns
+func scanTareaLimpieza(row rowScanner) (*domain.TareaLimpieza, error) {
+	var tarea domain.TareaLimpieza
+	var reservaID, personalID sql.NullInt64
+	var iniciada, terminada sql.NullTime
+
+	err := row.Scan(
+		&tarea.ID,
+		&tarea.HabitacionID,
+		&reservaID,
+		&tarea.Origen,
+		&tarea.Estado,
+		&personalID,
+		&tarea.Notas,
+		&tarea.CreadaEn,
+		&iniciada,
+		&terminada,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear tarea de limpieza: %w", err)
+	}
+
+	completarTareaLimpieza(&tarea, reservaID, personalID, iniciada, terminada)
+	return &tarea, nil
+}
+
+// completarTareaLimpieza asigna a la tarea las columnas que admiten NULL
+func completarTareaLimpieza(tarea *domain.TareaLimpieza, reservaID, personalID sql.NullInt64, iniciada, terminada sql.NullTime) {
+	if reservaID.Valid {
+		id := int(reservaID.Int64)
+		tarea.ReservaID = &id
+	}
+	if personalID.Valid {
+		id := int(personalID.Int64)
+		tarea.PersonalID = &id
+	}
+	if iniciada.Valid {
+		tarea.IniciadaEn = &iniciada.Time
+	}
+	if terminada.Valid {
+		tarea.TerminadaEn = &terminada.Time
+	}
+}
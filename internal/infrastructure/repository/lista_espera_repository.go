@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type listaEsperaRepository struct {
+	db dbtx
+}
+
+// NewListaEsperaRepository crea una nueva instancia del repositorio de lista de espera
+func NewListaEsperaRepository(db *sql.DB) domain.ListaEsperaRepository {
+	return &listaEsperaRepository{db: db}
+}
+
+const selectListaEspera = `
+	SELECT
+		waitlist_id,
+		room_type_id,
+		check_in_date,
+		check_out_date,
+		adults,
+		children,
+		name,
+		email,
+		phone,
+		status,
+		token,
+		notified_at,
+		link_expires_at,
+		reservation_id,
+		created_at
+	FROM waitlist
+`
+
+// scanInscripcion lee una fila de waitlist en una inscripción
+func scanInscripcion(row interface {
+	Scan(dest ...interface{}) error
+}) (*domain.InscripcionListaEspera, error) {
+	var (
+		inscripcion       domain.InscripcionListaEspera
+		telefono, token   sql.NullString
+		notificada, vence sql.NullTime
+		reservaID         sql.NullInt64
+	)
+
+	err := row.Scan(
+		&inscripcion.ID,
+		&inscripcion.TipoHabitacionID,
+		&inscripcion.FechaEntrada,
+		&inscripcion.FechaSalida,
+		&inscripcion.CantidadAdultos,
+		&inscripcion.CantidadNinhos,
+		&inscripcion.Nombre,
+		&inscripcion.Email,
+		&telefono,
+		&inscripcion.Estado,
+		&token,
+		&notificada,
+		&vence,
+		&reservaID,
+		&inscripcion.FechaCreacion,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if telefono.Valid {
+		inscripcion.Telefono = &telefono.String
+	}
+	if token.Valid {
+		inscripcion.Token = &token.String
+	}
+	if notificada.Valid {
+		inscripcion.FechaNotificacion = &notificada.Time
+	}
+	if vence.Valid {
+		inscripcion.VenceEnlace = &vence.Time
+	}
+	if reservaID.Valid {
+		id := int(reservaID.Int64)
+		inscripcion.ReservaID = &id
+	}
+
+	return &inscripcion, nil
+}
+
+// Create registra una nueva inscripción
+func (r *listaEsperaRepository) Create(inscripcion *domain.InscripcionListaEspera) error {
+	query := `
+		INSERT INTO waitlist (
+			room_type_id,
+			check_in_date,
+			check_out_date,
+			adults,
+			children,
+			name,
+			email,
+			phone,
+			status,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING waitlist_id
+	`
+
+	err := r.db.QueryRow(
+		query,
+		inscripcion.TipoHabitacionID,
+		inscripcion.FechaEntrada,
+		inscripcion.FechaSalida,
+		inscripcion.CantidadAdultos,
+		inscripcion.CantidadNinhos,
+		inscripcion.Nombre,
+		inscripcion.Email,
+		inscripcion.Telefono,
+		inscripcion.Estado,
+		inscripcion.FechaCreacion,
+	).Scan(&inscripcion.ID)
+	if err != nil {
+		return fmt.Errorf("error al registrar inscripción en lista de espera: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID obtiene una inscripción por su ID
+func (r *listaEsperaRepository) GetByID(id int) (*domain.InscripcionListaEspera, error) {
+	inscripcion, err := scanInscripcion(r.db.QueryRow(selectListaEspera+` WHERE waitlist_id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: ID %d", domain.ErrListaEsperaNoEncontrada, id)
+		}
+		return nil, fmt.Errorf("error al obtener inscripción en lista de espera: %w", err)
+	}
+
+	return inscripcion, nil
+}
+
+// GetByToken obtiene la inscripción a la que se envió el enlace con el token dado
+func (r *listaEsperaRepository) GetByToken(token string) (*domain.InscripcionListaEspera, error) {
+	inscripcion, err := scanInscripcion(r.db.QueryRow(selectListaEspera+` WHERE token = $1`, token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrListaEsperaNoEncontrada
+		}
+		return nil, fmt.Errorf("error al obtener inscripción por token: %w", err)
+	}
+
+	return inscripcion, nil
+}
+
+// GetActivas obtiene las inscripciones esperando o notificadas desde la fecha dada, en orden FIFO
+func (r *listaEsperaRepository) GetActivas(desde time.Time) ([]domain.InscripcionListaEspera, error) {
+	query := selectListaEspera + `
+		WHERE status IN ('Esperando', 'Notificada')
+		AND check_in_date >= $1::date
+		ORDER BY created_at, waitlist_id
+	`
+
+	rows, err := r.db.Query(query, desde)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener lista de espera: %w", err)
+	}
+	defer rows.Close()
+
+	var inscripciones []domain.InscripcionListaEspera
+	for rows.Next() {
+		inscripcion, err := scanInscripcion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear inscripción en lista de espera: %w", err)
+		}
+		inscripciones = append(inscripciones, *inscripcion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar lista de espera: %w", err)
+	}
+
+	return inscripciones, nil
+}
+
+// GetEnlacesVigentes obtiene las inscripciones notificadas con enlace vigente que se solapan con el rango
+func (r *listaEsperaRepository) GetEnlacesVigentes(desde, hasta, ahora time.Time) ([]domain.InscripcionListaEspera, error) {
+	query := selectListaEspera + `
+		WHERE status = $1
+		AND link_expires_at > $2
+		AND check_in_date < $4::date
+		AND check_out_date > $3::date
+		ORDER BY created_at, waitlist_id
+	`
+
+	rows, err := r.db.Query(query, domain.ListaEsperaNotificada, ahora, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener enlaces vigentes de lista de espera: %w", err)
+	}
+	defer rows.Close()
+
+	var inscripciones []domain.InscripcionListaEspera
+	for rows.Next() {
+		inscripcion, err := scanInscripcion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear inscripción en lista de espera: %w", err)
+		}
+		inscripciones = append(inscripciones, *inscripcion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar lista de espera: %w", err)
+	}
+
+	return inscripciones, nil
+}
+
+// MarcarNotificada registra el envío del enlace de reserva y su vencimiento
+func (r *listaEsperaRepository) MarcarNotificada(id int, token string, notificada, vence time.Time) error {
+	query := `
+		UPDATE waitlist
+		SET status = $1, token = $2, notified_at = $3, link_expires_at = $4
+		WHERE waitlist_id = $5
+	`
+
+	if _, err := r.db.Exec(query, domain.ListaEsperaNotificada, token, notificada, vence, id); err != nil {
+		return fmt.Errorf("error al marcar inscripción como notificada: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEstado cambia el estado de una inscripción
+func (r *listaEsperaRepository) UpdateEstado(id int, estado domain.EstadoListaEspera) error {
+	result, err := r.db.Exec(`UPDATE waitlist SET status = $1 WHERE waitlist_id = $2`, estado, id)
+	if err != nil {
+		return fmt.Errorf("error al actualizar inscripción en lista de espera: %w", err)
+	}
+
+	if filas, err := result.RowsAffected(); err == nil && filas == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrListaEsperaNoEncontrada, id)
+	}
+
+	return nil
+}
+
+// MarcarReservada registra la reserva creada con el enlace
+func (r *listaEsperaRepository) MarcarReservada(id, reservaID int) error {
+	query := `
+		UPDATE waitlist
+		SET status = $1, reservation_id = $2
+		WHERE waitlist_id = $3
+	`
+
+	if _, err := r.db.Exec(query, domain.ListaEsperaReservada, reservaID, id); err != nil {
+		return fmt.Errorf("error al marcar inscripción como reservada: %w", err)
+	}
+
+	return nil
+}
+
+// VencerEnlaces marca como vencidas las inscripciones notificadas cuyo enlace venció
+func (r *listaEsperaRepository) VencerEnlaces(ahora time.Time) ([]int, error) {
+	query := `
+		UPDATE waitlist
+		SET status = $1
+		WHERE status = $2
+		AND link_expires_at <= $3
+		RETURNING waitlist_id
+	`
+
+	rows, err := r.db.Query(query, domain.ListaEsperaVencida, domain.ListaEsperaNotificada, ahora)
+	if err != nil {
+		return nil, fmt.Errorf("error al vencer enlaces de lista de espera: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al escanear inscripción vencida: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar inscripciones vencidas: %w", err)
+	}
+
+	return ids, nil
+}
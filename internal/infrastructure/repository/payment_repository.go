@@ -8,7 +8,7 @@ import (
 )
 
 type paymentRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewPaymentRepository crea una nueva instancia del repositorio de pagos
@@ -57,6 +57,8 @@ func (r *paymentRepository) GetByReservationID(reservationID int) (*domain.Payme
 			reservation_id
 		FROM payment
 		WHERE reservation_id = $1
+		ORDER BY payment_id
+		LIMIT 1
 	`
 
 	payment := &domain.Payment{}
@@ -80,6 +82,51 @@ func (r *paymentRepository) GetByReservationID(reservationID int) (*domain.Payme
 	return payment, nil
 }
 
+// GetAllByReservationID obtiene todos los pagos y reembolsos de una reserva
+func (r *paymentRepository) GetAllByReservationID(reservationID int) ([]domain.Payment, error) {
+	query := `
+		SELECT 
+			payment_id,
+			amount,
+			date,
+			payment_method,
+			status,
+			reservation_id
+		FROM payment
+		WHERE reservation_id = $1
+		ORDER BY payment_id
+	`
+
+	rows, err := r.db.Query(query, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener pagos: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []domain.Payment
+	for rows.Next() {
+		var payment domain.Payment
+		err := rows.Scan(
+			&payment.PaymentID,
+			&payment.Amount,
+			&payment.Date,
+			&payment.PaymentMethod,
+			&payment.Status,
+			&payment.ReservationID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear pago: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar pagos: %w", err)
+	}
+
+	return payments, nil
+}
+
 // UpdateStatus actualiza el estado de un pago
 func (r *paymentRepository) UpdateStatus(paymentID int, status domain.PaymentStatus) error {
 	query := `
@@ -104,3 +151,22 @@ func (r *paymentRepository) UpdateStatus(paymentID int, status domain.PaymentSta
 
 	return nil
 }
+
+// UpdateAmount actualiza el monto de un pago
+func (r *paymentRepository) UpdateAmount(paymentID int, amount float64) error {
+	result, err := r.db.Exec(`UPDATE payment SET amount = $1 WHERE payment_id = $2`, amount, paymentID)
+	if err != nil {
+		return fmt.Errorf("error al actualizar monto del pago: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pago con ID %d no encontrado", paymentID)
+	}
+
+	return nil
+}
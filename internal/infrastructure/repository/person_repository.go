@@ -8,7 +8,7 @@ import (
 )
 
 type personRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewPersonRepository crea una nueva instancia del repositorio de personas
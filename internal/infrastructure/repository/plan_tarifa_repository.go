@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
+)
+
+type planTarifaRepository struct {
+	db dbtx
+}
+
+// NewPlanTarifaRepository crea una nueva instancia del repositorio de planes tarifarios
+func NewPlanTarifaRepository(db *sql.DB) domain.PlanTarifaRepository {
+	return &planTarifaRepository{db: db}
+}
+
+const planTarifaColumns = `
+	p.rate_plan_id,
+	p.room_type_id,
+	p.code,
+	p.name,
+	p.description,
+	p.modifier_type,
+	p.modifier_value,
+	p.free_cancellation_hours,
+	p.penalty_type,
+	p.penalty_value,
+	p.non_refundable,
+	p.active,
+	COALESCE(ARRAY(
+		SELECT ps.service_id FROM rate_plan_service ps
+		WHERE ps.rate_plan_id = p.rate_plan_id
+		ORDER BY ps.service_id
+	), '{}')`
+
+// GetAll obtiene los planes de un tipo de habitación (0 = todos los tipos)
+func (r *planTarifaRepository) GetAll(tipoHabitacionID int) ([]domain.PlanTarifa, error) {
+	query := `SELECT ` + planTarifaColumns + `
+		FROM rate_plan p
+		WHERE ($1 = 0 OR p.room_type_id = $1)
+		ORDER BY p.room_type_id, p.rate_plan_id`
+
+	return r.queryPlanes(query, tipoHabitacionID)
+}
+
+// GetActivos obtiene los planes activos de un tipo de habitación
+func (r *planTarifaRepository) GetActivos(tipoHabitacionID int) ([]domain.PlanTarifa, error) {
+	query := `SELECT ` + planTarifaColumns + `
+		FROM rate_plan p
+		WHERE p.active AND p.room_type_id = $1
+		ORDER BY p.rate_plan_id`
+
+	return r.queryPlanes(query, tipoHabitacionID)
+}
+
+// GetByID obtiene un plan por su ID
+func (r *planTarifaRepository) GetByID(id int) (*domain.PlanTarifa, error) {
+	query := `SELECT ` + planTarifaColumns + `
+		FROM rate_plan p
+		WHERE p.rate_plan_id = $1`
+
+	plan, err := scanPlanTarifa(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrPlanTarifaNoEncontrado, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// GetByCodigo obtiene el plan activo con el código dado para el tipo de habitación, o nil
+func (r *planTarifaRepository) GetByCodigo(tipoHabitacionID int, codigo string) (*domain.PlanTarifa, error) {
+	query := `SELECT ` + planTarifaColumns + `
+		FROM rate_plan p
+		WHERE p.active AND p.room_type_id = $1 AND p.code = $2`
+
+	plan, err := scanPlanTarifa(r.db.QueryRow(query, tipoHabitacionID, codigo))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// queryPlanes ejecuta una consulta que retorna las columnas de planTarifaColumns
+func (r *planTarifaRepository) queryPlanes(query string, args ...interface{}) ([]domain.PlanTarifa, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener planes tarifarios: %w", err)
+	}
+	defer rows.Close()
+
+	planes := make([]domain.PlanTarifa, 0)
+	for rows.Next() {
+		plan, err := scanPlanTarifa(rows)
+		if err != nil {
+			return nil, err
+		}
+		planes = append(planes, *plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar planes tarifarios: %w", err)
+	}
+
+	return planes, nil
+}
+
+// Create crea un nuevo plan con sus servicios incluidos
+func (r *planTarifaRepository) Create(plan *domain.PlanTarifa) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		horas, tipoPenalidad, valorPenalidad, noReembolsable := columnasCancelacion(plan.Cancelacion)
+
+		err := tx.QueryRow(`
+			INSERT INTO rate_plan (
+				room_type_id,
+				code,
+				name,
+				description,
+				modifier_type,
+				modifier_value,
+				free_cancellation_hours,
+				penalty_type,
+				penalty_value,
+				non_refundable,
+				active
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING rate_plan_id`,
+			plan.TipoHabitacionID,
+			plan.Codigo,
+			plan.Nombre,
+			plan.Descripcion,
+			plan.TipoModificador,
+			plan.ValorModificador,
+			horas,
+			tipoPenalidad,
+			valorPenalidad,
+			noReembolsable,
+			plan.Activo,
+		).Scan(&plan.ID)
+		if err != nil {
+			return fmt.Errorf("error al crear plan tarifario: %w", err)
+		}
+
+		return guardarServiciosPlan(tx, plan)
+	})
+}
+
+// Update actualiza un plan y reemplaza sus servicios incluidos
+func (r *planTarifaRepository) Update(plan *domain.PlanTarifa) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		horas, tipoPenalidad, valorPenalidad, noReembolsable := columnasCancelacion(plan.Cancelacion)
+
+		result, err := tx.Exec(`
+			UPDATE rate_plan
+			SET room_type_id = $1,
+				code = $2,
+				name = $3,
+				description = $4,
+				modifier_type = $5,
+				modifier_value = $6,
+				free_cancellation_hours = $7,
+				penalty_type = $8,
+				penalty_value = $9,
+				non_refundable = $10,
+				active = $11
+			WHERE rate_plan_id = $12`,
+			plan.TipoHabitacionID,
+			plan.Codigo,
+			plan.Nombre,
+			plan.Descripcion,
+			plan.TipoModificador,
+			plan.ValorModificador,
+			horas,
+			tipoPenalidad,
+			valorPenalidad,
+			noReembolsable,
+			plan.Activo,
+			plan.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("error al actualizar plan tarifario: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error al verificar filas afectadas: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("%w: ID %d", domain.ErrPlanTarifaNoEncontrado, plan.ID)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM rate_plan_service WHERE rate_plan_id = $1`, plan.ID); err != nil {
+			return fmt.Errorf("error al actualizar servicios del plan: %w", err)
+		}
+
+		return guardarServiciosPlan(tx, plan)
+	})
+}
+
+// guardarServiciosPlan registra los servicios incluidos del plan
+func guardarServiciosPlan(tx dbtx, plan *domain.PlanTarifa) error {
+	if len(plan.ServiciosIncluidos) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO rate_plan_service (rate_plan_id, service_id)
+		SELECT $1, UNNEST($2::int[])
+		ON CONFLICT DO NOTHING
+	`, plan.ID, pq.Array(plan.ServiciosIncluidos))
+	if err != nil {
+		return fmt.Errorf("error al guardar servicios del plan: %w", err)
+	}
+
+	return nil
+}
+
+// columnasCancelacion convierte las condiciones de cancelación del plan en columnas;
+// sin condiciones propias se guardan NULL para usar la política del tipo de habitación
+func columnasCancelacion(politica *domain.PoliticaCancelacion) (horas *int, tipoPenalidad *string, valorPenalidad *float64, noReembolsable bool) {
+	if politica == nil {
+		return nil, nil, nil, false
+	}
+
+	tipo := string(politica.TipoPenalidad)
+	return &politica.HorasCancelacionGratuita, &tipo, &politica.ValorPenalidad, politica.NoReembolsable
+}
+
+// scanPlanTarifa escanea una fila con las columnas de planTarifaColumns
+func scanPlanTarifa(row rowScanner) (*domain.PlanTarifa, error) {
+	var plan domain.PlanTarifa
+	var horas sql.NullInt64
+	var tipoPenalidad sql.NullString
+	var valorPenalidad sql.NullFloat64
+	var noReembolsable bool
+	var servicios pq.Int64Array
+
+	err := row.Scan(
+		&plan.ID,
+		&plan.TipoHabitacionID,
+		&plan.Codigo,
+		&plan.Nombre,
+		&plan.Descripcion,
+		&plan.TipoModificador,
+		&plan.ValorModificador,
+		&horas,
+		&tipoPenalidad,
+		&valorPenalidad,
+		&noReembolsable,
+		&plan.Activo,
+		&servicios,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear plan tarifario: %w", err)
+	}
+
+	if horas.Valid || noReembolsable {
+		tipoHabitacionID := plan.TipoHabitacionID
+		plan.Cancelacion = &domain.PoliticaCancelacion{
+			Nombre:                   plan.Nombre,
+			TipoHabitacionID:         &tipoHabitacionID,
+			HorasCancelacionGratuita: int(horas.Int64),
+			TipoPenalidad:            domain.TipoPenalidad(tipoPenalidad.String),
+			ValorPenalidad:           valorPenalidad.Float64,
+			NoReembolsable:           noReembolsable,
+			Activa:                   true,
+		}
+	}
+
+	plan.ServiciosIncluidos = make([]int, len(servicios))
+	for i, id := range servicios {
+		plan.ServiciosIncluidos[i] = int(id)
+	}
+
+	return &plan, nil
+}
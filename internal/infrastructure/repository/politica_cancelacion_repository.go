@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type politicaCancelacionRepository struct {
+	db *sql.DB
+}
+
+// NewPoliticaCancelacionRepository crea una nueva instancia del repositorio de políticas de cancelación
+func NewPoliticaCancelacionRepository(db *sql.DB) domain.PoliticaCancelacionRepository {
+	return &politicaCancelacionRepository{db: db}
+}
+
+const politicaCancelacionColumns = `
+	cancellation_policy_id,
+	name,
+	room_type_id,
+	free_cancellation_hours,
+	penalty_type,
+	penalty_value,
+	non_refundable,
+	active`
+
+// GetAll obtiene todas las políticas configuradas
+func (r *politicaCancelacionRepository) GetAll() ([]domain.PoliticaCancelacion, error) {
+	query := `SELECT ` + politicaCancelacionColumns + `
+		FROM cancellation_policy
+		ORDER BY room_type_id NULLS FIRST, cancellation_policy_id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener políticas de cancelación: %w", err)
+	}
+	defer rows.Close()
+
+	politicas := make([]domain.PoliticaCancelacion, 0)
+	for rows.Next() {
+		politica, err := scanPoliticaCancelacion(rows)
+		if err != nil {
+			return nil, err
+		}
+		politicas = append(politicas, *politica)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar políticas de cancelación: %w", err)
+	}
+
+	return politicas, nil
+}
+
+// GetParaTipoHabitacion obtiene la política activa del tipo de habitación o la general del hotel
+func (r *politicaCancelacionRepository) GetParaTipoHabitacion(tipoHabitacionID int) (*domain.PoliticaCancelacion, error) {
+	query := `SELECT ` + politicaCancelacionColumns + `
+		FROM cancellation_policy
+		WHERE active
+		AND (room_type_id = $1 OR room_type_id IS NULL)
+		ORDER BY room_type_id NULLS LAST
+		LIMIT 1`
+
+	politica, err := scanPoliticaCancelacion(r.db.QueryRow(query, tipoHabitacionID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return politica, nil
+}
+
+// Create crea una nueva política
+func (r *politicaCancelacionRepository) Create(politica *domain.PoliticaCancelacion) error {
+	query := `
+		INSERT INTO cancellation_policy (
+			name,
+			room_type_id,
+			free_cancellation_hours,
+			penalty_type,
+			penalty_value,
+			non_refundable,
+			active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING cancellation_policy_id`
+
+	err := r.db.QueryRow(
+		query,
+		politica.Nombre,
+		politica.TipoHabitacionID,
+		politica.HorasCancelacionGratuita,
+		politica.TipoPenalidad,
+		politica.ValorPenalidad,
+		politica.NoReembolsable,
+		politica.Activa,
+	).Scan(&politica.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear política de cancelación: %w", err)
+	}
+
+	return nil
+}
+
+// Update actualiza una política existente
+func (r *politicaCancelacionRepository) Update(politica *domain.PoliticaCancelacion) error {
+	query := `
+		UPDATE cancellation_policy
+		SET name = $1,
+			room_type_id = $2,
+			free_cancellation_hours = $3,
+			penalty_type = $4,
+			penalty_value = $5,
+			non_refundable = $6,
+			active = $7
+		WHERE cancellation_policy_id = $8`
+
+	result, err := r.db.Exec(
+		query,
+		politica.Nombre,
+		politica.TipoHabitacionID,
+		politica.HorasCancelacionGratuita,
+		politica.TipoPenalidad,
+		politica.ValorPenalidad,
+		politica.NoReembolsable,
+		politica.Activa,
+		politica.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar política de cancelación: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("política de cancelación con ID %d no encontrada", politica.ID)
+	}
+
+	return nil
+}
+
+// rowScanner permite escanear tanto *sql.Row como *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPoliticaCancelacion escanea una fila con las columnas de politicaCancelacionColumns
+func scanPoliticaCancelacion(row rowScanner) (*domain.PoliticaCancelacion, error) {
+	var politica domain.PoliticaCancelacion
+	var tipoHabitacionID sql.NullInt64
+
+	err := row.Scan(
+		&politica.ID,
+		&politica.Nombre,
+		&tipoHabitacionID,
+		&politica.HorasCancelacionGratuita,
+		&politica.TipoPenalidad,
+		&politica.ValorPenalidad,
+		&politica.NoReembolsable,
+		&politica.Activa,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear política de cancelación: %w", err)
+	}
+
+	if tipoHabitacionID.Valid {
+		id := int(tipoHabitacionID.Int64)
+		politica.TipoHabitacionID = &id
+	}
+
+	return &politica, nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type reglaPrecioRepository struct {
+	db dbtx
+}
+
+// NewReglaPrecioRepository crea una nueva instancia del repositorio de reglas de precio dinámico
+func NewReglaPrecioRepository(db *sql.DB) domain.ReglaPrecioRepository {
+	return &reglaPrecioRepository{db: db}
+}
+
+const reglaPrecioColumns = `
+	dynamic_pricing_rule_id,
+	name,
+	room_type_id,
+	min_occupancy,
+	max_occupancy,
+	min_lead_days,
+	max_lead_days,
+	modifier_type,
+	modifier_value,
+	priority,
+	active`
+
+// GetAll obtiene las reglas que aplican a un tipo de habitación, incluidas las generales
+// (0 = todas las reglas)
+func (r *reglaPrecioRepository) GetAll(tipoHabitacionID int) ([]domain.ReglaPrecio, error) {
+	query := `SELECT ` + reglaPrecioColumns + `
+		FROM dynamic_pricing_rule
+		WHERE ($1 = 0 OR room_type_id IS NULL OR room_type_id = $1)
+		ORDER BY priority DESC, dynamic_pricing_rule_id DESC`
+
+	return r.queryReglas(query, tipoHabitacionID)
+}
+
+// GetActivas obtiene las reglas activas que aplican a un tipo de habitación
+func (r *reglaPrecioRepository) GetActivas(tipoHabitacionID int) ([]domain.ReglaPrecio, error) {
+	query := `SELECT ` + reglaPrecioColumns + `
+		FROM dynamic_pricing_rule
+		WHERE active
+		AND (room_type_id IS NULL OR room_type_id = $1)
+		ORDER BY priority DESC, dynamic_pricing_rule_id DESC`
+
+	return r.queryReglas(query, tipoHabitacionID)
+}
+
+// GetByID obtiene una regla por su ID
+func (r *reglaPrecioRepository) GetByID(id int) (*domain.ReglaPrecio, error) {
+	query := `SELECT ` + reglaPrecioColumns + `
+		FROM dynamic_pricing_rule
+		WHERE dynamic_pricing_rule_id = $1`
+
+	regla, err := scanReglaPrecio(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrReglaPrecioNoEncontrada, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return regla, nil
+}
+
+// queryReglas ejecuta una consulta que retorna las columnas de reglaPrecioColumns
+func (r *reglaPrecioRepository) queryReglas(query string, args ...interface{}) ([]domain.ReglaPrecio, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reglas de precio: %w", err)
+	}
+	defer rows.Close()
+
+	reglas := make([]domain.ReglaPrecio, 0)
+	for rows.Next() {
+		regla, err := scanReglaPrecio(rows)
+		if err != nil {
+			return nil, err
+		}
+		reglas = append(reglas, *regla)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar reglas de precio: %w", err)
+	}
+
+	return reglas, nil
+}
+
+// Create crea una nueva regla
+func (r *reglaPrecioRepository) Create(regla *domain.ReglaPrecio) error {
+	query := `
+		INSERT INTO dynamic_pricing_rule (
+			name,
+			room_type_id,
+			min_occupancy,
+			max_occupancy,
+			min_lead_days,
+			max_lead_days,
+			modifier_type,
+			modifier_value,
+			priority,
+			active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING dynamic_pricing_rule_id`
+
+	err := r.db.QueryRow(
+		query,
+		regla.Nombre,
+		regla.TipoHabitacionID,
+		regla.OcupacionMinima,
+		regla.OcupacionMaxima,
+		regla.AntelacionMinima,
+		regla.AntelacionMaxima,
+		regla.TipoModificador,
+		regla.ValorModificador,
+		regla.Prioridad,
+		regla.Activa,
+	).Scan(&regla.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear regla de precio: %w", err)
+	}
+
+	return nil
+}
+
+// Update actualiza una regla existente
+func (r *reglaPrecioRepository) Update(regla *domain.ReglaPrecio) error {
+	query := `
+		UPDATE dynamic_pricing_rule
+		SET name = $1,
+			room_type_id = $2,
+			min_occupancy = $3,
+			max_occupancy = $4,
+			min_lead_days = $5,
+			max_lead_days = $6,
+			modifier_type = $7,
+			modifier_value = $8,
+			priority = $9,
+			active = $10
+		WHERE dynamic_pricing_rule_id = $11`
+
+	result, err := r.db.Exec(
+		query,
+		regla.Nombre,
+		regla.TipoHabitacionID,
+		regla.OcupacionMinima,
+		regla.OcupacionMaxima,
+		regla.AntelacionMinima,
+		regla.AntelacionMaxima,
+		regla.TipoModificador,
+		regla.ValorModificador,
+		regla.Prioridad,
+		regla.Activa,
+		regla.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar regla de precio: %w", err)
+	}
+
+	return verificarReglaAfectada(result, regla.ID)
+}
+
+// Delete elimina una regla
+func (r *reglaPrecioRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM dynamic_pricing_rule WHERE dynamic_pricing_rule_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar regla de precio: %w", err)
+	}
+
+	return verificarReglaAfectada(result, id)
+}
+
+// verificarReglaAfectada retorna ErrReglaPrecioNoEncontrada si la operación no afectó ninguna fila
+func verificarReglaAfectada(result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrReglaPrecioNoEncontrada, id)
+	}
+
+	return nil
+}
+
+// scanReglaPrecio escanea una fila con las columnas de reglaPrecioColumns
+func scanReglaPrecio(row rowScanner) (*domain.ReglaPrecio, error) {
+	var regla domain.ReglaPrecio
+	var tipoHabitacionID, antelacionMinima, antelacionMaxima sql.NullInt64
+	var ocupacionMinima, ocupacionMaxima sql.NullFloat64
+
+	err := row.Scan(
+		&regla.ID,
+		&regla.Nombre,
+		&tipoHabitacionID,
+		&ocupacionMinima,
+		&ocupacionMaxima,
+		&antelacionMinima,
+		&antelacionMaxima,
+		&regla.TipoModificador,
+		&regla.ValorModificador,
+		&regla.Prioridad,
+		&regla.Activa,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear regla de precio: %w", err)
+	}
+
+	if tipoHabitacionID.Valid {
+		tipo := int(tipoHabitacionID.Int64)
+		regla.TipoHabitacionID = &tipo
+	}
+	if ocupacionMinima.Valid {
+		regla.OcupacionMinima = &ocupacionMinima.Float64
+	}
+	if ocupacionMaxima.Valid {
+		regla.OcupacionMaxima = &ocupacionMaxima.Float64
+	}
+	if antelacionMinima.Valid {
+		dias := int(antelacionMinima.Int64)
+		regla.AntelacionMinima = &dias
+	}
+	if antelacionMaxima.Valid {
+		dias := int(antelacionMaxima.Int64)
+		regla.AntelacionMaxima = &dias
+	}
+
+	return &regla, nil
+}
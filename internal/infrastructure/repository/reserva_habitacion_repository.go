@@ -6,10 +6,11 @@ import (
 	"time"
 
 	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
 )
 
 type reservaHabitacionRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewReservaHabitacionRepository crea una nueva instancia del repositorio
@@ -24,10 +25,13 @@ func (r *reservaHabitacionRepository) CreateReservaHabitacion(reservaHabitacion
 			reservation_id,
 			room_id,
 			price,
+			total_price,
+			occupancy_surcharge,
+			rate_plan_id,
 			check_in_date,
 			check_out_date,
 			status
-		) VALUES ($1, $2, $3, $4, $5, $6)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.Exec(
@@ -35,6 +39,9 @@ func (r *reservaHabitacionRepository) CreateReservaHabitacion(reservaHabitacion
 		reservaHabitacion.ReservaID,
 		reservaHabitacion.HabitacionID,
 		reservaHabitacion.Precio,
+		reservaHabitacion.Importe(),
+		reservaHabitacion.Recargo,
+		reservaHabitacion.PlanTarifaID,
 		reservaHabitacion.FechaEntrada,
 		reservaHabitacion.FechaSalida,
 		reservaHabitacion.Estado,
@@ -54,6 +61,9 @@ func (r *reservaHabitacionRepository) GetReservaHabitacionesByReservaID(reservaI
 			rh.reservation_id,
 			rh.room_id,
 			rh.price,
+			COALESCE(rh.total_price, 0),
+			rh.occupancy_surcharge,
+			rh.rate_plan_id,
 			rh.check_in_date,
 			rh.check_out_date,
 			rh.status,
@@ -80,6 +90,9 @@ func (r *reservaHabitacionRepository) GetReservaHabitacionesByReservaID(reservaI
 			&rh.ReservaID,
 			&rh.HabitacionID,
 			&rh.Precio,
+			&rh.Total,
+			&rh.Recargo,
+			&rh.PlanTarifaID,
 			&rh.FechaEntrada,
 			&rh.FechaSalida,
 			&rh.Estado,
@@ -124,39 +137,62 @@ func (r *reservaHabitacionRepository) UpdateReservaHabitacionEstado(reservaID, h
 	return nil
 }
 
-// VerificarDisponibilidad verifica si una habitación está disponible para las fechas dadas
-func (r *reservaHabitacionRepository) VerificarDisponibilidad(habitacionID int, fechaEntrada, fechaSalida time.Time) (bool, error) {
+// GetOcupaciones obtiene las habitaciones activas cuyas fechas se solapan con [desde, hasta)
+func (r *reservaHabitacionRepository) GetOcupaciones(desde, hasta time.Time) ([]domain.Ocupacion, error) {
 	query := `
-		SELECT COUNT(*) 
+		SELECT 
+			rh.room_id,
+			rh.reservation_id,
+			r.status,
+			rh.check_in_date,
+			rh.check_out_date,
+			r.hold_expires_at
 		FROM reservation_room rh
 		INNER JOIN reservation r ON r.reservation_id = rh.reservation_id
-		WHERE rh.room_id = $1 
-		AND rh.status = 1
-		AND r.status NOT IN ('Cancelada')
-		AND (
-			(rh.check_in_date < $3 AND rh.check_out_date > $2)
-		)
+		WHERE rh.status = 1
+		AND rh.check_in_date < $2
+		AND rh.check_out_date > $1
+		ORDER BY rh.room_id, rh.check_in_date
 	`
 
-	var count int
-	err := r.db.QueryRow(query, habitacionID, fechaEntrada, fechaSalida).Scan(&count)
+	rows, err := r.db.Query(query, desde, hasta)
 	if err != nil {
-		return false, fmt.Errorf("error al verificar disponibilidad: %w", err)
+		return nil, fmt.Errorf("error al obtener ocupaciones: %w", err)
 	}
+	defer rows.Close()
 
-	// Si count es 0, la habitación está disponible
-	return count == 0, nil
+	var ocupaciones []domain.Ocupacion
+	for rows.Next() {
+		var o domain.Ocupacion
+		var venceRetencion sql.NullTime
+		if err := rows.Scan(&o.HabitacionID, &o.ReservaID, &o.EstadoReserva, &o.FechaEntrada, &o.FechaSalida, &venceRetencion); err != nil {
+			return nil, fmt.Errorf("error al escanear ocupación: %w", err)
+		}
+		if venceRetencion.Valid {
+			o.VenceRetencion = &venceRetencion.Time
+		}
+		ocupaciones = append(ocupaciones, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar ocupaciones: %w", err)
+	}
+
+	return ocupaciones, nil
 }
 
 // GetReservasEnRango obtiene todas las reservas activas en un rango de fechas
 func (r *reservaHabitacionRepository) GetReservasEnRango(fechaInicio, fechaFin time.Time) ([]domain.ReservaHabitacion, error) {
 	query := `
 		SELECT 
-			rh.reservaid,
-			rh.habitacionid,
-			rh.precio,
-			rh.fechaentrada,
-			rh.fechasalida,
+			rh.reservation_id,
+			rh.room_id,
+			rh.price,
+			COALESCE(rh.total_price, 0),
+			rh.occupancy_surcharge,
+			rh.rate_plan_id,
+			rh.check_in_date,
+			rh.check_out_date,
 			rh.status,
 			h.name,
 			h.capacity,
@@ -165,16 +201,13 @@ func (r *reservaHabitacionRepository) GetReservasEnRango(fechaInicio, fechaFin t
 		INNER JOIN room h ON h.room_id = rh.room_id
 		INNER JOIN reservation r ON r.reservation_id = rh.reservation_id
 		WHERE rh.status = 1
-		AND r.status NOT IN ('Cancelada')
-		AND (
-			(rh.check_in_date BETWEEN $1 AND $2)
-			OR (rh.check_out_date BETWEEN $1 AND $2)
-			OR (rh.check_in_date < $1 AND rh.check_out_date > $2)
-		)
+		AND r.status::text = ANY($3)
+		AND rh.check_in_date < $2
+		AND rh.check_out_date > $1
 		ORDER BY rh.check_in_date
 	`
 
-	rows, err := r.db.Query(query, fechaInicio, fechaFin)
+	rows, err := r.db.Query(query, fechaInicio, fechaFin, pq.Array(estadosQueRetienenInventario()))
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener reservas en rango: %w", err)
 	}
@@ -189,6 +222,9 @@ func (r *reservaHabitacionRepository) GetReservasEnRango(fechaInicio, fechaFin t
 			&rh.ReservaID,
 			&rh.HabitacionID,
 			&rh.Precio,
+			&rh.Total,
+			&rh.Recargo,
+			&rh.PlanTarifaID,
 			&rh.FechaEntrada,
 			&rh.FechaSalida,
 			&rh.Estado,
@@ -207,3 +243,48 @@ func (r *reservaHabitacionRepository) GetReservasEnRango(fechaInicio, fechaFin t
 
 	return reservasHabitacion, nil
 }
+
+// GetOcupacionesHabitaciones obtiene las habitaciones activas de las habitaciones indicadas
+// cuya salida es posterior a desde
+func (r *reservaHabitacionRepository) GetOcupacionesHabitaciones(habitacionIDs []int, desde time.Time) ([]domain.Ocupacion, error) {
+	query := `
+		SELECT 
+			rh.room_id,
+			rh.reservation_id,
+			r.status,
+			rh.check_in_date,
+			rh.check_out_date,
+			r.hold_expires_at
+		FROM reservation_room rh
+		INNER JOIN reservation r ON r.reservation_id = rh.reservation_id
+		WHERE rh.status = 1
+		AND rh.room_id = ANY($1)
+		AND rh.check_out_date > $2
+		ORDER BY rh.check_in_date, rh.room_id
+	`
+
+	rows, err := r.db.Query(query, pq.Array(habitacionIDs), desde)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener ocupaciones: %w", err)
+	}
+	defer rows.Close()
+
+	var ocupaciones []domain.Ocupacion
+	for rows.Next() {
+		var o domain.Ocupacion
+		var venceRetencion sql.NullTime
+		if err := rows.Scan(&o.HabitacionID, &o.ReservaID, &o.EstadoReserva, &o.FechaEntrada, &o.FechaSalida, &venceRetencion); err != nil {
+			return nil, fmt.Errorf("error al escanear ocupación: %w", err)
+		}
+		if venceRetencion.Valid {
+			o.VenceRetencion = &venceRetencion.Time
+		}
+		ocupaciones = append(ocupaciones, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar ocupaciones: %w", err)
+	}
+
+	return ocupaciones, nil
+}
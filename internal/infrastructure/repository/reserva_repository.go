@@ -2,13 +2,20 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/lib/pq"
 )
 
+// advisoryLockHabitacion es el espacio de nombres de los advisory locks por habitación
+const advisoryLockHabitacion = 1001
+
 type reservaRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewReservaRepository crea una nueva instancia del repositorio de reservas
@@ -21,27 +28,52 @@ func (r *reservaRepository) GetReservaByID(id int) (*domain.Reserva, error) {
 	query := `
 		SELECT 
 			r.reservation_id,
+			r.reservation_code,
 			r.adults_count,
 			r.children_count,
+			r.children_ages,
 			r.status,
 			r.client_id,
 			r.subtotal,
 			r.discount,
-			r.confirmation_date
+			r.confirmation_date,
+			r.hold_expires_at,
+			r.checked_in_at,
+			r.checked_out_at,
+			r.group_block_id,
+			COALESCE(r.channel_code, ''),
+			COALESCE(r.channel_booking_ref, ''),
+			COALESCE((
+				SELECT pc.code FROM promo_code_redemption pr
+				INNER JOIN promo_code pc ON pc.promo_code_id = pr.promo_code_id
+				WHERE pr.reservation_id = r.reservation_id
+			), '')
 		FROM reservation r
 		WHERE r.reservation_id = $1
 	`
 
 	reserva := &domain.Reserva{}
+	var venceRetencion, checkIn, checkOut sql.NullTime
+	var bloqueID sql.NullInt64
+	var edadesNinhos pq.Int64Array
 	err := r.db.QueryRow(query, id).Scan(
 		&reserva.ID,
+		&reserva.Codigo,
 		&reserva.CantidadAdultos,
 		&reserva.CantidadNinhos,
+		&edadesNinhos,
 		&reserva.Estado,
 		&reserva.ClienteID,
 		&reserva.Subtotal,
 		&reserva.Descuento,
 		&reserva.FechaConfirmacion,
+		&venceRetencion,
+		&checkIn,
+		&checkOut,
+		&bloqueID,
+		&reserva.Canal,
+		&reserva.ReferenciaCanal,
+		&reserva.CodigoPromocional,
 	)
 
 	if err != nil {
@@ -50,6 +82,12 @@ func (r *reservaRepository) GetReservaByID(id int) (*domain.Reserva, error) {
 		}
 		return nil, fmt.Errorf("error al obtener reserva: %w", err)
 	}
+	if venceRetencion.Valid {
+		reserva.VenceRetencion = &venceRetencion.Time
+	}
+	asignarFechasEstancia(reserva, checkIn, checkOut)
+	asignarBloqueGrupo(reserva, bloqueID)
+	asignarEdadesNinhos(reserva, edadesNinhos)
 
 	// Obtener las habitaciones de la reserva
 	habitacionesQuery := `
@@ -57,6 +95,9 @@ func (r *reservaRepository) GetReservaByID(id int) (*domain.Reserva, error) {
 			rh.reservation_id,
 			rh.room_id,
 			rh.price,
+			COALESCE(rh.total_price, 0),
+			rh.occupancy_surcharge,
+			rh.rate_plan_id,
 			rh.check_in_date,
 			rh.check_out_date,
 			rh.status,
@@ -83,6 +124,9 @@ func (r *reservaRepository) GetReservaByID(id int) (*domain.Reserva, error) {
 			&rh.ReservaID,
 			&rh.HabitacionID,
 			&rh.Precio,
+			&rh.Total,
+			&rh.Recargo,
+			&rh.PlanTarifaID,
 			&rh.FechaEntrada,
 			&rh.FechaSalida,
 			&rh.Estado,
@@ -100,121 +144,502 @@ func (r *reservaRepository) GetReservaByID(id int) (*domain.Reserva, error) {
 	}
 
 	reserva.Habitaciones = habitaciones
+
+	reserva.Servicios, err = serviciosActivos(r.db, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return reserva, nil
 }
 
-// CreateReserva crea una nueva reserva
-func (r *reservaRepository) CreateReserva(reserva *domain.Reserva) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("error al iniciar transacción: %w", err)
+// asignarFechasEstancia completa las horas reales de check-in y check-out si existen
+func asignarFechasEstancia(reserva *domain.Reserva, checkIn, checkOut sql.NullTime) {
+	if checkIn.Valid {
+		reserva.FechaCheckIn = &checkIn.Time
 	}
-	defer tx.Rollback()
+	if checkOut.Valid {
+		reserva.FechaCheckOut = &checkOut.Time
+	}
+}
 
-	// Insertar la reserva principal
-	query := `
-		INSERT INTO reservation (
-			adults_count,
-			children_count,
-			status,
-			client_id,
-			subtotal,
-			discount,
-			confirmation_date
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING reservation_id
-	`
+// asignarBloqueGrupo completa el bloque de grupo leído de la base de datos
+func asignarBloqueGrupo(reserva *domain.Reserva, bloqueID sql.NullInt64) {
+	if bloqueID.Valid {
+		id := int(bloqueID.Int64)
+		reserva.BloqueGrupoID = &id
+	}
+}
 
-	err = tx.QueryRow(
-		query,
-		reserva.CantidadAdultos,
-		reserva.CantidadNinhos,
-		reserva.Estado,
-		reserva.ClienteID,
-		reserva.Subtotal,
-		reserva.Descuento,
-		reserva.FechaConfirmacion,
-	).Scan(&reserva.ID)
+// asignarEdadesNinhos completa las edades de los niños leídas de la base de datos
+func asignarEdadesNinhos(reserva *domain.Reserva, edades pq.Int64Array) {
+	reserva.EdadesNinhos = make([]int, len(edades))
+	for i, edad := range edades {
+		reserva.EdadesNinhos[i] = int(edad)
+	}
+}
 
+// GetReservaByCodigo obtiene una reserva por su código de confirmación con sus habitaciones
+func (r *reservaRepository) GetReservaByCodigo(codigo string) (*domain.Reserva, error) {
+	var id int
+	err := r.db.QueryRow(
+		`SELECT reservation_id FROM reservation WHERE reservation_code = $1`,
+		codigo,
+	).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("error al crear reserva: %w", err)
-	}
-
-	// Insertar las habitaciones de la reserva
-	for i := range reserva.Habitaciones {
-		habitacionQuery := `
-			INSERT INTO reservation_room (
-				reservation_id,
-				room_id,
-				price,
-				check_in_date,
-				check_out_date,
-				status
-			) VALUES ($1, $2, $3, $4, $5, $6)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: código %s", domain.ErrReservaNoEncontrada, codigo)
+		}
+		return nil, fmt.Errorf("error al buscar reserva por código: %w", err)
+	}
+
+	return r.GetReservaByID(id)
+}
+
+// GetReservaByReferenciaCanal obtiene una reserva por su identificador en un canal
+func (r *reservaRepository) GetReservaByReferenciaCanal(canal, referencia string) (*domain.Reserva, error) {
+	var id int
+	err := r.db.QueryRow(
+		`SELECT reservation_id FROM reservation WHERE channel_code = $1 AND channel_booking_ref = $2`,
+		canal, referencia,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s %s", domain.ErrReservaNoEncontrada, canal, referencia)
+		}
+		return nil, fmt.Errorf("error al buscar reserva por referencia del canal: %w", err)
+	}
+
+	return r.GetReservaByID(id)
+}
+
+// CreateReserva crea una nueva reserva
+func (r *reservaRepository) CreateReserva(reserva *domain.Reserva) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		// Serializar las reservas concurrentes de las mismas habitaciones y
+		// volver a verificar la disponibilidad ya con el lock tomado
+		if err := lockHabitaciones(tx, reserva.Habitaciones); err != nil {
+			return err
+		}
+		// Las retenciones vencidas que el scheduler aún no procesó no deben bloquear la reserva
+		if _, err := liberarRetencionesVencidas(tx, time.Now().UTC(), idsHabitaciones(reserva.Habitaciones)); err != nil {
+			return err
+		}
+		for _, hab := range reserva.Habitaciones {
+			if err := verificarHabitacionLibre(tx, hab); err != nil {
+				return err
+			}
+		}
+		// Las recogidas contra un bloque de grupo no pueden superar su cupo
+		if reserva.BloqueGrupoID != nil {
+			if err := verificarCupoBloque(tx, *reserva.BloqueGrupoID, reserva.Habitaciones); err != nil {
+				return err
+			}
+		}
+
+		if err := asignarCodigoReserva(tx, reserva); err != nil {
+			return err
+		}
+
+		// Insertar la reserva principal
+		query := `
+			INSERT INTO reservation (
+				reservation_code,
+				adults_count,
+				children_count,
+				children_ages,
+				status,
+				client_id,
+				subtotal,
+				discount,
+				confirmation_date,
+				hold_expires_at,
+				group_block_id,
+				channel_code,
+				channel_booking_ref
+			) VALUES ($1, $2, $3, COALESCE($4::int[], '{}'), $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''))
+			RETURNING reservation_id
 		`
 
-		_, err = tx.Exec(
-			habitacionQuery,
-			reserva.ID,
-			reserva.Habitaciones[i].HabitacionID,
-			reserva.Habitaciones[i].Precio,
-			reserva.Habitaciones[i].FechaEntrada,
-			reserva.Habitaciones[i].FechaSalida,
-			1, // status activo
-		)
+		err := tx.QueryRow(
+			query,
+			reserva.Codigo,
+			reserva.CantidadAdultos,
+			reserva.CantidadNinhos,
+			pq.Array(reserva.EdadesNinhos),
+			reserva.Estado,
+			reserva.ClienteID,
+			reserva.Subtotal,
+			reserva.Descuento,
+			reserva.FechaConfirmacion,
+			reserva.VenceRetencion,
+			reserva.BloqueGrupoID,
+			reserva.Canal,
+			reserva.ReferenciaCanal,
+		).Scan(&reserva.ID)
 
 		if err != nil {
-			return fmt.Errorf("error al crear reserva de habitación: %w", err)
+			return fmt.Errorf("error al crear reserva: %w", err)
 		}
 
-		reserva.Habitaciones[i].ReservaID = reserva.ID
-		reserva.Habitaciones[i].Estado = 1
-	}
-
-	// Insertar los servicios de la reserva
-	if len(reserva.Servicios) > 0 {
-		for _, servicio := range reserva.Servicios {
-			servicioQuery := `
-				INSERT INTO reservation_service (
+		// Insertar las habitaciones de la reserva
+		for i := range reserva.Habitaciones {
+			habitacionQuery := `
+				INSERT INTO reservation_room (
 					reservation_id,
-					service_id,
-					start_date,
-					end_date,
+					room_id,
+					price,
+					total_price,
+					occupancy_surcharge,
+					rate_plan_id,
+					check_in_date,
+					check_out_date,
 					status
-				) VALUES ($1, $2, $3, $4, $5)
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 			`
 
 			_, err = tx.Exec(
-				servicioQuery,
+				habitacionQuery,
 				reserva.ID,
-				servicio.ServiceID,
-				servicio.StartDate,
-				servicio.EndDate,
+				reserva.Habitaciones[i].HabitacionID,
+				reserva.Habitaciones[i].Precio,
+				reserva.Habitaciones[i].Importe(),
+				reserva.Habitaciones[i].Recargo,
+				reserva.Habitaciones[i].PlanTarifaID,
+				reserva.Habitaciones[i].FechaEntrada,
+				reserva.Habitaciones[i].FechaSalida,
 				1, // status activo
 			)
 
 			if err != nil {
-				return fmt.Errorf("error al crear servicio de reserva: %w", err)
+				if isExclusionViolation(err) {
+					return fmt.Errorf("%w: habitación %d", domain.ErrHabitacionNoDisponible, reserva.Habitaciones[i].HabitacionID)
+				}
+				return fmt.Errorf("error al crear reserva de habitación: %w", err)
 			}
+
+			reserva.Habitaciones[i].ReservaID = reserva.ID
+			reserva.Habitaciones[i].Estado = 1
+		}
+
+		// Con las habitaciones ya insertadas, las de bloques de grupo siguen apartadas
+		if err := verificarRetencionBloques(tx, reserva.Habitaciones); err != nil {
+			return err
 		}
+
+		// Insertar los servicios de la reserva
+		for i := range reserva.Servicios {
+			reserva.Servicios[i].ReservaID = reserva.ID
+			reserva.Servicios[i].Status = 1 // status activo
+			if err := insertarReservaServicio(tx, &reserva.Servicios[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ModificarReserva actualiza la reserva y reemplaza sus habitaciones activas en una transacción
+func (r *reservaRepository) ModificarReserva(reserva *domain.Reserva) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		// Bloquear tanto las habitaciones nuevas como las actuales de la reserva
+		actuales, err := habitacionesActivas(tx, reserva.ID)
+		if err != nil {
+			return err
+		}
+		if err := lockHabitaciones(tx, append(actuales, reserva.Habitaciones...)); err != nil {
+			return err
+		}
+		if _, err := liberarRetencionesVencidas(tx, time.Now().UTC(), idsHabitaciones(reserva.Habitaciones)); err != nil {
+			return err
+		}
+
+		// Desactivar las habitaciones actuales para que no cuenten contra la nueva disponibilidad
+		if _, err := tx.Exec(`UPDATE reservation_room SET status = 0 WHERE reservation_id = $1 AND status = 1`, reserva.ID); err != nil {
+			return fmt.Errorf("error al liberar habitaciones de la reserva: %w", err)
+		}
+
+		for _, hab := range reserva.Habitaciones {
+			if err := verificarHabitacionLibre(tx, hab); err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.Exec(`
+			UPDATE reservation
+			SET adults_count = $1,
+				children_count = $2,
+				children_ages = COALESCE($3::int[], '{}'),
+				subtotal = $4,
+				discount = $5
+			WHERE reservation_id = $6
+		`, reserva.CantidadAdultos, reserva.CantidadNinhos, pq.Array(reserva.EdadesNinhos), reserva.Subtotal, reserva.Descuento, reserva.ID)
+		if err != nil {
+			return fmt.Errorf("error al actualizar reserva: %w", err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return fmt.Errorf("reserva con ID %d no encontrada", reserva.ID)
+		}
+
+		// El monto del canje sigue al descuento recalculado para que el reporte de la campaña cuadre
+		if _, err := tx.Exec(`
+			UPDATE promo_code_redemption SET amount = $1 WHERE reservation_id = $2
+		`, reserva.Descuento, reserva.ID); err != nil {
+			return fmt.Errorf("error al actualizar canje del código promocional: %w", err)
+		}
+
+		// La PK es (room_id, reservation_id): si la habitación ya estuvo en la reserva se reactiva
+		for i := range reserva.Habitaciones {
+			_, err := tx.Exec(`
+				INSERT INTO reservation_room (
+					reservation_id,
+					room_id,
+					price,
+					total_price,
+					occupancy_surcharge,
+					rate_plan_id,
+					check_in_date,
+					check_out_date,
+					status
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1)
+				ON CONFLICT (room_id, reservation_id) DO UPDATE
+				SET price = EXCLUDED.price,
+					total_price = EXCLUDED.total_price,
+					occupancy_surcharge = EXCLUDED.occupancy_surcharge,
+					rate_plan_id = EXCLUDED.rate_plan_id,
+					check_in_date = EXCLUDED.check_in_date,
+					check_out_date = EXCLUDED.check_out_date,
+					status = 1
+			`,
+				reserva.ID,
+				reserva.Habitaciones[i].HabitacionID,
+				reserva.Habitaciones[i].Precio,
+				reserva.Habitaciones[i].Importe(),
+				reserva.Habitaciones[i].Recargo,
+				reserva.Habitaciones[i].PlanTarifaID,
+				reserva.Habitaciones[i].FechaEntrada,
+				reserva.Habitaciones[i].FechaSalida,
+			)
+			if err != nil {
+				if isExclusionViolation(err) {
+					return fmt.Errorf("%w: habitación %d", domain.ErrHabitacionNoDisponible, reserva.Habitaciones[i].HabitacionID)
+				}
+				return fmt.Errorf("error al actualizar habitación de la reserva: %w", err)
+			}
+
+			reserva.Habitaciones[i].ReservaID = reserva.ID
+			reserva.Habitaciones[i].Estado = 1
+		}
+
+		return verificarRetencionBloques(tx, reserva.Habitaciones)
+	})
+}
+
+// habitacionesActivas retorna las habitaciones activas de una reserva (solo IDs y fechas)
+func habitacionesActivas(tx dbtx, reservaID int) ([]domain.ReservaHabitacion, error) {
+	rows, err := tx.Query(`
+		SELECT room_id, check_in_date, check_out_date
+		FROM reservation_room
+		WHERE reservation_id = $1 AND status = 1
+	`, reservaID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener habitaciones de la reserva: %w", err)
+	}
+	defer rows.Close()
+
+	var habitaciones []domain.ReservaHabitacion
+	for rows.Next() {
+		hab := domain.ReservaHabitacion{ReservaID: reservaID, Estado: 1}
+		if err := rows.Scan(&hab.HabitacionID, &hab.FechaEntrada, &hab.FechaSalida); err != nil {
+			return nil, fmt.Errorf("error al escanear habitación: %w", err)
+		}
+		habitaciones = append(habitaciones, hab)
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("error al confirmar transacción: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar habitaciones: %w", err)
+	}
+
+	return habitaciones, nil
+}
+
+// intentosCodigoReserva limita la búsqueda de un código libre; con 31^6 combinaciones
+// una colisión repetida indica un problema y no mala suerte
+const intentosCodigoReserva = 5
+
+// asignarCodigoReserva genera un código de confirmación que no esté en uso
+func asignarCodigoReserva(tx dbtx, reserva *domain.Reserva) error {
+	for i := 0; i < intentosCodigoReserva; i++ {
+		codigo, err := domain.GenerarCodigoReserva()
+		if err != nil {
+			return err
+		}
+
+		var existe bool
+		err = tx.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM reservation WHERE reservation_code = $1)`,
+			codigo,
+		).Scan(&existe)
+		if err != nil {
+			return fmt.Errorf("error al verificar código de reserva: %w", err)
+		}
+		if !existe {
+			reserva.Codigo = codigo
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no se pudo generar un código de reserva único")
+}
+
+// lockHabitaciones toma un advisory lock transaccional por cada habitación, en orden
+// ascendente para evitar deadlocks entre reservas que comparten habitaciones
+func lockHabitaciones(tx dbtx, habitaciones []domain.ReservaHabitacion) error {
+	for _, id := range idsHabitaciones(habitaciones) {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, advisoryLockHabitacion, id); err != nil {
+			return fmt.Errorf("error al bloquear habitación %d: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// idsHabitaciones retorna los IDs de habitación sin repetir y ordenados
+func idsHabitaciones(habitaciones []domain.ReservaHabitacion) []int {
+	ids := make([]int, 0, len(habitaciones))
+	vistos := make(map[int]bool)
+	for _, hab := range habitaciones {
+		if !vistos[hab.HabitacionID] {
+			vistos[hab.HabitacionID] = true
+			ids = append(ids, hab.HabitacionID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// liberarRetencionesVencidas cancela las reservas pendientes con retención vencida y desactiva
+// sus habitaciones. Si habitacionIDs no es nil, solo procesa las reservas que las incluyen
+func liberarRetencionesVencidas(db dbtx, ahora time.Time, habitacionIDs []int) ([]int, error) {
+	query := `
+		WITH vencidas AS (
+			UPDATE reservation r
+			SET status = 'Cancelada',
+				hold_expires_at = NULL
+			WHERE r.status = 'Pendiente'
+			AND r.hold_expires_at IS NOT NULL
+			AND r.hold_expires_at <= $1
+			AND (
+				$2::int[] IS NULL
+				OR EXISTS (
+					SELECT 1 FROM reservation_room rh
+					WHERE rh.reservation_id = r.reservation_id
+					AND rh.status = 1
+					AND rh.room_id = ANY($2::int[])
+				)
+			)
+			RETURNING r.reservation_id
+		), habitaciones AS (
+			UPDATE reservation_room rh
+			SET status = 0
+			FROM vencidas v
+			WHERE rh.reservation_id = v.reservation_id
+			RETURNING rh.reservation_id
+		), historial AS (
+			INSERT INTO reservation_status_history (reservation_id, previous_status, new_status, changed_at, actor, reason)
+			SELECT reservation_id, 'Pendiente', 'Cancelada', $1, $3, 'Retención vencida'
+			FROM vencidas
+		)
+		SELECT reservation_id FROM vencidas
+	`
+
+	rows, err := db.Query(query, ahora, pq.Array(habitacionIDs), domain.ActorSistema)
+	if err != nil {
+		return nil, fmt.Errorf("error al liberar retenciones vencidas: %w", err)
+	}
+	defer rows.Close()
+
+	var liberadas []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al escanear reserva liberada: %w", err)
+		}
+		liberadas = append(liberadas, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar reservas liberadas: %w", err)
+	}
+
+	return liberadas, nil
+}
+
+// estadosQueRetienenInventario convierte domain.EstadosQueRetienenInventario al formato
+// que espera el parámetro ANY($n) de las consultas
+func estadosQueRetienenInventario() []string {
+	estados := make([]string, len(domain.EstadosQueRetienenInventario))
+	for i, e := range domain.EstadosQueRetienenInventario {
+		estados[i] = string(e)
+	}
+	return estados
+}
+
+// verificarHabitacionLibre comprueba dentro de la transacción que la habitación no tenga
+// otra reserva activa ni un bloqueo de un calendario externo activo que se solape (rangos
+// semiabiertos: el día de salida queda libre)
+func verificarHabitacionLibre(tx dbtx, hab domain.ReservaHabitacion) error {
+	query := `
+		SELECT
+			(SELECT COUNT(*)
+			FROM reservation_room rh
+			INNER JOIN reservation r ON r.reservation_id = rh.reservation_id
+			WHERE rh.room_id = $1
+			AND rh.status = 1
+			AND r.status::text = ANY($4)
+			AND rh.check_in_date < $3
+			AND rh.check_out_date > $2)
+			+
+			(SELECT COUNT(*)
+			FROM external_block eb
+			INNER JOIN external_calendar ec ON ec.external_calendar_id = eb.external_calendar_id
+			WHERE ec.active
+			AND eb.room_id = $1
+			AND eb.start_date < $3
+			AND eb.end_date > $2)
+	`
+
+	var count int
+	err := tx.QueryRow(query, hab.HabitacionID, hab.FechaEntrada, hab.FechaSalida, pq.Array(estadosQueRetienenInventario())).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("error al verificar disponibilidad: %w", err)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%w: habitación %d", domain.ErrHabitacionNoDisponible, hab.HabitacionID)
 	}
 
 	return nil
 }
 
-// UpdateReservastatus actualiza el status de una reserva
-func (r *reservaRepository) UpdateReservaEstado(id int, status domain.EstadoReserva) error {
+// isExclusionViolation indica si el error proviene de la restricción reservation_room_no_overlap
+func isExclusionViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23P01"
+}
+
+// UpdateReservaEstado actualiza el status de una reserva siempre que siga en el estado anterior
+func (r *reservaRepository) UpdateReservaEstado(id int, anterior, status domain.EstadoReserva) error {
 	query := `
 		UPDATE reservation 
-		SET status = $1 
+		SET status = $1,
+			hold_expires_at = CASE WHEN $1::text = 'Pendiente' THEN hold_expires_at ELSE NULL END
 		WHERE reservation_id = $2
+		AND status::text = $3
 	`
 
-	result, err := r.db.Exec(query, status, id)
+	result, err := r.db.Exec(query, status, id, anterior)
 	if err != nil {
 		return fmt.Errorf("error al actualizar status de reserva: %w", err)
 	}
@@ -224,8 +649,9 @@ func (r *reservaRepository) UpdateReservaEstado(id int, status domain.EstadoRese
 		return fmt.Errorf("error al verificar filas afectadas: %w", err)
 	}
 
+	// Otra operación cambió el estado entre la lectura y la actualización (o la reserva no existe)
 	if rowsAffected == 0 {
-		return fmt.Errorf("reserva con ID %d no encontrada", id)
+		return fmt.Errorf("%w: la reserva %d ya no está en estado %s", domain.ErrTransicionInvalida, id, anterior)
 	}
 
 	return nil
@@ -236,13 +662,26 @@ func (r *reservaRepository) GetReservasCliente(clientID int) ([]domain.Reserva,
 	query := `
 		SELECT
 			r.reservation_id,
+			r.reservation_code,
 			r.adults_count,
 			r.children_count,
+			r.children_ages,
 			r.status,
 			r.client_id,
 			r.subtotal,
 			r.discount,
-			r.confirmation_date
+			r.confirmation_date,
+			r.hold_expires_at,
+			r.checked_in_at,
+			r.checked_out_at,
+			r.group_block_id,
+			COALESCE(r.channel_code, ''),
+			COALESCE(r.channel_booking_ref, ''),
+			COALESCE((
+				SELECT pc.code FROM promo_code_redemption pr
+				INNER JOIN promo_code pc ON pc.promo_code_id = pr.promo_code_id
+				WHERE pr.reservation_id = r.reservation_id
+			), '')
 		FROM reservation r
 		WHERE r.client_id = $1
 		ORDER BY r.confirmation_date DESC
@@ -257,19 +696,37 @@ func (r *reservaRepository) GetReservasCliente(clientID int) ([]domain.Reserva,
 	var reservas []domain.Reserva
 	for rows.Next() {
 		var reserva domain.Reserva
+		var venceRetencion, checkIn, checkOut sql.NullTime
+		var bloqueID sql.NullInt64
+		var edadesNinhos pq.Int64Array
 		err := rows.Scan(
 			&reserva.ID,
+			&reserva.Codigo,
 			&reserva.CantidadAdultos,
 			&reserva.CantidadNinhos,
+			&edadesNinhos,
 			&reserva.Estado,
 			&reserva.ClienteID,
 			&reserva.Subtotal,
 			&reserva.Descuento,
 			&reserva.FechaConfirmacion,
+			&venceRetencion,
+			&checkIn,
+			&checkOut,
+			&bloqueID,
+			&reserva.Canal,
+			&reserva.ReferenciaCanal,
+			&reserva.CodigoPromocional,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error al escanear reserva: %w", err)
 		}
+		if venceRetencion.Valid {
+			reserva.VenceRetencion = &venceRetencion.Time
+		}
+		asignarFechasEstancia(&reserva, checkIn, checkOut)
+		asignarBloqueGrupo(&reserva, bloqueID)
+		asignarEdadesNinhos(&reserva, edadesNinhos)
 
 		// Obtener las habitaciones de cada reserva
 		habitacionesQuery := `
@@ -277,6 +734,9 @@ func (r *reservaRepository) GetReservasCliente(clientID int) ([]domain.Reserva,
 				rh.reservation_id,
 				rh.room_id,
 				rh.price,
+				COALESCE(rh.total_price, 0),
+				rh.occupancy_surcharge,
+				rh.rate_plan_id,
 				rh.check_in_date,
 				rh.check_out_date,
 				rh.status,
@@ -302,6 +762,9 @@ func (r *reservaRepository) GetReservasCliente(clientID int) ([]domain.Reserva,
 				&rh.ReservaID,
 				&rh.HabitacionID,
 				&rh.Precio,
+				&rh.Total,
+				&rh.Recargo,
+				&rh.PlanTarifaID,
 				&rh.FechaEntrada,
 				&rh.FechaSalida,
 				&rh.Estado,
@@ -329,58 +792,201 @@ func (r *reservaRepository) GetReservasCliente(clientID int) ([]domain.Reserva,
 
 // CreateReservaServicios crea los servicios asociados a una reserva
 func (r *reservaRepository) CreateReservaServicios(reservaID int, servicios []domain.ReservaServicio) error {
-	for _, servicio := range servicios {
-		query := `
-			INSERT INTO reservation_service (
-				reservation_id,
-				service_id,
-				start_date,
-				end_date,
-				status
-			) VALUES ($1, $2, $3, $4, $5)
-		`
+	for i := range servicios {
+		servicios[i].ReservaID = reservaID
+		if err := insertarReservaServicio(r.db, &servicios[i]); err != nil {
+			return err
+		}
+	}
 
-		_, err := r.db.Exec(
-			query,
-			reservaID,
-			servicio.ServiceID,
-			servicio.StartDate,
-			servicio.EndDate,
-			servicio.Status,
+	return nil
+}
+
+// GetReservaServicios obtiene los servicios activos de una reserva
+func (r *reservaRepository) GetReservaServicios(reservaID int) ([]domain.ReservaServicio, error) {
+	return serviciosActivos(r.db, reservaID)
+}
+
+// AddReservaServicio agrega un servicio y suma su costo al subtotal de la reserva en una transacción
+func (r *reservaRepository) AddReservaServicio(servicio *domain.ReservaServicio) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		if err := insertarReservaServicio(tx, servicio); err != nil {
+			return err
+		}
+		return ajustarSubtotal(tx, servicio.ReservaID, servicio.Costo())
+	})
+}
+
+// RemoveReservaServicio desactiva un servicio y descuenta su costo del subtotal de la reserva
+// en una transacción
+func (r *reservaRepository) RemoveReservaServicio(reservaID, reservaServicioID int) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		var servicio domain.ReservaServicio
+		err := tx.QueryRow(`
+			UPDATE reservation_service rs
+			SET status = 0
+			FROM service s
+			WHERE s.service_id = rs.service_id
+			AND rs.reservation_id = $1 AND rs.reservation_service_id = $2 AND rs.status = 1
+			RETURNING rs.start_date, rs.end_date, rs.quantity, COALESCE(rs.unit_price, s.price)
+		`, reservaID, reservaServicioID).Scan(
+			&servicio.StartDate,
+			&servicio.EndDate,
+			&servicio.Quantity,
+			&servicio.UnitPrice,
 		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("servicio %d no encontrado en la reserva %d", reservaServicioID, reservaID)
+			}
+			return fmt.Errorf("error al quitar servicio de reserva: %w", err)
+		}
+
+		return ajustarSubtotal(tx, reservaID, -servicio.Costo())
+	})
+}
+
+// insertarReservaServicio inserta un servicio de reserva y completa su ID
+func insertarReservaServicio(db dbtx, servicio *domain.ReservaServicio) error {
+	query := `
+		INSERT INTO reservation_service (
+			reservation_id,
+			service_id,
+			start_date,
+			end_date,
+			quantity,
+			unit_price,
+			status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING reservation_service_id
+	`
+
+	err := db.QueryRow(
+		query,
+		servicio.ReservaID,
+		servicio.ServiceID,
+		servicio.StartDate,
+		servicio.EndDate,
+		servicio.Quantity,
+		servicio.UnitPrice,
+		servicio.Status,
+	).Scan(&servicio.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear servicio de reserva: %w", err)
+	}
+
+	return nil
+}
+
+// serviciosActivos obtiene los servicios activos de una reserva con el nombre del servicio
+func serviciosActivos(db dbtx, reservaID int) ([]domain.ReservaServicio, error) {
+	query := `
+		SELECT
+			rs.reservation_service_id,
+			rs.reservation_id,
+			rs.service_id,
+			rs.start_date,
+			rs.end_date,
+			rs.quantity,
+			COALESCE(rs.unit_price, s.price),
+			s.name,
+			rs.status
+		FROM reservation_service rs
+		INNER JOIN service s ON s.service_id = rs.service_id
+		WHERE rs.reservation_id = $1 AND rs.status = 1
+		ORDER BY rs.start_date, rs.reservation_service_id
+	`
+
+	rows, err := db.Query(query, reservaID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener servicios de la reserva: %w", err)
+	}
+	defer rows.Close()
 
+	servicios := make([]domain.ReservaServicio, 0)
+	for rows.Next() {
+		var rs domain.ReservaServicio
+		err := rows.Scan(
+			&rs.ID,
+			&rs.ReservaID,
+			&rs.ServiceID,
+			&rs.StartDate,
+			&rs.EndDate,
+			&rs.Quantity,
+			&rs.UnitPrice,
+			&rs.ServiceName,
+			&rs.Status,
+		)
 		if err != nil {
-			return fmt.Errorf("error al crear servicio de reserva: %w", err)
+			return nil, fmt.Errorf("error al escanear servicio de reserva: %w", err)
 		}
+		servicios = append(servicios, rs)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar servicios de la reserva: %w", err)
+	}
+
+	return servicios, nil
+}
+
+// ajustarSubtotal suma (o resta, si es negativa) la diferencia al subtotal de la reserva.
+// Se actualiza de forma relativa para no perder cambios concurrentes
+func ajustarSubtotal(db dbtx, reservaID int, diferencia float64) error {
+	_, err := db.Exec(`UPDATE reservation SET subtotal = subtotal + $1 WHERE reservation_id = $2`, diferencia, reservaID)
+	if err != nil {
+		return fmt.Errorf("error al actualizar subtotal de la reserva: %w", err)
+	}
 	return nil
 }
 
-// UpdateExpiredReservations actualiza reservas confirmadas a completadas cuando la fecha de checkout ha pasado
-func (r *reservaRepository) UpdateExpiredReservations() error {
+// GetNoShowCandidatos obtiene las reservas confirmadas sin check-in que ya superaron el corte
+func (r *reservaRepository) GetNoShowCandidatos(limite, hoy time.Time) ([]int, error) {
 	query := `
-		UPDATE reservation r
-		SET status = 'Completada'
+		SELECT r.reservation_id
+		FROM reservation r
+		INNER JOIN reservation_room rh ON rh.reservation_id = r.reservation_id AND rh.status = 1
 		WHERE r.status = 'Confirmada'
-		AND EXISTS (
-			SELECT 1 
-			FROM reservation_room rh
-			WHERE rh.reservation_id = r.reservation_id
-			GROUP BY rh.reservation_id
-			HAVING MAX(rh.check_out_date) < CURRENT_DATE
-		)
+		AND r.checked_in_at IS NULL
+		GROUP BY r.reservation_id
+		HAVING MIN(rh.check_in_date) <= $1::timestamp
+		OR MAX(rh.check_out_date) <= $2::date
+		ORDER BY r.reservation_id
 	`
 
-	result, err := r.db.Exec(query)
+	rows, err := r.db.Query(query, limite, hoy)
 	if err != nil {
-		return fmt.Errorf("error al actualizar reservas expiradas: %w", err)
+		return nil, fmt.Errorf("error al obtener candidatos a no-show: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected > 0 {
-		fmt.Printf("Reservas actualizadas a Completada: %d\n", rowsAffected)
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al escanear candidato a no-show: %w", err)
+		}
+		ids = append(ids, id)
 	}
 
-	return nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar candidatos a no-show: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ReleaseExpiredHolds cancela las reservas pendientes cuya retención venció y libera sus habitaciones
+func (r *reservaRepository) ReleaseExpiredHolds(ahora time.Time) ([]int, error) {
+	var liberadas []int
+	err := runInTx(r.db, func(tx dbtx) error {
+		var err error
+		liberadas, err = liberarRetencionesVencidas(tx, ahora, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return liberadas, nil
 }
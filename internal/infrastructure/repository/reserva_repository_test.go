@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// Driver de database/sql que no ejecuta nada: registra cada consulta con sus argumentos y
+// responde una fila con el valor 1, suficiente para los RETURNING de un ID
+
+type consultaRegistrada struct {
+	query string
+	args  []driver.Value
+}
+
+type registroDriver struct {
+	consultas *[]consultaRegistrada
+}
+
+func (d registroDriver) Open(string) (driver.Conn, error) {
+	return registroConn(d), nil
+}
+
+type registroConn registroDriver
+
+func (c registroConn) Prepare(query string) (driver.Stmt, error) {
+	return registroStmt{conn: c, query: query}, nil
+}
+
+func (c registroConn) Close() error { return nil }
+
+func (c registroConn) Begin() (driver.Tx, error) { return registroTx{}, nil }
+
+type registroTx struct{}
+
+func (registroTx) Commit() error   { return nil }
+func (registroTx) Rollback() error { return nil }
+
+type registroStmt struct {
+	conn  registroConn
+	query string
+}
+
+func (s registroStmt) Close() error { return nil }
+
+// NumInput retorna -1 para que database/sql no valide la cantidad de argumentos: esa es
+// justamente la verificación que hace la prueba
+func (s registroStmt) NumInput() int { return -1 }
+
+func (s registroStmt) Exec(args []driver.Value) (driver.Result, error) {
+	*s.conn.consultas = append(*s.conn.consultas, consultaRegistrada{query: s.query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s registroStmt) Query(args []driver.Value) (driver.Rows, error) {
+	*s.conn.consultas = append(*s.conn.consultas, consultaRegistrada{query: s.query, args: args})
+	return &registroRows{}, nil
+}
+
+type registroRows struct {
+	leida bool
+}
+
+func (r *registroRows) Columns() []string { return []string{"id"} }
+func (r *registroRows) Close() error      { return nil }
+
+func (r *registroRows) Next(dest []driver.Value) error {
+	if r.leida {
+		return io.EOF
+	}
+	r.leida = true
+	dest[0] = int64(1)
+	return nil
+}
+
+var contadorDriversRegistro int
+
+// nuevaDBRegistro abre una conexión sobre un driver de registro nuevo
+func nuevaDBRegistro(t *testing.T) (*sql.DB, *[]consultaRegistrada) {
+	t.Helper()
+	consultas := &[]consultaRegistrada{}
+	contadorDriversRegistro++
+	nombre := "registro" + strconv.Itoa(contadorDriversRegistro)
+	sql.Register(nombre, registroDriver{consultas: consultas})
+
+	db, err := sql.Open(nombre, "")
+	if err != nil {
+		t.Fatalf("error al abrir la base de registro: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, consultas
+}
+
+var placeholderRegexp = regexp.MustCompile(`\$(\d+)`)
+
+// verificarPlaceholders falla si la consulta no usa exactamente los placeholders $1..$n de
+// sus n argumentos
+func verificarPlaceholders(t *testing.T, c consultaRegistrada) {
+	t.Helper()
+	usados := make(map[int]bool)
+	for _, m := range placeholderRegexp.FindAllStringSubmatch(c.query, -1) {
+		n, _ := strconv.Atoi(m[1])
+		usados[n] = true
+	}
+	for n := range usados {
+		if n < 1 || n > len(c.args) {
+			t.Errorf("la consulta usa $%d pero recibe %d argumentos:\n%s", n, len(c.args), c.query)
+		}
+	}
+	for n := 1; n <= len(c.args); n++ {
+		if !usados[n] {
+			t.Errorf("la consulta recibe el argumento $%d pero no lo usa:\n%s", n, c.query)
+		}
+	}
+}
+
+func TestInsertarReservaServicioPlaceholders(t *testing.T) {
+	db, consultas := nuevaDBRegistro(t)
+
+	servicio := &domain.ReservaServicio{
+		ReservaID: 10,
+		ServiceID: 3,
+		StartDate: time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2030, 3, 12, 0, 0, 0, 0, time.UTC),
+		Quantity:  2,
+		UnitPrice: 25,
+		Status:    1,
+	}
+	if err := insertarReservaServicio(db, servicio); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if servicio.ID != 1 {
+		t.Errorf("ID = %d, se esperaba el ID retornado por la base", servicio.ID)
+	}
+
+	if len(*consultas) != 1 {
+		t.Fatalf("consultas = %d, se esperaba 1", len(*consultas))
+	}
+	verificarPlaceholders(t, (*consultas)[0])
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type reservationGuestRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 // NewReservationGuestRepository crea una nueva instancia del repositorio de huéspedes
@@ -38,35 +38,26 @@ func (r *reservationGuestRepository) CreateMultiple(reservationID int, personIDs
 	}
 
 	// Usar una transacción para insertar todas las relaciones
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("error al iniciar transacción: %w", err)
-	}
-	defer tx.Rollback()
-
-	query := `
-		INSERT INTO reservation_guest (reservation_id, person_id)
-		VALUES ($1, $2)
-	`
+	return runInTx(r.db, func(tx dbtx) error {
+		query := `
+			INSERT INTO reservation_guest (reservation_id, person_id)
+			VALUES ($1, $2)
+		`
 
-	stmt, err := tx.Prepare(query)
-	if err != nil {
-		return fmt.Errorf("error al preparar statement: %w", err)
-	}
-	defer stmt.Close()
-
-	for i, personID := range personIDs {
-		_, err := stmt.Exec(reservationID, personID)
+		stmt, err := tx.Prepare(query)
 		if err != nil {
-			return fmt.Errorf("error al crear relación %d: %w", i+1, err)
+			return fmt.Errorf("error al preparar statement: %w", err)
 		}
-	}
+		defer stmt.Close()
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("error al confirmar transacción: %w", err)
-	}
+		for i, personID := range personIDs {
+			if _, err := stmt.Exec(reservationID, personID); err != nil {
+				return fmt.Errorf("error al crear relación %d: %w", i+1, err)
+			}
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // GetByReservationID obtiene todos los person_id de una reserva
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type restriccionEstadiaRepository struct {
+	db dbtx
+}
+
+// NewRestriccionEstadiaRepository crea una nueva instancia del repositorio de restricciones de estadía
+func NewRestriccionEstadiaRepository(db *sql.DB) domain.RestriccionEstadiaRepository {
+	return &restriccionEstadiaRepository{db: db}
+}
+
+const restriccionEstadiaColumns = `
+	stay_restriction_id,
+	room_type_id,
+	restriction_type,
+	start_date,
+	end_date,
+	nights,
+	reason,
+	active`
+
+// GetAll obtiene las restricciones de un tipo de habitación, incluidas las de todo el hotel
+// (0 = todas las restricciones)
+func (r *restriccionEstadiaRepository) GetAll(tipoHabitacionID int) ([]domain.RestriccionEstadia, error) {
+	query := `SELECT ` + restriccionEstadiaColumns + `
+		FROM stay_restriction
+		WHERE ($1 = 0 OR room_type_id IS NULL OR room_type_id = $1)
+		ORDER BY start_date, stay_restriction_id`
+
+	return r.queryRestricciones(query, tipoHabitacionID)
+}
+
+// GetEnRango obtiene las restricciones activas de todos los tipos que se solapan con [desde, hasta]
+func (r *restriccionEstadiaRepository) GetEnRango(desde, hasta time.Time) ([]domain.RestriccionEstadia, error) {
+	query := `SELECT ` + restriccionEstadiaColumns + `
+		FROM stay_restriction
+		WHERE active
+		AND start_date <= $2::date
+		AND end_date >= $1::date
+		ORDER BY start_date, stay_restriction_id`
+
+	return r.queryRestricciones(query, desde, hasta)
+}
+
+// GetByID obtiene una restricción por su ID
+func (r *restriccionEstadiaRepository) GetByID(id int) (*domain.RestriccionEstadia, error) {
+	query := `SELECT ` + restriccionEstadiaColumns + `
+		FROM stay_restriction
+		WHERE stay_restriction_id = $1`
+
+	restriccion, err := scanRestriccionEstadia(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: ID %d", domain.ErrRestriccionNoEncontrada, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return restriccion, nil
+}
+
+// queryRestricciones ejecuta una consulta que retorna las columnas de restriccionEstadiaColumns
+func (r *restriccionEstadiaRepository) queryRestricciones(query string, args ...interface{}) ([]domain.RestriccionEstadia, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener restricciones de estadía: %w", err)
+	}
+	defer rows.Close()
+
+	restricciones := make([]domain.RestriccionEstadia, 0)
+	for rows.Next() {
+		restriccion, err := scanRestriccionEstadia(rows)
+		if err != nil {
+			return nil, err
+		}
+		restricciones = append(restricciones, *restriccion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar restricciones de estadía: %w", err)
+	}
+
+	return restricciones, nil
+}
+
+// Create crea una nueva restricción
+func (r *restriccionEstadiaRepository) Create(restriccion *domain.RestriccionEstadia) error {
+	query := `
+		INSERT INTO stay_restriction (
+			room_type_id,
+			restriction_type,
+			start_date,
+			end_date,
+			nights,
+			reason,
+			active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING stay_restriction_id`
+
+	err := r.db.QueryRow(
+		query,
+		restriccion.TipoHabitacionID,
+		restriccion.Tipo,
+		restriccion.FechaInicio,
+		restriccion.FechaFin,
+		restriccion.Noches,
+		restriccion.Motivo,
+		restriccion.Activa,
+	).Scan(&restriccion.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear restricción de estadía: %w", err)
+	}
+
+	return nil
+}
+
+// Update actualiza una restricción existente
+func (r *restriccionEstadiaRepository) Update(restriccion *domain.RestriccionEstadia) error {
+	query := `
+		UPDATE stay_restriction
+		SET room_type_id = $1,
+			restriction_type = $2,
+			start_date = $3,
+			end_date = $4,
+			nights = $5,
+			reason = $6,
+			active = $7
+		WHERE stay_restriction_id = $8`
+
+	result, err := r.db.Exec(
+		query,
+		restriccion.TipoHabitacionID,
+		restriccion.Tipo,
+		restriccion.FechaInicio,
+		restriccion.FechaFin,
+		restriccion.Noches,
+		restriccion.Motivo,
+		restriccion.Activa,
+		restriccion.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar restricción de estadía: %w", err)
+	}
+
+	return verificarRestriccionAfectada(result, restriccion.ID)
+}
+
+// Delete elimina una restricción
+func (r *restriccionEstadiaRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM stay_restriction WHERE stay_restriction_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar restricción de estadía: %w", err)
+	}
+
+	return verificarRestriccionAfectada(result, id)
+}
+
+// verificarRestriccionAfectada retorna ErrRestriccionNoEncontrada si la operación no afectó ninguna fila
+func verificarRestriccionAfectada(result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", domain.ErrRestriccionNoEncontrada, id)
+	}
+
+	return nil
+}
+
+// scanRestriccionEstadia escanea una fila con las columnas de restriccionEstadiaColumns
+func scanRestriccionEstadia(row rowScanner) (*domain.RestriccionEstadia, error) {
+	var restriccion domain.RestriccionEstadia
+	var tipoHabitacionID, noches sql.NullInt64
+
+	err := row.Scan(
+		&restriccion.ID,
+		&tipoHabitacionID,
+		&restriccion.Tipo,
+		&restriccion.FechaInicio,
+		&restriccion.FechaFin,
+		&noches,
+		&restriccion.Motivo,
+		&restriccion.Activa,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al escanear restricción de estadía: %w", err)
+	}
+
+	if tipoHabitacionID.Valid {
+		tipo := int(tipoHabitacionID.Int64)
+		restriccion.TipoHabitacionID = &tipo
+	}
+	if noches.Valid {
+		cantidad := int(noches.Int64)
+		restriccion.Noches = &cantidad
+	}
+	restriccion.FechaInicio = restriccion.FechaInicio.UTC()
+	restriccion.FechaFin = restriccion.FechaFin.UTC()
+
+	return &restriccion, nil
+}
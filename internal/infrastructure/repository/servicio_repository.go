@@ -58,3 +58,33 @@ func (r *servicioRepository) GetAllServices() ([]domain.Servicio, error) {
 
 	return servicios, nil
 }
+
+// GetByID implementa domain.ServicioRepository
+func (r *servicioRepository) GetByID(id int) (*domain.Servicio, error) {
+	query := `
+		SELECT 
+			service_id,
+			name,
+			description,
+			price
+		FROM 
+			service
+		WHERE 
+			service_id = $1;`
+
+	var s domain.Servicio
+	err := r.db.QueryRow(query, id).Scan(
+		&s.ID,
+		&s.Name,
+		&s.Description,
+		&s.Price,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("servicio con ID %d no encontrado", id)
+		}
+		return nil, fmt.Errorf("error querying service: %w", err)
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+type tarifaRepository struct {
+	db *sql.DB
+}
+
+// NewTarifaRepository crea una nueva instancia del repositorio del calendario de tarifas
+func NewTarifaRepository(db *sql.DB) domain.TarifaRepository {
+	return &tarifaRepository{db: db}
+}
+
+const temporadaTarifaColumns = `
+	rate_season_id,
+	room_type_id,
+	name,
+	start_date,
+	end_date,
+	nightly_price,
+	weekend_price,
+	priority,
+	active`
+
+// GetTemporadas obtiene las temporadas de un tipo de habitación (0 = todos los tipos)
+func (r *tarifaRepository) GetTemporadas(tipoHabitacionID int) ([]domain.TemporadaTarifa, error) {
+	query := `SELECT ` + temporadaTarifaColumns + `
+		FROM rate_season
+		WHERE ($1 = 0 OR room_type_id = $1)
+		ORDER BY room_type_id, start_date, priority DESC`
+
+	return r.queryTemporadas(query, tipoHabitacionID)
+}
+
+// GetTemporadasEnRango obtiene las temporadas activas del tipo que se solapan con [desde, hasta)
+func (r *tarifaRepository) GetTemporadasEnRango(tipoHabitacionID int, desde, hasta time.Time) ([]domain.TemporadaTarifa, error) {
+	query := `SELECT ` + temporadaTarifaColumns + `
+		FROM rate_season
+		WHERE active
+		AND room_type_id = $1
+		AND start_date < $3::date
+		AND end_date >= $2::date
+		ORDER BY priority DESC, rate_season_id DESC`
+
+	return r.queryTemporadas(query, tipoHabitacionID, desde, hasta)
+}
+
+// queryTemporadas ejecuta una consulta que retorna las columnas de temporadaTarifaColumns
+func (r *tarifaRepository) queryTemporadas(query string, args ...interface{}) ([]domain.TemporadaTarifa, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener temporadas de tarifa: %w", err)
+	}
+	defer rows.Close()
+
+	temporadas := make([]domain.TemporadaTarifa, 0)
+	for rows.Next() {
+		var temporada domain.TemporadaTarifa
+		var precioFinSemana sql.NullFloat64
+
+		err := rows.Scan(
+			&temporada.ID,
+			&temporada.TipoHabitacionID,
+			&temporada.Nombre,
+			&temporada.FechaInicio,
+			&temporada.FechaFin,
+			&temporada.PrecioNoche,
+			&precioFinSemana,
+			&temporada.Prioridad,
+			&temporada.Activa,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear temporada de tarifa: %w", err)
+		}
+
+		if precioFinSemana.Valid {
+			temporada.PrecioFinSemana = &precioFinSemana.Float64
+		}
+		temporada.FechaInicio = temporada.FechaInicio.UTC()
+		temporada.FechaFin = temporada.FechaFin.UTC()
+		temporadas = append(temporadas, temporada)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar temporadas de tarifa: %w", err)
+	}
+
+	return temporadas, nil
+}
+
+// CreateTemporada crea una nueva temporada
+func (r *tarifaRepository) CreateTemporada(temporada *domain.TemporadaTarifa) error {
+	query := `
+		INSERT INTO rate_season (
+			room_type_id,
+			name,
+			start_date,
+			end_date,
+			nightly_price,
+			weekend_price,
+			priority,
+			active
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING rate_season_id`
+
+	err := r.db.QueryRow(
+		query,
+		temporada.TipoHabitacionID,
+		temporada.Nombre,
+		temporada.FechaInicio,
+		temporada.FechaFin,
+		temporada.PrecioNoche,
+		temporada.PrecioFinSemana,
+		temporada.Prioridad,
+		temporada.Activa,
+	).Scan(&temporada.ID)
+	if err != nil {
+		return fmt.Errorf("error al crear temporada de tarifa: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTemporada actualiza una temporada existente
+func (r *tarifaRepository) UpdateTemporada(temporada *domain.TemporadaTarifa) error {
+	query := `
+		UPDATE rate_season
+		SET room_type_id = $1,
+			name = $2,
+			start_date = $3,
+			end_date = $4,
+			nightly_price = $5,
+			weekend_price = $6,
+			priority = $7,
+			active = $8
+		WHERE rate_season_id = $9`
+
+	result, err := r.db.Exec(
+		query,
+		temporada.TipoHabitacionID,
+		temporada.Nombre,
+		temporada.FechaInicio,
+		temporada.FechaFin,
+		temporada.PrecioNoche,
+		temporada.PrecioFinSemana,
+		temporada.Prioridad,
+		temporada.Activa,
+		temporada.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar temporada de tarifa: %w", err)
+	}
+
+	return verificarTarifaAfectada(result, fmt.Sprintf("temporada %d", temporada.ID))
+}
+
+// DeleteTemporada elimina una temporada
+func (r *tarifaRepository) DeleteTemporada(id int) error {
+	result, err := r.db.Exec(`DELETE FROM rate_season WHERE rate_season_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar temporada de tarifa: %w", err)
+	}
+
+	return verificarTarifaAfectada(result, fmt.Sprintf("temporada %d", id))
+}
+
+// GetPreciosFecha obtiene los precios por fecha del tipo en [desde, hasta)
+func (r *tarifaRepository) GetPreciosFecha(tipoHabitacionID int, desde, hasta time.Time) ([]domain.PrecioFecha, error) {
+	query := `
+		SELECT rate_date_override_id, room_type_id, rate_date, price, reason
+		FROM rate_date_override
+		WHERE ($1 = 0 OR room_type_id = $1)
+		AND rate_date >= $2::date
+		AND rate_date < $3::date
+		ORDER BY rate_date, room_type_id`
+
+	rows, err := r.db.Query(query, tipoHabitacionID, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener precios por fecha: %w", err)
+	}
+	defer rows.Close()
+
+	precios := make([]domain.PrecioFecha, 0)
+	for rows.Next() {
+		var precio domain.PrecioFecha
+		if err := rows.Scan(&precio.ID, &precio.TipoHabitacionID, &precio.Fecha, &precio.Precio, &precio.Motivo); err != nil {
+			return nil, fmt.Errorf("error al escanear precio por fecha: %w", err)
+		}
+		precio.Fecha = precio.Fecha.UTC()
+		precios = append(precios, precio)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar precios por fecha: %w", err)
+	}
+
+	return precios, nil
+}
+
+// GuardarPrecioFecha crea o reemplaza el precio de una fecha para el tipo de habitación
+func (r *tarifaRepository) GuardarPrecioFecha(precio *domain.PrecioFecha) error {
+	query := `
+		INSERT INTO rate_date_override (room_type_id, rate_date, price, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (room_type_id, rate_date) DO UPDATE
+		SET price = EXCLUDED.price,
+			reason = EXCLUDED.reason
+		RETURNING rate_date_override_id`
+
+	err := r.db.QueryRow(query, precio.TipoHabitacionID, precio.Fecha, precio.Precio, precio.Motivo).Scan(&precio.ID)
+	if err != nil {
+		return fmt.Errorf("error al guardar precio por fecha: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePrecioFecha elimina el precio de una fecha para el tipo de habitación
+func (r *tarifaRepository) DeletePrecioFecha(tipoHabitacionID int, fecha time.Time) error {
+	result, err := r.db.Exec(`
+		DELETE FROM rate_date_override
+		WHERE room_type_id = $1 AND rate_date = $2::date
+	`, tipoHabitacionID, fecha)
+	if err != nil {
+		return fmt.Errorf("error al eliminar precio por fecha: %w", err)
+	}
+
+	return verificarTarifaAfectada(result, fmt.Sprintf("precio del %s", fecha.Format("2006-01-02")))
+}
+
+// GetTarifaOcupacion obtiene los recargos por huéspedes del tipo, o nil si no tiene configurados
+func (r *tarifaRepository) GetTarifaOcupacion(tipoHabitacionID int) (*domain.TarifaOcupacion, error) {
+	tarifa := &domain.TarifaOcupacion{TipoHabitacionID: tipoHabitacionID}
+	err := r.db.QueryRow(`
+		SELECT included_adults, extra_adult_price
+		FROM room_type_occupancy_rate
+		WHERE room_type_id = $1
+	`, tipoHabitacionID).Scan(&tarifa.AdultosIncluidos, &tarifa.PrecioAdultoExtra)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener tarifa por ocupación: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT min_age, max_age, nightly_price
+		FROM room_type_child_rate
+		WHERE room_type_id = $1
+		ORDER BY min_age
+	`, tipoHabitacionID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener bandas de edad: %w", err)
+	}
+	defer rows.Close()
+
+	tarifa.BandasNinhos = make([]domain.BandaEdadNinho, 0)
+	for rows.Next() {
+		var banda domain.BandaEdadNinho
+		if err := rows.Scan(&banda.EdadMinima, &banda.EdadMaxima, &banda.PrecioNoche); err != nil {
+			return nil, fmt.Errorf("error al escanear banda de edad: %w", err)
+		}
+		tarifa.BandasNinhos = append(tarifa.BandasNinhos, banda)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar bandas de edad: %w", err)
+	}
+
+	return tarifa, nil
+}
+
+// GuardarTarifaOcupacion crea o reemplaza los recargos por huéspedes del tipo con sus bandas de edad
+func (r *tarifaRepository) GuardarTarifaOcupacion(tarifa *domain.TarifaOcupacion) error {
+	return runInTx(r.db, func(tx dbtx) error {
+		_, err := tx.Exec(`
+			INSERT INTO room_type_occupancy_rate (room_type_id, included_adults, extra_adult_price)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (room_type_id) DO UPDATE
+			SET included_adults = EXCLUDED.included_adults,
+				extra_adult_price = EXCLUDED.extra_adult_price
+		`, tarifa.TipoHabitacionID, tarifa.AdultosIncluidos, tarifa.PrecioAdultoExtra)
+		if err != nil {
+			return fmt.Errorf("error al guardar tarifa por ocupación: %w", err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM room_type_child_rate WHERE room_type_id = $1`, tarifa.TipoHabitacionID); err != nil {
+			return fmt.Errorf("error al actualizar bandas de edad: %w", err)
+		}
+		for _, banda := range tarifa.BandasNinhos {
+			_, err := tx.Exec(`
+				INSERT INTO room_type_child_rate (room_type_id, min_age, max_age, nightly_price)
+				VALUES ($1, $2, $3, $4)
+			`, tarifa.TipoHabitacionID, banda.EdadMinima, banda.EdadMaxima, banda.PrecioNoche)
+			if err != nil {
+				return fmt.Errorf("error al guardar banda de edad: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// verificarTarifaAfectada retorna ErrTarifaNoEncontrada si la operación no afectó ninguna fila
+func verificarTarifaAfectada(result sql.Result, descripcion string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrTarifaNoEncontrada, descripcion)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Maxito7/hotel_backend/internal/domain"
+)
+
+// dbtx abstrae *sql.DB y *sql.Tx para que un repositorio pueda operar
+// tanto de forma independiente como dentro de una transacción compartida
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// runInTx ejecuta fn dentro de una transacción. Si db ya es una transacción
+// (el repositorio fue creado por un UnitOfWork) fn se ejecuta sobre ella y el
+// commit/rollback queda a cargo de quien la abrió
+func runInTx(db dbtx, fn func(tx dbtx) error) error {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := sqlDB.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
+
+type unitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork crea una unidad de trabajo que comparte una transacción entre repositorios
+func NewUnitOfWork(db *sql.DB) domain.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+// Do abre una transacción, construye los repositorios sobre ella y ejecuta fn.
+// Si fn retorna error se hace rollback; en caso contrario, commit
+func (u *unitOfWork) Do(fn func(repos domain.Repositories) error) error {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	repos := domain.Repositories{
+		Person:            &personRepository{db: tx},
+		Client:            &clientRepository{db: tx},
+		Reserva:           &reservaRepository{db: tx},
+		ReservaHabitacion: &reservaHabitacionRepository{db: tx},
+		ReservationGuest:  &reservationGuestRepository{db: tx},
+		Payment:           &paymentRepository{db: tx},
+		HistorialEstado:   &historialEstadoRepository{db: tx},
+		Estancia:          &estanciaRepository{db: tx},
+		Alerta:            &alertaRepository{db: tx},
+		CodigoPromocional: &codigoPromocionalRepository{db: tx},
+		Limpieza:          &limpiezaRepository{db: tx},
+	}
+
+	if err := fn(repos); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BloqueGrupoHandler struct {
+	service *application.BloqueGrupoService
+}
+
+func NewBloqueGrupoHandler(service *application.BloqueGrupoService) *BloqueGrupoHandler {
+	return &BloqueGrupoHandler{service: service}
+}
+
+// BloqueGrupoRequest representa la petición para crear o modificar un bloque de grupo
+type BloqueGrupoRequest struct {
+	Nombre           string   `json:"nombre"`
+	Contacto         string   `json:"contacto"`
+	Email            *string  `json:"email,omitempty"`
+	TipoHabitacionID int      `json:"tipoHabitacionId"`
+	Cantidad         int      `json:"cantidad"`
+	FechaEntrada     string   `json:"fechaEntrada"`
+	FechaSalida      string   `json:"fechaSalida"`
+	FechaLiberacion  string   `json:"fechaLiberacion"`
+	Precio           *float64 `json:"precio,omitempty"`
+}
+
+// toDomain convierte la petición en un bloque de grupo
+func (r BloqueGrupoRequest) toDomain() (*domain.BloqueGrupo, error) {
+	fechaEntrada, err := time.Parse("2006-01-02", r.FechaEntrada)
+	if err != nil {
+		return nil, errors.New("Formato de fechaEntrada inválido. Use YYYY-MM-DD")
+	}
+	fechaSalida, err := time.Parse("2006-01-02", r.FechaSalida)
+	if err != nil {
+		return nil, errors.New("Formato de fechaSalida inválido. Use YYYY-MM-DD")
+	}
+	fechaLiberacion, err := time.Parse("2006-01-02", r.FechaLiberacion)
+	if err != nil {
+		return nil, errors.New("Formato de fechaLiberacion inválido. Use YYYY-MM-DD")
+	}
+
+	return &domain.BloqueGrupo{
+		Nombre:           r.Nombre,
+		Contacto:         r.Contacto,
+		Email:            r.Email,
+		TipoHabitacionID: r.TipoHabitacionID,
+		Cantidad:         r.Cantidad,
+		FechaEntrada:     fechaEntrada,
+		FechaSalida:      fechaSalida,
+		FechaLiberacion:  fechaLiberacion,
+		Precio:           r.Precio,
+	}, nil
+}
+
+// GetAll lista los bloques de grupo
+func (h *BloqueGrupoHandler) GetAll(c *fiber.Ctx) error {
+	bloques, err := h.service.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": bloques})
+}
+
+// GetByID obtiene un bloque de grupo
+func (h *BloqueGrupoHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de bloque inválido"})
+	}
+
+	bloque, err := h.service.GetByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": bloque})
+}
+
+// Create aparta un nuevo bloque de habitaciones para un grupo
+func (h *BloqueGrupoHandler) Create(c *fiber.Ctx) error {
+	var req BloqueGrupoRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	bloque, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.service.Create(bloque); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": bloque})
+}
+
+// Update modifica un bloque de grupo activo
+func (h *BloqueGrupoHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de bloque inválido"})
+	}
+
+	var req BloqueGrupoRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	bloque, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	bloque.ID = id
+
+	if err := h.service.Update(bloque); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": bloque})
+}
+
+// Liberar devuelve a la venta general las habitaciones no recogidas del bloque
+func (h *BloqueGrupoHandler) Liberar(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de bloque inválido"})
+	}
+
+	if err := h.service.Liberar(id); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Bloque liberado exitosamente"})
+}
+
+func (h *BloqueGrupoHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrBloqueGrupoNoEncontrado):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrBloqueGrupoInvalido):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrHabitacionNoDisponible):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
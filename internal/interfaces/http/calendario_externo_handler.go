@@ -0,0 +1,236 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// tamanoMaximoArchivoICal es el tamaño máximo de un .ics subido (5 MB)
+const tamanoMaximoArchivoICal = 5 << 20
+
+type CalendarioExternoHandler struct {
+	service *application.CalendarioExternoService
+}
+
+func NewCalendarioExternoHandler(service *application.CalendarioExternoService) *CalendarioExternoHandler {
+	return &CalendarioExternoHandler{service: service}
+}
+
+// CalendarioExternoRequest representa la petición para registrar o modificar un calendario
+// externo leído desde una URL
+type CalendarioExternoRequest struct {
+	HabitacionID int    `json:"habitacionId"`
+	Nombre       string `json:"nombre"`
+	URL          string `json:"url,omitempty"`    // http(s) o webcal; se conserva la actual si se omite al modificar
+	Activo       *bool  `json:"activo,omitempty"` // Por defecto true
+}
+
+// toDomain convierte la petición en un calendario externo
+func (r CalendarioExternoRequest) toDomain() *domain.CalendarioExterno {
+	activo := true
+	if r.Activo != nil {
+		activo = *r.Activo
+	}
+
+	return &domain.CalendarioExterno{
+		HabitacionID: r.HabitacionID,
+		Nombre:       r.Nombre,
+		URL:          r.URL,
+		Activo:       activo,
+	}
+}
+
+// GetAll lista los calendarios externos (filtro opcional: habitacionId)
+func (h *CalendarioExternoHandler) GetAll(c *fiber.Ctx) error {
+	calendarios, err := h.service.GetAll(c.QueryInt("habitacionId", 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": calendarios})
+}
+
+// GetByID obtiene un calendario externo con sus bloqueos importados
+func (h *CalendarioExternoHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de calendario inválido"})
+	}
+
+	calendario, bloqueos, err := h.service.GetByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": fiber.Map{
+		"calendario": calendario,
+		"bloqueos":   bloqueos,
+	}})
+}
+
+// GetConflictos lista los bloqueos importados que se solapan con reservas del hotel
+// (filtro opcional: habitacionId)
+func (h *CalendarioExternoHandler) GetConflictos(c *fiber.Ctx) error {
+	conflictos, err := h.service.GetConflictos(c.QueryInt("habitacionId", 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": conflictos})
+}
+
+// Create registra un calendario externo leído desde una URL y lo sincroniza
+func (h *CalendarioExternoHandler) Create(c *fiber.Ctx) error {
+	var req CalendarioExternoRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	calendario := req.toDomain()
+	resultado, err := h.service.Create(calendario)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": fiber.Map{
+		"calendario":     calendario,
+		"sincronizacion": resultado,
+	}})
+}
+
+// CreateArchivo registra un calendario externo desde un .ics subido (multipart: habitacionId,
+// nombre, activo opcional y el archivo en "archivo") y lo sincroniza
+func (h *CalendarioExternoHandler) CreateArchivo(c *fiber.Ctx) error {
+	habitacionID, err := strconv.Atoi(c.FormValue("habitacionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "habitacionId inválido"})
+	}
+
+	contenido, err := leerArchivoICal(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	calendario := &domain.CalendarioExterno{
+		HabitacionID: habitacionID,
+		Nombre:       c.FormValue("nombre"),
+		Contenido:    contenido,
+		Activo:       c.FormValue("activo", "true") != "false",
+	}
+	resultado, err := h.service.Create(calendario)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": fiber.Map{
+		"calendario":     calendario,
+		"sincronizacion": resultado,
+	}})
+}
+
+// Update modifica un calendario externo y lo vuelve a sincronizar
+func (h *CalendarioExternoHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de calendario inválido"})
+	}
+
+	var req CalendarioExternoRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	calendario := req.toDomain()
+	calendario.ID = id
+	resultado, err := h.service.Update(calendario)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": fiber.Map{
+		"calendario":     calendario,
+		"sincronizacion": resultado,
+	}})
+}
+
+// SubirArchivo reemplaza el origen de un calendario externo por un .ics subido (multipart:
+// "archivo") y lo sincroniza
+func (h *CalendarioExternoHandler) SubirArchivo(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de calendario inválido"})
+	}
+
+	contenido, err := leerArchivoICal(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resultado, err := h.service.SubirArchivo(id, contenido)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": resultado})
+}
+
+// Sincronizar importa de nuevo un calendario externo y reporta sus conflictos
+func (h *CalendarioExternoHandler) Sincronizar(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de calendario inválido"})
+	}
+
+	resultado, err := h.service.Sincronizar(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	if resultado.Error != "" {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": resultado.Error, "data": resultado})
+	}
+	return c.JSON(fiber.Map{"data": resultado})
+}
+
+// Delete elimina un calendario externo y libera sus bloqueos
+func (h *CalendarioExternoHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de calendario inválido"})
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Calendario externo eliminado exitosamente"})
+}
+
+// leerArchivoICal lee el .ics subido en el campo "archivo"
+func leerArchivoICal(c *fiber.Ctx) (string, error) {
+	fileHeader, err := c.FormFile("archivo")
+	if err != nil {
+		return "", errors.New("Debe subir el archivo .ics en el campo 'archivo'")
+	}
+	if fileHeader.Size > tamanoMaximoArchivoICal {
+		return "", errors.New("El archivo supera el tamaño máximo de 5 MB")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", errors.New("Error al abrir el archivo")
+	}
+	defer file.Close()
+
+	contenido, err := io.ReadAll(file)
+	if err != nil {
+		return "", errors.New("Error al leer el archivo")
+	}
+	return string(contenido), nil
+}
+
+func (h *CalendarioExternoHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCalendarioExternoNoEncontrado):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrCalendarioExternoInvalido):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
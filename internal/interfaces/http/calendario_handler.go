@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CalendarioHandler struct {
+	service *application.CalendarioService
+}
+
+func NewCalendarioHandler(service *application.CalendarioService) *CalendarioHandler {
+	return &CalendarioHandler{service: service}
+}
+
+// ExportarHabitacion publica el feed iCal de una habitación (?token=)
+func (h *CalendarioHandler) ExportarHabitacion(c *fiber.Ctx) error {
+	return h.exportar(c, domain.CalendarioHabitacion)
+}
+
+// ExportarTipoHabitacion publica el feed iCal de un tipo de habitación (?token=)
+func (h *CalendarioHandler) ExportarTipoHabitacion(c *fiber.Ctx) error {
+	return h.exportar(c, domain.CalendarioTipoHabitacion)
+}
+
+// GetFeedHabitacion retorna el token y la URL del feed iCal de una habitación
+func (h *CalendarioHandler) GetFeedHabitacion(c *fiber.Ctx) error {
+	return h.getFeed(c, domain.CalendarioHabitacion, false)
+}
+
+// GetFeedTipoHabitacion retorna el token y la URL del feed iCal de un tipo de habitación
+func (h *CalendarioHandler) GetFeedTipoHabitacion(c *fiber.Ctx) error {
+	return h.getFeed(c, domain.CalendarioTipoHabitacion, false)
+}
+
+// RotarTokenHabitacion genera un token nuevo para el feed iCal de una habitación
+func (h *CalendarioHandler) RotarTokenHabitacion(c *fiber.Ctx) error {
+	return h.getFeed(c, domain.CalendarioHabitacion, true)
+}
+
+// RotarTokenTipoHabitacion genera un token nuevo para el feed iCal de un tipo de habitación
+func (h *CalendarioHandler) RotarTokenTipoHabitacion(c *fiber.Ctx) error {
+	return h.getFeed(c, domain.CalendarioTipoHabitacion, true)
+}
+
+func (h *CalendarioHandler) exportar(c *fiber.Ctx, alcance domain.AlcanceCalendario) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	ical, err := h.service.ExportarICal(alcance, id, c.Query("token"))
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="calendar.ics"`)
+	return c.SendString(ical)
+}
+
+func (h *CalendarioHandler) getFeed(c *fiber.Ctx, alcance domain.AlcanceCalendario, rotar bool) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var feed *domain.CalendarioFeed
+	if rotar {
+		feed, err = h.service.RotarToken(alcance, id)
+	} else {
+		feed, err = h.service.GetFeed(alcance, id)
+	}
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	ruta := fmt.Sprintf("/api/habitaciones/%d/calendar.ics", id)
+	if alcance == domain.CalendarioTipoHabitacion {
+		ruta = fmt.Sprintf("/api/habitaciones/tipos/%d/calendar.ics", id)
+	}
+
+	return c.JSON(fiber.Map{"data": fiber.Map{
+		"feed": feed,
+		"url":  c.BaseURL() + ruta + "?token=" + feed.Token,
+	}})
+}
+
+func (h *CalendarioHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTokenCalendarioInvalido):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrCalendarioNoEncontrado):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CanalHandler struct {
+	service *application.CanalService
+}
+
+func NewCanalHandler(service *application.CanalService) *CanalHandler {
+	return &CanalHandler{service: service}
+}
+
+// CanalRequest representa la petición para registrar o modificar un canal de venta
+type CanalRequest struct {
+	Codigo string              `json:"codigo"` // Debe coincidir con un adaptador registrado
+	Nombre string              `json:"nombre"`
+	Activo *bool               `json:"activo,omitempty"` // Por defecto true
+	Mapeos []domain.MapeoCanal `json:"mapeos"`
+}
+
+// toDomain convierte la petición en un canal
+func (r CanalRequest) toDomain() *domain.Canal {
+	activo := true
+	if r.Activo != nil {
+		activo = *r.Activo
+	}
+
+	return &domain.Canal{
+		Codigo: r.Codigo,
+		Nombre: r.Nombre,
+		Activo: activo,
+		Mapeos: r.Mapeos,
+	}
+}
+
+// GetAll lista los canales de venta con sus mapeos y su última sincronización
+func (h *CanalHandler) GetAll(c *fiber.Ctx) error {
+	canales, err := h.service.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": canales})
+}
+
+// GetByID obtiene un canal de venta
+func (h *CanalHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de canal inválido"})
+	}
+
+	canal, err := h.service.GetByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": canal})
+}
+
+// Create registra un canal de venta
+func (h *CanalHandler) Create(c *fiber.Ctx) error {
+	var req CanalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	canal := req.toDomain()
+	if err := h.service.Create(canal); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": canal})
+}
+
+// Update modifica un canal de venta y reemplaza sus mapeos
+func (h *CanalHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de canal inválido"})
+	}
+
+	var req CanalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	canal := req.toDomain()
+	canal.ID = id
+	if err := h.service.Update(canal); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": canal})
+}
+
+// UpdateMapeos reemplaza los mapeos de tipos de habitación de un canal
+func (h *CanalHandler) UpdateMapeos(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de canal inválido"})
+	}
+
+	var mapeos []domain.MapeoCanal
+	if err := c.BodyParser(&mapeos); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	canal, err := h.service.UpdateMapeos(id, mapeos)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": canal})
+}
+
+// Sincronizar importa las reservas del canal y le publica inventario y tarifas
+func (h *CanalHandler) Sincronizar(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de canal inválido"})
+	}
+
+	resultado, err := h.service.Sincronizar(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	if resultado.Error != "" {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": resultado.Error, "data": resultado})
+	}
+	return c.JSON(fiber.Map{"data": resultado})
+}
+
+func (h *CanalHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCanalNoEncontrado):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrCanalInvalido):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CodigoPromocionalHandler struct {
+	service *application.CodigoPromocionalService
+}
+
+func NewCodigoPromocionalHandler(service *application.CodigoPromocionalService) *CodigoPromocionalHandler {
+	return &CodigoPromocionalHandler{service: service}
+}
+
+// CodigoPromocionalRequest representa la petición para crear o modificar un código promocional
+type CodigoPromocionalRequest struct {
+	CampanaID       int                    `json:"campanaId"`
+	Codigo          string                 `json:"codigo"`
+	Descripcion     string                 `json:"descripcion"`
+	TipoDescuento   domain.TipoModificador `json:"tipoDescuento"` // "porcentaje" o "monto"
+	Valor           float64                `json:"valor"`
+	VigenteDesde    string                 `json:"vigenteDesde"` // Formato: YYYY-MM-DD
+	VigenteHasta    string                 `json:"vigenteHasta"` // Formato: YYYY-MM-DD (inclusive)
+	NochesMinimas   int                    `json:"nochesMinimas"`
+	UsosMaximos     *int                   `json:"usosMaximos,omitempty"`     // Sin límite si se omite
+	TiposHabitacion []int                  `json:"tiposHabitacion,omitempty"` // Todos los tipos si se omite
+	Activo          *bool                  `json:"activo,omitempty"`          // Por defecto true
+}
+
+// toDomain convierte la petición en un código promocional
+func (r CodigoPromocionalRequest) toDomain() (*domain.CodigoPromocional, error) {
+	vigenteDesde, err := time.Parse("2006-01-02", r.VigenteDesde)
+	if err != nil {
+		return nil, errors.New("Formato de vigenteDesde inválido. Use YYYY-MM-DD")
+	}
+	vigenteHasta, err := time.Parse("2006-01-02", r.VigenteHasta)
+	if err != nil {
+		return nil, errors.New("Formato de vigenteHasta inválido. Use YYYY-MM-DD")
+	}
+
+	activo := true
+	if r.Activo != nil {
+		activo = *r.Activo
+	}
+
+	return &domain.CodigoPromocional{
+		CampanaID:       r.CampanaID,
+		Codigo:          r.Codigo,
+		Descripcion:     r.Descripcion,
+		TipoDescuento:   r.TipoDescuento,
+		Valor:           r.Valor,
+		VigenteDesde:    vigenteDesde,
+		VigenteHasta:    vigenteHasta,
+		NochesMinimas:   r.NochesMinimas,
+		UsosMaximos:     r.UsosMaximos,
+		TiposHabitacion: r.TiposHabitacion,
+		Activo:          activo,
+	}, nil
+}
+
+// GetAll lista los códigos promocionales (filtro opcional: campanaId)
+func (h *CodigoPromocionalHandler) GetAll(c *fiber.Ctx) error {
+	codigos, err := h.service.GetAll(c.QueryInt("campanaId", 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": codigos})
+}
+
+// GetByID obtiene un código promocional con sus usos
+func (h *CodigoPromocionalHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de código inválido"})
+	}
+
+	codigo, err := h.service.GetByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": codigo})
+}
+
+// Create crea un código promocional para una campaña
+func (h *CodigoPromocionalHandler) Create(c *fiber.Ctx) error {
+	var req CodigoPromocionalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	codigo, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.service.Create(codigo); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": codigo})
+}
+
+// Update modifica un código promocional
+func (h *CodigoPromocionalHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de código inválido"})
+	}
+
+	var req CodigoPromocionalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	codigo, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	codigo.ID = id
+	if err := h.service.Update(codigo); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": codigo})
+}
+
+// GetReporteCampana resume los canjes y el descuento otorgado por los códigos de una campaña
+func (h *CodigoPromocionalHandler) GetReporteCampana(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de campaña inválido"})
+	}
+
+	reporte, err := h.service.GetReporteCampana(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": reporte})
+}
+
+func (h *CodigoPromocionalHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCodigoPromocionalNoEncontrado), errors.Is(err, domain.ErrCampanaNoEncontrada):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrCodigoPromocionalInvalido):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -442,7 +442,7 @@ func (h *HabitacionHandler) GetAvailableRooms(c *fiber.Ctx) error {
 	}
 
 	// Get available room types
-	roomTypes, err := h.service.GetAvailableRooms(fechaEntrada, fechaSalida)
+	roomTypes, rechazos, err := h.service.GetAvailableRooms(fechaEntrada, fechaSalida)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error al obtener las habitaciones disponibles",
@@ -458,9 +458,34 @@ func (h *HabitacionHandler) GetAvailableRooms(c *fiber.Ctx) error {
 		}
 	}
 
+	// Si hay habitaciones libres pero las restricciones de estadía no admiten las fechas,
+	// explicar por qué se rechaza la estadía
+	if len(roomTypesFiltrados) == 0 && len(rechazos) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":    domain.ErrEstadiaRestringida.Error(),
+			"motivos":  motivosRechazo(rechazos),
+			"rechazos": rechazos,
+		})
+	}
+
 	return c.JSON(roomTypesFiltrados)
 }
 
+// motivosRechazo junta los motivos de rechazo de todos los tipos sin repetirlos
+func motivosRechazo(rechazos []domain.RechazoEstadia) []string {
+	vistos := make(map[string]bool)
+	motivos := make([]string, 0)
+	for _, rechazo := range rechazos {
+		for _, motivo := range rechazo.Motivos {
+			if !vistos[motivo] {
+				vistos[motivo] = true
+				motivos = append(motivos, motivo)
+			}
+		}
+	}
+	return motivos
+}
+
 // ListAmenities returns all amenities (public)
 func (h *HabitacionHandler) ListAmenities(c *fiber.Ctx) error {
 	amenities, err := h.service.ListAmenities()
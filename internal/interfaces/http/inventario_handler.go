@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type InventarioHandler struct {
+	service *application.InventarioService
+}
+
+func NewInventarioHandler(service *application.InventarioService) *InventarioHandler {
+	return &InventarioHandler{service: service}
+}
+
+// GetInventario retorna la matriz de inventario por tipo de habitación y noche
+// Query params: desde, hasta (YYYY-MM-DD, inclusive)
+func (h *InventarioHandler) GetInventario(c *fiber.Ctx) error {
+	desde, err := time.Parse("2006-01-02", c.Query("desde"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de desde inválido. Use YYYY-MM-DD"})
+	}
+	hasta, err := time.Parse("2006-01-02", c.Query("hasta"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de hasta inválido. Use YYYY-MM-DD"})
+	}
+
+	inventario, err := h.service.GetInventario(desde, hasta)
+	if err != nil {
+		if errors.Is(err, domain.ErrRangoInventarioInvalido) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": inventario})
+}
@@ -0,0 +1,265 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LimpiezaHandler struct {
+	service *application.LimpiezaService
+}
+
+func NewLimpiezaHandler(service *application.LimpiezaService) *LimpiezaHandler {
+	return &LimpiezaHandler{service: service}
+}
+
+// EstadoLimpiezaRequest representa la petición para cambiar el estado de limpieza de una habitación
+type EstadoLimpiezaRequest struct {
+	Estado domain.EstadoLimpieza `json:"estado"`
+	Motivo string                `json:"motivo,omitempty"` // Requerido para FueraDeServicio
+}
+
+// TareaLimpiezaRequest representa la petición para crear una tarea de limpieza manual
+type TareaLimpiezaRequest struct {
+	HabitacionID int    `json:"habitacionId"`
+	PersonalID   *int   `json:"personalId,omitempty"`
+	Notas        string `json:"notas,omitempty"`
+}
+
+// AsignarTareaRequest representa la petición para asignar una tarea; personalId nulo la desasigna
+type AsignarTareaRequest struct {
+	PersonalID *int `json:"personalId"`
+}
+
+// NotasTareaRequest representa las notas opcionales al completar o cancelar una tarea
+type NotasTareaRequest struct {
+	Notas string `json:"notas,omitempty"`
+}
+
+// PersonalLimpiezaRequest representa la petición para registrar o modificar personal de limpieza
+type PersonalLimpiezaRequest struct {
+	Nombre string `json:"nombre"`
+	Activo *bool  `json:"activo,omitempty"` // Por defecto true
+}
+
+// toDomain convierte la petición en un miembro del personal
+func (r PersonalLimpiezaRequest) toDomain() *domain.PersonalLimpieza {
+	activo := true
+	if r.Activo != nil {
+		activo = *r.Activo
+	}
+
+	return &domain.PersonalLimpieza{
+		Nombre: r.Nombre,
+		Activo: activo,
+	}
+}
+
+// GetTablero retorna el estado de limpieza de todas las habitaciones
+// Query params: fecha (YYYY-MM-DD, por defecto hoy) para las llegadas y salidas del día
+func (h *LimpiezaHandler) GetTablero(c *fiber.Ctx) error {
+	fecha := time.Now().UTC()
+	if f := c.Query("fecha"); f != "" {
+		var err error
+		fecha, err = time.Parse("2006-01-02", f)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fecha inválido. Use YYYY-MM-DD"})
+		}
+	}
+
+	tablero, err := h.service.GetTablero(fecha)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tablero})
+}
+
+// CambiarEstadoHabitacion cambia el estado de limpieza de una habitación
+func (h *LimpiezaHandler) CambiarEstadoHabitacion(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de habitación inválido"})
+	}
+
+	var req EstadoLimpiezaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	if err := h.service.CambiarEstadoHabitacion(id, req.Estado, req.Motivo); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": fiber.Map{"habitacionId": id, "estadoLimpieza": req.Estado}})
+}
+
+// GetTareas lista las tareas de limpieza
+// Query params opcionales: estado, habitacionId, personalId
+func (h *LimpiezaHandler) GetTareas(c *fiber.Ctx) error {
+	filtro := domain.FiltroTareasLimpieza{
+		Estado:       domain.EstadoTareaLimpieza(c.Query("estado")),
+		HabitacionID: c.QueryInt("habitacionId", 0),
+		PersonalID:   c.QueryInt("personalId", 0),
+	}
+
+	tareas, err := h.service.GetTareas(filtro)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tareas})
+}
+
+// GetTareaByID obtiene una tarea de limpieza
+func (h *LimpiezaHandler) GetTareaByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de tarea inválido"})
+	}
+
+	tarea, err := h.service.GetTareaByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tarea})
+}
+
+// CreateTarea crea una tarea de limpieza manual
+func (h *LimpiezaHandler) CreateTarea(c *fiber.Ctx) error {
+	var req TareaLimpiezaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	tarea := &domain.TareaLimpieza{
+		HabitacionID: req.HabitacionID,
+		PersonalID:   req.PersonalID,
+		Notas:        req.Notas,
+	}
+	if err := h.service.CreateTarea(tarea); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": tarea})
+}
+
+// AsignarTarea asigna una tarea a un miembro del personal
+func (h *LimpiezaHandler) AsignarTarea(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de tarea inválido"})
+	}
+
+	var req AsignarTareaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	tarea, err := h.service.AsignarTarea(id, req.PersonalID)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tarea})
+}
+
+// IniciarTarea marca una tarea como en curso
+func (h *LimpiezaHandler) IniciarTarea(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de tarea inválido"})
+	}
+
+	tarea, err := h.service.IniciarTarea(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tarea})
+}
+
+// CompletarTarea termina una tarea y deja la habitación limpia
+func (h *LimpiezaHandler) CompletarTarea(c *fiber.Ctx) error {
+	return h.cerrarTarea(c, h.service.CompletarTarea)
+}
+
+// CancelarTarea cancela una tarea abierta
+func (h *LimpiezaHandler) CancelarTarea(c *fiber.Ctx) error {
+	return h.cerrarTarea(c, h.service.CancelarTarea)
+}
+
+// cerrarTarea lee el ID y las notas opcionales y ejecuta el cierre indicado
+func (h *LimpiezaHandler) cerrarTarea(c *fiber.Ctx, cerrar func(id int, notas string) (*domain.TareaLimpieza, error)) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de tarea inválido"})
+	}
+
+	var req NotasTareaRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+		}
+	}
+
+	tarea, err := cerrar(id, req.Notas)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tarea})
+}
+
+// GetPersonal lista el personal de limpieza
+func (h *LimpiezaHandler) GetPersonal(c *fiber.Ctx) error {
+	personal, err := h.service.GetPersonal()
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": personal})
+}
+
+// CreatePersonal registra un miembro del personal de limpieza
+func (h *LimpiezaHandler) CreatePersonal(c *fiber.Ctx) error {
+	var req PersonalLimpiezaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	personal := req.toDomain()
+	if err := h.service.CreatePersonal(personal); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": personal})
+}
+
+// UpdatePersonal modifica un miembro del personal de limpieza
+func (h *LimpiezaHandler) UpdatePersonal(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de personal inválido"})
+	}
+
+	var req PersonalLimpiezaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	personal := req.toDomain()
+	personal.ID = id
+	if err := h.service.UpdatePersonal(personal); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": personal})
+}
+
+func (h *LimpiezaHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrHabitacionNoEncontrada),
+		errors.Is(err, domain.ErrTareaLimpiezaNoEncontrada),
+		errors.Is(err, domain.ErrPersonalLimpiezaNoEncontrado):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrLimpiezaInvalida):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ListaEsperaHandler struct {
+	service *application.ListaEsperaService
+}
+
+func NewListaEsperaHandler(service *application.ListaEsperaService) *ListaEsperaHandler {
+	return &ListaEsperaHandler{service: service}
+}
+
+// InscribirListaEsperaRequest representa la petición para unirse a la lista de espera
+type InscribirListaEsperaRequest struct {
+	TipoHabitacionID int     `json:"tipoHabitacionId"`
+	FechaEntrada     string  `json:"fechaEntrada"`
+	FechaSalida      string  `json:"fechaSalida"`
+	CantidadAdultos  int     `json:"cantidadAdultos"`
+	CantidadNinhos   int     `json:"cantidadNinhos"`
+	Nombre           string  `json:"nombre"`
+	Email            string  `json:"email"`
+	Telefono         *string `json:"telefono,omitempty"`
+}
+
+// Inscribir agrega al huésped a la lista de espera
+func (h *ListaEsperaHandler) Inscribir(c *fiber.Ctx) error {
+	var req InscribirListaEsperaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	fechaEntrada, err := time.Parse("2006-01-02", req.FechaEntrada)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fechaEntrada inválido. Use YYYY-MM-DD"})
+	}
+	fechaSalida, err := time.Parse("2006-01-02", req.FechaSalida)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fechaSalida inválido. Use YYYY-MM-DD"})
+	}
+
+	inscripcion := &domain.InscripcionListaEspera{
+		TipoHabitacionID: req.TipoHabitacionID,
+		FechaEntrada:     fechaEntrada,
+		FechaSalida:      fechaSalida,
+		CantidadAdultos:  req.CantidadAdultos,
+		CantidadNinhos:   req.CantidadNinhos,
+		Nombre:           req.Nombre,
+		Email:            req.Email,
+		Telefono:         req.Telefono,
+	}
+
+	if err := h.service.Inscribir(inscripcion); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data":    inscripcion,
+		"message": "Te avisaremos por correo si se libera una habitación",
+	})
+}
+
+// GetByID obtiene una inscripción de la lista de espera
+func (h *ListaEsperaHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de inscripción inválido"})
+	}
+
+	inscripcion, err := h.service.GetByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": inscripcion})
+}
+
+// Cancelar retira una inscripción de la lista de espera
+func (h *ListaEsperaHandler) Cancelar(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de inscripción inválido"})
+	}
+
+	if err := h.service.Cancelar(id); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Inscripción cancelada exitosamente"})
+}
+
+// ValidarEnlace verifica que un enlace de reserva enviado a la lista de espera siga vigente
+func (h *ListaEsperaHandler) ValidarEnlace(c *fiber.Ctx) error {
+	inscripcion, err := h.service.ValidarEnlace(c.Params("token"))
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": inscripcion})
+}
+
+func (h *ListaEsperaHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrListaEsperaNoEncontrada):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrEnlaceListaEsperaVencido):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PoliticaCancelacionHandler struct {
+	service *application.PoliticaCancelacionService
+}
+
+func NewPoliticaCancelacionHandler(service *application.PoliticaCancelacionService) *PoliticaCancelacionHandler {
+	return &PoliticaCancelacionHandler{service: service}
+}
+
+// GetAll lista las políticas de cancelación
+func (h *PoliticaCancelacionHandler) GetAll(c *fiber.Ctx) error {
+	politicas, err := h.service.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": politicas})
+}
+
+// Create registra una nueva política de cancelación
+func (h *PoliticaCancelacionHandler) Create(c *fiber.Ctx) error {
+	var politica domain.PoliticaCancelacion
+	if err := c.BodyParser(&politica); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	if err := h.service.Create(&politica); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": politica})
+}
+
+// Update actualiza una política de cancelación existente
+func (h *PoliticaCancelacionHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de política inválido"})
+	}
+
+	var politica domain.PoliticaCancelacion
+	if err := c.BodyParser(&politica); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+	politica.ID = id
+
+	if err := h.service.Update(&politica); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": politica})
+}
+
+func (h *PoliticaCancelacionHandler) errorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrPoliticaCancelacionInvalida) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
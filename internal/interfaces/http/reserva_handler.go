@@ -1,7 +1,9 @@
 package http
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"time"
 
@@ -11,13 +13,15 @@ import (
 )
 
 type ReservaHandler struct {
-	service *application.ReservaService
+	service     *application.ReservaService
+	listaEspera *application.ListaEsperaService
 }
 
 // NewReservaHandler crea una nueva instancia del handler de reservas
-func NewReservaHandler(service *application.ReservaService) *ReservaHandler {
+func NewReservaHandler(service *application.ReservaService, listaEspera *application.ListaEsperaService) *ReservaHandler {
 	return &ReservaHandler{
-		service: service,
+		service:     service,
+		listaEspera: listaEspera,
 	}
 }
 
@@ -25,12 +29,19 @@ func NewReservaHandler(service *application.ReservaService) *ReservaHandler {
 type CreateReservaRequest struct {
 	CantidadAdultos int                       `json:"cantidadAdultos"`
 	CantidadNinhos  int                       `json:"cantidadNinhos"`
-	Descuento       float64                   `json:"descuento"`
 	Cliente         ClienteData               `json:"cliente"`
 	Huespedes       []HuespedData             `json:"huespedes,omitempty"` // Huéspedes adicionales
 	Habitaciones    []CreateHabitacionReserva `json:"habitaciones"`
 	Servicios       []int                     `json:"servicios,omitempty"` // Array de IDs de servicios
 	Pago            *PaymentData              `json:"pago,omitempty"`      // Opcional
+	// ListaEsperaToken es el token del enlace enviado a la lista de espera (opcional)
+	ListaEsperaToken string `json:"listaEsperaToken,omitempty"`
+	// BloqueGrupoID recoge la reserva contra un bloque de grupo (opcional)
+	BloqueGrupoID *int `json:"bloqueGrupoId,omitempty"`
+	// CodigoPromocional es el código de campaña a canjear (opcional); el descuento lo calcula el servidor
+	CodigoPromocional string `json:"codigoPromocional,omitempty"`
+	// EdadesNinhos son las edades de los niños para cobrarlos por banda de edad (opcional)
+	EdadesNinhos []int `json:"edadesNinhos,omitempty"`
 }
 
 // PaymentData representa los datos del pago
@@ -67,17 +78,68 @@ type HuespedData struct {
 	BirthDate      string  `json:"birthDate"` // Formato: YYYY-MM-DD
 }
 
-// CreateHabitacionReserva representa una habitación a reservar
+// CreateHabitacionReserva representa una o más habitaciones de un tipo a reservar
 type CreateHabitacionReserva struct {
-	RoomTypeID   int     `json:"roomTypeId"` // ID del tipo de habitación
-	Precio       float64 `json:"precio"`
-	FechaEntrada string  `json:"fechaEntrada"` // Formato: YYYY-MM-DD
-	FechaSalida  string  `json:"fechaSalida"`  // Formato: YYYY-MM-DD
+	RoomTypeID   int    `json:"roomTypeId"`             // ID del tipo de habitación
+	Cantidad     int    `json:"cantidad,omitempty"`     // Habitaciones del tipo (por defecto 1)
+	PlanTarifaID int    `json:"planTarifaId,omitempty"` // Plan tarifario elegido (opcional)
+	FechaEntrada string `json:"fechaEntrada"`           // Formato: YYYY-MM-DD
+	FechaSalida  string `json:"fechaSalida"`            // Formato: YYYY-MM-DD
 }
 
 // UpdateEstadoRequest representa la petición para actualizar el estado de una reserva
 type UpdateEstadoRequest struct {
 	Estado string `json:"estado"`
+	Actor  string `json:"actor"`
+	Motivo string `json:"motivo"`
+}
+
+// AgregarServicioRequest representa la petición para agregar un servicio adicional a una reserva.
+// Sin fechas, el servicio cubre toda la estadía
+type AgregarServicioRequest struct {
+	ServiceID int    `json:"serviceId"`
+	StartDate string `json:"startDate,omitempty"` // YYYY-MM-DD
+	EndDate   string `json:"endDate,omitempty"`   // YYYY-MM-DD
+	Quantity  int    `json:"quantity"`
+}
+
+// CambioEstadoRequest representa los datos opcionales de auditoría de un cambio de estado
+type CambioEstadoRequest struct {
+	Actor  string `json:"actor"`
+	Motivo string `json:"motivo"`
+}
+
+// CheckInRequest representa la petición de check-in. Sin asignaciones se confirman las
+// habitaciones reservadas
+type CheckInRequest struct {
+	Asignaciones []domain.AsignacionHabitacion `json:"asignaciones,omitempty"`
+	Actor        string                        `json:"actor"`
+	Motivo       string                        `json:"motivo"`
+}
+
+// CheckOutRequest representa la petición de check-out con el pago final opcional
+type CheckOutRequest struct {
+	Pago   *PaymentData `json:"pago,omitempty"`
+	Actor  string       `json:"actor"`
+	Motivo string       `json:"motivo"`
+}
+
+// ModificarReservaRequest representa la petición para modificar una reserva existente
+type ModificarReservaRequest struct {
+	CantidadAdultos *int                      `json:"cantidadAdultos,omitempty"`
+	CantidadNinhos  *int                      `json:"cantidadNinhos,omitempty"`
+	Habitaciones    []ModificarHabitacionData `json:"habitaciones,omitempty"`
+	// EdadesNinhos reemplaza las edades de los niños, que definen su recargo (opcional)
+	EdadesNinhos []int `json:"edadesNinhos,omitempty"`
+}
+
+// ModificarHabitacionData representa los cambios sobre una habitación de la reserva
+type ModificarHabitacionData struct {
+	HabitacionID          int    `json:"habitacionId"`                    // Habitación actual de la reserva
+	NuevaHabitacionID     int    `json:"nuevaHabitacionId,omitempty"`     // Cambiar a una habitación concreta
+	NuevoTipoHabitacionID int    `json:"nuevoTipoHabitacionId,omitempty"` // Cambiar a otro tipo de habitación
+	FechaEntrada          string `json:"fechaEntrada,omitempty"`          // Formato: YYYY-MM-DD
+	FechaSalida           string `json:"fechaSalida,omitempty"`           // Formato: YYYY-MM-DD
 }
 
 // VerificarDisponibilidadRequest representa la petición para verificar disponibilidad
@@ -109,6 +171,22 @@ func (h *ReservaHandler) CreateReserva(c *fiber.Ctx) error {
 		})
 	}
 
+	// Una reserva desde la lista de espera solo se acepta con el enlace vigente
+	var inscripcion *domain.InscripcionListaEspera
+	if req.ListaEsperaToken != "" && h.listaEspera != nil {
+		var err error
+		inscripcion, err = h.listaEspera.ValidarEnlace(req.ListaEsperaToken)
+		if err != nil {
+			status := fiber.StatusBadRequest
+			if errors.Is(err, domain.ErrEnlaceListaEsperaVencido) {
+				status = fiber.StatusGone
+			}
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	// Parsear fecha de nacimiento
 	birthDate, err := time.Parse("2006-01-02", req.Cliente.BirthDate)
 	if err != nil {
@@ -117,8 +195,8 @@ func (h *ReservaHandler) CreateReserva(c *fiber.Ctx) error {
 		})
 	}
 
-	// Convertir habitaciones y buscar habitación disponible de cada tipo
-	habitaciones := make([]domain.ReservaHabitacion, len(req.Habitaciones))
+	// Convertir habitaciones a solicitudes por tipo y cantidad
+	solicitudes := make([]domain.SolicitudHabitaciones, len(req.Habitaciones))
 	for i, hab := range req.Habitaciones {
 		// Validar que roomTypeId sea válido
 		if hab.RoomTypeID <= 0 {
@@ -141,33 +219,46 @@ func (h *ReservaHandler) CreateReserva(c *fiber.Ctx) error {
 			})
 		}
 
-		// Buscar una habitación disponible del tipo especificado
-		habitacionID, err := h.service.FindAvailableRoomByType(hab.RoomTypeID, fechaEntrada, fechaSalida)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("No hay habitaciones disponibles del tipo %d para las fechas seleccionadas: %v", hab.RoomTypeID, err),
-			})
+		// Sin cantidad se reserva una habitación del tipo
+		cantidad := hab.Cantidad
+		if cantidad == 0 {
+			cantidad = 1
 		}
 
-		// Obtener el precio del tipo de habitación desde la BD si no se proporciona
-		precio := hab.Precio
-		if precio <= 0 {
-			roomTypePrice, err := h.service.GetRoomTypePrice(hab.RoomTypeID)
-			if err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-					"error": fmt.Sprintf("Error al obtener precio del tipo de habitación %d: %v", hab.RoomTypeID, err),
-				})
-			}
-			precio = roomTypePrice
+		solicitudes[i] = domain.SolicitudHabitaciones{
+			TipoHabitacionID: hab.RoomTypeID,
+			Cantidad:         cantidad,
+			FechaEntrada:     fechaEntrada,
+			FechaSalida:      fechaSalida,
+			PlanTarifaID:     hab.PlanTarifaID,
+		}
+		if req.BloqueGrupoID != nil {
+			solicitudes[i].BloqueGrupoID = *req.BloqueGrupoID
+		}
+	}
+
+	// El enlace de la lista de espera solo libera la habitación apartada para su tipo y fechas
+	if inscripcion != nil {
+		if err := h.listaEspera.AsociarSolicitudes(inscripcion, solicitudes); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
 		}
+	}
 
-		habitaciones[i] = domain.ReservaHabitacion{
-			HabitacionID: habitacionID,
-			Precio:       precio,
-			FechaEntrada: fechaEntrada,
-			FechaSalida:  fechaSalida,
-			Estado:       1, // Activa
+	// Asignar habitaciones distintas y disponibles para cada tipo
+	habitaciones, err := h.service.AsignarHabitaciones(solicitudes)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, domain.ErrHabitacionNoDisponible), errors.Is(err, domain.ErrBloqueGrupoSinCupo):
+			status = fiber.StatusConflict
+		case errors.Is(err, domain.ErrBloqueGrupoNoEncontrado), errors.Is(err, domain.ErrPlanTarifaNoEncontrado):
+			status = fiber.StatusNotFound
 		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
 
 	// Convertir servicios (si se enviaron)
@@ -206,32 +297,17 @@ func (h *ReservaHandler) CreateReserva(c *fiber.Ctx) error {
 		BirthDate:        birthDate,
 	}
 
-	// Calcular subtotal desde el amount del pago o desde habitaciones
-	subtotal := 0.0
-	if req.Pago != nil && req.Pago.Amount > 0 {
-		// Si hay pago, usar el amount como subtotal (ya incluye servicios)
-		subtotal = req.Pago.Amount
-	} else {
-		// Si no hay pago, calcular desde habitaciones (fallback)
-		for _, hab := range habitaciones {
-			dias := hab.FechaSalida.Sub(hab.FechaEntrada).Hours() / 24
-			if dias < 1 {
-				dias = 1
-			}
-			subtotal += hab.Precio * dias
-		}
-	}
-
 	// Crear la reserva con los datos del cliente
 	reserva := &domain.Reserva{
 		CantidadAdultos:   req.CantidadAdultos,
 		CantidadNinhos:    req.CantidadNinhos,
-		Descuento:         req.Descuento,
-		Subtotal:          subtotal,
-		Estado:            domain.ReservaPendiente,
+		Estado:            domain.ReservaPendiente, // El subtotal se calcula con las tarifas cotizadas
 		FechaConfirmacion: time.Now(),
 		Habitaciones:      habitaciones,
 		Servicios:         servicios,
+		BloqueGrupoID:     req.BloqueGrupoID,
+		CodigoPromocional: req.CodigoPromocional,
+		EdadesNinhos:      req.EdadesNinhos,
 	}
 
 	// Crear el pago si se proporcionó
@@ -277,17 +353,112 @@ func (h *ReservaHandler) CreateReserva(c *fiber.Ctx) error {
 
 	// Llamar al servicio para crear la reserva con el cliente, huéspedes y el pago
 	if err := h.service.CreateReservaWithClientAndPayment(person, reserva, huespedes, payment); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		// La habitación fue tomada por otra reserva concurrente, el bloque se quedó sin cupo
+		// o el código promocional alcanzó su límite de usos
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, domain.ErrHabitacionNoDisponible), errors.Is(err, domain.ErrBloqueGrupoSinCupo),
+			errors.Is(err, domain.ErrCodigoPromocionalAgotado):
+			status = fiber.StatusConflict
+		case errors.Is(err, domain.ErrCodigoPromocionalNoEncontrado):
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	if inscripcion != nil {
+		if err := h.listaEspera.MarcarReservada(inscripcion.ID, reserva.ID); err != nil {
+			log.Printf("⚠️ Reserva %d creada pero no se pudo cerrar la inscripción %d de la lista de espera: %v", reserva.ID, inscripcion.ID, err)
+		}
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Reserva creada exitosamente",
 		"data":    reserva,
 	})
 }
 
+// ModificarReserva cambia fechas, habitación o huéspedes de una reserva
+func (h *ReservaHandler) ModificarReserva(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	var req ModificarReservaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	cambios := domain.ModificacionReserva{
+		CantidadAdultos: req.CantidadAdultos,
+		CantidadNinhos:  req.CantidadNinhos,
+		EdadesNinhos:    req.EdadesNinhos,
+	}
+	for i, hab := range req.Habitaciones {
+		if hab.HabitacionID <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("habitacionId inválido en habitación %d", i+1),
+			})
+		}
+
+		cambio := domain.ModificacionHabitacion{
+			HabitacionID:          hab.HabitacionID,
+			NuevaHabitacionID:     hab.NuevaHabitacionID,
+			NuevoTipoHabitacionID: hab.NuevoTipoHabitacionID,
+		}
+
+		if hab.FechaEntrada != "" {
+			fechaEntrada, err := time.Parse("2006-01-02", hab.FechaEntrada)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Formato de fechaEntrada inválido. Use YYYY-MM-DD",
+				})
+			}
+			cambio.FechaEntrada = &fechaEntrada
+		}
+
+		if hab.FechaSalida != "" {
+			fechaSalida, err := time.Parse("2006-01-02", hab.FechaSalida)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Formato de fechaSalida inválido. Use YYYY-MM-DD",
+				})
+			}
+			cambio.FechaSalida = &fechaSalida
+		}
+
+		cambios.Habitaciones = append(cambios.Habitaciones, cambio)
+	}
+
+	resultado, err := h.service.ModificarReserva(id, cambios)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrHabitacionNoDisponible) ||
+			errors.Is(err, domain.ErrRetencionVencida) ||
+			errors.Is(err, domain.ErrReservaNoModificable) ||
+			errors.Is(err, domain.ErrCodigoPromocionalNoAplicable) ||
+			errors.Is(err, domain.ErrCodigoPromocionalAgotado) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Reserva modificada exitosamente",
+		"data":    resultado,
+	})
+}
+
 // GetReservaByID obtiene una reserva por su ID
 func (h *ReservaHandler) GetReservaByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
@@ -310,6 +481,33 @@ func (h *ReservaHandler) GetReservaByID(c *fiber.Ctx) error {
 	})
 }
 
+// LookupReserva permite a un huésped consultar su reserva con el código y su email
+func (h *ReservaHandler) LookupReserva(c *fiber.Ctx) error {
+	codigo := c.Query("codigo")
+	email := c.Query("email")
+	if codigo == "" || email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "codigo y email son requeridos",
+		})
+	}
+
+	reserva, err := h.service.BuscarReservaPorCodigo(codigo, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrReservaNoEncontrada) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "No se encontró una reserva con ese código y email",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": reserva,
+	})
+}
+
 // GetReservasCliente obtiene todas las reservas de un cliente
 func (h *ReservaHandler) GetReservasCliente(c *fiber.Ctx) error {
 	clienteIDStr := c.Params("clienteId")
@@ -357,9 +555,17 @@ func (h *ReservaHandler) UpdateReservaEstado(c *fiber.Ctx) error {
 
 	// Convertir el estado a EstadoReserva
 	estado := domain.EstadoReserva(req.Estado)
-
-	if err := h.service.UpdateReservaEstado(id, estado); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+	cambio := domain.CambioEstado{Actor: actorDesdeRequest(c, req.Actor), Motivo: req.Motivo}
+
+	if err := h.service.UpdateReservaEstado(id, estado, cambio); err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrTransicionInvalida) ||
+			errors.Is(err, domain.ErrRetencionVencida) ||
+			errors.Is(err, domain.ErrCheckInFueraDeFecha) ||
+			errors.Is(err, domain.ErrSaldoPendiente) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -379,14 +585,27 @@ func (h *ReservaHandler) CancelarReserva(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.CancelarReserva(id); err != nil {
+	cambio, err := cambioEstadoDesdeRequest(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	desglose, err := h.service.CancelarReserva(id, cambio)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrTransicionInvalida) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	return c.JSON(fiber.Map{
 		"message": "Reserva cancelada exitosamente",
+		"data":    desglose,
 	})
 }
 
@@ -400,8 +619,19 @@ func (h *ReservaHandler) ConfirmarReserva(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.ConfirmarReserva(id); err != nil {
+	cambio, err := cambioEstadoDesdeRequest(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	if err := h.service.ConfirmarReserva(id, cambio); err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrRetencionVencida) || errors.Is(err, domain.ErrTransicionInvalida) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -421,8 +651,19 @@ func (h *ReservaHandler) ConfirmarPago(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.ConfirmarReserva(id); err != nil {
+	cambio, err := cambioEstadoDesdeRequest(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	if err := h.service.ConfirmarReserva(id, cambio); err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrRetencionVencida) || errors.Is(err, domain.ErrTransicionInvalida) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -432,6 +673,252 @@ func (h *ReservaHandler) ConfirmarPago(c *fiber.Ctx) error {
 	})
 }
 
+// GetServicios lista los servicios adicionales de una reserva
+func (h *ReservaHandler) GetServicios(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	servicios, err := h.service.GetServiciosReserva(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": servicios,
+	})
+}
+
+// AgregarServicio agrega un servicio adicional a una reserva y la recotiza
+func (h *ReservaHandler) AgregarServicio(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	var req AgregarServicioRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	if req.ServiceID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "serviceId es requerido",
+		})
+	}
+
+	servicio := domain.ReservaServicio{
+		ServiceID: req.ServiceID,
+		Quantity:  req.Quantity,
+	}
+	if req.StartDate != "" {
+		servicio.StartDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Formato de startDate inválido. Use YYYY-MM-DD",
+			})
+		}
+	}
+	if req.EndDate != "" {
+		servicio.EndDate, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Formato de endDate inválido. Use YYYY-MM-DD",
+			})
+		}
+	}
+
+	reserva, err := h.service.AgregarServicio(id, servicio)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrReservaNoModificable) || errors.Is(err, domain.ErrRetencionVencida) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Servicio agregado exitosamente",
+		"data":    reserva,
+	})
+}
+
+// QuitarServicio quita un servicio adicional de una reserva y la recotiza
+func (h *ReservaHandler) QuitarServicio(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	servicioID, err := strconv.Atoi(c.Params("servicioId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de servicio inválido",
+		})
+	}
+
+	reserva, err := h.service.QuitarServicio(id, servicioID)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrReservaNoModificable) || errors.Is(err, domain.ErrRetencionVencida) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Servicio quitado exitosamente",
+		"data":    reserva,
+	})
+}
+
+// CheckIn registra la llegada de los huéspedes de una reserva
+func (h *ReservaHandler) CheckIn(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	var req CheckInRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Formato de solicitud inválido",
+			})
+		}
+	}
+	cambio := domain.CambioEstado{Actor: actorDesdeRequest(c, req.Actor), Motivo: req.Motivo}
+
+	registro, err := h.service.CheckIn(id, req.Asignaciones, cambio)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrTransicionInvalida) ||
+			errors.Is(err, domain.ErrCheckInFueraDeFecha) ||
+			errors.Is(err, domain.ErrHabitacionNoDisponible) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Check-in registrado exitosamente",
+		"data":    registro,
+	})
+}
+
+// CheckOut registra la salida de los huéspedes y cierra el folio de la reserva
+func (h *ReservaHandler) CheckOut(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	var req CheckOutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Formato de solicitud inválido",
+			})
+		}
+	}
+	cambio := domain.CambioEstado{Actor: actorDesdeRequest(c, req.Actor), Motivo: req.Motivo}
+
+	var pago *domain.Payment
+	if req.Pago != nil {
+		pago = &domain.Payment{
+			Amount:        req.Pago.Amount,
+			PaymentMethod: domain.PaymentMethod(req.Pago.PaymentMethod),
+		}
+	}
+
+	cierre, err := h.service.CheckOut(id, pago, cambio)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(err, domain.ErrTransicionInvalida) || errors.Is(err, domain.ErrSaldoPendiente) {
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Check-out registrado exitosamente",
+		"data":    cierre,
+	})
+}
+
+// GetHistorial obtiene el historial de cambios de estado de una reserva
+func (h *ReservaHandler) GetHistorial(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de reserva inválido",
+		})
+	}
+
+	historial, err := h.service.GetHistorialEstados(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": historial,
+	})
+}
+
+// cambioEstadoDesdeRequest lee el actor y el motivo opcionales del cuerpo de la petición
+func cambioEstadoDesdeRequest(c *fiber.Ctx) (domain.CambioEstado, error) {
+	var req CambioEstadoRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return domain.CambioEstado{}, err
+		}
+	}
+	return domain.CambioEstado{Actor: actorDesdeRequest(c, req.Actor), Motivo: req.Motivo}, nil
+}
+
+// actorDesdeRequest identifica quién realiza el cambio: el campo actor del cuerpo,
+// el header X-Actor o, por defecto, la API
+func actorDesdeRequest(c *fiber.Ctx, actor string) string {
+	if actor != "" {
+		return actor
+	}
+	if header := c.Get("X-Actor"); header != "" {
+		return header
+	}
+	return domain.ActorAPI
+}
+
 // VerificarDisponibilidad verifica si una habitación está disponible
 func (h *ReservaHandler) VerificarDisponibilidad(c *fiber.Ctx) error {
 	var req VerificarDisponibilidadRequest
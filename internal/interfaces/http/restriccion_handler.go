@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RestriccionHandler struct {
+	service *application.RestriccionService
+}
+
+func NewRestriccionHandler(service *application.RestriccionService) *RestriccionHandler {
+	return &RestriccionHandler{service: service}
+}
+
+// RestriccionRequest representa la petición para crear o modificar una restricción de estadía
+type RestriccionRequest struct {
+	TipoHabitacionID *int                   `json:"tipoHabitacionId,omitempty"` // Todo el hotel si se omite
+	Tipo             domain.TipoRestriccion `json:"tipo"`                       // estadia_minima, estadia_maxima, cerrado_llegada, cerrado_salida o bloqueo
+	FechaInicio      string                 `json:"fechaInicio"`                // Formato: YYYY-MM-DD
+	FechaFin         string                 `json:"fechaFin"`                   // Formato: YYYY-MM-DD (inclusive)
+	Noches           *int                   `json:"noches,omitempty"`           // Solo estadía mínima y máxima
+	Motivo           string                 `json:"motivo,omitempty"`
+	Activa           *bool                  `json:"activa,omitempty"` // Por defecto true
+}
+
+// toDomain convierte la petición en una restricción de estadía
+func (r RestriccionRequest) toDomain() (*domain.RestriccionEstadia, error) {
+	fechaInicio, err := time.Parse("2006-01-02", r.FechaInicio)
+	if err != nil {
+		return nil, errors.New("Formato de fechaInicio inválido. Use YYYY-MM-DD")
+	}
+	fechaFin, err := time.Parse("2006-01-02", r.FechaFin)
+	if err != nil {
+		return nil, errors.New("Formato de fechaFin inválido. Use YYYY-MM-DD")
+	}
+
+	activa := true
+	if r.Activa != nil {
+		activa = *r.Activa
+	}
+
+	return &domain.RestriccionEstadia{
+		TipoHabitacionID: r.TipoHabitacionID,
+		Tipo:             r.Tipo,
+		FechaInicio:      fechaInicio,
+		FechaFin:         fechaFin,
+		Noches:           r.Noches,
+		Motivo:           r.Motivo,
+		Activa:           activa,
+	}, nil
+}
+
+// GetAll lista las restricciones de estadía (filtro opcional: tipoHabitacionId, que incluye
+// las de todo el hotel)
+func (h *RestriccionHandler) GetAll(c *fiber.Ctx) error {
+	restricciones, err := h.service.GetAll(c.QueryInt("tipoHabitacionId", 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": restricciones})
+}
+
+// GetByID obtiene una restricción de estadía
+func (h *RestriccionHandler) GetByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de restricción inválido"})
+	}
+
+	restriccion, err := h.service.GetByID(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": restriccion})
+}
+
+// Create crea una restricción de estadía
+func (h *RestriccionHandler) Create(c *fiber.Ctx) error {
+	var req RestriccionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	restriccion, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.service.Create(restriccion); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": restriccion})
+}
+
+// Update modifica una restricción de estadía
+func (h *RestriccionHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de restricción inválido"})
+	}
+
+	var req RestriccionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	restriccion, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	restriccion.ID = id
+	if err := h.service.Update(restriccion); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": restriccion})
+}
+
+// Delete elimina una restricción de estadía
+func (h *RestriccionHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de restricción inválido"})
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Restricción eliminada exitosamente"})
+}
+
+func (h *RestriccionHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrRestriccionNoEncontrada):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrRestriccionInvalida):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
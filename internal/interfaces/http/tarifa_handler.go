@@ -0,0 +1,562 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maxito7/hotel_backend/internal/application"
+	"github.com/Maxito7/hotel_backend/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TarifaHandler struct {
+	service *application.TarifaService
+}
+
+func NewTarifaHandler(service *application.TarifaService) *TarifaHandler {
+	return &TarifaHandler{service: service}
+}
+
+// TemporadaTarifaRequest representa la petición para crear o modificar una temporada de tarifa
+type TemporadaTarifaRequest struct {
+	TipoHabitacionID int      `json:"tipoHabitacionId"`
+	Nombre           string   `json:"nombre"`
+	FechaInicio      string   `json:"fechaInicio"` // Formato: YYYY-MM-DD
+	FechaFin         string   `json:"fechaFin"`    // Formato: YYYY-MM-DD (inclusive)
+	PrecioNoche      float64  `json:"precioNoche"`
+	PrecioFinSemana  *float64 `json:"precioFinSemana,omitempty"`
+	Prioridad        int      `json:"prioridad"`
+	Activa           *bool    `json:"activa,omitempty"` // Por defecto true
+}
+
+// toDomain convierte la petición en una temporada de tarifa
+func (r TemporadaTarifaRequest) toDomain() (*domain.TemporadaTarifa, error) {
+	fechaInicio, err := time.Parse("2006-01-02", r.FechaInicio)
+	if err != nil {
+		return nil, errors.New("Formato de fechaInicio inválido. Use YYYY-MM-DD")
+	}
+	fechaFin, err := time.Parse("2006-01-02", r.FechaFin)
+	if err != nil {
+		return nil, errors.New("Formato de fechaFin inválido. Use YYYY-MM-DD")
+	}
+
+	activa := true
+	if r.Activa != nil {
+		activa = *r.Activa
+	}
+
+	return &domain.TemporadaTarifa{
+		TipoHabitacionID: r.TipoHabitacionID,
+		Nombre:           r.Nombre,
+		FechaInicio:      fechaInicio,
+		FechaFin:         fechaFin,
+		PrecioNoche:      r.PrecioNoche,
+		PrecioFinSemana:  r.PrecioFinSemana,
+		Prioridad:        r.Prioridad,
+		Activa:           activa,
+	}, nil
+}
+
+// PrecioFechaRequest representa la petición para fijar el precio de una fecha
+type PrecioFechaRequest struct {
+	TipoHabitacionID int     `json:"tipoHabitacionId"`
+	Fecha            string  `json:"fecha"` // Formato: YYYY-MM-DD
+	Precio           float64 `json:"precio"`
+	Motivo           string  `json:"motivo,omitempty"`
+}
+
+// PlanTarifaRequest representa la petición para crear o modificar un plan tarifario
+type PlanTarifaRequest struct {
+	TipoHabitacionID int                    `json:"tipoHabitacionId"`
+	Codigo           string                 `json:"codigo"`
+	Nombre           string                 `json:"nombre"`
+	Descripcion      string                 `json:"descripcion"`
+	TipoModificador  domain.TipoModificador `json:"tipoModificador"` // "porcentaje" o "monto"
+	ValorModificador float64                `json:"valorModificador"`
+	// Cancelacion son las condiciones propias del plan; sin ellas aplica la política del tipo
+	Cancelacion        *CancelacionPlanRequest `json:"cancelacion,omitempty"`
+	ServiciosIncluidos []int                   `json:"serviciosIncluidos,omitempty"`
+	Activo             *bool                   `json:"activo,omitempty"` // Por defecto true
+}
+
+// CancelacionPlanRequest representa las condiciones de cancelación de un plan tarifario
+type CancelacionPlanRequest struct {
+	HorasCancelacionGratuita int                  `json:"horasCancelacionGratuita"`
+	TipoPenalidad            domain.TipoPenalidad `json:"tipoPenalidad"`
+	ValorPenalidad           float64              `json:"valorPenalidad"`
+	NoReembolsable           bool                 `json:"noReembolsable"`
+}
+
+// toDomain convierte la petición en un plan tarifario
+func (r PlanTarifaRequest) toDomain() *domain.PlanTarifa {
+	activo := true
+	if r.Activo != nil {
+		activo = *r.Activo
+	}
+
+	plan := &domain.PlanTarifa{
+		TipoHabitacionID:   r.TipoHabitacionID,
+		Codigo:             r.Codigo,
+		Nombre:             r.Nombre,
+		Descripcion:        r.Descripcion,
+		TipoModificador:    r.TipoModificador,
+		ValorModificador:   r.ValorModificador,
+		ServiciosIncluidos: r.ServiciosIncluidos,
+		Activo:             activo,
+	}
+	if r.Cancelacion != nil {
+		plan.Cancelacion = &domain.PoliticaCancelacion{
+			HorasCancelacionGratuita: r.Cancelacion.HorasCancelacionGratuita,
+			TipoPenalidad:            r.Cancelacion.TipoPenalidad,
+			ValorPenalidad:           r.Cancelacion.ValorPenalidad,
+			NoReembolsable:           r.Cancelacion.NoReembolsable,
+		}
+	}
+
+	return plan
+}
+
+// Cotizar retorna el desglose por noche del precio de una estadía
+// Query params: tipoHabitacionId, fechaEntrada, fechaSalida (YYYY-MM-DD), planTarifaId (opcional)
+// y adultos, ninhos y edadesNinhos (separadas por coma) para sumar los recargos por huéspedes
+func (h *TarifaHandler) Cotizar(c *fiber.Ctx) error {
+	tipoHabitacionID, err := strconv.Atoi(c.Query("tipoHabitacionId"))
+	if err != nil || tipoHabitacionID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tipoHabitacionId inválido"})
+	}
+
+	fechaEntrada, err := time.Parse("2006-01-02", c.Query("fechaEntrada"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fechaEntrada inválido. Use YYYY-MM-DD"})
+	}
+	fechaSalida, err := time.Parse("2006-01-02", c.Query("fechaSalida"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fechaSalida inválido. Use YYYY-MM-DD"})
+	}
+
+	var cotizacion *domain.CotizacionEstadia
+	if planTarifaID := c.QueryInt("planTarifaId", 0); planTarifaID > 0 {
+		cotizacion, err = h.service.CotizarPlan(planTarifaID, tipoHabitacionID, fechaEntrada, fechaSalida)
+	} else {
+		cotizacion, err = h.service.Cotizar(tipoHabitacionID, fechaEntrada, fechaSalida)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrPlanTarifaNoEncontrado) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if adultos := c.QueryInt("adultos", 0); adultos > 0 {
+		edades, err := parseEdades(c.Query("edadesNinhos"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		ocupacion, err := domain.NuevaOcupacion(adultos, max(c.QueryInt("ninhos", 0), len(edades)), edades)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err := h.service.AgregarRecargosOcupacion(cotizacion, ocupacion); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"cotizacion":     cotizacion,
+			"precioPromedio": cotizacion.PrecioPromedio(),
+		},
+	})
+}
+
+// GetTemporadas lista las temporadas de tarifa (filtro opcional: tipoHabitacionId)
+func (h *TarifaHandler) GetTemporadas(c *fiber.Ctx) error {
+	tipoHabitacionID := c.QueryInt("tipoHabitacionId", 0)
+
+	temporadas, err := h.service.GetTemporadas(tipoHabitacionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": temporadas})
+}
+
+// CreateTemporada crea una temporada de tarifa
+func (h *TarifaHandler) CreateTemporada(c *fiber.Ctx) error {
+	var req TemporadaTarifaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	temporada, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.service.CreateTemporada(temporada); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": temporada})
+}
+
+// UpdateTemporada modifica una temporada de tarifa
+func (h *TarifaHandler) UpdateTemporada(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de temporada inválido"})
+	}
+
+	var req TemporadaTarifaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	temporada, err := req.toDomain()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	temporada.ID = id
+
+	if err := h.service.UpdateTemporada(temporada); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": temporada})
+}
+
+// DeleteTemporada elimina una temporada de tarifa
+func (h *TarifaHandler) DeleteTemporada(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de temporada inválido"})
+	}
+
+	if err := h.service.DeleteTemporada(id); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Temporada eliminada exitosamente"})
+}
+
+// GetPreciosFecha lista los precios por fecha de un rango
+// Query params: desde, hasta (YYYY-MM-DD) y tipoHabitacionId (opcional)
+func (h *TarifaHandler) GetPreciosFecha(c *fiber.Ctx) error {
+	desde, err := time.Parse("2006-01-02", c.Query("desde"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de desde inválido. Use YYYY-MM-DD"})
+	}
+	hasta, err := time.Parse("2006-01-02", c.Query("hasta"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de hasta inválido. Use YYYY-MM-DD"})
+	}
+
+	precios, err := h.service.GetPreciosFecha(c.QueryInt("tipoHabitacionId", 0), desde, hasta)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": precios})
+}
+
+// GuardarPrecioFecha fija (o reemplaza) el precio de un tipo de habitación para una fecha
+func (h *TarifaHandler) GuardarPrecioFecha(c *fiber.Ctx) error {
+	var req PrecioFechaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	fecha, err := time.Parse("2006-01-02", req.Fecha)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fecha inválido. Use YYYY-MM-DD"})
+	}
+
+	precio := &domain.PrecioFecha{
+		TipoHabitacionID: req.TipoHabitacionID,
+		Fecha:            fecha,
+		Precio:           req.Precio,
+		Motivo:           req.Motivo,
+	}
+	if err := h.service.GuardarPrecioFecha(precio); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": precio})
+}
+
+// DeletePrecioFecha elimina el precio fijado para una fecha
+func (h *TarifaHandler) DeletePrecioFecha(c *fiber.Ctx) error {
+	tipoHabitacionID, err := strconv.Atoi(c.Params("tipoHabitacionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tipoHabitacionId inválido"})
+	}
+	fecha, err := time.Parse("2006-01-02", c.Params("fecha"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de fecha inválido. Use YYYY-MM-DD"})
+	}
+
+	if err := h.service.DeletePrecioFecha(tipoHabitacionID, fecha); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Precio eliminado exitosamente"})
+}
+
+// GetPlanes lista los planes tarifarios (filtro opcional: tipoHabitacionId)
+func (h *TarifaHandler) GetPlanes(c *fiber.Ctx) error {
+	planes, err := h.service.GetPlanes(c.QueryInt("tipoHabitacionId", 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": planes})
+}
+
+// GetPlan obtiene un plan tarifario
+func (h *TarifaHandler) GetPlan(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de plan inválido"})
+	}
+
+	plan, err := h.service.GetPlan(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": plan})
+}
+
+// CreatePlan crea un plan tarifario
+func (h *TarifaHandler) CreatePlan(c *fiber.Ctx) error {
+	var req PlanTarifaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	plan := req.toDomain()
+	if err := h.service.CreatePlan(plan); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": plan})
+}
+
+// UpdatePlan modifica un plan tarifario
+func (h *TarifaHandler) UpdatePlan(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de plan inválido"})
+	}
+
+	var req PlanTarifaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	plan := req.toDomain()
+	plan.ID = id
+	if err := h.service.UpdatePlan(plan); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": plan})
+}
+
+// GetTarifaOcupacion obtiene los recargos por huéspedes adicionales de un tipo de habitación
+func (h *TarifaHandler) GetTarifaOcupacion(c *fiber.Ctx) error {
+	tipoHabitacionID, err := strconv.Atoi(c.Params("tipoHabitacionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tipoHabitacionId inválido"})
+	}
+
+	tarifa, err := h.service.GetTarifaOcupacion(tipoHabitacionID)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tarifa})
+}
+
+// GuardarTarifaOcupacion crea o reemplaza los recargos por huéspedes adicionales de un tipo
+func (h *TarifaHandler) GuardarTarifaOcupacion(c *fiber.Ctx) error {
+	tipoHabitacionID, err := strconv.Atoi(c.Params("tipoHabitacionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tipoHabitacionId inválido"})
+	}
+
+	var tarifa domain.TarifaOcupacion
+	if err := c.BodyParser(&tarifa); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	tarifa.TipoHabitacionID = tipoHabitacionID
+	if err := h.service.GuardarTarifaOcupacion(&tarifa); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": tarifa})
+}
+
+// ReglaPrecioRequest representa la petición para crear o modificar una regla de precio dinámico
+type ReglaPrecioRequest struct {
+	Nombre           string                 `json:"nombre"`
+	TipoHabitacionID *int                   `json:"tipoHabitacionId,omitempty"` // Todos los tipos si se omite
+	OcupacionMinima  *float64               `json:"ocupacionMinima,omitempty"`  // Porcentaje 0-100
+	OcupacionMaxima  *float64               `json:"ocupacionMaxima,omitempty"`  // Porcentaje 0-100
+	AntelacionMinima *int                   `json:"antelacionMinima,omitempty"` // Días hasta la llegada
+	AntelacionMaxima *int                   `json:"antelacionMaxima,omitempty"` // Días hasta la llegada
+	TipoModificador  domain.TipoModificador `json:"tipoModificador"`            // "porcentaje" o "monto"
+	ValorModificador float64                `json:"valorModificador"`
+	Prioridad        int                    `json:"prioridad"`
+	Activa           *bool                  `json:"activa,omitempty"` // Por defecto true
+}
+
+// toDomain convierte la petición en una regla de precio dinámico
+func (r ReglaPrecioRequest) toDomain() *domain.ReglaPrecio {
+	activa := true
+	if r.Activa != nil {
+		activa = *r.Activa
+	}
+
+	return &domain.ReglaPrecio{
+		Nombre:           r.Nombre,
+		TipoHabitacionID: r.TipoHabitacionID,
+		OcupacionMinima:  r.OcupacionMinima,
+		OcupacionMaxima:  r.OcupacionMaxima,
+		AntelacionMinima: r.AntelacionMinima,
+		AntelacionMaxima: r.AntelacionMaxima,
+		TipoModificador:  r.TipoModificador,
+		ValorModificador: r.ValorModificador,
+		Prioridad:        r.Prioridad,
+		Activa:           activa,
+	}
+}
+
+// GetReglasPrecio lista las reglas de precio dinámico (filtro opcional: tipoHabitacionId,
+// que incluye las reglas generales)
+func (h *TarifaHandler) GetReglasPrecio(c *fiber.Ctx) error {
+	reglas, err := h.service.GetReglasPrecio(c.QueryInt("tipoHabitacionId", 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": reglas})
+}
+
+// GetReglaPrecio obtiene una regla de precio dinámico
+func (h *TarifaHandler) GetReglaPrecio(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de regla inválido"})
+	}
+
+	regla, err := h.service.GetReglaPrecio(id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": regla})
+}
+
+// CreateReglaPrecio crea una regla de precio dinámico
+func (h *TarifaHandler) CreateReglaPrecio(c *fiber.Ctx) error {
+	var req ReglaPrecioRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	regla := req.toDomain()
+	if err := h.service.CreateReglaPrecio(regla); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": regla})
+}
+
+// UpdateReglaPrecio modifica una regla de precio dinámico
+func (h *TarifaHandler) UpdateReglaPrecio(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de regla inválido"})
+	}
+
+	var req ReglaPrecioRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de solicitud inválido"})
+	}
+
+	regla := req.toDomain()
+	regla.ID = id
+	if err := h.service.UpdateReglaPrecio(regla); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"data": regla})
+}
+
+// DeleteReglaPrecio elimina una regla de precio dinámico
+func (h *TarifaHandler) DeleteReglaPrecio(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID de regla inválido"})
+	}
+
+	if err := h.service.DeleteReglaPrecio(id); err != nil {
+		return h.errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Regla eliminada exitosamente"})
+}
+
+// SimularReglasPrecio muestra el precio resultante de cada noche de un rango con las reglas
+// activas, sin crear reservas
+// Query params: tipoHabitacionId, desde, hasta (YYYY-MM-DD, inclusive) y ocupacion (opcional,
+// porcentaje que reemplaza la ocupación real para probar escenarios)
+func (h *TarifaHandler) SimularReglasPrecio(c *fiber.Ctx) error {
+	tipoHabitacionID, err := strconv.Atoi(c.Query("tipoHabitacionId"))
+	if err != nil || tipoHabitacionID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "tipoHabitacionId inválido"})
+	}
+
+	desde, err := time.Parse("2006-01-02", c.Query("desde"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de desde inválido. Use YYYY-MM-DD"})
+	}
+	hasta, err := time.Parse("2006-01-02", c.Query("hasta"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Formato de hasta inválido. Use YYYY-MM-DD"})
+	}
+
+	var ocupacion *float64
+	if valor := c.Query("ocupacion"); valor != "" {
+		porcentaje, err := strconv.ParseFloat(valor, 64)
+		if err != nil || porcentaje < 0 || porcentaje > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ocupacion debe ser un porcentaje entre 0 y 100"})
+		}
+		ocupacion = &porcentaje
+	}
+
+	simulacion, err := h.service.SimularReglasPrecio(tipoHabitacionID, desde, hasta, ocupacion)
+	if err != nil {
+		if errors.Is(err, domain.ErrTarifaNoEncontrada) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": simulacion})
+}
+
+// parseEdades convierte una lista de edades separadas por coma ("3,7")
+func parseEdades(valor string) ([]int, error) {
+	if strings.TrimSpace(valor) == "" {
+		return nil, nil
+	}
+
+	partes := strings.Split(valor, ",")
+	edades := make([]int, len(partes))
+	for i, parte := range partes {
+		edad, err := strconv.Atoi(strings.TrimSpace(parte))
+		if err != nil {
+			return nil, errors.New("edadesNinhos inválido. Use edades separadas por coma, p. ej. 3,7")
+		}
+		edades[i] = edad
+	}
+	return edades, nil
+}
+
+func (h *TarifaHandler) errorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTarifaNoEncontrada), errors.Is(err, domain.ErrPlanTarifaNoEncontrado),
+		errors.Is(err, domain.ErrReglaPrecioNoEncontrada):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrTarifaInvalida), errors.Is(err, domain.ErrPlanTarifaInvalido),
+		errors.Is(err, domain.ErrReglaPrecioInvalida):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -7,20 +7,110 @@ import (
 	"github.com/Maxito7/hotel_backend/internal/domain"
 )
 
+const (
+	// holdReleaseInterval es la frecuencia con que se liberan las retenciones vencidas
+	holdReleaseInterval = time.Minute
+	// noShowInterval es la frecuencia con que se buscan reservas no-show
+	noShowInterval = time.Hour
+	// calendarSyncInterval es la frecuencia con que se importan los calendarios iCal externos
+	calendarSyncInterval = 30 * time.Minute
+	// channelSyncInterval es la frecuencia con que se sincronizan los canales de venta
+	channelSyncInterval = 15 * time.Minute
+)
+
+// NoShowProcessor marca como no-show las reservas confirmadas sin check-in que superaron el corte
+type NoShowProcessor interface {
+	ProcesarNoShows(ahora time.Time, corte time.Duration) ([]int, error)
+}
+
+// ListaEsperaNotifier avisa a la lista de espera cuando se libera inventario
+type ListaEsperaNotifier interface {
+	VencerEnlaces(ahora time.Time) ([]int, error)
+	NotificarDisponibilidad() error
+}
+
+// CalendarioExternoSincronizador importa los calendarios iCal de otras plataformas
+type CalendarioExternoSincronizador interface {
+	SincronizarTodos() ([]domain.ResultadoSincronizacion, error)
+}
+
+// CanalSincronizador importa las reservas de los canales de venta y les publica inventario y tarifas
+type CanalSincronizador interface {
+	SincronizarTodos() ([]domain.ResultadoSincronizacionCanal, error)
+}
+
 type ReservationScheduler struct {
-	reservaRepo domain.ReservaRepository
-	ticker      *time.Ticker
+	reservaRepo  domain.ReservaRepository
+	noShows      NoShowProcessor
+	noShowCutoff time.Duration
+	listaEspera  ListaEsperaNotifier
+	bloqueRepo   domain.BloqueGrupoRepository
+	ticker       *time.Ticker
+	holdTicker   *time.Ticker
+	noShowTicker *time.Ticker
+	calendarios  CalendarioExternoSincronizador
+	syncTicker   *time.Ticker
+	canales      CanalSincronizador
+	canalTicker  *time.Ticker
 }
 
 // NewReservationScheduler crea una nueva instancia del scheduler de reservas
-func NewReservationScheduler(reservaRepo domain.ReservaRepository) *ReservationScheduler {
+func NewReservationScheduler(
+	reservaRepo domain.ReservaRepository,
+	noShows NoShowProcessor,
+	noShowCutoff time.Duration,
+	listaEspera ListaEsperaNotifier,
+	bloqueRepo domain.BloqueGrupoRepository,
+	calendarios CalendarioExternoSincronizador,
+	canales CanalSincronizador,
+) *ReservationScheduler {
 	return &ReservationScheduler{
-		reservaRepo: reservaRepo,
+		reservaRepo:  reservaRepo,
+		noShows:      noShows,
+		noShowCutoff: noShowCutoff,
+		listaEspera:  listaEspera,
+		bloqueRepo:   bloqueRepo,
+		calendarios:  calendarios,
+		canales:      canales,
 	}
 }
 
-// Start inicia el scheduler que actualiza reservas expiradas cada 24 horas a las 00:01 AM
+// Start inicia el scheduler. Cada 24 horas, a las 00:01 AM, libera los bloques de grupo vencidos;
+// cada minuto libera las retenciones vencidas de reservas pendientes y cada hora marca las
+// reservas no-show. Las reservas solo pasan a Completada con el check-out. Los calendarios
+// externos se importan al iniciar y cada 30 minutos, y los canales de venta se sincronizan al
+// iniciar y cada 15 minutos
 func (s *ReservationScheduler) Start() {
+	s.holdTicker = time.NewTicker(holdReleaseInterval)
+	go func() {
+		for range s.holdTicker.C {
+			s.ReleaseExpiredHolds()
+		}
+	}()
+
+	s.noShowTicker = time.NewTicker(noShowInterval)
+	go func() {
+		for range s.noShowTicker.C {
+			s.DetectNoShows()
+		}
+	}()
+
+	go s.SyncExternalCalendars()
+	s.syncTicker = time.NewTicker(calendarSyncInterval)
+	go func() {
+		for range s.syncTicker.C {
+			s.SyncExternalCalendars()
+		}
+	}()
+
+	go s.SyncChannels()
+	s.canalTicker = time.NewTicker(channelSyncInterval)
+	go func() {
+		for range s.canalTicker.C {
+			s.SyncChannels()
+		}
+	}()
+
 	// Programar ejecución cada 24 horas a las 00:01 AM
 	now := time.Now()
 	nextRun := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 1, 0, 0, now.Location())
@@ -28,13 +118,13 @@ func (s *ReservationScheduler) Start() {
 
 	// Esperar hasta la próxima ejecución
 	time.AfterFunc(durationUntilNextRun, func() {
-		s.UpdateCompletedReservations()
+		s.ReleaseGroupBlocks()
 
 		// Luego ejecutar cada 24 horas
 		s.ticker = time.NewTicker(24 * time.Hour)
 		go func() {
 			for range s.ticker.C {
-				s.UpdateCompletedReservations()
+				s.ReleaseGroupBlocks()
 			}
 		}()
 	})
@@ -42,19 +132,143 @@ func (s *ReservationScheduler) Start() {
 
 // Stop detiene el scheduler
 func (s *ReservationScheduler) Stop() {
+	if s.holdTicker != nil {
+		s.holdTicker.Stop()
+	}
+	if s.noShowTicker != nil {
+		s.noShowTicker.Stop()
+	}
+	if s.syncTicker != nil {
+		s.syncTicker.Stop()
+	}
+	if s.canalTicker != nil {
+		s.canalTicker.Stop()
+	}
 	if s.ticker != nil {
 		s.ticker.Stop()
 		log.Println("🛑 Scheduler de reservas detenido")
 	}
 }
 
-// UpdateCompletedReservations actualiza las reservas que ya han pasado su fecha de checkout
-func (s *ReservationScheduler) UpdateCompletedReservations() {
-	log.Println("🔄 Ejecutando actualización de reservas completadas...")
+// DetectNoShows marca como no-show las reservas confirmadas sin check-in que superaron el corte,
+// aplicando su penalidad y dejando una alerta para recepción
+func (s *ReservationScheduler) DetectNoShows() {
+	if s.noShows == nil {
+		return
+	}
+
+	marcadas, err := s.noShows.ProcesarNoShows(time.Now(), s.noShowCutoff)
+	if err != nil {
+		log.Printf("❌ Error detectando no-shows: %v", err)
+	}
+
+	if len(marcadas) > 0 {
+		log.Printf("✅ Reservas marcadas como no-show: %v", marcadas)
+	}
+}
+
+// ReleaseGroupBlocks devuelve a la venta general las habitaciones no recogidas de los bloques
+// de grupo cuya fecha de liberación llegó
+func (s *ReservationScheduler) ReleaseGroupBlocks() {
+	if s.bloqueRepo == nil {
+		return
+	}
+
+	ahora := time.Now()
+	liberados, err := s.bloqueRepo.LiberarVencidos(ahora, ahora.UTC())
+	if err != nil {
+		log.Printf("❌ Error liberando bloques de grupo: %v", err)
+		return
+	}
+
+	if len(liberados) == 0 {
+		return
+	}
+	log.Printf("✅ Bloques de grupo liberados: %v", liberados)
+
+	if s.listaEspera != nil {
+		if err := s.listaEspera.NotificarDisponibilidad(); err != nil {
+			log.Printf("❌ Error notificando lista de espera: %v", err)
+		}
+	}
+}
+
+// ReleaseExpiredHolds cancela las reservas pendientes cuya retención venció y libera sus habitaciones.
+// Si se liberó inventario o venció algún enlace de la lista de espera, avisa al siguiente en la lista
+func (s *ReservationScheduler) ReleaseExpiredHolds() {
+	ahora := time.Now().UTC()
+
+	liberadas, err := s.reservaRepo.ReleaseExpiredHolds(ahora)
+	if err != nil {
+		log.Printf("❌ Error liberando retenciones vencidas: %v", err)
+		return
+	}
+
+	if len(liberadas) > 0 {
+		log.Printf("✅ Retenciones vencidas liberadas: %v", liberadas)
+	}
+
+	if s.listaEspera == nil {
+		return
+	}
+
+	vencidos, err := s.listaEspera.VencerEnlaces(ahora)
+	if err != nil {
+		log.Printf("❌ Error venciendo enlaces de lista de espera: %v", err)
+		return
+	}
+
+	if len(liberadas) > 0 || len(vencidos) > 0 {
+		if err := s.listaEspera.NotificarDisponibilidad(); err != nil {
+			log.Printf("❌ Error notificando lista de espera: %v", err)
+		}
+	}
+}
+
+// SyncExternalCalendars importa los calendarios iCal externos activos como bloqueos de
+// habitaciones y registra los errores y los conflictos con reservas del hotel
+func (s *ReservationScheduler) SyncExternalCalendars() {
+	if s.calendarios == nil {
+		return
+	}
+
+	resultados, err := s.calendarios.SincronizarTodos()
+	if err != nil {
+		log.Printf("❌ Error sincronizando calendarios externos: %v", err)
+		return
+	}
+
+	for _, r := range resultados {
+		if r.Error != "" {
+			log.Printf("❌ Error sincronizando calendario externo %d: %s", r.CalendarioExternoID, r.Error)
+			continue
+		}
+		if len(r.Conflictos) > 0 {
+			log.Printf("⚠️ Calendario externo %d: %d conflictos con reservas del hotel", r.CalendarioExternoID, len(r.Conflictos))
+		}
+	}
+}
+
+// SyncChannels sincroniza los canales de venta activos: importa sus reservas y publica el
+// inventario y las tarifas de los tipos mapeados
+func (s *ReservationScheduler) SyncChannels() {
+	if s.canales == nil {
+		return
+	}
+
+	resultados, err := s.canales.SincronizarTodos()
+	if err != nil {
+		log.Printf("❌ Error sincronizando canales de venta: %v", err)
+		return
+	}
 
-	if err := s.reservaRepo.UpdateExpiredReservations(); err != nil {
-		log.Printf("❌ Error actualizando reservas completadas: %v", err)
-	} else {
-		log.Println("✅ Reservas completadas actualizadas exitosamente")
+	for _, r := range resultados {
+		if r.Error != "" {
+			log.Printf("❌ Error sincronizando canal %s: %s", r.Codigo, r.Error)
+			continue
+		}
+		if len(r.Rechazadas) > 0 {
+			log.Printf("⚠️ Canal %s: %d reservas rechazadas", r.Codigo, len(r.Rechazadas))
+		}
 	}
 }
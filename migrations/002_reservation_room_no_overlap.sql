@@ -0,0 +1,30 @@
+-- Migration to prevent double-booking of a room
+-- Date: 2026-10-16
+-- Description: Adds an exclusion constraint so two active reservation_room rows can never
+-- overlap for the same room. Ranges are half-open: the checkout day is free for a new check-in.
+-- Cancelling or completing a reservation used to change only reservation.status, so their
+-- reservation_room rows are released first. Overlaps between reservations that still hold
+-- inventory must be resolved by hand before running this migration.
+
+-- Release the rooms of reservations that no longer hold inventory
+UPDATE reservation_room rr
+SET status = 0
+FROM reservation r
+WHERE r.reservation_id = rr.reservation_id
+  AND r.status IN ('Cancelada', 'Completada')
+  AND rr.status = 1;
+
+-- btree_gist is required to combine the integer equality with the range overlap operator
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE reservation_room
+DROP CONSTRAINT IF EXISTS reservation_room_no_overlap;
+
+ALTER TABLE reservation_room
+ADD CONSTRAINT reservation_room_no_overlap
+EXCLUDE USING gist (
+    room_id WITH =,
+    tsrange(check_in_date, check_out_date, '[)') WITH &&
+) WHERE (status = 1);
+
+COMMENT ON CONSTRAINT reservation_room_no_overlap ON reservation_room IS 'Prevents two active bookings of the same room for overlapping dates';
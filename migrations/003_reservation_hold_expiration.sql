@@ -0,0 +1,15 @@
+-- Migration to add expiring holds for pending reservations
+-- Date: 2026-10-16
+-- Description: Pending reservations hold their rooms only until hold_expires_at (UTC).
+-- Once the deadline passes the scheduler cancels them and frees their reservation_room rows.
+-- Reservations in any other status keep hold_expires_at NULL.
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS hold_expires_at TIMESTAMP;
+
+-- Index used by the scheduler to find expired holds quickly
+CREATE INDEX IF NOT EXISTS idx_reservation_hold_expires_at
+ON reservation (hold_expires_at)
+WHERE status = 'Pendiente' AND hold_expires_at IS NOT NULL;
+
+COMMENT ON COLUMN reservation.hold_expires_at IS 'UTC deadline until which a pending reservation blocks its rooms';
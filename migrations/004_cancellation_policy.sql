@@ -0,0 +1,32 @@
+-- Migration to add configurable cancellation policies
+-- Date: 2026-10-16
+-- Description: Cancellation policies per room type (room_type_id NULL = hotel-wide default).
+-- Outside the free window a penalty is charged as a percentage of the room amount or as a
+-- number of nights. Non-refundable policies always charge the full amount.
+
+CREATE TABLE IF NOT EXISTS cancellation_policy (
+    cancellation_policy_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    room_type_id INTEGER REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    free_cancellation_hours INTEGER NOT NULL DEFAULT 48 CHECK (free_cancellation_hours >= 0),
+    penalty_type VARCHAR(20) NOT NULL DEFAULT 'noches' CHECK (penalty_type IN ('porcentaje', 'noches')),
+    penalty_value NUMERIC(10, 2) NOT NULL DEFAULT 1 CHECK (penalty_value >= 0),
+    non_refundable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Only one active policy per room type and one active hotel-wide default
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cancellation_policy_room_type_active
+ON cancellation_policy (room_type_id)
+WHERE active AND room_type_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cancellation_policy_default_active
+ON cancellation_policy ((room_type_id IS NULL))
+WHERE active AND room_type_id IS NULL;
+
+-- Default policy matching what the chatbot announces: free cancellation up to 48 hours before
+INSERT INTO cancellation_policy (name, room_type_id, free_cancellation_hours, penalty_type, penalty_value, non_refundable)
+SELECT 'Flexible 48 horas', NULL, 48, 'noches', 1, FALSE
+WHERE NOT EXISTS (SELECT 1 FROM cancellation_policy WHERE room_type_id IS NULL AND active);
+
+COMMENT ON TABLE cancellation_policy IS 'Cancellation terms per room type; room_type_id NULL is the hotel-wide default';
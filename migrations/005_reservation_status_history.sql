@@ -0,0 +1,24 @@
+-- Migration to add the reservation state machine and its audit trail
+-- Date: 2026-10-16
+-- Description: Adds the EnCurso (checked in) and NoShow states to reservation_status and a
+-- reservation_status_history table that records every transition with its actor and reason.
+-- NOTE: ALTER TYPE ... ADD VALUE cannot run inside a transaction block on PostgreSQL < 12.
+
+ALTER TYPE reservation_status ADD VALUE IF NOT EXISTS 'EnCurso';
+ALTER TYPE reservation_status ADD VALUE IF NOT EXISTS 'NoShow';
+
+CREATE TABLE IF NOT EXISTS reservation_status_history (
+    history_id SERIAL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL REFERENCES reservation(reservation_id) ON DELETE CASCADE,
+    previous_status reservation_status,
+    new_status reservation_status NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    actor VARCHAR(100) NOT NULL,
+    reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservation_status_history_reservation
+ON reservation_status_history (reservation_id, changed_at);
+
+COMMENT ON TABLE reservation_status_history IS 'Audit trail of reservation status transitions (previous_status NULL = creation)';
+COMMENT ON COLUMN reservation_status_history.actor IS 'Who triggered the change: a user identifier, api, chatbot or sistema';
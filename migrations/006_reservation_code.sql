@@ -0,0 +1,21 @@
+-- Migration to add human-friendly reservation codes
+-- Date: 2026-10-16
+-- Description: Every reservation gets a short, unguessable confirmation code (e.g. INCA-7KQ2X9)
+-- generated by the application at creation time. Guests use it together with their email to
+-- look up their booking without knowing the internal reservation_id.
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS reservation_code VARCHAR(20);
+
+-- Backfill existing reservations with a random code
+UPDATE reservation
+SET reservation_code = 'INCA-' || UPPER(SUBSTRING(MD5(RANDOM()::text || reservation_id::text) FROM 1 FOR 6))
+WHERE reservation_code IS NULL;
+
+ALTER TABLE reservation
+ALTER COLUMN reservation_code SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservation_code
+ON reservation (reservation_code);
+
+COMMENT ON COLUMN reservation.reservation_code IS 'Public confirmation code shown to guests (INCA-XXXXXX)';
@@ -0,0 +1,48 @@
+-- Migration to price add-on services attached to reservations
+-- Date: 2026-10-16
+-- Description: reservation_service rows get their own identifier as primary key (so a single
+-- line can be removed and the same service attached more than once), a quantity and the unit
+-- price captured when the service was attached. The cost of a line is
+-- unit_price × quantity × days, and it is included in reservation.subtotal.
+
+ALTER TABLE reservation_service
+ADD COLUMN IF NOT EXISTS reservation_service_id SERIAL;
+
+ALTER TABLE reservation_service
+ADD COLUMN IF NOT EXISTS quantity INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE reservation_service
+ADD COLUMN IF NOT EXISTS unit_price DECIMAL(10,2);
+
+-- Existing rows keep the current catalog price
+UPDATE reservation_service rs
+SET unit_price = s.price
+FROM service s
+WHERE s.service_id = rs.service_id
+AND rs.unit_price IS NULL;
+
+ALTER TABLE reservation_service
+ALTER COLUMN unit_price SET DEFAULT 0;
+
+-- Each line is identified by reservation_service_id: the same service can be attached more than
+-- once to a reservation (other dates or quantities, several plans that include it, or again
+-- after a removed line, which is kept with status = 0)
+ALTER TABLE reservation_service
+DROP CONSTRAINT IF EXISTS reservation_service_pkey;
+
+ALTER TABLE reservation_service
+ADD CONSTRAINT reservation_service_pkey PRIMARY KEY (reservation_service_id);
+
+DROP INDEX IF EXISTS idx_reservation_service_id;
+
+CREATE INDEX IF NOT EXISTS idx_reservation_service_reservation
+ON reservation_service (reservation_id);
+
+ALTER TABLE reservation_service
+DROP CONSTRAINT IF EXISTS reservation_service_quantity_positive;
+
+ALTER TABLE reservation_service
+ADD CONSTRAINT reservation_service_quantity_positive CHECK (quantity > 0);
+
+COMMENT ON COLUMN reservation_service.quantity IS 'Units of the service per day (e.g. number of breakfasts)';
+COMMENT ON COLUMN reservation_service.unit_price IS 'Service price per unit and day when it was attached';
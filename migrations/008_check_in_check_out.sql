@@ -0,0 +1,26 @@
+-- Migration to support the front-desk check-in and check-out workflow
+-- Date: 2026-10-16
+-- Description: Records the actual arrival and departure times of a reservation and of each of
+-- its registered guests, marks when the folio was closed, and adds a housekeeping status to
+-- rooms (independent of room.status, which controls whether a room can be sold).
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS checked_out_at TIMESTAMP;
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS folio_closed_at TIMESTAMP;
+
+ALTER TABLE reservation_guest
+ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
+
+ALTER TABLE room
+ADD COLUMN IF NOT EXISTS housekeeping_status VARCHAR(20) NOT NULL DEFAULT 'Limpia';
+
+COMMENT ON COLUMN reservation.checked_in_at IS 'UTC time the guests actually arrived (check-in)';
+COMMENT ON COLUMN reservation.checked_out_at IS 'UTC time the guests actually left (check-out)';
+COMMENT ON COLUMN reservation.folio_closed_at IS 'UTC time the folio was settled and closed';
+COMMENT ON COLUMN reservation_guest.checked_in_at IS 'UTC time the guest was registered at the front desk';
+COMMENT ON COLUMN room.housekeeping_status IS 'Cleaning status of the physical room: Limpia, Sucia';
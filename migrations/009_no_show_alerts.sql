@@ -0,0 +1,26 @@
+-- Migration to support automatic no-show detection
+-- Date: 2026-10-16
+-- Description: Adapts the existing alert table so the no-show job can leave a notice for the
+-- front desk tied to a reservation: adds reservation_id, lets unread alerts have no read_date
+-- and gives defaults to was_read, registration_date and status. Also adds an index to find
+-- confirmed reservations without check-in.
+
+ALTER TABLE alert
+ADD COLUMN IF NOT EXISTS reservation_id INTEGER REFERENCES reservation(reservation_id) ON DELETE CASCADE;
+
+ALTER TABLE alert ALTER COLUMN read_date DROP NOT NULL;
+ALTER TABLE alert ALTER COLUMN was_read SET DEFAULT FALSE;
+ALTER TABLE alert ALTER COLUMN registration_date SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE alert ALTER COLUMN status SET DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_alert_unread
+ON alert (registration_date)
+WHERE was_read = FALSE;
+
+-- Index used by the no-show job
+CREATE INDEX IF NOT EXISTS idx_reservation_confirmed_without_check_in
+ON reservation (reservation_id)
+WHERE status = 'Confirmada' AND checked_in_at IS NULL;
+
+COMMENT ON COLUMN alert.alert_type IS '1: no-show, 2: external calendar conflict, 3: rejected channel reservation';
+COMMENT ON COLUMN alert.reservation_id IS 'Reservation the alert refers to, if any';
@@ -0,0 +1,36 @@
+-- Migration to add a waitlist for sold-out dates
+-- Date: 2026-10-16
+-- Description: Guests join the waitlist for a room type and date range. When inventory is freed
+-- they are notified in FIFO order (created_at) with a booking link that expires at link_expires_at.
+
+CREATE TABLE IF NOT EXISTS waitlist (
+    waitlist_id SERIAL PRIMARY KEY,
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id),
+    check_in_date DATE NOT NULL,
+    check_out_date DATE NOT NULL,
+    adults INTEGER NOT NULL DEFAULT 1,
+    children INTEGER NOT NULL DEFAULT 0,
+    name VARCHAR(150) NOT NULL,
+    email VARCHAR(150) NOT NULL,
+    phone VARCHAR(30),
+    status VARCHAR(20) NOT NULL DEFAULT 'Esperando',
+    token VARCHAR(64),
+    notified_at TIMESTAMP,
+    link_expires_at TIMESTAMP,
+    reservation_id INTEGER REFERENCES reservation(reservation_id),
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    CONSTRAINT chk_waitlist_dates CHECK (check_out_date > check_in_date),
+    CONSTRAINT chk_waitlist_status CHECK (status IN ('Esperando', 'Notificada', 'Reservada', 'Vencida', 'Cancelada'))
+);
+
+-- FIFO scan of active entries
+CREATE INDEX IF NOT EXISTS idx_waitlist_active
+ON waitlist (created_at, waitlist_id)
+WHERE status IN ('Esperando', 'Notificada');
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_token
+ON waitlist (token)
+WHERE token IS NOT NULL;
+
+COMMENT ON TABLE waitlist IS 'Guests waiting for a room type to free up on sold-out dates';
+COMMENT ON COLUMN waitlist.link_expires_at IS 'Expiration of the booking link sent when inventory was freed';
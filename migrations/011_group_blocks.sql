@@ -0,0 +1,38 @@
+-- Migration to add group blocks (allotments)
+-- Date: 2026-10-16
+-- Description: A group block holds a number of rooms of one type for a date range under a group
+-- name. Reservations picked up against the block reference it through reservation.group_block_id.
+-- Unpicked rooms go back to general sale on release_date (scheduler) or when released manually.
+
+CREATE TABLE IF NOT EXISTS group_block (
+    group_block_id SERIAL PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    contact_name VARCHAR(150) NOT NULL DEFAULT '',
+    contact_email VARCHAR(150),
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id),
+    quantity INTEGER NOT NULL,
+    check_in_date DATE NOT NULL,
+    check_out_date DATE NOT NULL,
+    release_date DATE NOT NULL,
+    negotiated_price NUMERIC(10, 2),
+    status VARCHAR(20) NOT NULL DEFAULT 'Activo',
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    released_at TIMESTAMP,
+    CONSTRAINT chk_group_block_quantity CHECK (quantity > 0),
+    CONSTRAINT chk_group_block_dates CHECK (check_out_date > check_in_date AND release_date <= check_in_date),
+    CONSTRAINT chk_group_block_status CHECK (status IN ('Activo', 'Liberado'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_block_active
+ON group_block (room_type_id, check_in_date, check_out_date)
+WHERE status = 'Activo';
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS group_block_id INTEGER REFERENCES group_block(group_block_id);
+
+CREATE INDEX IF NOT EXISTS idx_reservation_group_block
+ON reservation (group_block_id)
+WHERE group_block_id IS NOT NULL;
+
+COMMENT ON TABLE group_block IS 'Rooms held for a group; unpicked rooms are subtracted from public availability until release_date';
+COMMENT ON COLUMN reservation.group_block_id IS 'Group block the reservation was picked up against';
@@ -0,0 +1,45 @@
+-- Migration to add a seasonal and day-of-week rate calendar
+-- Date: 2026-10-16
+-- Description: Rate seasons set the nightly price of a room type between two dates (inclusive),
+-- optionally with a different price for Friday and Saturday nights. Per-date overrides take
+-- precedence over any season. Nights not covered fall back to room_type.price.
+-- reservation_room.total_price stores the quoted stay amount so totals never drift from the
+-- per-night breakdown; price keeps the average nightly rate.
+
+CREATE TABLE IF NOT EXISTS rate_season (
+    rate_season_id SERIAL PRIMARY KEY,
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    nightly_price NUMERIC(10, 2) NOT NULL CHECK (nightly_price > 0),
+    weekend_price NUMERIC(10, 2) CHECK (weekend_price > 0),
+    priority INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_season_room_type_dates
+ON rate_season (room_type_id, start_date, end_date)
+WHERE active;
+
+CREATE TABLE IF NOT EXISTS rate_date_override (
+    rate_date_override_id SERIAL PRIMARY KEY,
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    rate_date DATE NOT NULL,
+    price NUMERIC(10, 2) NOT NULL CHECK (price > 0),
+    reason VARCHAR(150) NOT NULL DEFAULT '',
+    UNIQUE (room_type_id, rate_date)
+);
+
+ALTER TABLE reservation_room
+ADD COLUMN IF NOT EXISTS total_price NUMERIC(10, 2);
+
+-- Existing stays were charged at a flat nightly price
+UPDATE reservation_room
+SET total_price = price * GREATEST(1, check_out_date::date - check_in_date::date)
+WHERE total_price IS NULL;
+
+COMMENT ON TABLE rate_season IS 'Nightly price of a room type for a date range; highest priority wins on overlap';
+COMMENT ON TABLE rate_date_override IS 'Nightly price of a room type for a single date; overrides any season';
+COMMENT ON COLUMN reservation_room.total_price IS 'Stay amount from the rate calendar; price is the average nightly rate';
@@ -0,0 +1,35 @@
+-- Migration to add rate plans per room type
+-- Date: 2026-10-16
+-- Description: A rate plan is a product sold on a room type (flexible, non-refundable, breakfast
+-- included...). It adjusts the rate calendar price of every night by a percentage or a fixed
+-- amount, may carry its own cancellation terms (NULL terms = room type policy) and includes
+-- services at no extra cost. reservation_room.rate_plan_id records the plan that was booked.
+
+CREATE TABLE IF NOT EXISTS rate_plan (
+    rate_plan_id SERIAL PRIMARY KEY,
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    code VARCHAR(30) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    modifier_type VARCHAR(20) NOT NULL DEFAULT 'porcentaje' CHECK (modifier_type IN ('porcentaje', 'monto')),
+    modifier_value NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    free_cancellation_hours INTEGER CHECK (free_cancellation_hours >= 0),
+    penalty_type VARCHAR(20) CHECK (penalty_type IN ('porcentaje', 'noches')),
+    penalty_value NUMERIC(10, 2) CHECK (penalty_value >= 0),
+    non_refundable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    UNIQUE (room_type_id, code)
+);
+
+CREATE TABLE IF NOT EXISTS rate_plan_service (
+    rate_plan_id INTEGER NOT NULL REFERENCES rate_plan(rate_plan_id) ON DELETE CASCADE,
+    service_id INTEGER NOT NULL REFERENCES service(service_id),
+    PRIMARY KEY (rate_plan_id, service_id)
+);
+
+ALTER TABLE reservation_room
+ADD COLUMN IF NOT EXISTS rate_plan_id INTEGER REFERENCES rate_plan(rate_plan_id);
+
+COMMENT ON TABLE rate_plan IS 'Products sold on a room type with their own price modifier, cancellation terms and included services';
+COMMENT ON COLUMN rate_plan.free_cancellation_hours IS 'NULL (and not non_refundable) means the room type cancellation policy applies';
+COMMENT ON COLUMN reservation_room.rate_plan_id IS 'Rate plan booked for the room; NULL = plain calendar rate';
@@ -0,0 +1,43 @@
+-- Migration to add promo codes tied to marketing campaigns
+-- Date: 2026-10-16
+-- Description: A promo code belongs to a campaign and grants a percentage or fixed discount on
+-- the room amount of a reservation. Codes have a booking window, minimum nights, an optional
+-- usage limit and optional room type restrictions (no rows = every room type). Each reservation
+-- that uses a code records a redemption with the discounted amount for per-campaign reporting.
+
+CREATE TABLE IF NOT EXISTS promo_code (
+    promo_code_id SERIAL PRIMARY KEY,
+    campaign_id INTEGER NOT NULL REFERENCES campaign(campaign_id),
+    code VARCHAR(40) NOT NULL UNIQUE,
+    description VARCHAR(300) NOT NULL DEFAULT '',
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('porcentaje', 'monto')),
+    discount_value NUMERIC(10, 2) NOT NULL CHECK (discount_value > 0),
+    valid_from DATE NOT NULL,
+    valid_to DATE NOT NULL,
+    min_nights INTEGER NOT NULL DEFAULT 0 CHECK (min_nights >= 0),
+    max_uses INTEGER CHECK (max_uses > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT chk_promo_code_dates CHECK (valid_to >= valid_from)
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_room_type (
+    promo_code_id INTEGER NOT NULL REFERENCES promo_code(promo_code_id) ON DELETE CASCADE,
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    PRIMARY KEY (promo_code_id, room_type_id)
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_redemption (
+    redemption_id SERIAL PRIMARY KEY,
+    promo_code_id INTEGER NOT NULL REFERENCES promo_code(promo_code_id),
+    reservation_id INTEGER NOT NULL UNIQUE REFERENCES reservation(reservation_id) ON DELETE CASCADE,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount >= 0),
+    redeemed_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_code_campaign ON promo_code (campaign_id);
+CREATE INDEX IF NOT EXISTS idx_promo_code_redemption_code ON promo_code_redemption (promo_code_id);
+
+COMMENT ON TABLE promo_code IS 'Discount codes linked to a marketing campaign';
+COMMENT ON COLUMN promo_code.code IS 'Stored upper-case; clients may type it in any case';
+COMMENT ON COLUMN promo_code.max_uses IS 'NULL = unlimited; redemptions of cancelled reservations do not count';
+COMMENT ON TABLE promo_code_redemption IS 'One row per reservation that used a promo code, with the discount granted';
@@ -0,0 +1,33 @@
+-- Migration to add occupancy-based pricing for extra guests
+-- Date: 2026-10-16
+-- Description: The room price covers included_adults. Each extra adult pays extra_adult_price per
+-- night and each child pays the nightly price of its age band (price 0 = free, e.g. under 5).
+-- Children whose age is outside every band pay as an extra adult. Room types without a row keep
+-- their full capacity included in the price. reservation_room.occupancy_surcharge is the part of
+-- total_price charged for extra guests and reservation.children_ages keeps the ages declared.
+
+CREATE TABLE IF NOT EXISTS room_type_occupancy_rate (
+    room_type_id INTEGER PRIMARY KEY REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    included_adults INTEGER NOT NULL CHECK (included_adults > 0),
+    extra_adult_price NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (extra_adult_price >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS room_type_child_rate (
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    min_age INTEGER NOT NULL CHECK (min_age >= 0),
+    max_age INTEGER NOT NULL,
+    nightly_price NUMERIC(10, 2) NOT NULL CHECK (nightly_price >= 0),
+    PRIMARY KEY (room_type_id, min_age),
+    CONSTRAINT chk_room_type_child_rate_ages CHECK (max_age >= min_age)
+);
+
+ALTER TABLE reservation_room
+ADD COLUMN IF NOT EXISTS occupancy_surcharge NUMERIC(10, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS children_ages INTEGER[] NOT NULL DEFAULT '{}';
+
+COMMENT ON TABLE room_type_occupancy_rate IS 'Extra guest surcharges per room type; no row = full capacity included in the price';
+COMMENT ON TABLE room_type_child_rate IS 'Nightly price per child age band (inclusive ages)';
+COMMENT ON COLUMN reservation_room.occupancy_surcharge IS 'Part of total_price charged for extra adults and children';
+COMMENT ON COLUMN reservation.children_ages IS 'Declared ages of the children; may hold fewer entries than children_count';
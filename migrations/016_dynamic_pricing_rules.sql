@@ -0,0 +1,33 @@
+-- Migration to add occupancy-driven dynamic pricing rules
+-- Date: 2026-10-16
+-- Description: A pricing rule adjusts the nightly calendar price by a percentage or a fixed amount
+-- when its conditions hold: hotel occupancy of the night between min_occupancy and max_occupancy
+-- (percent, inclusive) and days from today to arrival between min_lead_days and max_lead_days.
+-- NULL conditions are not evaluated and NULL room_type_id applies the rule to every room type.
+-- When several rules match a night only the one with the highest priority is applied. Prices set
+-- for a specific date and agreed fixed prices are never adjusted.
+
+CREATE TABLE IF NOT EXISTS dynamic_pricing_rule (
+    dynamic_pricing_rule_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    room_type_id INTEGER REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    min_occupancy NUMERIC(5, 2) CHECK (min_occupancy BETWEEN 0 AND 100),
+    max_occupancy NUMERIC(5, 2) CHECK (max_occupancy BETWEEN 0 AND 100),
+    min_lead_days INTEGER CHECK (min_lead_days >= 0),
+    max_lead_days INTEGER CHECK (max_lead_days >= 0),
+    modifier_type VARCHAR(20) NOT NULL DEFAULT 'porcentaje' CHECK (modifier_type IN ('porcentaje', 'monto')),
+    modifier_value NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    priority INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT chk_dynamic_pricing_rule_condition CHECK (
+        min_occupancy IS NOT NULL OR max_occupancy IS NOT NULL
+        OR min_lead_days IS NOT NULL OR max_lead_days IS NOT NULL
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_dynamic_pricing_rule_room_type
+ON dynamic_pricing_rule(room_type_id) WHERE active;
+
+COMMENT ON TABLE dynamic_pricing_rule IS 'Nightly price adjustments driven by live occupancy and booking lead time';
+COMMENT ON COLUMN dynamic_pricing_rule.room_type_id IS 'NULL = the rule applies to every room type';
+COMMENT ON COLUMN dynamic_pricing_rule.min_lead_days IS 'Days between today and the arrival date (inclusive)';
@@ -0,0 +1,31 @@
+-- Migration to add stay restrictions
+-- Date: 2026-10-16
+-- Description: Restrictions limit which stays can be sold between start_date and end_date
+-- (inclusive), for one room type or for the whole hotel (room_type_id NULL):
+--   estadia_minima / estadia_maxima: arrivals in the range must stay at least / at most nights
+--   cerrado_llegada: no arrivals in the range
+--   cerrado_salida: no departures in the range
+--   bloqueo: no night in the range is sold
+-- Group block pickups keep the dates agreed for the block and are not restricted.
+
+CREATE TABLE IF NOT EXISTS stay_restriction (
+    stay_restriction_id SERIAL PRIMARY KEY,
+    room_type_id INTEGER REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    restriction_type VARCHAR(20) NOT NULL CHECK (restriction_type IN ('estadia_minima', 'estadia_maxima', 'cerrado_llegada', 'cerrado_salida', 'bloqueo')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    nights INTEGER CHECK (nights > 0),
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT chk_stay_restriction_dates CHECK (end_date >= start_date),
+    CONSTRAINT chk_stay_restriction_nights CHECK (
+        (restriction_type IN ('estadia_minima', 'estadia_maxima')) = (nights IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_stay_restriction_dates
+ON stay_restriction(start_date, end_date) WHERE active;
+
+COMMENT ON TABLE stay_restriction IS 'Length of stay, closed to arrival/departure and blackout restrictions';
+COMMENT ON COLUMN stay_restriction.room_type_id IS 'NULL = the restriction applies to the whole hotel';
+COMMENT ON COLUMN stay_restriction.nights IS 'Minimum or maximum nights; only for estadia_minima and estadia_maxima';
@@ -0,0 +1,23 @@
+-- Migration to add iCalendar feeds
+-- Date: 2026-10-16
+-- Description: Each room and room type can publish an iCal feed of its occupied ranges
+-- (reservation_room rows of reservations that hold inventory). The feed URL carries a secret
+-- token; rotating it invalidates the URLs already shared with owners and OTAs.
+
+CREATE TABLE IF NOT EXISTS calendar_feed (
+    calendar_feed_id SERIAL PRIMARY KEY,
+    room_id INTEGER REFERENCES room(room_id) ON DELETE CASCADE,
+    room_type_id INTEGER REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    CONSTRAINT chk_calendar_feed_scope CHECK ((room_id IS NULL) <> (room_type_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feed_room
+ON calendar_feed(room_id) WHERE room_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feed_room_type
+ON calendar_feed(room_type_id) WHERE room_type_id IS NOT NULL;
+
+COMMENT ON TABLE calendar_feed IS 'Secret tokens of the iCal occupancy feeds per room or room type';
+COMMENT ON COLUMN calendar_feed.token IS 'Secret included in the feed URL; rotated on demand';
@@ -0,0 +1,39 @@
+-- Migration to import external iCal calendars
+-- Date: 2026-10-16
+-- Description: Rooms sold on other platforms block inventory here. Each external_calendar is an
+-- iCal feed URL or an uploaded .ics file (ics_content) for one room; the scheduler imports its
+-- events as external_block rows, which availability treats as occupied half-open ranges
+-- [start_date, end_date). A failed sync keeps the previous blocks and records last_error.
+
+CREATE TABLE IF NOT EXISTS external_calendar (
+    external_calendar_id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL REFERENCES room(room_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    url TEXT,
+    ics_content TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_synced_at TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    CONSTRAINT chk_external_calendar_source CHECK ((url IS NULL) <> (ics_content IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS external_block (
+    external_block_id SERIAL PRIMARY KEY,
+    external_calendar_id INTEGER NOT NULL REFERENCES external_calendar(external_calendar_id) ON DELETE CASCADE,
+    room_id INTEGER NOT NULL REFERENCES room(room_id) ON DELETE CASCADE,
+    uid VARCHAR(255) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    summary VARCHAR(255) NOT NULL DEFAULT '',
+    CONSTRAINT chk_external_block_dates CHECK (end_date > start_date),
+    CONSTRAINT uq_external_block_uid UNIQUE (external_calendar_id, uid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_block_room_dates
+ON external_block(room_id, start_date, end_date);
+
+COMMENT ON TABLE external_calendar IS 'iCal feeds of other platforms that block a room';
+COMMENT ON COLUMN external_calendar.ics_content IS 'Uploaded .ics file; NULL when the calendar is read from url';
+COMMENT ON TABLE external_block IS 'Occupied ranges imported from external calendars';
+COMMENT ON COLUMN external_block.uid IS 'UID of the iCal event within its calendar';
@@ -0,0 +1,37 @@
+-- Migration to add sales channels (OTAs and channel managers)
+-- Date: 2026-10-16
+-- Description: Each channel has a code that selects its adapter in the application and maps
+-- room types to the channel's room codes. Bookings pulled from a channel are created as regular
+-- reservations that record the channel code and the channel's booking reference; the unique
+-- index makes the import idempotent.
+
+CREATE TABLE IF NOT EXISTS channel (
+    channel_id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_synced_at TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS channel_room_mapping (
+    channel_id INTEGER NOT NULL REFERENCES channel(channel_id) ON DELETE CASCADE,
+    room_type_id INTEGER NOT NULL REFERENCES room_type(room_type_id) ON DELETE CASCADE,
+    external_code VARCHAR(100) NOT NULL,
+    PRIMARY KEY (channel_id, room_type_id),
+    CONSTRAINT uq_channel_room_mapping_code UNIQUE (channel_id, external_code)
+);
+
+ALTER TABLE reservation
+ADD COLUMN IF NOT EXISTS channel_code VARCHAR(50),
+ADD COLUMN IF NOT EXISTS channel_booking_ref VARCHAR(100);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservation_channel_booking
+ON reservation(channel_code, channel_booking_ref)
+WHERE channel_code IS NOT NULL;
+
+COMMENT ON TABLE channel IS 'Sales channels synchronized through a channel adapter';
+COMMENT ON COLUMN channel.code IS 'Selects the adapter registered in the application';
+COMMENT ON TABLE channel_room_mapping IS 'Room type codes used by each channel';
+COMMENT ON COLUMN reservation.channel_code IS 'Channel the reservation came from; NULL = direct sale';
+COMMENT ON COLUMN reservation.channel_booking_ref IS 'Booking identifier in the channel';
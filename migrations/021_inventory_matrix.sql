@@ -0,0 +1,11 @@
+-- Migration to support the daily inventory matrix
+-- Date: 2026-10-16
+-- Description: GET /api/inventario counts every room type and night of a range in one query.
+-- The reservation_room_no_overlap constraint indexes (room_id, tsrange) and cannot serve a scan
+-- of all rooms by date, so active reservation rooms get a date index of their own.
+
+CREATE INDEX IF NOT EXISTS idx_reservation_room_active_dates
+ON reservation_room (check_in_date, check_out_date)
+WHERE status = 1;
+
+COMMENT ON INDEX idx_reservation_room_active_dates IS 'Date range scans of active reservation rooms (inventory matrix)';
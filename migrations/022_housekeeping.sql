@@ -0,0 +1,49 @@
+-- Migration to add the housekeeping module
+-- Date: 2026-10-16
+-- Description: Extends room.housekeeping_status with the Inspeccionada and FueraDeServicio
+-- states (an out-of-service room is excluded from availability; a dirty one is still sold),
+-- records why a room is out of service, and adds the housekeeping staff and the cleaning tasks
+-- created at check-out or by hand. A room has at most one open task.
+
+ALTER TABLE room
+ADD COLUMN IF NOT EXISTS out_of_service_reason TEXT;
+
+ALTER TABLE room
+DROP CONSTRAINT IF EXISTS chk_room_housekeeping_status;
+
+ALTER TABLE room
+ADD CONSTRAINT chk_room_housekeeping_status
+CHECK (housekeeping_status IN ('Limpia', 'Sucia', 'Inspeccionada', 'FueraDeServicio'));
+
+CREATE TABLE IF NOT EXISTS housekeeping_staff (
+    staff_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS housekeeping_task (
+    task_id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL REFERENCES room(room_id) ON DELETE CASCADE,
+    reservation_id INTEGER REFERENCES reservation(reservation_id) ON DELETE SET NULL,
+    origin VARCHAR(20) NOT NULL CHECK (origin IN ('Salida', 'Manual')),
+    status VARCHAR(20) NOT NULL DEFAULT 'Pendiente'
+        CHECK (status IN ('Pendiente', 'EnCurso', 'Terminada', 'Cancelada')),
+    staff_id INTEGER REFERENCES housekeeping_staff(staff_id) ON DELETE SET NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_housekeeping_task_open_room
+ON housekeeping_task(room_id)
+WHERE status IN ('Pendiente', 'EnCurso');
+
+CREATE INDEX IF NOT EXISTS idx_housekeeping_task_staff
+ON housekeeping_task(staff_id, status);
+
+COMMENT ON COLUMN room.housekeeping_status IS 'Cleaning status of the physical room: Limpia, Sucia, Inspeccionada, FueraDeServicio';
+COMMENT ON COLUMN room.out_of_service_reason IS 'Why the room is out of service; NULL unless housekeeping_status = FueraDeServicio';
+COMMENT ON TABLE housekeeping_staff IS 'Staff members cleaning tasks can be assigned to';
+COMMENT ON TABLE housekeeping_task IS 'Room cleaning tasks, created at check-out (Salida) or by staff (Manual)';
+COMMENT ON COLUMN housekeeping_task.reservation_id IS 'Reservation whose check-out created the task';